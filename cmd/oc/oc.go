@@ -5,11 +5,8 @@ import (
 	"path/filepath"
 	"runtime"
 
-	"github.com/spf13/pflag"
-
 	"github.com/openshift/library-go/pkg/serviceability"
 	kcli "k8s.io/component-base/cli"
-	"k8s.io/component-base/logs"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 
@@ -21,12 +18,6 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 )
 
-func injectLoglevelFlag(flags *pflag.FlagSet) {
-	if vFlag := flags.Lookup("v"); vFlag != nil {
-		flags.Var(&flagValueWrapper{vFlag.Value}, "loglevel", "Set the level of log output (0-10)")
-	}
-}
-
 func main() {
 	defer serviceability.BehaviorOnPanic(os.Getenv("OPENSHIFT_ON_PANIC"), version.Get())()
 	defer serviceability.Profile(os.Getenv("OPENSHIFT_PROFILE")).Stop()
@@ -46,8 +37,7 @@ func main() {
 
 	basename := filepath.Base(os.Args[0])
 	command := cli.CommandFor(basename)
-	logs.AddFlags(command.PersistentFlags())
-	injectLoglevelFlag(command.PersistentFlags())
+	cli.AddLogFlags(command.PersistentFlags())
 	if err := kcli.RunNoErrOutput(command); err != nil {
 		// Pretty-print the error and exit with an error.
 		kcmdutil.CheckErr(err)
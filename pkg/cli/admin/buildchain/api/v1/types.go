@@ -0,0 +1,213 @@
+// Package v1 holds the types of the v1 json and ndjson outputs of
+// 'oc adm build-chain', for the tools reading them. The v1 output only ever
+// gains optional fields: fields are never removed, renamed or retyped, and
+// new fields are never required, so that the outputs of older versions of
+// oc still decode into these types.
+//
+// build-chain converts what it describes into these types before writing
+// them, so that the output doesn't change along with the types of the
+// describer: a field only reaches the output once it is added here.
+package v1
+
+import "time"
+
+// Version is the version of the outputs described by these types.
+const Version = "v1"
+
+// ChainOutput is a build chain, the json output.
+type ChainOutput struct {
+	// Root is the ID of the image stream tag the chain was computed for.
+	Root string `json:"root,omitempty"`
+	// Roots are the IDs of the image stream tags a merged chain was computed
+	// for.
+	Roots []string    `json:"roots,omitempty"`
+	Nodes []ChainNode `json:"nodes"`
+	Edges []ChainEdge `json:"edges"`
+	// Provenance records how the chain was computed.
+	Provenance *ChainProvenance `json:"provenance,omitempty"`
+	// Warnings are the problems found in the chain, identified by stable
+	// codes.
+	Warnings []ChainWarning `json:"warnings,omitempty"`
+}
+
+// ChainNode is an image stream tag, a build config, a deployment config or an
+// image of an external registry taking part in a build chain. External images
+// have no namespace, their name is their pull spec.
+type ChainNode struct {
+	// ID uniquely identifies the node in the chain, edges refer to nodes by ID.
+	ID string `json:"id"`
+	// Kind is ImageStreamTag, ImageStream, BuildConfig, DeploymentConfig or
+	// DockerImageReference.
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Environments are the values of the environment label of the running
+	// deployment configs an image stream tag triggers.
+	Environments []string `json:"environments,omitempty"`
+	// Truncated is set when dependencies of the node were left out of the
+	// chain because of the maximum depth.
+	Truncated bool `json:"truncated,omitempty"`
+	// Metadata is what --enrich-command attached to the node.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Vulnerabilities are the known vulnerabilities of the image of an image
+	// stream tag, with --show-vulnerabilities.
+	Vulnerabilities *VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+	// Image is the image an image stream tag points to, with
+	// --show-image-details.
+	Image *ImageDetails `json:"image,omitempty"`
+	// Custom is set on build configs using the Custom strategy, whose input
+	// image is the builder image running their builds.
+	Custom bool `json:"custom,omitempty"`
+	// Cluster is the kubeconfig context of the cluster the node was found
+	// in, with --contexts.
+	Cluster string `json:"cluster,omitempty"`
+	// Aliases are the image stream tags of every cluster pointing to the
+	// image of an image stream tag, with --contexts.
+	Aliases []TagAlias `json:"aliases,omitempty"`
+}
+
+// TagAlias is an image stream tag of a cluster pointing to the image of a
+// node merged across clusters.
+type TagAlias struct {
+	// Cluster is the kubeconfig context of the cluster of the image stream
+	// tag.
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// VulnerabilityCounts are the numbers of known vulnerabilities of an image by
+// severity.
+type VulnerabilityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+// ImageDetails describe the image an image stream tag points to.
+type ImageDetails struct {
+	// Digest is the digest of the image, e.g. sha256:<hex>.
+	Digest string `json:"digest"`
+	// Created is when the tag was last updated to point to the image.
+	Created time.Time `json:"created"`
+	// Size is the size of the image in bytes, zero when unknown.
+	Size int64 `json:"size,omitempty"`
+}
+
+// ChainEdge is a dependency between two nodes of a build chain.
+type ChainEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kinds []string `json:"kinds"`
+	// Tag is the image stream tag the dependency goes through.
+	Tag string `json:"tag,omitempty"`
+	// BuildConfig describes the build config the dependency leads to or
+	// comes from. It is left out of anonymized output.
+	BuildConfig *EdgeBuildConfig `json:"buildConfig,omitempty"`
+	// Cycle is set when the dependency is part of a cycle between build
+	// configs.
+	Cycle bool `json:"cycle,omitempty"`
+	// CrossNamespace is set, with --flag-cross-namespace, on dependencies
+	// between nodes of different namespaces.
+	CrossNamespace bool `json:"crossNamespace,omitempty"`
+}
+
+// EdgeBuildConfig describes the build config a dependency leads to: how it
+// builds, from which repository and where it pushes to.
+type EdgeBuildConfig struct {
+	Strategy string `json:"strategy"`
+	GitURI   string `json:"gitURI,omitempty"`
+	GitRef   string `json:"gitRef,omitempty"`
+	// OutputKind is ImageStreamTag, ImageStreamImage or DockerImage.
+	OutputKind string `json:"outputKind,omitempty"`
+	// Output is the namespace/name of the image stream tag or image, or the
+	// pull spec of the image, the build pushes to.
+	Output string `json:"output,omitempty"`
+}
+
+// ChainRecord is a node of a build chain along with the dependencies leading
+// from it, a line of the ndjson output.
+type ChainRecord struct {
+	Node ChainNode `json:"node"`
+	// Root is set on the image stream tags the chain was computed for.
+	Root  bool        `json:"root,omitempty"`
+	Edges []ChainEdge `json:"edges"`
+}
+
+// ChainGroups is a build chain aggregated by the value of a label of its
+// build configs, the json output with --group-by-label.
+type ChainGroups struct {
+	Label        string            `json:"label"`
+	Groups       []string          `json:"groups"`
+	Dependencies []GroupDependency `json:"dependencies"`
+	// Provenance records how the groups were computed.
+	Provenance *ChainProvenance `json:"provenance,omitempty"`
+}
+
+// GroupDependency counts the build configs of group To depending on images
+// built by build configs of group From.
+type GroupDependency struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// ChainProvenance records how a build chain was computed.
+type ChainProvenance struct {
+	// ClientVersion is the version of oc that computed the chain.
+	ClientVersion string `json:"clientVersion"`
+	// ServerVersion is the version of the cluster, when it could be read.
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// User is the user that computed the chain, when it could be read. It is
+	// left out of anonymized output.
+	User string `json:"user,omitempty"`
+	// Namespaces are the namespaces the build configs were looked up in.
+	Namespaces []string `json:"namespaces"`
+	// AllNamespaces is set when the namespaces were listed with --all.
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+	// Flags are the flags set on the command line, as --name=value. Only
+	// their names are kept in anonymized output.
+	Flags []string `json:"flags,omitempty"`
+	// ContentHash is the sha256 of the json output without its provenance.
+	ContentHash string `json:"contentHash"`
+}
+
+// WarningCode identifies the kind of a ChainWarning.
+type WarningCode string
+
+const (
+	// WarningCycleDetected is reported for the cycles between the build
+	// configs of a chain.
+	WarningCycleDetected WarningCode = "CYCLE_DETECTED"
+	// WarningMissingOutputStream is reported for the build configs pushing
+	// to an image stream tag whose image stream doesn't exist.
+	WarningMissingOutputStream WarningCode = "MISSING_OUTPUT_STREAM"
+	// WarningMalformedTrigger is reported for the image change triggers that
+	// can't follow an image stream tag.
+	WarningMalformedTrigger WarningCode = "MALFORMED_TRIGGER"
+	// WarningPermissionDeniedNamespace is reported for the namespaces whose
+	// build configs can't be listed.
+	WarningPermissionDeniedNamespace WarningCode = "PERMISSION_DENIED_NAMESPACE"
+	// WarningNamespaceUnavailable is reported for the namespaces left out
+	// because of any other error.
+	WarningNamespaceUnavailable WarningCode = "NAMESPACE_UNAVAILABLE"
+	// WarningMissingTag is reported for the references to image stream tags
+	// without a tag, which are assumed to be to latest.
+	WarningMissingTag WarningCode = "MISSING_TAG"
+)
+
+// ChainWarning is a problem found while describing a build chain.
+type ChainWarning struct {
+	Code WarningCode `json:"code"`
+	// Namespace is the namespace the problem was found in, if any.
+	Namespace string `json:"namespace,omitempty"`
+	// BuildConfig is the name of the build config the problem was found in,
+	// if any.
+	BuildConfig string `json:"buildConfig,omitempty"`
+	// Message describes the problem. It is left out of anonymized output.
+	Message string `json:"message,omitempty"`
+	// Cluster is the kubeconfig context of the cluster the problem was found
+	// in, with --contexts.
+	Cluster string `json:"cluster,omitempty"`
+}
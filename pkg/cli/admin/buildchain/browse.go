@@ -0,0 +1,364 @@
+package buildchain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	kterm "k8s.io/kubectl/pkg/util/term"
+
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+const (
+	// browseHelp is the last line of the --interactive browser.
+	browseHelp = "↑/↓ move  →/enter expand  ← collapse  b build config  q quit"
+	// browseHeight is the number of lines of the browser when the height of
+	// the terminal can't be read.
+	browseHeight = 24
+)
+
+// treeNode is a node of the dependency tree browsed with --interactive. The
+// same node of the chain can appear several times in the tree, once for
+// every path leading to it from the root.
+type treeNode struct {
+	id       string
+	label    string
+	depth    int
+	parent   *treeNode
+	children []*treeNode
+	expanded bool
+	// cycle is set when the node already appears on the path from the root,
+	// its dependencies aren't shown again.
+	cycle bool
+}
+
+// browser holds the state of the --interactive browser: the trees of the
+// roots of the chain and the node under the cursor.
+type browser struct {
+	chain  *describe.ChainOutput
+	nodes  map[string]describe.ChainNode
+	next   map[string][]string
+	bcs    map[string]*describe.EdgeBuildConfig
+	roots  []*treeNode
+	cursor *treeNode
+	// namespaced is set when the chain spans several namespaces, which the
+	// labels then show.
+	namespaced bool
+	// height is the number of lines the browser is drawn on.
+	height int
+}
+
+// newBrowser returns a browser of chain with its roots expanded, following
+// the dependants of the nodes, or their dependencies when reverse is set.
+func newBrowser(chain *describe.ChainOutput, reverse bool) *browser {
+	b := &browser{
+		chain:  chain,
+		nodes:  map[string]describe.ChainNode{},
+		next:   map[string][]string{},
+		bcs:    map[string]*describe.EdgeBuildConfig{},
+		height: browseHeight,
+	}
+	for _, node := range chain.Nodes {
+		b.nodes[node.ID] = node
+		b.namespaced = b.namespaced || node.Namespace != chain.Nodes[0].Namespace
+	}
+	for _, e := range chain.Edges {
+		from, to := e.From, e.To
+		if reverse {
+			from, to = to, from
+		}
+		b.next[from] = append(b.next[from], to)
+		if e.BuildConfig != nil {
+			bc := e.To
+			if b.nodes[e.From].Kind == "BuildConfig" {
+				bc = e.From
+			}
+			b.bcs[bc] = e.BuildConfig
+		}
+	}
+	for id := range b.next {
+		sort.Slice(b.next[id], func(i, j int) bool { return b.label(b.next[id][i]) < b.label(b.next[id][j]) })
+	}
+
+	roots := chain.Roots
+	if len(chain.Root) > 0 {
+		roots = []string{chain.Root}
+	}
+	for _, id := range roots {
+		root := &treeNode{id: id, label: b.label(id)}
+		b.expand(root)
+		b.roots = append(b.roots, root)
+	}
+	if len(b.roots) > 0 {
+		b.cursor = b.roots[0]
+	}
+	return b
+}
+
+// label returns the label of the node of the chain identified by id, with
+// its namespace when the chain spans several namespaces.
+func (b *browser) label(id string) string {
+	node, ok := b.nodes[id]
+	if !ok {
+		return id
+	}
+	kind := map[string]string{"ImageStreamTag": "istag", "BuildConfig": "bc", "DeploymentConfig": "dc"}[node.Kind]
+	if len(kind) == 0 {
+		kind = strings.ToLower(node.Kind)
+	}
+	label := kind + "/" + node.Name
+	if b.namespaced {
+		label = fmt.Sprintf("<%s %s>", node.Namespace, label)
+	}
+	return label
+}
+
+// expand adds the children of node, unless it was already expanded once or
+// closes a cycle, and shows them.
+func (b *browser) expand(node *treeNode) {
+	if node.cycle {
+		return
+	}
+	if node.children == nil {
+		node.children = []*treeNode{}
+		for _, id := range b.next[node.id] {
+			child := &treeNode{id: id, label: b.label(id), depth: node.depth + 1, parent: node}
+			for ancestor := node; ancestor != nil; ancestor = ancestor.parent {
+				if ancestor.id == id {
+					child.cycle = true
+				}
+			}
+			node.children = append(node.children, child)
+		}
+	}
+	node.expanded = true
+}
+
+// visible returns the nodes shown by the browser, in order.
+func (b *browser) visible() []*treeNode {
+	nodes := []*treeNode{}
+	var walk func(*treeNode)
+	walk = func(node *treeNode) {
+		nodes = append(nodes, node)
+		if node.expanded {
+			for _, child := range node.children {
+				walk(child)
+			}
+		}
+	}
+	for _, root := range b.roots {
+		walk(root)
+	}
+	return nodes
+}
+
+// position returns the index of the cursor in nodes.
+func (b *browser) position(nodes []*treeNode) int {
+	for i, node := range nodes {
+		if node == b.cursor {
+			return i
+		}
+	}
+	return 0
+}
+
+// handle updates the browser for key and returns whether it must be closed.
+func (b *browser) handle(key string) bool {
+	if b.cursor == nil {
+		return true
+	}
+	nodes := b.visible()
+	position := b.position(nodes)
+	switch key {
+	case "q", "\x03", "\x04":
+		return true
+	case "up", "k":
+		if position > 0 {
+			b.cursor = nodes[position-1]
+		}
+	case "down", "j":
+		if position < len(nodes)-1 {
+			b.cursor = nodes[position+1]
+		}
+	case "right", "l", "\r", "\n":
+		if b.cursor.expanded && len(b.cursor.children) > 0 {
+			b.cursor = b.cursor.children[0]
+		} else {
+			b.expand(b.cursor)
+		}
+	case "left", "h":
+		if b.cursor.expanded && len(b.cursor.children) > 0 {
+			b.cursor.expanded = false
+		} else if b.cursor.parent != nil {
+			b.cursor = b.cursor.parent
+		}
+	case "b":
+		b.jumpToBuildConfig()
+	}
+	return false
+}
+
+// jumpToBuildConfig moves the cursor to the build config that outputs the
+// image stream tag under the cursor, which is its parent in the tree of
+// dependants or one of its children in the tree of dependencies.
+func (b *browser) jumpToBuildConfig() {
+	producer := ""
+	for _, e := range b.chain.Edges {
+		if e.To == b.cursor.id && b.nodes[e.From].Kind == "BuildConfig" {
+			producer = e.From
+			break
+		}
+	}
+	if len(producer) == 0 {
+		return
+	}
+	if b.cursor.parent != nil && b.cursor.parent.id == producer {
+		b.cursor = b.cursor.parent
+		return
+	}
+	b.expand(b.cursor)
+	for _, child := range b.cursor.children {
+		if child.id == producer {
+			b.cursor = child
+			return
+		}
+	}
+}
+
+// render draws the nodes around the cursor followed by the details of the
+// node under the cursor and the help line.
+func (b *browser) render(w io.Writer) {
+	nodes := b.visible()
+	position := b.position(nodes)
+	rows := b.height - 2
+	if rows < 1 {
+		rows = 1
+	}
+	first := 0
+	if position >= rows {
+		first = position - rows + 1
+	}
+	last := first + rows
+	if last > len(nodes) {
+		last = len(nodes)
+	}
+
+	lines := []string{}
+	for _, node := range nodes[first:last] {
+		marker := " "
+		switch {
+		case node.cycle:
+			marker = "↺"
+		case len(b.next[node.id]) == 0:
+		case node.expanded:
+			marker = "▾"
+		default:
+			marker = "▸"
+		}
+		line := strings.Repeat("  ", node.depth) + marker + " " + node.label
+		if node == b.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) < rows {
+		lines = append(lines, "")
+	}
+	lines = append(lines, b.details(), browseHelp)
+	// the terminal is in raw mode, lines must return to the first column
+	fmt.Fprint(w, "\x1b[H\x1b[2J"+strings.Join(lines, "\r\n"))
+}
+
+// details describes the node under the cursor: how a build config builds, or
+// the environments an image stream tag runs in.
+func (b *browser) details() string {
+	if b.cursor == nil {
+		return ""
+	}
+	node := b.nodes[b.cursor.id]
+	details := []string{b.cursor.id}
+	if bc := b.bcs[b.cursor.id]; bc != nil {
+		details = append(details, bc.Strategy+" strategy")
+		if len(bc.GitURI) > 0 {
+			details = append(details, "from "+bc.GitURI)
+		}
+		if len(bc.Output) > 0 {
+			details = append(details, "pushes to "+bc.Output)
+		}
+	}
+	if len(node.Environments) > 0 {
+		details = append(details, "runs in "+strings.Join(node.Environments, ", "))
+	}
+	if b.cursor.cycle {
+		details = append(details, "already shown above, closes a cycle")
+	}
+	return strings.Join(details, ", ")
+}
+
+// readKey reads a key, translating the escape sequences of the arrow keys.
+func readKey(r *bufio.Reader) (string, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if c != '\x1b' || r.Buffered() < 2 {
+		return string(c), nil
+	}
+	sequence := make([]byte, 2)
+	if _, err := io.ReadFull(r, sequence); err != nil {
+		return "", err
+	}
+	switch string(sequence) {
+	case "[A":
+		return "up", nil
+	case "[B":
+		return "down", nil
+	case "[C":
+		return "right", nil
+	case "[D":
+		return "left", nil
+	}
+	return "", nil
+}
+
+// browse opens the --interactive browser on output, the build chain in json,
+// until it is closed. The terminal of the standard input is switched to raw
+// mode meanwhile so that keys are read as they are pressed.
+func (o *BuildChainOptions) browse(output string) error {
+	chain := &describe.ChainOutput{}
+	if err := json.Unmarshal([]byte(output), chain); err != nil {
+		return fmt.Errorf("unable to read the build chain: %v", err)
+	}
+	b := newBrowser(chain, o.reverse)
+	if file, ok := o.Out.(*os.File); ok && kterm.IsTerminal(file) {
+		if size := kterm.GetSize(file.Fd()); size != nil && size.Height > 0 {
+			b.height = int(size.Height)
+		}
+	}
+
+	tty := kterm.TTY{In: o.In, Out: o.Out, Raw: true}
+	return tty.Safe(func() error {
+		r := bufio.NewReader(o.In)
+		for {
+			b.render(o.Out)
+			key, err := readKey(r)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if b.handle(key) {
+				break
+			}
+		}
+		// leave the last screen on the terminal
+		fmt.Fprint(o.Out, "\r\n")
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package buildchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+func newBrowseChain() *describe.ChainOutput {
+	node := func(kind, name string) describe.ChainNode {
+		return describe.ChainNode{ID: kind + "|test/" + name, Kind: kind, Namespace: "test", Name: name}
+	}
+	edge := func(from, to string) describe.ChainEdge {
+		return describe.ChainEdge{From: from, To: to}
+	}
+	return &describe.ChainOutput{
+		Root: "ImageStreamTag|test/base:latest",
+		Nodes: []describe.ChainNode{
+			node("ImageStreamTag", "base:latest"),
+			node("BuildConfig", "app"),
+			node("ImageStreamTag", "app:latest"),
+			node("BuildConfig", "web"),
+			node("ImageStreamTag", "web:latest"),
+		},
+		Edges: []describe.ChainEdge{
+			edge("ImageStreamTag|test/base:latest", "BuildConfig|test/app"),
+			{
+				From:        "BuildConfig|test/app",
+				To:          "ImageStreamTag|test/app:latest",
+				BuildConfig: &describe.EdgeBuildConfig{Strategy: "Docker", GitURI: "https://github.com/openshift/app", Output: "test/app:latest"},
+			},
+			edge("ImageStreamTag|test/app:latest", "BuildConfig|test/web"),
+			edge("BuildConfig|test/web", "ImageStreamTag|test/web:latest"),
+			// web is rebuilt from its own output
+			edge("ImageStreamTag|test/web:latest", "BuildConfig|test/web"),
+		},
+	}
+}
+
+func visibleLabels(b *browser) []string {
+	labels := []string{}
+	for _, node := range b.visible() {
+		labels = append(labels, strings.Repeat("  ", node.depth)+node.label)
+	}
+	return labels
+}
+
+func TestBrowser(t *testing.T) {
+	b := newBrowser(newBrowseChain(), false)
+	if expected := []string{"istag/base:latest", "  bc/app"}; !reflect.DeepEqual(visibleLabels(b), expected) {
+		t.Errorf("expected the root expanded, got %v", visibleLabels(b))
+	}
+
+	for _, key := range []string{"down", "right", "right", "l", "right", "right", "right", "right", "right"} {
+		if b.handle(key) {
+			t.Fatalf("unexpected quit on %q", key)
+		}
+	}
+	expected := []string{
+		"istag/base:latest",
+		"  bc/app",
+		"    istag/app:latest",
+		"      bc/web",
+		"        istag/web:latest",
+		"          bc/web",
+	}
+	if !reflect.DeepEqual(visibleLabels(b), expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(visibleLabels(b), "\n"))
+	}
+	if !b.cursor.cycle || b.cursor.id != "BuildConfig|test/web" {
+		t.Errorf("expected the cursor on the build config closing the cycle, got %#v", b.cursor)
+	}
+	if b.handle("right"); len(b.cursor.children) > 0 {
+		t.Errorf("expected the cycle not to be expanded again")
+	}
+
+	// from istag/app:latest, b jumps to the build config outputting it
+	for _, key := range []string{"up", "up", "up"} {
+		b.handle(key)
+	}
+	if b.cursor.id != "ImageStreamTag|test/app:latest" {
+		t.Fatalf("expected the cursor on istag/app:latest, got %s", b.cursor.id)
+	}
+	b.handle("b")
+	if b.cursor.id != "BuildConfig|test/app" {
+		t.Errorf("expected b to jump to bc/app, got %s", b.cursor.id)
+	}
+	if details := b.details(); !strings.Contains(details, "Docker strategy, from https://github.com/openshift/app, pushes to test/app:latest") {
+		t.Errorf("expected the details of bc/app, got %q", details)
+	}
+
+	b.handle("left")
+	if expected := []string{"istag/base:latest", "  bc/app"}; !reflect.DeepEqual(visibleLabels(b), expected) {
+		t.Errorf("expected bc/app collapsed, got %v", visibleLabels(b))
+	}
+	if !b.handle("q") {
+		t.Errorf("expected q to quit")
+	}
+
+	reverse := newBrowser(&describe.ChainOutput{
+		Root:  "ImageStreamTag|test/app:latest",
+		Nodes: newBrowseChain().Nodes,
+		Edges: newBrowseChain().Edges,
+	}, true)
+	reverse.handle("b")
+	if reverse.cursor.id != "BuildConfig|test/app" {
+		t.Errorf("expected b to jump to bc/app among the dependencies, got %s", reverse.cursor.id)
+	}
+}
+
+func TestBrowse(t *testing.T) {
+	data, err := json.Marshal(newBrowseChain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	o := &BuildChainOptions{IOStreams: genericiooptions.IOStreams{In: strings.NewReader("\x1b[Bq"), Out: out}}
+	if err := o.browse(string(data)); err != nil {
+		t.Fatal(err)
+	}
+	screens := strings.Split(out.String(), "\x1b[H\x1b[2J")
+	if len(screens) != 3 {
+		t.Fatalf("expected 2 screens, got %d:\n%q", len(screens)-1, out.String())
+	}
+	if !strings.Contains(screens[2], "\x1b[7m  ▸ bc/app\x1b[0m") {
+		t.Errorf("expected the cursor on bc/app after the down arrow, got:\n%q", screens[2])
+	}
+}
@@ -1,27 +1,43 @@
 package buildchain
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/completion"
 	"k8s.io/kubectl/pkg/util/templates"
+	kterm "k8s.io/kubectl/pkg/util/term"
 
 	"github.com/openshift/api/image"
+	imagev1 "github.com/openshift/api/image/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
 	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
 	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	projectv1client "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
 	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/client/cache"
+	"github.com/openshift/oc/pkg/client/paging"
 	osutil "github.com/openshift/oc/pkg/helpers/cmd"
 	"github.com/openshift/oc/pkg/helpers/describe"
 	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
@@ -30,13 +46,186 @@ import (
 // BuildChainRecommendedCommandName is the recommended command name
 const BuildChainRecommendedCommandName = "build-chain"
 
+// defaultConcurrency is the number of namespaces listed at once by default,
+// enough to scan hundreds of them quickly without flooding the API server.
+const defaultConcurrency = 10
+
 var (
 	buildChainLong = templates.LongDesc(`
 		Output the inputs and dependencies of your builds.
 
-		Supported formats for the generated graph are dot and a human-readable output.
-		Tag and namespace are optional and if they are not specified, 'latest' and the
-		default namespace will be used respectively.
+		Supported formats for the generated graph are dot, json, ndjson, graphml, ascii, spdx,
+		mermaid, list, template and a human-readable output. The ndjson output has a line per node,
+		holding the node and its dependencies, for streaming consumers and line-based diffs. The
+		graphml output can be imported into yEd, Gephi and other graph analysis tools, with the
+		namespace, name and tag of the nodes and the build config of the edges as attributes.
+		The ascii output draws the graph in the terminal, shortening the labels to fit its width
+		or --max-width. The spdx output is an SPDX document made of relationships only, for
+		compliance tooling: images are GENERATED_FROM the images they are built from, and build
+		configs are BUILD_TOOL_OF the images they push to. The mermaid output is a Mermaid
+		flowchart, rendered by markdown viewers and wikis. The list output prints the image
+		stream tags in build order, one namespace/name:tag per line, every image stream tag
+		after the ones it is built from. The summary output prints metrics of the complexity of
+		the chain: its number of nodes and edges, its maximum depth and the number of configs
+		built or deployed from every image stream tag. The template output is a Template of the
+		build configs of the chain and of the image streams of their namespaces, parameterized
+		by those namespaces, to replicate the pipeline into other projects or clusters with 'oc
+		process'; the image streams are created empty, so the images no build config of the
+		chain pushes have to be imported again. Tag and namespace are optional and if
+		they are not specified, 'latest' and the default namespace will be used respectively.
+
+		Build configs using the Custom strategy are marked as custom in every output: the image
+		they are built from is the builder image running their builds rather than a base image
+		of the images they push.
+
+		Problems found while describing the chains are printed as warnings. The json output
+		also lists them, and the summary output counts them, by a stable code for automation:
+		CYCLE_DETECTED, MISSING_OUTPUT_STREAM for build configs pushing to image streams that
+		don't exist, in the namespaces whose image streams are listed, MALFORMED_TRIGGER for
+		image change triggers left out of the chains, PERMISSION_DENIED_NAMESPACE and
+		NAMESPACE_UNAVAILABLE for namespaces that couldn't be loaded, and MISSING_TAG for
+		references to image stream tags without a tag.
+
+		Several image stream tags can be given as arguments, e.g. the handful of base images
+		a team owns. Their chains are described in turn, or all of them in a single graph with
+		--merge.
+
+		When '-' is given instead of an image stream tag, newline separated image streams
+		or image stream tags are read from the standard input, either as printed by
+		'oc get -o name' or as namespace/name:tag. Every entry is described in turn,
+		or all of them in a single graph with --merge. They can also be read from a file
+		with --roots-file instead, e.g. to report on a curated set of base images.
+
+		The tag of an image stream tag can be a glob, such as 'ruby:3.*', or be replaced by
+		the glob given with --tags, to describe every tag of the image stream matching it,
+		as found in its status.
+
+		Build configs are looked up in the namespace of the image stream tag. Several
+		comma separated namespaces can be given with --namespace to look them up in
+		all of those, the image stream tag being in the first one unless given as
+		namespace/name:tag. With --all, they are looked up in every namespace but the
+		ones given with --exclude-namespace, e.g. to keep the build configs of the
+		infrastructure out of the dependency trees of applications.
+
+		The dependencies of an image stream tag are found from the build configs referring
+		to it, so they are described even if it doesn't exist yet. An image stream tag
+		without any dependencies must exist, unless --create-missing-ok is given. Build
+		configs pushing by reference to the integrated registry are linked to the image
+		stream tags they update, when the image streams of the namespaces they push to can be
+		listed.
+
+		Requests failing with transient errors are retried with backoff. Namespaces and image
+		stream tags that still can't be read are left out with a warning so that the rest of
+		the chain is still reported, unless --fail-fast is given.
+
+		With --mine, the chain is limited to the namespaces where you can edit build configs,
+		as told by access reviews, along with the nodes leading to them from the image
+		stream tag, so that you only see the part of the chain you can act on.
+
+		With --flag-cross-namespace, the dependencies between namespaces are marked in the
+		output and counted. With --deny-cross-namespace, build-chain fails when the chain
+		has dependencies between namespaces other than the ones allowed with
+		--allow-cross-namespace, as FROM:TO namespaces where '*' matches any namespace, to
+		enforce the isolation between teams.
+
+		With --critical-path, the longest dependency path of the chain is reported instead of
+		the chain, weighted by the mean duration of the completed builds of its build configs,
+		along with the cumulative duration of the builds up to every build config, or by the
+		number of builds with --critical-path=hops. It tells how long a change of the image
+		stream tag, such as a security fix of a base image, takes at least to be built into
+		every image of the chain.
+
+		With --simulate, the builds that a change of the image stream tag would trigger are
+		reported instead of the chain, in stages: the builds of a stage are triggered at the
+		same time and run in parallel, once the builds of the previous stages pushed the images
+		they are triggered by. It tells how far a new base image goes before it is pushed.
+		With --start, those builds are started, stage by stage, each stage once the builds
+		of the previous one completed, stopping at the first failure.
+
+		With --color-by-status, the build configs of the dot output, and the dependencies
+		through them, are colored by the phase of their latest build: green when it completed,
+		red when it failed and yellow while it is running, for an at-a-glance view of the
+		health of the pipeline.
+
+		With --only-failing, the chain is pruned to its branches with a build config whose
+		latest build failed: the nodes leading to those build configs and the ones depending
+		on them, i.e. what is broken downstream of the image stream tag.
+
+		With --include-deployments, the deployment configs whose image change triggers follow
+		the image stream tags of the chain are added to it, so that it shows everything built
+		and deployed again when the image stream tag changes.
+
+		With --include-external, the images of external registries the build configs of the
+		chain are built from are added to it as terminal nodes, so that it shows the true
+		upstream base images of the chain. Use it with --reverse to walk the chain up to them.
+
+		With --env-label, the image stream tags are annotated with the values of that label
+		on the running deployment configs they trigger, e.g. the environments the images
+		are deployed to.
+
+		With --at-time, or --at-generation of the image stream tag, the build configs and
+		builds created since are left out to show the chain as it was then, and the image
+		the tag pointed to is reported. Build configs changed since are shown as they are
+		now.
+
+		With --selector, only the build configs matching the label selector are part of the
+		chain. The selector is sent to the server, which keeps scans of namespaces with many
+		build configs fast.
+
+		With --watch, the build configs and image streams of the namespaces are watched and the
+		dependency tree is output again every time it changes, until interrupted.
+
+		With --interactive, the dependency tree is browsed in the terminal instead of printed:
+		nodes are expanded and collapsed with the arrow keys and 'b' jumps from an image
+		stream tag to the build config that outputs it, with its strategy, source and output
+		shown at the bottom of the screen.
+
+		With --render, the dot output is laid out by the dot command of graphviz into an
+		svg or png image written to --output-file, which requires graphviz to be installed.
+		Any output can be written to --output-file instead of the standard output, which keeps
+		logs and warnings out of large dumps.
+
+		The json output records its provenance: the versions of oc and of the cluster, the
+		user, the namespaces searched, the flags set and a hash of its content, so that
+		archived chains can be audited and computed again.
+
+		With --show-vulnerabilities, the image stream tags are annotated with the number of
+		known vulnerabilities of their images by severity, as reported by the image scanner at
+		--scanner-url: the image of an image stream tag is requested as
+		GET <scanner-url>/<image digest> and the scanner answers with a json object such as
+		{"critical": 1, "high": 3}, or 404 for images it didn't scan. With --fail-on, build-chain
+		fails when images have vulnerabilities of that severity or worse, listing them in the
+		order to fix them: the most critical first and, among equals, the ones most build configs
+		are built from first.
+
+		With --show-image-details, the image stream tags are annotated with the image they
+		point to: its digest, when the tag was last updated to it and its size, which helps
+		spotting the images that weren't rebuilt since the images they are built from changed.
+
+		With --hide-tags, the chain is rendered at the repository level: the tags of every
+		image stream are merged into a node of the image stream, and so are the dependencies
+		going through them, which keeps the chains of image streams with many tags readable.
+
+		With --contexts, the merged build chain is described in the clusters of several
+		kubeconfig contexts and output as a single json graph. The image stream tags pointing
+		to the same image, as an image promoted from a cluster to another, are a single node
+		identified by the digest of the image, listing the image stream tag of every cluster
+		as an alias, while the build configs and the other nodes tell their cluster.
+
+		With --enrich-command, the command is run for every node of the json and ndjson
+		outputs with the node in json on its standard input. The json object it prints, if
+		any, is added to the metadata of the node, e.g. to attach inventory IDs or
+		vulnerability counts from tools of your own.
+
+		Build chains saved in json can be compared with 'build-chain diff', or with the
+		current build chain with --diff-against, which outputs the nodes and edges added,
+		removed or changed since instead of the chain. The schema of the json output is
+		printed with --print-schema, as a JSON schema or as protocol buffers messages.
+
+		The json and ndjson outputs are versioned, the current version being v1, and stay
+		backward compatible within a version: fields may be added but are never removed,
+		renamed or retyped. Go programs can decode them with the types of
+		github.com/openshift/oc/pkg/cli/admin/buildchain/api/v1.
 	`)
 
 	buildChainExample = templates.Examples(`
@@ -46,118 +235,684 @@ var (
 		# Build the dependency tree for the 'v2' tag in dot format and visualize it via the dot utility
 		oc adm build-chain <image-stream>:v2 -o dot | dot -T svg -o deps.svg
 
+		# Render the dependency tree to an svg image with graphviz
+		oc adm build-chain <image-stream>:v2 --render=svg --output-file=deps.svg
+
+		# Render the dependency tree colored by the status of the latest builds
+		oc adm build-chain <image-stream> --color-by-status --render=svg --output-file=deps.svg
+
+		# Show what is broken downstream of <image-stream>
+		oc adm build-chain <image-stream> --only-failing
+
+		# Browse a large dependency tree in the terminal
+		oc adm build-chain <image-stream> --all --interactive
+
+		# Only follow the build configs of the team=web label
+		oc adm build-chain <image-stream> -l team=web
+
+		# Estimate how long a change of <image-stream> takes to be built into every dependant image
+		oc adm build-chain <image-stream> --critical-path
+
+		# Store the dependency tree with a line per node, to diff it in git
+		oc adm build-chain <image-stream> -o ndjson > chain.ndjson
+
+		# Import the dependency tree into yEd or Gephi
+		oc adm build-chain <image-stream> -o graphml > chain.graphml
+
+		# Draw the dependency tree in the terminal
+		oc adm build-chain <image-stream> -o ascii
+
+		# Output the dependency tree as a Mermaid flowchart to paste in markdown
+		oc adm build-chain <image-stream> -o mermaid
+
+		# List the image stream tags depending on <image-stream> in the order to rebuild them
+		oc adm build-chain <image-stream> -o list
+
+		# Print the size, depth and fan-out of the dependency tree of <image-stream>
+		oc adm build-chain <image-stream> -o summary
+
+		# Save the dependency tree as an SPDX document for compliance tooling
+		oc adm build-chain <image-stream> -o spdx > chain.spdx.json
+
+		# Replicate the build pipeline of <image-stream> into the project staging
+		oc adm build-chain <image-stream> -o template | oc process -f - -p NAMESPACE=staging | oc create -f -
+
+		# Show the builds and the deployments that follow a change of <image-stream>
+		oc adm build-chain <image-stream> --include-deployments
+
+		# Show the images of external registries <image-stream> is ultimately built from
+		oc adm build-chain <image-stream> --reverse --include-external
+
+		# Show the environments, from the 'environment' label of the deployment configs, the images run in
+		oc adm build-chain <image-stream> --env-label=environment
+
 		# Build the dependency tree across all namespaces for the specified image stream tag found in the 'test' namespace
 		oc adm build-chain <image-stream> -n test --all
+
+		# Build the dependency tree across all namespaces but the ones of the platform
+		oc adm build-chain <image-stream> -n test --all --exclude-namespace=openshift,default
+
+		# Build the dependency tree in dot format with edges weighted by the builds run over the last day
+		oc adm build-chain <image-stream> -o dot --weight-by-activity --since=24h
+
+		# Summarize the dependencies between the teams owning the build configs across all namespaces
+		oc adm build-chain <image-stream> --all --group-by-label=team
+
+		# Show what changed in the dependency tree since it was saved for the last release
+		oc adm build-chain <image-stream> --diff-against=release-4.15.json
+
+		# Fail when images of the dependency tree have critical vulnerabilities, e.g. in CI
+		oc adm build-chain <image-stream> --show-vulnerabilities --scanner-url=https://scanner.example.com/api/v1/vulnerabilities --fail-on=critical
+
+		# Spot the images that weren't rebuilt since their base image was updated
+		oc adm build-chain <image-stream> --show-image-details
+
+		# Build the dependency tree between image streams, regardless of their tags, in dot format
+		oc adm build-chain <image-stream> -o dot --hide-tags
+
+		# Attach the metadata printed by ./cmdb-lookup.sh for every node to the json output
+		oc adm build-chain <image-stream> -o json --enrich-command=./cmdb-lookup.sh
+
+		# Build the dependency tree in dot format without revealing project and image names
+		oc adm build-chain <image-stream> -o dot --anonymize
+
+		# Build a single dependency graph for the base images ruby:3.1 and python:3.11
+		oc adm build-chain ruby:3.1 python:3.11 --merge -o dot
+
+		# Build a single dependency graph for all the image streams labeled 'team=web'
+		oc get imagestreams -l team=web -o name | oc adm build-chain - --merge -o dot
+
+		# Build a single dependency graph for <image-stream> across the staging and production clusters
+		oc adm build-chain <image-stream> --merge --contexts=staging,production -o json
+
+		# Build a single dependency graph for the release tags of <image-stream>
+		oc adm build-chain <image-stream> --tags='release-*' --merge
+
+		# Build the dependency trees of the namespace/name:tag image stream tags listed in repos.txt
+		oc adm build-chain --roots-file=repos.txt --all
+
+		# Build the dependency tree as a clickable SVG linking to the web console
+		oc adm build-chain <image-stream> -o dot --link-base=https://console.example.com | dot -T svg -o deps.svg
+
+		# Build the dependency tree for <image-stream> in 'web' across the 'web' and 'base' namespaces
+		oc adm build-chain <image-stream> -n web,base
+
+		# Fail if the build chain depends on namespaces other than 'base', or leads to other namespaces
+		oc adm build-chain <image-stream> --all --deny-cross-namespace --allow-cross-namespace='base:*'
+
+		# Build the dependency tree across all namespaces, only showing the parts you can edit
+		oc adm build-chain <image-stream> --all --mine
+
+		# Build the dependency tree across all namespaces, keeping at most 500 nodes
+		oc adm build-chain <image-stream> --all --max-nodes=500
+
+		# Build the dependency tree of <image-stream> down to the images two builds away
+		oc adm build-chain <image-stream> --max-depth=4
+
+		# List the builds a new <image-stream> image would trigger, and their order
+		oc adm build-chain <image-stream> --simulate
+
+		# Rebuild everything built from <image-stream>, in dependency order
+		oc adm build-chain <image-stream> --start
+
+		# Build the dependency tree as it was when the 'latest' tag of <image-stream> was at generation 4
+		oc adm build-chain <image-stream> --at-generation=4
 	`)
 )
 
 // BuildChainOptions contains all the options needed for build-chain
 type BuildChainOptions struct {
-	name string
+	entries   []chainEntry
+	merge     bool
+	rootsFile string
+	tags      string
+	contexts  []string
+	// clusters are the clusters of --contexts, whose build chains are
+	// merged. Complete sets them from the kubeconfig when empty.
+	clusters []chainCluster
+
+	defaultNamespace  string
+	namespaces        sets.String
+	allNamespaces     bool
+	excludeNamespaces []string
+	provenance        *describe.ChainProvenance
+	triggerOnly       bool
+	reverse           bool
+
+	weightByActivity bool
+	since            time.Duration
+	splitByTag       bool
+	groupByLabel     string
+	criticalPath     string
+	simulate         bool
+	colorByStatus    bool
+	onlyFailing      bool
+	anonymize        bool
+	linkBase         string
+	labelMaxLength   int
+	wrapLabels       bool
+	maxNodes         int
+	maxDepth         int
+	includeManual    bool
+	strict           bool
+	failFast         bool
+	createMissingOK  bool
+	mine             bool
+
+	flagCrossNamespace  bool
+	denyCrossNamespace  bool
+	allowCrossNamespace []string
+	maxWidth            int
+	envLabel            string
+	includeDeployments  bool
+	includeExternal     bool
+	selector            string
+	atTime              string
+	atGeneration        int64
+	utc                 bool
+	at                  *time.Time
+	enrichCommand       string
+	showVulnerabilities bool
+	scannerURL          string
+	failOn              string
+	showImageDetails    bool
+	hideTags            bool
+
+	output      string
+	outputFile  string
+	render      string
+	diffAgainst string
+	interactive bool
+	start       bool
+	// startPollInterval is how often --start checks whether the builds it
+	// started completed.
+	startPollInterval time.Duration
+	printSchema       string
+	watch             bool
+	// watchSettle is how long --watch waits for changes to stop coming in
+	// before describing the chain again.
+	watchSettle time.Duration
 
-	defaultNamespace string
-	namespaces       sets.String
-	allNamespaces    bool
-	triggerOnly      bool
-	reverse          bool
+	cacheOptions *cache.Options
+	chunkSize    int64
+	concurrency  int
 
-	output string
+	// BuildConfigs, ImageStreams, Projects and, with --env-label or
+	// --include-deployments, DeploymentConfigs are the clients build-chain reads from. Complete sets
+	// the ones that are nil from the factory.
+	BuildConfigs      describe.BuildConfigLister
+	ImageStreams      ImageStreamGetter
+	Projects          ProjectLister
+	DeploymentConfigs describe.DeploymentConfigLister
+	// AccessReviewer, with --mine, finds the namespaces the current user can
+	// edit.
+	AccessReviewer AccessReviewer
+	// Watcher, with --watch, tells when the chain must be described again.
+	// Complete sets it from the factory when nil.
+	Watcher ChainWatcher
+	// Renderer, with --render, lays out the dot output. Complete sets it to
+	// graphviz when nil.
+	Renderer Renderer
+	// BuildStarter, with --start, starts the builds of the chain. Complete
+	// sets it from the factory when nil.
+	BuildStarter BuildStarter
+	// Enricher, with --enrich-command, attaches metadata to the nodes of the
+	// json outputs. Complete sets it to run the command when nil.
+	Enricher describe.NodeEnricher
+	// Scanner, with --show-vulnerabilities, tells the vulnerabilities of the
+	// images of the chain. Complete sets it to ask --scanner-url when nil.
+	Scanner describe.VulnerabilityScanner
+	// Inspector, with --show-image-details, tells the images of the chain.
+	// Complete sets it to look them up with ImageStreams when nil.
+	Inspector describe.ImageInspector
 
-	buildClient   buildv1client.BuildV1Interface
-	imageClient   imagev1client.ImageV1Interface
-	projectClient projectv1client.ProjectV1Interface
+	genericiooptions.IOStreams
 }
 
-// NewCmdBuildChain implements the OpenShift experimental build-chain command
+// chainEntry is an image stream tag to describe the build chain of.
+type chainEntry struct {
+	namespace string
+	name      string
+}
+
+// NewCmdBuildChain implements the OpenShift build-chain command
 func NewCmdBuildChain(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
 	options := &BuildChainOptions{
-		namespaces: sets.NewString(),
+		namespaces:        sets.NewString(),
+		since:             7 * 24 * time.Hour,
+		cacheOptions:      cache.NewOptions(),
+		chunkSize:         paging.DefaultChunkSize,
+		concurrency:       defaultConcurrency,
+		watchSettle:       time.Second,
+		startPollInterval: 5 * time.Second,
+		IOStreams:         streams,
 	}
 	cmd := &cobra.Command{
-		Use:               "build-chain IMAGESTREAMTAG",
+		Use:               "build-chain (IMAGESTREAMTAG... | - | --roots-file=FILE)",
 		Short:             "Output the inputs and dependencies of your builds",
 		Long:              buildChainLong,
 		Example:           buildChainExample,
 		ValidArgsFunction: completion.ResourceNameCompletionFunc(f, "pod"),
 		Run: func(cmd *cobra.Command, args []string) {
+			if len(options.printSchema) > 0 {
+				kcmdutil.CheckErr(options.PrintSchema())
+				return
+			}
 			kcmdutil.CheckErr(options.Complete(f, cmd, args, streams.Out))
 			kcmdutil.CheckErr(options.Validate())
+			if options.watch {
+				kcmdutil.CheckErr(options.WatchBuildChain(context.TODO()))
+				return
+			}
 			kcmdutil.CheckErr(options.RunBuildChain())
 		},
 	}
 
+	cmd.AddCommand(NewCmdBuildChainDiff(streams))
+
 	cmd.Flags().BoolVar(&options.allNamespaces, "all", false, "If true, build dependency tree for the specified image stream tag across all namespaces")
+	cmd.Flags().StringSliceVar(&options.excludeNamespaces, "exclude-namespace", nil, "Comma separated namespaces to leave out of the dependency tree when --all is set, e.g. openshift,default.")
 	cmd.Flags().BoolVar(&options.triggerOnly, "trigger-only", true, "If true, only include dependencies based on build triggers. If false, include all dependencies.")
+	cmd.Flags().BoolVar(&options.includeManual, "include-manual", false, "If true, include the build configs that use an image without being triggered by it, marking the dependency as manual.")
 	cmd.Flags().BoolVar(&options.reverse, "reverse", false, "If true, show the istags dependencies instead of its dependants.")
-	cmd.Flags().StringVarP(&options.output, "output", "o", "", "Output format of dependency tree")
+	cmd.Flags().BoolVar(&options.weightByActivity, "weight-by-activity", false, "If true, weight the dependencies by the number of builds each build config ran within the --since window.")
+	cmd.Flags().DurationVar(&options.since, "since", options.since, "Window of build activity to consider when --weight-by-activity is set.")
+	cmd.Flags().BoolVar(&options.splitByTag, "split-by-tag", false, "If true, show the image stream tag each dependency goes through.")
+	cmd.Flags().StringVar(&options.groupByLabel, "group-by-label", "", "If set, aggregate build configs by the value of this label and output the dependencies between those groups instead of the tree.")
+	cmd.Flags().StringVar(&options.criticalPath, "critical-path", "", "If set, output the longest dependency path of the chain instead of the tree, weighted by the mean duration of the completed builds or by the number of builds. One of: (duration, hops)")
+	cmd.Flags().Lookup("critical-path").NoOptDefVal = describe.CriticalPathByDuration
+	cmd.Flags().BoolVar(&options.simulate, "simulate", false, "If true, output the builds a change of the image stream tag would trigger, stage by stage, instead of the tree.")
+	cmd.Flags().BoolVar(&options.start, "start", false, "If true, start the builds a change of the image stream tag would trigger, stage by stage, waiting for the builds of a stage to complete before starting the next.")
+	cmd.Flags().BoolVar(&options.colorByStatus, "color-by-status", false, "If true, color the build configs of the dot output by the status of their latest build.")
+	cmd.Flags().BoolVar(&options.onlyFailing, "only-failing", false, "If true, only show the branches of the chain with a build config whose latest build failed.")
+	cmd.Flags().BoolVar(&options.merge, "merge", false, "If true, describe all the image stream tags given as arguments, read from the standard input or from --roots-file in a single output.")
+	cmd.Flags().StringVar(&options.tags, "tags", "", "If set, describe the tags of the image streams matching this glob, e.g. 'release-*', instead of the tag of the image stream tags.")
+	cmd.Flags().StringSliceVar(&options.contexts, "contexts", options.contexts, "If set, describe the build chain in the clusters of these kubeconfig contexts instead of the current one, merged into a single graph where the image stream tags pointing to the same image are a single node. Requires --merge and -o json.")
+	cmd.Flags().StringVar(&options.rootsFile, "roots-file", "", "If set, describe the newline separated image stream tags, as namespace/name:tag, read from this file instead of an argument.")
+	cmd.Flags().BoolVar(&options.anonymize, "anonymize", false, "If true, replace namespaces, names and label values with stable hashes so that the output can be shared.")
+	cmd.Flags().StringVar(&options.linkBase, "link-base", "", "URL of the web console the nodes of the dot output link to, making rendered graphs clickable.")
+	cmd.Flags().IntVar(&options.labelMaxLength, "label-max-length", 0, "If positive, shorten the node labels of the dot output to this many characters. Full names remain available as tooltips and in the json output.")
+	cmd.Flags().BoolVar(&options.wrapLabels, "wrap-labels", false, "If true, split the node labels of the dot output over several lines.")
+	cmd.Flags().StringVarP(&options.selector, "selector", "l", "", "If set, only include the build configs matching this label selector, which the server filters on.")
+	cmd.Flags().BoolVar(&options.includeDeployments, "include-deployments", false, "If true, include the deployment configs triggered by the image stream tags of the chain.")
+	cmd.Flags().BoolVar(&options.includeExternal, "include-external", false, "If true, include the images of external registries the build configs of the chain are built from.")
+	cmd.Flags().StringVar(&options.envLabel, "env-label", "", "If set, annotate the image stream tags with the values of this label on the running deployment configs they trigger.")
+	cmd.Flags().BoolVar(&options.mine, "mine", false, "If true, only show the parts of the chain in namespaces where you can edit build configs, and the nodes leading to them.")
+	cmd.Flags().BoolVar(&options.flagCrossNamespace, "flag-cross-namespace", false, "If true, mark the dependencies between namespaces in the output and warn about how many there are.")
+	cmd.Flags().BoolVar(&options.denyCrossNamespace, "deny-cross-namespace", false, "If true, fail when the chain has dependencies between namespaces not allowed by --allow-cross-namespace.")
+	cmd.Flags().StringSliceVar(&options.allowCrossNamespace, "allow-cross-namespace", nil, "Dependencies between namespaces, as FROM:TO where '*' matches any namespace, allowed by --deny-cross-namespace.")
+	cmd.Flags().BoolVar(&options.createMissingOK, "create-missing-ok", false, "If true, report image stream tags that don't exist yet and don't have any dependencies instead of failing, e.g. before their first import.")
+	cmd.Flags().BoolVar(&options.failFast, "fail-fast", false, "If true, fail when a namespace or an image stream tag can't be read instead of leaving it out with a warning. Transient errors are retried either way.")
+	cmd.Flags().BoolVar(&options.strict, "strict", false, "If true, fail on build configs referring to image stream tags without a tag instead of assuming 'latest' with a warning.")
+	cmd.Flags().StringVar(&options.atTime, "at-time", "", "If set, show the build chain as it was at this RFC3339 time, leaving out the build configs and builds created since.")
+	cmd.Flags().Int64Var(&options.atGeneration, "at-generation", 0, "If positive, show the build chain as it was when the image stream tag was at this generation, leaving out the build configs and builds created since.")
+	cmd.Flags().BoolVar(&options.utc, "utc", false, "If true, report times in UTC instead of the local time zone.")
+	cmd.Flags().BoolVar(&options.showVulnerabilities, "show-vulnerabilities", false, "If true, annotate the image stream tags with the vulnerabilities of their images, by severity, as reported by the image scanner at --scanner-url.")
+	cmd.Flags().StringVar(&options.scannerURL, "scanner-url", "", "URL of the image scanner asked about the vulnerabilities of the images with --show-vulnerabilities.")
+	cmd.Flags().StringVar(&options.failOn, "fail-on", "", "If set with --show-vulnerabilities, fail when images of the chain have vulnerabilities of this severity or worse. One of: (critical, high, medium, low)")
+	cmd.Flags().BoolVar(&options.showImageDetails, "show-image-details", false, "If true, annotate the image stream tags with the digest, update time and size of the image they point to.")
+	cmd.Flags().BoolVar(&options.hideTags, "hide-tags", false, "If true, render image streams instead of their tags, merging the dependencies going through their tags.")
+	cmd.Flags().StringVar(&options.enrichCommand, "enrich-command", "", "If set, run this command for every node of the json or ndjson output, with the node in json on its standard input, and add the json object it prints to the metadata of the node.")
+	cmd.Flags().IntVar(&options.maxDepth, "max-depth", 0, "If positive, leave out the nodes more than this many dependencies away from the image stream tags, marking the nodes the chain continues from as truncated.")
+	cmd.Flags().IntVar(&options.maxNodes, "max-nodes", 0, "If positive, truncate build chains made of more nodes than this to the nodes closest to the image stream tags, and warn about it.")
+	cmd.Flags().StringVarP(&options.output, "output", "o", "", "Output format of dependency tree. One of: (dot, json, ndjson, graphml, ascii, spdx, mermaid, list, summary, template)")
+	cmd.Flags().StringVar(&options.outputFile, "output-file", "", "If set, write the output to this file instead of the standard output.")
+	cmd.Flags().StringVar(&options.diffAgainst, "diff-against", "", "If set, compare the dependency tree with the one saved with -o json in this file and output the nodes and edges added, removed or changed since.")
+	cmd.Flags().BoolVar(&options.interactive, "interactive", false, "If true, browse the dependency tree in the terminal, expanding and collapsing its nodes, instead of printing it.")
+	cmd.Flags().StringVar(&options.render, "render", "", "If set, render the dot output with graphviz into an image written to --output-file. One of: (svg, png)")
+	cmd.Flags().BoolVarP(&options.watch, "watch", "w", false, "If true, keep watching the build configs and image streams and output the dependency tree again every time it changes.")
+	cmd.Flags().IntVar(&options.maxWidth, "max-width", 0, "If positive, shorten the labels of the ascii output so that its lines fit in that many columns. Defaults to the width of the terminal.")
+	options.cacheOptions.AddFlags(cmd.Flags())
+	kcmdutil.AddChunkSizeFlag(cmd, &options.chunkSize)
+	cmd.Flags().IntVar(&options.concurrency, "concurrency", options.concurrency, "Number of namespaces whose objects are listed at once, all of them when 0.")
+	cmd.Flags().StringVar(&options.printSchema, "print-schema", "", "Print the schema of the json output instead of a dependency tree. One of: (json, proto)")
 	return cmd
 }
 
 // Complete completes the required options for build-chain
 func (o *BuildChainOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string, out io.Writer) error {
-	if len(args) != 1 {
+	switch {
+	case len(o.rootsFile) > 0 && len(args) != 0:
+		return kcmdutil.UsageErrorf(cmd, "--roots-file can't be combined with an image stream tag argument")
+	case len(o.rootsFile) == 0 && len(args) == 0:
 		return kcmdutil.UsageErrorf(cmd, "Must pass an image stream tag. If only an image stream name is specified, 'latest' will be used for the tag.")
+	case len(args) > 1 && sets.NewString(args...).Has("-"):
+		return kcmdutil.UsageErrorf(cmd, "'-' can't be combined with other image stream tag arguments")
 	}
 
-	clientConfig, err := f.ToRESTConfig()
-	if err != nil {
-		return err
+	if _, err := labels.Parse(o.selector); err != nil {
+		return kcmdutil.UsageErrorf(cmd, "invalid --selector: %v", err)
 	}
-	o.buildClient, err = buildv1client.NewForConfig(clientConfig)
-	if err != nil {
+	if err := o.completeClients(f); err != nil {
 		return err
 	}
-	o.imageClient, err = imagev1client.NewForConfig(clientConfig)
+
+	if (len(o.diffAgainst) > 0 || o.interactive || o.start) && len(o.output) == 0 {
+		o.output = "json"
+	}
+	if len(o.render) > 0 {
+		if len(o.output) == 0 {
+			o.output = "dot"
+		}
+		if o.Renderer == nil {
+			o.Renderer = NewGraphvizRenderer()
+		}
+	}
+
+	if len(o.enrichCommand) > 0 && o.Enricher == nil {
+		o.Enricher = NewCommandEnricher(o.enrichCommand)
+	}
+	if o.showVulnerabilities && o.Scanner == nil {
+		o.Scanner = NewHTTPScanner(o.scannerURL, o.ImageStreams, &http.Client{Timeout: 30 * time.Second})
+	}
+	if o.showImageDetails && o.Inspector == nil {
+		o.Inspector = NewImageInspector(o.ImageStreams)
+	}
+
+	if o.output == "ascii" && o.maxWidth == 0 {
+		if file, ok := out.(*os.File); ok && kterm.IsTerminal(file) {
+			if size := kterm.GetSize(file.Fd()); size != nil {
+				o.maxWidth = int(size.Width)
+			}
+		}
+	}
+
+	namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
 	if err != nil {
 		return err
 	}
-	o.projectClient, err = projectv1client.NewForConfig(clientConfig)
+	scope, err := parseNamespaces(namespace)
 	if err != nil {
 		return err
 	}
+	o.defaultNamespace = scope[0]
+	o.namespaces.Insert(scope...)
 
-	resource := schema.GroupResource{}
 	mapper, err := f.ToRESTMapper()
 	if err != nil {
 		return err
 	}
-	resource, o.name, err = osutil.ResolveResource(image.Resource("imagestreamtags"), args[0], mapper)
-	if err != nil {
+	switch {
+	case len(o.rootsFile) > 0:
+		o.entries, err = readEntriesFile(o.rootsFile, mapper, o.defaultNamespace)
+		if err != nil {
+			return err
+		}
+	case args[0] == "-":
+		o.entries, err = readEntries(o.In, mapper, o.defaultNamespace)
+		if err != nil {
+			return err
+		}
+	default:
+		o.entries, err = parseEntries(args, mapper, o.defaultNamespace)
+		if err != nil {
+			return err
+		}
+	}
+	if o.entries, err = o.expandTagGlobs(context.TODO(), o.entries); err != nil {
 		return err
 	}
+	for _, entry := range o.entries {
+		klog.V(4).Infof("Using %q in %q as an image stream tag to look dependencies for", entry.name, entry.namespace)
+		o.namespaces.Insert(entry.namespace)
+	}
 
-	switch resource {
-	case image.Resource("imagestreamtags"):
-		o.name = normalizeImageStreamTag(o.name)
-		klog.V(4).Infof("Using %q as the image stream tag to look dependencies for", o.name)
-	default:
-		return fmt.Errorf("invalid resource provided: %v", resource)
+	if err := o.completeClusters(f); err != nil {
+		return err
 	}
 
 	// Setup namespace
-	if o.allNamespaces {
-		// TODO: Handle different uses of build-chain; user and admin
-		projectList, err := o.projectClient.Projects().List(context.TODO(), metav1.ListOptions{})
-		if err != nil {
+	if o.allNamespaces && len(o.clusters) == 0 {
+		if err := o.completeAllNamespaces(context.TODO()); err != nil {
 			return err
 		}
-		for _, project := range projectList.Items {
-			klog.V(4).Infof("Found namespace %q", project.Name)
-			o.namespaces.Insert(project.Name)
+	}
+
+	klog.V(4).Infof("Will look for deps in %s", strings.Join(o.namespaces.List(), ","))
+
+	if o.output == "json" && len(o.criticalPath) == 0 && !o.simulate && !o.start && len(o.diffAgainst) == 0 && !o.interactive {
+		o.completeProvenance(f, cmd)
+	}
+
+	return nil
+}
+
+// completeAllNamespaces adds the projects listed by o.Projects to the
+// namespaces build configs are looked up in, except the ones excluded with
+// --exclude-namespace.
+func (o *BuildChainOptions) completeAllNamespaces(ctx context.Context) error {
+	// TODO: Handle different uses of build-chain; user and admin
+	projects, err := o.Projects.ListProjects(ctx)
+	if err != nil {
+		return err
+	}
+	excluded := sets.NewString(o.excludeNamespaces...)
+	for _, project := range projects {
+		if excluded.Has(project.Name) {
+			klog.V(4).Infof("Skipping excluded namespace %q", project.Name)
+			continue
 		}
+		klog.V(4).Infof("Found namespace %q", project.Name)
+		o.namespaces.Insert(project.Name)
 	}
+	return nil
+}
 
-	o.defaultNamespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+func (o *BuildChainOptions) completeClients(f kcmdutil.Factory) error {
+	if o.BuildConfigs != nil && o.ImageStreams != nil && o.Projects != nil && (o.DeploymentConfigs != nil || (len(o.envLabel) == 0 && !o.includeDeployments)) && (o.AccessReviewer != nil || !o.mine) && (o.Watcher != nil || !o.watch) && (o.BuildStarter != nil || !o.start) {
+		return nil
+	}
+	clientConfig, err := f.ToRESTConfig()
 	if err != nil {
 		return err
 	}
-	klog.V(4).Infof("Using %q as the namespace for %q", o.defaultNamespace, o.name)
-	o.namespaces.Insert(o.defaultNamespace)
-	klog.V(4).Infof("Will look for deps in %s", strings.Join(o.namespaces.List(), ","))
+	return o.completeClientsFromConfig(clientConfig)
+}
 
+// completeClientsFromConfig sets the clients that are nil to read from the
+// cluster of clientConfig.
+func (o *BuildChainOptions) completeClientsFromConfig(clientConfig *rest.Config) error {
+	if o.BuildConfigs == nil {
+		buildClient, err := buildv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		selector, err := labels.Parse(o.selector)
+		if err != nil {
+			return err
+		}
+		// builds are listed by chunks through the same client as build
+		// configs
+		o.BuildConfigs = describe.NewFilteredBuildConfigLister(cache.BuildV1(o.cacheOptions.ToCache(clientConfig), paging.BuildV1(buildClient, o.chunkSize)), selector)
+	}
+	if o.ImageStreams == nil {
+		imageClient, err := imagev1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.ImageStreams = NewImageStreamGetter(paging.ImageV1(imageClient, o.chunkSize))
+	}
+	if o.Projects == nil {
+		projectClient, err := projectv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.Projects = NewProjectLister(paging.ProjectV1(projectClient, o.chunkSize))
+	}
+	if o.DeploymentConfigs == nil && (len(o.envLabel) > 0 || o.includeDeployments) {
+		appsClient, err := appsv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.DeploymentConfigs = describe.NewDeploymentConfigLister(appsClient)
+	}
+	if o.AccessReviewer == nil && o.mine {
+		authorizationClient, err := authorizationv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.AccessReviewer = NewAccessReviewer(authorizationClient)
+	}
+	if o.Watcher == nil && o.watch {
+		buildClient, err := buildv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		imageClient, err := imagev1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		selector, err := labels.Parse(o.selector)
+		if err != nil {
+			return err
+		}
+		o.Watcher = NewChainWatcher(buildClient, imageClient, selector)
+	}
+	if o.BuildStarter == nil && o.start {
+		buildClient, err := buildv1client.NewForConfig(clientConfig)
+		if err != nil {
+			return err
+		}
+		o.BuildStarter = NewBuildStarter(buildClient)
+	}
 	return nil
 }
 
+// readEntries reads the newline separated image streams or image stream tags
+// of in. Empty lines and lines starting with '#' are ignored.
+func readEntries(in io.Reader, mapper meta.RESTMapper, defaultNamespace string) ([]chainEntry, error) {
+	entries := []chainEntry{}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, err := parseEntry(line, mapper, defaultNamespace)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// parseEntries resolves the image stream tag arguments, leaving out the ones
+// given several times.
+func parseEntries(args []string, mapper meta.RESTMapper, defaultNamespace string) ([]chainEntry, error) {
+	entries := []chainEntry{}
+	seen := map[chainEntry]bool{}
+	for _, arg := range args {
+		entry, err := parseEntry(arg, mapper, defaultNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// readEntriesFile reads the image stream tags of the file at path, as
+// readEntries does.
+func readEntriesFile(path string, mapper meta.RESTMapper, defaultNamespace string) ([]chainEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := readEntries(f, mapper, defaultNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("invalid roots file %s: %v", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("roots file %s doesn't list any image stream tag", path)
+	}
+	return entries, nil
+}
+
+// parseNamespaces splits the comma separated namespaces build configs are
+// looked up in. The first one is the namespace of the image stream tags.
+func parseNamespaces(value string) ([]string, error) {
+	namespaces := strings.Split(value, ",")
+	for _, namespace := range namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, ", "))
+		}
+	}
+	return namespaces, nil
+}
+
+// parseEntry resolves value, an image stream tag optionally prefixed by its
+// resource type or by its namespace, into the image stream tag to describe.
+func parseEntry(value string, mapper meta.RESTMapper, defaultNamespace string) (chainEntry, error) {
+	resource, name, err := osutil.ResolveResource(image.Resource("imagestreamtags"), value, mapper)
+	if err != nil {
+		namespace, name, ok := strings.Cut(value, "/")
+		if !ok || !meta.IsNoMatchError(err) {
+			return chainEntry{}, err
+		}
+		return chainEntry{namespace: namespace, name: normalizeImageStreamTag(name)}, nil
+	}
+
+	switch resource {
+	case image.Resource("imagestreamtags"), image.Resource("imagestreams"):
+		return chainEntry{namespace: defaultNamespace, name: normalizeImageStreamTag(name)}, nil
+	}
+	return chainEntry{}, fmt.Errorf("invalid resource provided: %v", resource)
+}
+
+// isTagGlob returns whether tag is a glob matching several tags rather than a
+// tag.
+func isTagGlob(tag string) bool {
+	return strings.ContainsAny(tag, "*?[")
+}
+
+// expandTagGlobs replaces the entries whose tag is a glob, or all of them with
+// --tags, by the tags of their image stream matching the glob, in the order
+// of the status of the image stream.
+func (o *BuildChainOptions) expandTagGlobs(ctx context.Context, entries []chainEntry) ([]chainEntry, error) {
+	expanded := []chainEntry{}
+	for _, entry := range entries {
+		stream, tag, _ := imageutil.SplitImageStreamTag(entry.name)
+		if len(o.tags) > 0 {
+			tag = o.tags
+		}
+		if !isTagGlob(tag) {
+			expanded = append(expanded, chainEntry{namespace: entry.namespace, name: imageutil.JoinImageStreamTag(stream, tag)})
+			continue
+		}
+		if _, err := path.Match(tag, ""); err != nil {
+			return nil, fmt.Errorf("invalid tag glob %q: %v", tag, err)
+		}
+
+		var is *imagev1.ImageStream
+		err := describe.RetryTransient(func() error {
+			var err error
+			is, err = o.ImageStreams.GetImageStream(ctx, entry.namespace, stream)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		matched := 0
+		for _, statusTag := range is.Status.Tags {
+			if ok, _ := path.Match(tag, statusTag.Tag); !ok {
+				continue
+			}
+			klog.V(4).Infof("Tag %q of image stream %q in %q matches %q", statusTag.Tag, stream, entry.namespace, tag)
+			expanded = append(expanded, chainEntry{namespace: entry.namespace, name: imageutil.JoinImageStreamTag(stream, statusTag.Tag)})
+			matched++
+		}
+		if matched == 0 {
+			return nil, fmt.Errorf("no tag of image stream %q in %q matches %q", stream, entry.namespace, tag)
+		}
+	}
+	return expanded, nil
+}
+
 // normalizeImageStreamTag normalizes an image stream tag by defaulting to 'latest'
 // if no tag has been specified.
 func normalizeImageStreamTag(name string) string {
@@ -171,46 +926,567 @@ func normalizeImageStreamTag(name string) string {
 
 // Validate returns validation errors regarding build-chain
 func (o *BuildChainOptions) Validate() error {
-	if len(o.name) == 0 {
+	if len(o.entries) == 0 {
 		return fmt.Errorf("image stream tag cannot be empty")
 	}
+	for _, entry := range o.entries {
+		if len(entry.name) == 0 {
+			return fmt.Errorf("image stream tag cannot be empty")
+		}
+	}
 	if len(o.defaultNamespace) == 0 {
 		return fmt.Errorf("default namespace cannot be empty")
 	}
-	if o.output != "" && o.output != "dot" {
-		return fmt.Errorf("output must be either empty or 'dot'")
+	if o.output != "" && o.output != "dot" && o.output != "json" && o.output != "ndjson" && o.output != "graphml" && o.output != "ascii" && o.output != "spdx" && o.output != "mermaid" && o.output != "list" && o.output != "summary" && o.output != "template" {
+		return fmt.Errorf("output must be either empty, 'dot', 'json', 'ndjson', 'graphml', 'ascii', 'spdx', 'mermaid', 'list', 'summary' or 'template'")
+	}
+	if len(o.render) > 0 {
+		if o.render != "svg" && o.render != "png" {
+			return fmt.Errorf("--render must be either 'svg' or 'png'")
+		}
+		if o.output != "dot" {
+			return fmt.Errorf("--render requires the dot output, got %q", o.output)
+		}
+		if len(o.outputFile) == 0 {
+			return fmt.Errorf("--render requires --output-file")
+		}
+		if o.Renderer == nil {
+			return fmt.Errorf("renderer must not be nil")
+		}
+	}
+	if len(o.diffAgainst) > 0 {
+		if o.output != "json" {
+			return fmt.Errorf("--diff-against requires the json output, got %q", o.output)
+		}
+		if len(o.entries) > 1 && !o.merge {
+			return fmt.Errorf("--diff-against requires a single image stream tag, or --merge")
+		}
+		if len(o.render) > 0 || o.watch || len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate {
+			return fmt.Errorf("--diff-against can't be combined with --render, --watch, --group-by-label, --critical-path or --simulate")
+		}
+		if _, err := os.Stat(o.diffAgainst); err != nil {
+			return fmt.Errorf("--diff-against file is not accessible: %v", err)
+		}
+	}
+	if o.interactive {
+		if o.output != "json" {
+			return fmt.Errorf("--interactive can't be combined with --output=%s", o.output)
+		}
+		if len(o.entries) > 1 && !o.merge {
+			return fmt.Errorf("--interactive requires a single image stream tag, or --merge")
+		}
+		if len(o.outputFile) > 0 || len(o.render) > 0 || o.watch || len(o.diffAgainst) > 0 || len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate {
+			return fmt.Errorf("--interactive can't be combined with --output-file, --render, --watch, --diff-against, --group-by-label, --critical-path or --simulate")
+		}
+	}
+	if o.start {
+		if o.output != "json" {
+			return fmt.Errorf("--start can't be combined with --output=%s", o.output)
+		}
+		if len(o.entries) > 1 && !o.merge {
+			return fmt.Errorf("--start requires a single image stream tag, or --merge")
+		}
+		if o.simulate || o.reverse || o.anonymize || o.interactive || len(o.outputFile) > 0 || len(o.render) > 0 || o.watch || len(o.diffAgainst) > 0 || len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || len(o.atTime) > 0 || o.atGeneration > 0 {
+			return fmt.Errorf("--start can't be combined with --simulate, --reverse, --anonymize, --interactive, --output-file, --render, --watch, --diff-against, --group-by-label, --critical-path, --at-time or --at-generation")
+		}
+		if o.BuildStarter == nil {
+			return fmt.Errorf("build starter must not be nil")
+		}
+	}
+	if len(o.outputFile) > 0 && len(o.entries) > 1 && !o.merge {
+		return fmt.Errorf("--output-file requires a single image stream tag, or --merge")
+	}
+	if len(o.outputFile) > 0 {
+		// fail before loading the chain rather than after
+		dir := filepath.Dir(o.outputFile)
+		if info, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("--output-file directory %q is not accessible: %v", dir, err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("--output-file directory %q is not a directory", dir)
+		}
+	}
+	if len(o.envLabel) > 0 {
+		if errs := validation.IsQualifiedName(o.envLabel); len(errs) > 0 {
+			return fmt.Errorf("--env-label must be a valid label key: %s", strings.Join(errs, ", "))
+		}
+	}
+	if (len(o.envLabel) > 0 || o.includeDeployments) && o.DeploymentConfigs == nil {
+		return fmt.Errorf("deploymentConfig client must not be nil")
+	}
+	if len(o.excludeNamespaces) > 0 {
+		if !o.allNamespaces {
+			return fmt.Errorf("--exclude-namespace requires --all")
+		}
+		for _, namespace := range o.excludeNamespaces {
+			if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+				return fmt.Errorf("invalid namespace %q in --exclude-namespace: %s", namespace, strings.Join(errs, ", "))
+			}
+		}
+	}
+	if o.concurrency < 0 {
+		return fmt.Errorf("--concurrency must not be negative")
+	}
+	if o.maxWidth < 0 {
+		return fmt.Errorf("--max-width must not be negative")
+	}
+	if len(o.groupByLabel) > 0 {
+		if errs := validation.IsQualifiedName(o.groupByLabel); len(errs) > 0 {
+			return fmt.Errorf("--group-by-label must be a valid label key: %s", strings.Join(errs, ", "))
+		}
+		if o.weightByActivity || o.splitByTag {
+			return fmt.Errorf("--group-by-label can't be combined with --weight-by-activity or --split-by-tag")
+		}
+		if o.output == "ndjson" || o.output == "graphml" || o.output == "ascii" || o.output == "spdx" || o.output == "mermaid" || o.output == "list" || o.output == "summary" || o.output == "template" {
+			return fmt.Errorf("--group-by-label doesn't support the %q output", o.output)
+		}
+		if len(o.envLabel) > 0 {
+			return fmt.Errorf("--group-by-label can't be combined with --env-label")
+		}
+	}
+	if len(o.criticalPath) > 0 {
+		if o.criticalPath != describe.CriticalPathByDuration && o.criticalPath != describe.CriticalPathByHops {
+			return fmt.Errorf("--critical-path must be either 'duration' or 'hops'")
+		}
+		if o.output != "" && o.output != "json" {
+			return fmt.Errorf("--critical-path doesn't support the %q output", o.output)
+		}
+		if len(o.groupByLabel) > 0 {
+			return fmt.Errorf("--critical-path can't be combined with --group-by-label")
+		}
+	}
+	if o.simulate {
+		if o.output != "" && o.output != "json" {
+			return fmt.Errorf("--simulate doesn't support the %q output", o.output)
+		}
+		if o.reverse || len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 {
+			return fmt.Errorf("--simulate can't be combined with --reverse, --group-by-label or --critical-path")
+		}
+	}
+	if len(o.enrichCommand) > 0 {
+		if o.output != "json" && o.output != "ndjson" {
+			return fmt.Errorf("--enrich-command requires the json or ndjson output")
+		}
+		if o.anonymize || len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate {
+			return fmt.Errorf("--enrich-command can't be combined with --anonymize, --group-by-label, --critical-path or --simulate")
+		}
+		if o.Enricher == nil {
+			return fmt.Errorf("enricher must not be nil")
+		}
+	}
+	if o.output == "template" && o.anonymize {
+		return fmt.Errorf("the template output can't be combined with --anonymize")
+	}
+	if o.includeExternal && (len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate) {
+		return fmt.Errorf("--include-external can't be combined with --group-by-label, --critical-path or --simulate")
+	}
+	if o.showVulnerabilities {
+		if u, err := url.Parse(o.scannerURL); err != nil || !u.IsAbs() {
+			return fmt.Errorf("--show-vulnerabilities requires --scanner-url, the absolute URL of the image scanner")
+		}
+		if len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate {
+			return fmt.Errorf("--show-vulnerabilities can't be combined with --group-by-label, --critical-path or --simulate")
+		}
+		if o.Scanner == nil {
+			return fmt.Errorf("scanner must not be nil")
+		}
+	}
+	if o.showImageDetails {
+		if len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate {
+			return fmt.Errorf("--show-image-details can't be combined with --group-by-label, --critical-path or --simulate")
+		}
+		if o.Inspector == nil {
+			return fmt.Errorf("inspector must not be nil")
+		}
+	}
+	if len(o.contexts) > 0 {
+		if !o.merge {
+			return fmt.Errorf("--contexts requires --merge")
+		}
+		if o.output != "json" {
+			return fmt.Errorf("--contexts requires -o json")
+		}
+		if o.watch || o.start || o.interactive || len(o.diffAgainst) > 0 || len(o.tags) > 0 {
+			return fmt.Errorf("--contexts can't be combined with --watch, --start, --interactive, --diff-against or --tags")
+		}
+		if len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate || o.hideTags || o.anonymize {
+			return fmt.Errorf("--contexts can't be combined with --group-by-label, --critical-path, --simulate, --hide-tags or --anonymize, which don't output the image stream tags the chains are merged by")
+		}
+	}
+	if o.hideTags {
+		if len(o.groupByLabel) > 0 || len(o.criticalPath) > 0 || o.simulate {
+			return fmt.Errorf("--hide-tags can't be combined with --group-by-label, --critical-path or --simulate")
+		}
+		if o.splitByTag || len(o.envLabel) > 0 || o.showVulnerabilities || o.showImageDetails {
+			return fmt.Errorf("--hide-tags can't be combined with --split-by-tag, --env-label, --show-vulnerabilities or --show-image-details, which annotate image stream tags")
+		}
+	}
+	if len(o.failOn) > 0 {
+		if !o.showVulnerabilities {
+			return fmt.Errorf("--fail-on requires --show-vulnerabilities")
+		}
+		if !sets.NewString(describe.Severities...).Has(o.failOn) {
+			return fmt.Errorf("--fail-on must be one of: %s", strings.Join(describe.Severities, ", "))
+		}
+	}
+	if o.colorByStatus && o.output != "dot" {
+		return fmt.Errorf("--color-by-status requires the dot output")
+	}
+	if len(o.linkBase) > 0 {
+		if u, err := url.Parse(o.linkBase); err != nil || !u.IsAbs() {
+			return fmt.Errorf("--link-base must be an absolute URL")
+		}
+		if o.anonymize {
+			return fmt.Errorf("--link-base can't be combined with --anonymize")
+		}
+	}
+	if o.labelMaxLength < 0 {
+		return fmt.Errorf("--label-max-length must not be negative")
+	}
+	if o.maxNodes < 0 {
+		return fmt.Errorf("--max-nodes must not be negative")
+	}
+	if o.maxDepth < 0 {
+		return fmt.Errorf("--max-depth must not be negative")
+	}
+	if len(o.allowCrossNamespace) > 0 && !o.denyCrossNamespace {
+		return fmt.Errorf("--allow-cross-namespace requires --deny-cross-namespace")
+	}
+	for _, allowed := range o.allowCrossNamespace {
+		if from, to, ok := strings.Cut(allowed, ":"); !ok || len(from) == 0 || len(to) == 0 {
+			return fmt.Errorf("--allow-cross-namespace must be FROM:TO namespaces, got %q", allowed)
+		}
+	}
+	if o.weightByActivity && o.since <= 0 {
+		return fmt.Errorf("--since must be a positive duration")
+	}
+	if len(o.atTime) > 0 {
+		if o.atGeneration != 0 {
+			return fmt.Errorf("--at-time can't be combined with --at-generation")
+		}
+		if _, err := time.Parse(time.RFC3339, o.atTime); err != nil {
+			return fmt.Errorf("--at-time must be an RFC3339 time: %v", err)
+		}
+	}
+	if o.atGeneration < 0 {
+		return fmt.Errorf("--at-generation must not be negative")
 	}
-	if o.buildClient == nil {
+	if o.atGeneration > 0 && len(o.entries) != 1 {
+		return fmt.Errorf("--at-generation requires a single image stream tag")
+	}
+	if o.BuildConfigs == nil {
 		return fmt.Errorf("buildConfig client must not be nil")
 	}
-	if o.imageClient == nil {
+	if o.ImageStreams == nil {
 		return fmt.Errorf("imageStreamTag client must not be nil")
 	}
-	if o.projectClient == nil {
+	if o.Projects == nil {
 		return fmt.Errorf("project client must not be nil")
 	}
+	if o.mine && o.AccessReviewer == nil {
+		return fmt.Errorf("access review client must not be nil")
+	}
+	if o.watch {
+		if o.cacheOptions != nil && o.cacheOptions.TTL > 0 {
+			return fmt.Errorf("--watch can't be combined with --cache-ttl, which would hide the changes")
+		}
+		if len(o.atTime) > 0 || o.atGeneration > 0 {
+			return fmt.Errorf("--watch can't be combined with --at-time or --at-generation")
+		}
+		if o.Watcher == nil {
+			return fmt.Errorf("watcher must not be nil")
+		}
+	}
 	return nil
 }
 
 // RunBuildChain contains all the necessary functionality for the OpenShift
-// experimental build-chain command
+// build-chain command
 func (o *BuildChainOptions) RunBuildChain() error {
-	ist := imagegraph.MakeImageStreamTagObjectMeta2(o.defaultNamespace, o.name)
+	if len(o.clusters) > 0 {
+		return o.runClusters()
+	}
+	if err := o.resolveAt(context.TODO()); err != nil {
+		return err
+	}
+
+	describer := describe.NewChainDescriber(o.BuildConfigs, o.namespaces, o.output)
+	describer.At = o.at
+	if o.weightByActivity {
+		since := time.Now().Add(-o.since)
+		if o.at != nil {
+			since = o.at.Add(-o.since)
+		}
+		describer.ActivitySince = &since
+	}
+	describer.SplitByTag = o.splitByTag
+	describer.GroupByLabel = o.groupByLabel
+	describer.CriticalPath = o.criticalPath
+	describer.Simulate = o.simulate || o.start
+	describer.ColorByStatus = o.colorByStatus
+	describer.OnlyFailing = o.onlyFailing
+	describer.Provenance = o.provenance
+	describer.Anonymize = o.anonymize
+	describer.LinkBase = o.linkBase
+	describer.LabelMaxLength = o.labelMaxLength
+	describer.WrapLabels = o.wrapLabels
+	describer.MaxWidth = o.maxWidth
+	describer.EnvironmentLabel = o.envLabel
+	describer.DeploymentConfigs = o.DeploymentConfigs
+	describer.IncludeDeployments = o.includeDeployments
+	describer.IncludeExternal = o.includeExternal
+	describer.ImageStreams = o.ImageStreams
+	describer.MaxNodes = o.maxNodes
+	describer.MaxDepth = o.maxDepth
+	describer.FlagCrossNamespace = o.flagCrossNamespace
+	describer.IncludeManual = o.includeManual
+	describer.Strict = o.strict
+	describer.FailFast = o.failFast
+	describer.Concurrency = o.concurrency
+	describer.Enricher = o.Enricher
+	if o.showVulnerabilities {
+		describer.Scanner = o.Scanner
+	}
+	if o.showImageDetails {
+		describer.Inspector = o.Inspector
+	}
+	describer.HideTags = o.hideTags
+	if o.mine {
+		owned, err := o.ownedNamespaces(context.TODO())
+		if err != nil {
+			return err
+		}
+		describer.OwnedNamespaces = owned
+	}
+
+	if o.merge {
+		ists := []*imagev1.ImageStreamTag{}
+		for _, entry := range o.entries {
+			ists = append(ists, imagegraph.MakeImageStreamTagObjectMeta2(entry.namespace, entry.name))
+		}
+		desc, err := describer.DescribeMerged(ists, !o.triggerOnly, o.reverse)
+		o.warn(describer.Warnings())
+		if err != nil {
+			if _, isNotFoundErr := err.(describe.NotFoundErr); isNotFoundErr {
+				fmt.Fprintln(o.Out, "None of the image stream tags have any dependencies.")
+				return nil
+			}
+			return err
+		}
+		if err := o.writeOutput(desc); err != nil {
+			return err
+		}
+		o.warnTruncated(describer.Truncated(), "the merged build chain")
+		o.warnCrossNamespace(describer.CrossNamespace(), "the merged build chain")
+		if err := o.checkCrossNamespace(describer.CrossNamespace()); err != nil {
+			return err
+		}
+		return o.checkVulnerabilities(describer.Vulnerable())
+	}
 
-	desc, err := describe.NewChainDescriber(o.buildClient, o.namespaces, o.output).Describe(ist, !o.triggerOnly, o.reverse)
+	ists := []*imagev1.ImageStreamTag{}
+	for _, entry := range o.entries {
+		ists = append(ists, imagegraph.MakeImageStreamTagObjectMeta2(entry.namespace, entry.name))
+	}
+	descs, err := describer.DescribeEach(ists, !o.triggerOnly, o.reverse)
+	o.warn(describer.Warnings())
 	if err != nil {
+		return err
+	}
+	crossings := []describe.CrossNamespaceEdge{}
+	vulnerable := []describe.VulnerableImage{}
+	for i, entry := range o.entries {
+		if i > 0 && len(o.output) == 0 {
+			fmt.Fprintln(o.Out)
+		}
+		if err := o.printEntry(entry, descs[i]); err != nil {
+			return err
+		}
+		crossings = append(crossings, descs[i].CrossNamespace...)
+		vulnerable = append(vulnerable, descs[i].Vulnerable...)
+	}
+	if err := o.checkCrossNamespace(crossings); err != nil {
+		return err
+	}
+	describe.SortVulnerableImages(vulnerable)
+	return o.checkVulnerabilities(vulnerable)
+}
+
+func (o *BuildChainOptions) printEntry(entry chainEntry, desc describe.ChainDescription) error {
+	if err := desc.Err; err != nil {
 		if _, isNotFoundErr := err.(describe.NotFoundErr); isNotFoundErr {
 			// Try to get the imageStreamTag via a direct GET
-			if _, getErr := o.imageClient.ImageStreamTags(o.defaultNamespace).Get(context.TODO(), o.name, metav1.GetOptions{}); getErr != nil {
-				return getErr
+			getErr := describe.RetryTransient(func() error {
+				_, err := o.ImageStreams.GetImageStreamTag(context.TODO(), entry.namespace, entry.name)
+				return err
+			})
+			if getErr != nil {
+				if !kerrors.IsNotFound(getErr) && !o.failFast {
+					fmt.Fprintf(o.ErrOut, "warning: unable to get image stream tag %q in %q, it is left out: %v\n", entry.name, entry.namespace, getErr)
+					return nil
+				}
+				if !o.createMissingOK || !kerrors.IsNotFound(getErr) {
+					return getErr
+				}
+				fmt.Fprintf(o.Out, "Image stream tag %q in %q doesn't exist yet and doesn't have any dependencies.\n", entry.name, entry.namespace)
+				return nil
 			}
-			fmt.Printf("Image stream tag %q in %q doesn't have any dependencies.\n", o.name, o.defaultNamespace)
+			fmt.Fprintf(o.Out, "Image stream tag %q in %q doesn't have any dependencies.\n", entry.name, entry.namespace)
 			return nil
 		}
 		return err
 	}
 
-	fmt.Println(desc)
+	if err := o.writeOutput(desc.Output); err != nil {
+		return err
+	}
+	o.warnTruncated(desc.Truncated, fmt.Sprintf("the build chain of %q in %q", entry.name, entry.namespace))
+	o.warnCrossNamespace(desc.CrossNamespace, fmt.Sprintf("the build chain of %q in %q", entry.name, entry.namespace))
+
+	return nil
+}
+
+// writeOutput prints output, or writes it to --output-file, rendered into an
+// image with --render or replaced by its differences with the chain saved in
+// --diff-against. With --interactive, output is browsed instead, and with
+// --start, the builds it simulates are started. The json and ndjson outputs
+// are written in the types of their version.
+func (o *BuildChainOptions) writeOutput(output string) error {
+	output, err := o.versionedOutput(output)
+	if err != nil {
+		return err
+	}
+	if o.interactive {
+		return o.browse(output)
+	}
+	if o.start {
+		return o.startBuilds(context.TODO(), output)
+	}
+	if len(o.diffAgainst) > 0 {
+		lines, err := o.diffAgainstSaved(output)
+		if err != nil {
+			return err
+		}
+		if len(lines) == 0 && len(o.outputFile) == 0 {
+			return nil
+		}
+		output = strings.Join(lines, "\n")
+	}
+	if len(o.outputFile) == 0 {
+		fmt.Fprintln(o.Out, output)
+		return nil
+	}
+	data := []byte(output + "\n")
+	if len(o.render) > 0 {
+		if data, err = o.Renderer.Render(o.render, []byte(output)); err != nil {
+			return err
+		}
+	}
+	// write next to the destination and rename, so that a failed write
+	// doesn't leave a truncated dump behind
+	f, err := os.CreateTemp(filepath.Dir(o.outputFile), "."+filepath.Base(o.outputFile)+"-")
+	if err != nil {
+		return fmt.Errorf("unable to write the build chain to %q: %v", o.outputFile, err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), o.outputFile)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write the build chain to %q: %v", o.outputFile, err)
+	}
+	klog.V(2).Infof("Wrote the build chain to %s", o.outputFile)
+	return nil
+}
+
+// ownedNamespaces returns the namespaces of the chain where the current user
+// can edit build configs.
+func (o *BuildChainOptions) ownedNamespaces(ctx context.Context) (sets.String, error) {
+	owned := sets.NewString()
+	for _, namespace := range o.namespaces.List() {
+		canEdit, err := o.AccessReviewer.CanEditBuildConfigs(ctx, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check access to %q: %v", namespace, err)
+		}
+		if canEdit {
+			owned.Insert(namespace)
+		}
+	}
+	klog.V(4).Infof("Can edit the build configs of %s", strings.Join(owned.List(), ","))
+	return owned, nil
+}
+
+func (o *BuildChainOptions) warn(warnings []describe.ChainWarning) {
+	for _, warning := range warnings {
+		fmt.Fprintf(o.ErrOut, "warning: %s\n", warning.Message)
+	}
+}
+
+func (o *BuildChainOptions) warnTruncated(truncated int, chain string) {
+	if truncated > 0 {
+		fmt.Fprintf(o.ErrOut, "warning: %d nodes of %s were left out because of --max-nodes=%d\n", truncated, chain, o.maxNodes)
+	}
+}
 
+func (o *BuildChainOptions) warnCrossNamespace(crossings []describe.CrossNamespaceEdge, chain string) {
+	if o.flagCrossNamespace && len(crossings) > 0 {
+		fmt.Fprintf(o.ErrOut, "warning: %d dependencies of %s cross namespaces\n", len(crossings), chain)
+	}
+}
+
+// checkCrossNamespace returns an error listing the dependencies between
+// namespaces that --allow-cross-namespace doesn't allow, with
+// --deny-cross-namespace.
+func (o *BuildChainOptions) checkCrossNamespace(crossings []describe.CrossNamespaceEdge) error {
+	if !o.denyCrossNamespace {
+		return nil
+	}
+	denied := sets.NewString()
+	for _, crossing := range crossings {
+		if !o.allowsCrossNamespace(crossing.FromNamespace, crossing.ToNamespace) {
+			denied.Insert(fmt.Sprintf("%s -> %s", crossing.From, crossing.To))
+		}
+	}
+	if denied.Len() > 0 {
+		return fmt.Errorf("%d dependencies cross namespaces without being allowed:\n  %s", denied.Len(), strings.Join(denied.List(), "\n  "))
+	}
+	return nil
+}
+
+// checkVulnerabilities returns an error listing the image stream tags with
+// vulnerabilities of the --fail-on severity or worse, in the order to fix
+// them.
+func (o *BuildChainOptions) checkVulnerabilities(vulnerable []describe.VulnerableImage) error {
+	if len(o.failOn) == 0 {
+		return nil
+	}
+	seen := sets.NewString()
+	lines := []string{}
+	for _, image := range vulnerable {
+		if seen.Has(image.ID) || image.Vulnerabilities.AtLeast(o.failOn) == 0 {
+			continue
+		}
+		seen.Insert(image.ID)
+		lines = append(lines, fmt.Sprintf("%s: %s, %d dependent build configs", image.ID, image.Vulnerabilities, image.Dependents))
+	}
+	if len(lines) > 0 {
+		return fmt.Errorf("%d image stream tags have %s vulnerabilities or worse:\n  %s", len(lines), o.failOn, strings.Join(lines, "\n  "))
+	}
 	return nil
 }
+
+func (o *BuildChainOptions) allowsCrossNamespace(from, to string) bool {
+	for _, allowed := range o.allowCrossNamespace {
+		allowedFrom, allowedTo, _ := strings.Cut(allowed, ":")
+		if (allowedFrom == "*" || allowedFrom == from) && (allowedTo == "*" || allowedTo == to) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,1149 @@
+package buildchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	projectv1 "github.com/openshift/api/project/v1"
+	buildchaintesting "github.com/openshift/oc/pkg/cli/admin/buildchain/testing"
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+func TestReadEntries(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range []string{"ImageStream", "ImageStreamTag"} {
+		mapper.Add(schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: kind}, meta.RESTScopeNamespace)
+	}
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+
+	in := strings.NewReader(`imagestream.image.openshift.io/ruby
+# comment
+
+imagestreamtag.image.openshift.io/python:3.9
+other/nodejs:18
+other/perl
+`)
+	entries, err := readEntries(in, mapper, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []chainEntry{
+		{namespace: "test", name: "ruby:latest"},
+		{namespace: "test", name: "python:3.9"},
+		{namespace: "other", name: "nodejs:18"},
+		{namespace: "other", name: "perl:latest"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+
+	if _, err := readEntries(strings.NewReader("pod/ruby\n"), mapper, "test"); err == nil {
+		t.Errorf("expected pods to be rejected")
+	}
+	if entry, err := parseEntry("other/nodejs:18", mapper, "test"); err != nil || entry != (chainEntry{namespace: "other", name: "nodejs:18"}) {
+		t.Errorf("expected a namespaced argument, got %v, %v", entry, err)
+	}
+}
+
+func TestReadEntriesFile(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStreamTag"}, meta.RESTScopeNamespace)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "repos.txt")
+	if err := os.WriteFile(path, []byte("# golden images\nbase/ruby:3.1\nbase/python\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readEntriesFile(path, mapper, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []chainEntry{
+		{namespace: "base", name: "ruby:3.1"},
+		{namespace: "base", name: "python:latest"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+
+	empty := filepath.Join(dir, "empty.txt")
+	if err := os.WriteFile(empty, []byte("# nothing yet\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readEntriesFile(empty, mapper, "test"); err == nil {
+		t.Errorf("expected a file without image stream tags to be rejected")
+	}
+	if _, err := readEntriesFile(filepath.Join(dir, "missing.txt"), mapper, "test"); err == nil {
+		t.Errorf("expected a missing file to be rejected")
+	}
+}
+
+func TestParseEntries(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStreamTag"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+
+	entries, err := parseEntries([]string{"ruby:3.1", "base/python", "ruby:3.1"}, mapper, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []chainEntry{
+		{namespace: "test", name: "ruby:3.1"},
+		{namespace: "base", name: "python:latest"},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %v, got %v", expected, entries)
+	}
+
+	if _, err := parseEntries([]string{"ruby:3.1", "pod/ruby"}, mapper, "test"); err == nil {
+		t.Errorf("expected pods to be rejected")
+	}
+}
+
+func TestParseNamespaces(t *testing.T) {
+	namespaces, err := parseNamespaces("web,base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(namespaces, []string{"web", "base"}) {
+		t.Errorf("unexpected namespaces: %v", namespaces)
+	}
+	for _, value := range []string{"web,", "web,,base", "Web"} {
+		if _, err := parseNamespaces(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestExpandTagGlobs(t *testing.T) {
+	imageStreams := &buildchaintesting.FakeImageStreamGetter{
+		ImageStreams: []imagev1.ImageStream{{
+			ObjectMeta: metav1.ObjectMeta{Name: "ruby", Namespace: "base"},
+			Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{
+				{Tag: "v1.0"}, {Tag: "latest"}, {Tag: "v1.1"}, {Tag: "v2.0"}, {Tag: "release-1"},
+			}},
+		}},
+	}
+	entries := []chainEntry{
+		{namespace: "base", name: "ruby:v1.*"},
+		{namespace: "test", name: "python:3.9"},
+	}
+
+	o := &BuildChainOptions{ImageStreams: imageStreams}
+	expanded, err := o.expandTagGlobs(context.TODO(), entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []chainEntry{
+		{namespace: "base", name: "ruby:v1.0"},
+		{namespace: "base", name: "ruby:v1.1"},
+		{namespace: "test", name: "python:3.9"},
+	}
+	if !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("expected %v, got %v", expected, expanded)
+	}
+
+	o.tags = "release-*"
+	expanded, err = o.expandTagGlobs(context.TODO(), entries[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []chainEntry{{namespace: "base", name: "ruby:release-1"}}; !reflect.DeepEqual(expanded, expected) {
+		t.Errorf("expected --tags to replace the tag, got %v", expanded)
+	}
+
+	for _, invalid := range []struct {
+		tags  string
+		entry chainEntry
+		err   string
+	}{
+		{entry: chainEntry{namespace: "base", name: "ruby:v3.*"}, err: "no tag of image stream"},
+		{tags: "v[1", entry: chainEntry{namespace: "base", name: "ruby:latest"}, err: "invalid tag glob"},
+		{entry: chainEntry{namespace: "base", name: "python:*"}, err: "not found"},
+	} {
+		o := &BuildChainOptions{tags: invalid.tags, ImageStreams: imageStreams}
+		if _, err := o.expandTagGlobs(context.TODO(), []chainEntry{invalid.entry}); err == nil || !strings.Contains(err.Error(), invalid.err) {
+			t.Errorf("expected error containing %q, got %v", invalid.err, err)
+		}
+	}
+}
+
+func TestCompleteAllNamespaces(t *testing.T) {
+	projects := []projectv1.Project{}
+	for _, name := range []string{"web", "base", "openshift", "default"} {
+		projects = append(projects, projectv1.Project{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	o := &BuildChainOptions{
+		namespaces:        sets.NewString("default"),
+		allNamespaces:     true,
+		excludeNamespaces: []string{"openshift", "default"},
+		Projects:          &buildchaintesting.FakeProjectLister{Projects: projects},
+	}
+	if err := o.completeAllNamespaces(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	// the namespace of the image stream tag is kept even when excluded
+	if expected := []string{"base", "default", "web"}; !reflect.DeepEqual(o.namespaces.List(), expected) {
+		t.Errorf("expected namespaces %v, got %v", expected, o.namespaces.List())
+	}
+
+	for _, invalid := range []struct {
+		all     bool
+		exclude []string
+		err     string
+	}{
+		{exclude: []string{"openshift"}, err: "requires --all"},
+		{all: true, exclude: []string{"OpenShift"}, err: "invalid namespace"},
+	} {
+		o := &BuildChainOptions{
+			entries:           []chainEntry{{namespace: "test", name: "base:latest"}},
+			defaultNamespace:  "test",
+			allNamespaces:     invalid.all,
+			excludeNamespaces: invalid.exclude,
+			BuildConfigs:      &buildchaintesting.FakeBuildConfigLister{},
+			ImageStreams:      &buildchaintesting.FakeImageStreamGetter{},
+			Projects:          &buildchaintesting.FakeProjectLister{},
+		}
+		if err := o.Validate(); err == nil || !strings.Contains(err.Error(), invalid.err) {
+			t.Errorf("expected error containing %q, got %v", invalid.err, err)
+		}
+	}
+}
+
+func TestValidateSimulate(t *testing.T) {
+	for _, tc := range []struct {
+		output  string
+		reverse bool
+		err     string
+	}{
+		{},
+		{output: "json"},
+		{output: "dot", err: `--simulate doesn't support the "dot" output`},
+		{reverse: true, err: "--simulate can't be combined with --reverse"},
+	} {
+		o := &BuildChainOptions{
+			entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+			defaultNamespace: "test",
+			simulate:         true,
+			output:           tc.output,
+			reverse:          tc.reverse,
+			BuildConfigs:     &buildchaintesting.FakeBuildConfigLister{},
+			ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+			Projects:         &buildchaintesting.FakeProjectLister{},
+		}
+		err := o.Validate()
+		if len(tc.err) == 0 && err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(tc.err) > 0 && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("expected error containing %q, got %v", tc.err, err)
+		}
+	}
+}
+
+func TestDiffChains(t *testing.T) {
+	oldChain := &describe.ChainOutput{
+		Nodes: []describe.ChainNode{{ID: "ImageStreamTag|test/ruby:latest"}, {ID: "BuildConfig|test/app"}, {ID: "BuildConfig|test/old"}},
+		Edges: []describe.ChainEdge{
+			{From: "ImageStreamTag|test/ruby:latest", To: "BuildConfig|test/app", Kinds: []string{"BuildInputImage"}, Tag: "latest"},
+			{From: "ImageStreamTag|test/ruby:latest", To: "BuildConfig|test/old", Kinds: []string{"BuildInputImage"}, Tag: "latest"},
+		},
+	}
+	newChain := &describe.ChainOutput{
+		Nodes: []describe.ChainNode{{ID: "ImageStreamTag|test/ruby:latest"}, {ID: "BuildConfig|test/app"}, {ID: "BuildConfig|test/new"}},
+		Edges: []describe.ChainEdge{
+			{From: "ImageStreamTag|test/ruby:latest", To: "BuildConfig|test/app", Kinds: []string{"BuildInputImage", "BuildTriggerImage"}, Tag: "latest"},
+			{From: "ImageStreamTag|test/ruby:latest", To: "BuildConfig|test/new", Kinds: []string{"BuildInputImage"}, Tag: "latest"},
+		},
+	}
+
+	expected := []string{
+		"+ BuildConfig|test/new",
+		"- BuildConfig|test/old",
+		"~ ImageStreamTag|test/ruby:latest -> BuildConfig|test/app [BuildInputImage,BuildTriggerImage (latest)] (was [BuildInputImage (latest)])",
+		"+ ImageStreamTag|test/ruby:latest -> BuildConfig|test/new [BuildInputImage (latest)]",
+		"- ImageStreamTag|test/ruby:latest -> BuildConfig|test/old [BuildInputImage (latest)]",
+	}
+	if got := diffChains(oldChain, newChain); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+	if got := diffChains(newChain, newChain); len(got) != 0 {
+		t.Errorf("expected no differences, got %v", got)
+	}
+}
+
+func TestRunBuildChainDiffAgainst(t *testing.T) {
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{
+		BuildConfigs: []buildv1.BuildConfig{{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}},
+	}
+	out := &bytes.Buffer{}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		triggerOnly:      true,
+		output:           "json",
+		BuildConfigs:     buildConfigs,
+		ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+		Projects:         &buildchaintesting.FakeProjectLister{},
+		IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	chain := &describe.ChainOutput{}
+	if err := json.Unmarshal(out.Bytes(), chain); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chain.json")
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	o.diffAgainst = path
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("expected no differences with the saved chain, got:\n%s", out.String())
+	}
+
+	chain.Nodes = append(chain.Nodes, describe.ChainNode{ID: "BuildConfig|test/old", Kind: "BuildConfig", Namespace: "test", Name: "old"})
+	data, err := json.Marshal(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "- BuildConfig|test/old\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	for _, invalid := range []struct {
+		output, diffAgainst, err string
+	}{
+		{output: "dot", diffAgainst: path, err: "requires the json output"},
+		{output: "json", diffAgainst: filepath.Join(t.TempDir(), "missing.json"), err: "is not accessible"},
+	} {
+		o.output, o.diffAgainst = invalid.output, invalid.diffAgainst
+		if err := o.Validate(); err == nil || !strings.Contains(err.Error(), invalid.err) {
+			t.Errorf("expected error containing %q, got %v", invalid.err, err)
+		}
+	}
+}
+
+func TestRunBuildChain(t *testing.T) {
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{
+		BuildConfigs: []buildv1.BuildConfig{{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}},
+	}
+	imageStreams := &buildchaintesting.FakeImageStreamGetter{
+		ImageStreamTags: []imagev1.ImageStreamTag{{ObjectMeta: metav1.ObjectMeta{Name: "lonely:latest", Namespace: "test"}}},
+	}
+
+	tests := []struct {
+		name          string
+		entry         chainEntry
+		createMissing bool
+		expected      string
+		err           string
+	}{
+		{
+			name:     "dependencies",
+			entry:    chainEntry{namespace: "test", name: "base:latest"},
+			expected: "bc/app",
+		},
+		{
+			name:     "no dependencies",
+			entry:    chainEntry{namespace: "test", name: "lonely:latest"},
+			expected: `Image stream tag "lonely:latest" in "test" doesn't have any dependencies.`,
+		},
+		{
+			name:  "missing image stream tag",
+			entry: chainEntry{namespace: "test", name: "missing:latest"},
+			err:   "not found",
+		},
+		{
+			name:          "missing image stream tag allowed",
+			entry:         chainEntry{namespace: "test", name: "missing:latest"},
+			createMissing: true,
+			expected:      `Image stream tag "missing:latest" in "test" doesn't exist yet and doesn't have any dependencies.`,
+		},
+		{
+			name:          "missing image stream tag with dependencies",
+			entry:         chainEntry{namespace: "test", name: "base:latest"},
+			createMissing: true,
+			expected:      "bc/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &BuildChainOptions{
+				entries:          []chainEntry{tt.entry},
+				defaultNamespace: "test",
+				namespaces:       sets.NewString("test"),
+				triggerOnly:      true,
+				createMissingOK:  tt.createMissing,
+				BuildConfigs:     buildConfigs,
+				ImageStreams:     imageStreams,
+				Projects:         &buildchaintesting.FakeProjectLister{},
+				IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			err := o.RunBuildChain()
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), tt.expected) {
+				t.Errorf("expected %q in output:\n%s", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestRunBuildChainFailFast(t *testing.T) {
+	for _, failFast := range []bool{false, true} {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		o := &BuildChainOptions{
+			entries:          []chainEntry{{namespace: "test", name: "lonely:latest"}, {namespace: "test", name: "other:latest"}},
+			defaultNamespace: "test",
+			namespaces:       sets.NewString("test"),
+			triggerOnly:      true,
+			failFast:         failFast,
+			BuildConfigs:     &buildchaintesting.FakeBuildConfigLister{},
+			ImageStreams:     &buildchaintesting.FakeImageStreamGetter{Err: kerrors.NewForbidden(imagev1.Resource("imagestreamtags"), "lonely:latest", fmt.Errorf("denied"))},
+			Projects:         &buildchaintesting.FakeProjectLister{},
+			IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: errOut},
+		}
+		err := o.RunBuildChain()
+		if failFast {
+			if !kerrors.IsForbidden(err) {
+				t.Errorf("expected the failure to get the image stream tag with --fail-fast, got %v", err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count := strings.Count(errOut.String(), "warning: unable to get image stream tag"); count != 2 {
+			t.Errorf("expected a warning for each image stream tag, got:\n%s", errOut.String())
+		}
+	}
+}
+
+// fakeRenderer records the dot output it is given instead of laying it out.
+type fakeRenderer struct {
+	format string
+	dot    string
+}
+
+func (r *fakeRenderer) Render(format string, dot []byte) ([]byte, error) {
+	r.format, r.dot = format, string(dot)
+	return []byte("<svg/>"), nil
+}
+
+func TestRunBuildChainRender(t *testing.T) {
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{
+		BuildConfigs: []buildv1.BuildConfig{{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}},
+	}
+	path := filepath.Join(t.TempDir(), "chain.svg")
+	renderer := &fakeRenderer{}
+	out := &bytes.Buffer{}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		triggerOnly:      true,
+		output:           "dot",
+		render:           "svg",
+		outputFile:       path,
+		BuildConfigs:     buildConfigs,
+		ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+		Projects:         &buildchaintesting.FakeProjectLister{},
+		Renderer:         renderer,
+		IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	if renderer.format != "svg" || !strings.HasPrefix(renderer.dot, "digraph") {
+		t.Errorf("expected the dot output to be rendered as svg, got %q from:\n%s", renderer.format, renderer.dot)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "<svg/>" {
+		t.Errorf("expected the rendered image to be written, got %q, %v", data, err)
+	}
+	if files, err := os.ReadDir(filepath.Dir(path)); err != nil || len(files) != 1 {
+		t.Errorf("expected only the output file to be left, got %v, %v", files, err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("expected nothing on the standard output, got:\n%s", out.String())
+	}
+
+	for _, invalid := range []struct {
+		render, output, outputFile, err string
+		colorByStatus                   bool
+	}{
+		{render: "pdf", output: "dot", outputFile: path, err: "'svg' or 'png'"},
+		{render: "png", output: "json", outputFile: path, err: "requires the dot output"},
+		{render: "png", output: "dot", err: "requires --output-file"},
+		{output: "json", colorByStatus: true, err: "--color-by-status requires the dot output"},
+		{output: "json", outputFile: filepath.Join(path, "chain.json"), err: "is not a directory"},
+		{output: "json", outputFile: filepath.Join(t.TempDir(), "missing", "chain.json"), err: "is not accessible"},
+	} {
+		o.render, o.output, o.outputFile, o.colorByStatus = invalid.render, invalid.output, invalid.outputFile, invalid.colorByStatus
+		if err := o.Validate(); err == nil || !strings.Contains(err.Error(), invalid.err) {
+			t.Errorf("expected error containing %q, got %v", invalid.err, err)
+		}
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := NewGraphvizRenderer().Render("svg", []byte("digraph {}")); err == nil || !strings.Contains(err.Error(), "install graphviz") {
+		t.Errorf("expected a missing graphviz to be reported, got %v", err)
+	}
+}
+
+func TestRunBuildChainEnrichCommand(t *testing.T) {
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{
+		BuildConfigs: []buildv1.BuildConfig{{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}},
+	}
+	// the script only knows about image stream tags and counts their vulnerabilities
+	script := filepath.Join(t.TempDir(), "enrich.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ngrep -q '\"kind\":\"ImageStreamTag\"' && echo '{\"cmdb\": \"CI-42\", \"vulnerabilities\": 3}'\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		triggerOnly:      true,
+		output:           "ndjson",
+		enrichCommand:    script,
+		BuildConfigs:     buildConfigs,
+		ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+		Projects:         &buildchaintesting.FakeProjectLister{},
+		Enricher:         NewCommandEnricher(script),
+		IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		record := describe.ChainRecord{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		metadata[record.Node.ID] = record.Node.Metadata
+	}
+	expected := map[string]map[string]string{
+		"BuildConfig|test/app":            nil,
+		"ImageStreamTag|test/app:latest":  {"cmdb": "CI-42", "vulnerabilities": "3"},
+		"ImageStreamTag|test/base:latest": {"cmdb": "CI-42", "vulnerabilities": "3"},
+	}
+	if !reflect.DeepEqual(metadata, expected) {
+		t.Errorf("expected metadata %v, got %v", expected, metadata)
+	}
+
+	o.output = "dot"
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--enrich-command requires the json or ndjson output") {
+		t.Errorf("expected the dot output to be rejected, got %v", err)
+	}
+
+	failing := filepath.Join(t.TempDir(), "fail.sh")
+	if err := os.WriteFile(failing, []byte("#!/bin/sh\necho 'cmdb unreachable' >&2\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCommandEnricher(failing).Enrich(describe.ChainNode{ID: "BuildConfig|test/app"}); err == nil || !strings.Contains(err.Error(), "cmdb unreachable") {
+		t.Errorf("expected the error of the command to be reported, got %v", err)
+	}
+}
+
+func TestRunBuildChainShowVulnerabilities(t *testing.T) {
+	newBuildConfig := func(name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	newImageStreamTag := func(name, digest string) imagev1.ImageStreamTag {
+		return imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: digest}},
+		}
+	}
+	// the scanner didn't scan the image of app:latest
+	scanner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/vulnerabilities/sha256:base":
+			w.Write([]byte(`{"critical": 0, "high": 2, "medium": 0, "low": 5}`))
+		case "/vulnerabilities/sha256:runtime":
+			w.Write([]byte(`{"critical": 1, "high": 0, "medium": 0, "low": 0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer scanner.Close()
+	images := &buildchaintesting.FakeImageStreamGetter{ImageStreamTags: []imagev1.ImageStreamTag{
+		newImageStreamTag("base:latest", "sha256:base"),
+		newImageStreamTag("runtime:latest", "sha256:runtime"),
+		newImageStreamTag("app:latest", "sha256:app"),
+	}}
+	newOptions := func(failOn string) (*BuildChainOptions, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &BuildChainOptions{
+			entries:             []chainEntry{{namespace: "test", name: "base:latest"}},
+			defaultNamespace:    "test",
+			namespaces:          sets.NewString("test"),
+			triggerOnly:         true,
+			showVulnerabilities: true,
+			scannerURL:          scanner.URL + "/vulnerabilities/",
+			failOn:              failOn,
+			BuildConfigs:        &buildchaintesting.FakeBuildConfigLister{BuildConfigs: []buildv1.BuildConfig{newBuildConfig("runtime", "base:latest"), newBuildConfig("app", "runtime:latest")}},
+			ImageStreams:        images,
+			Projects:            &buildchaintesting.FakeProjectLister{},
+			Scanner:             NewHTTPScanner(scanner.URL+"/vulnerabilities/", images, scanner.Client()),
+			IOStreams:           genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+		}, out
+	}
+
+	o, out := newOptions("")
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"istag/base:latest [vulnerabilities: 2 high, 5 low]",
+		"istag/runtime:latest [vulnerabilities: 1 critical]",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out.String(), "istag/app:latest [vulnerabilities") {
+		t.Errorf("expected no vulnerabilities for the image that wasn't scanned:\n%s", out)
+	}
+
+	o, _ = newOptions("high")
+	err := o.RunBuildChain()
+	expected := "2 image stream tags have high vulnerabilities or worse:\n" +
+		"  ImageStreamTag|test/runtime:latest: 1 critical, 1 dependent build configs\n" +
+		"  ImageStreamTag|test/base:latest: 2 high, 5 low, 2 dependent build configs"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error:\n%s\ngot:\n%v", expected, err)
+	}
+
+	o, _ = newOptions("critical")
+	o.entries = []chainEntry{{namespace: "test", name: "app:latest"}}
+	if err := o.RunBuildChain(); err != nil {
+		t.Errorf("expected no image of severity critical in the chain of app, got %v", err)
+	}
+
+	o, _ = newOptions("severe")
+	if err := o.Validate(); err == nil || err.Error() != "--fail-on must be one of: critical, high, medium, low" {
+		t.Errorf("expected an unknown severity to be rejected, got %v", err)
+	}
+	o, _ = newOptions("")
+	o.scannerURL = "scanner"
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "requires --scanner-url") {
+		t.Errorf("expected a relative --scanner-url to be rejected, got %v", err)
+	}
+	o, _ = newOptions("critical")
+	o.showVulnerabilities = false
+	if err := o.Validate(); err == nil || err.Error() != "--fail-on requires --show-vulnerabilities" {
+		t.Errorf("expected --fail-on without --show-vulnerabilities to be rejected, got %v", err)
+	}
+}
+
+func TestRunBuildChainShowImageDetails(t *testing.T) {
+	newBuildConfig := func(name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	newImageStreamTag := func(name, digest string, updated time.Time, size int64) imagev1.ImageStreamTag {
+		return imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", CreationTimestamp: metav1.NewTime(updated)},
+			Image: imagev1.Image{
+				ObjectMeta:          metav1.ObjectMeta{Name: digest},
+				DockerImageMetadata: runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"kind": "DockerImage", "apiVersion": "1.0", "Size": %d}`, size))},
+			},
+		}
+	}
+	// runtime wasn't rebuilt since base was updated, app wasn't pushed yet
+	images := &buildchaintesting.FakeImageStreamGetter{ImageStreamTags: []imagev1.ImageStreamTag{
+		newImageStreamTag("base:latest", "sha256:0123456789abcdef0123", time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), 100*1000*1000),
+		newImageStreamTag("runtime:latest", "sha256:fedcba9876543210fedc", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), 0),
+	}}
+	newOptions := func(output string) (*BuildChainOptions, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &BuildChainOptions{
+			entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+			defaultNamespace: "test",
+			namespaces:       sets.NewString("test"),
+			triggerOnly:      true,
+			showImageDetails: true,
+			output:           output,
+			BuildConfigs:     &buildchaintesting.FakeBuildConfigLister{BuildConfigs: []buildv1.BuildConfig{newBuildConfig("runtime", "base:latest"), newBuildConfig("app", "runtime:latest")}},
+			ImageStreams:     images,
+			Projects:         &buildchaintesting.FakeProjectLister{},
+			Inspector:        NewImageInspector(images),
+			IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+		}, out
+	}
+
+	o, out := newOptions("")
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"istag/base:latest [image: sha256:0123456789ab, created 2026-03-02T00:00:00Z, 100MB]",
+		"istag/runtime:latest [image: sha256:fedcba987654, created 2026-03-01T00:00:00Z]",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out.String(), "istag/app:latest [image") {
+		t.Errorf("expected no image for the tag that wasn't pushed:\n%s", out)
+	}
+
+	o, out = newOptions("json")
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	chain := describe.ChainOutput{}
+	if err := json.Unmarshal(out.Bytes(), &chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range chain.Nodes {
+		if node.ID != "ImageStreamTag|test/base:latest" {
+			continue
+		}
+		expected := &describe.ImageDetails{Digest: "sha256:0123456789abcdef0123", Created: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Size: 100 * 1000 * 1000}
+		if !reflect.DeepEqual(node.Image, expected) {
+			t.Errorf("expected image %#v, got %#v", expected, node.Image)
+		}
+	}
+
+	o, _ = newOptions("")
+	o.simulate = true
+	if err := o.Validate(); err == nil || err.Error() != "--show-image-details can't be combined with --group-by-label, --critical-path or --simulate" {
+		t.Errorf("expected --show-image-details with --simulate to be rejected, got %v", err)
+	}
+
+	// the images are those of the tags, which --hide-tags merges
+	o, _ = newOptions("")
+	o.hideTags = true
+	if err := o.Validate(); err == nil || !strings.HasPrefix(err.Error(), "--hide-tags can't be combined with --split-by-tag") {
+		t.Errorf("expected --hide-tags with --show-image-details to be rejected, got %v", err)
+	}
+}
+
+func TestRunBuildChainMine(t *testing.T) {
+	newBuildConfig := func(namespace, name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "base", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{
+		BuildConfigs: []buildv1.BuildConfig{
+			newBuildConfig("base", "lib", "base:latest"),
+			newBuildConfig("base", "tool", "base:latest"),
+			newBuildConfig("team", "app", "lib:latest"),
+		},
+	}
+
+	out := &bytes.Buffer{}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "base", name: "base:latest"}},
+		defaultNamespace: "base",
+		namespaces:       sets.NewString("base", "team"),
+		triggerOnly:      true,
+		mine:             true,
+		BuildConfigs:     buildConfigs,
+		ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+		Projects:         &buildchaintesting.FakeProjectLister{},
+		AccessReviewer:   &buildchaintesting.FakeAccessReviewer{Editable: sets.NewString("team")},
+		IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"bc/lib", "bc/app"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "bc/tool") {
+		t.Errorf("expected bc/tool, which doesn't lead to an editable namespace, to be left out:\n%s", out.String())
+	}
+}
+
+func TestRunBuildChainCrossNamespace(t *testing.T) {
+	newBuildConfig := func(namespace, name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "base", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{
+		BuildConfigs: []buildv1.BuildConfig{
+			newBuildConfig("base", "lib", "base:latest"),
+			newBuildConfig("team", "app", "lib:latest"),
+		},
+	}
+
+	tests := []struct {
+		name  string
+		allow []string
+		err   string
+	}{
+		{
+			name: "denied",
+			err:  "1 dependencies cross namespaces without being allowed:\n  ImageStreamTag|base/lib:latest -> BuildConfig|team/app",
+		},
+		{
+			name:  "allowed",
+			allow: []string{"base:team"},
+		},
+		{
+			name:  "allowed to any namespace",
+			allow: []string{"base:*"},
+		},
+		{
+			name:  "allowed from other namespaces",
+			allow: []string{"team:*", "*:base"},
+			err:   "BuildConfig|team/app",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			o := &BuildChainOptions{
+				entries:             []chainEntry{{namespace: "base", name: "base:latest"}},
+				defaultNamespace:    "base",
+				namespaces:          sets.NewString("base", "team"),
+				triggerOnly:         true,
+				flagCrossNamespace:  true,
+				denyCrossNamespace:  true,
+				allowCrossNamespace: tt.allow,
+				BuildConfigs:        buildConfigs,
+				ImageStreams:        &buildchaintesting.FakeImageStreamGetter{},
+				Projects:            &buildchaintesting.FakeProjectLister{},
+				IOStreams:           genericiooptions.IOStreams{Out: out, ErrOut: errOut},
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			err := o.RunBuildChain()
+			if len(tt.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error containing %q, got %v", tt.err, err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), "<team bc/app> (cross-namespace)") {
+				t.Errorf("expected the dependency of bc/app to be flagged:\n%s", out.String())
+			}
+			if !strings.Contains(errOut.String(), "warning: 1 dependencies of the build chain") {
+				t.Errorf("expected a warning about the dependencies between namespaces, got:\n%s", errOut.String())
+			}
+		})
+	}
+
+	o := &BuildChainOptions{entries: []chainEntry{{namespace: "base", name: "base:latest"}}, defaultNamespace: "base", denyCrossNamespace: true, allowCrossNamespace: []string{"base"}}
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "FROM:TO") {
+		t.Errorf("expected an invalid allowed dependency to be rejected, got %v", err)
+	}
+}
+
+func TestRunBuildChainAtGeneration(t *testing.T) {
+	created := func(hour int) metav1.Time {
+		return metav1.NewTime(time.Date(2024, 1, 1, hour, 0, 0, 0, time.UTC))
+	}
+	newBuildConfig := func(name string, hour int) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", CreationTimestamp: created(hour)},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+			},
+		}
+	}
+	imageStreams := &buildchaintesting.FakeImageStreamGetter{
+		ImageStreamTags: []imagev1.ImageStreamTag{{ObjectMeta: metav1.ObjectMeta{Name: "base:latest", Namespace: "test"}}},
+		ImageStreams: []imagev1.ImageStream{{
+			ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "test"},
+			Status: imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{{
+				Tag: "latest",
+				Items: []imagev1.TagEvent{
+					{Image: "sha256:new", Generation: 5, Created: created(12)},
+					{Image: "sha256:old", Generation: 2, Created: created(6)},
+				},
+			}}},
+		}},
+	}
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		atGeneration:     2,
+		BuildConfigs: &buildchaintesting.FakeBuildConfigLister{
+			BuildConfigs: []buildv1.BuildConfig{newBuildConfig("before", 3), newBuildConfig("after", 9)},
+		},
+		ImageStreams: imageStreams,
+		Projects:     &buildchaintesting.FakeProjectLister{},
+		IOStreams:    genericiooptions.IOStreams{Out: out, ErrOut: errOut},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "bc/before") || strings.Contains(out.String(), "bc/after") {
+		t.Errorf("expected only the build config created before generation 2, got:\n%s", out)
+	}
+	if !strings.Contains(errOut.String(), "pointed to sha256:old (generation 2)") {
+		t.Errorf("unexpected output %q", errOut)
+	}
+
+	errOut.Reset()
+	o.at, o.utc = nil, true
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(errOut.String(), "(generation 2) at 2024-01-01T06:00:00Z") {
+		t.Errorf("expected the time in UTC with --utc, got %q", errOut)
+	}
+
+	o.atGeneration = 3
+	o.at = nil
+	if err := o.RunBuildChain(); err == nil || !strings.Contains(err.Error(), "not in the history") {
+		t.Errorf("expected a missing generation error, got %v", err)
+	}
+}
+
+func TestRunBuildChainContexts(t *testing.T) {
+	newBuildConfig := func(name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	newImageStreamTag := func(name, digest string) imagev1.ImageStreamTag {
+		return imagev1.ImageStreamTag{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: digest}},
+		}
+	}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		triggerOnly:      true,
+		merge:            true,
+		output:           "json",
+		contexts:         []string{"staging", "production"},
+		// the current context isn't described
+		BuildConfigs: &buildchaintesting.FakeBuildConfigLister{},
+		ImageStreams: &buildchaintesting.FakeImageStreamGetter{},
+		Projects:     &buildchaintesting.FakeProjectLister{},
+	}
+	out := &bytes.Buffer{}
+	o.IOStreams = genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}}
+	// base:latest points to the same image in both clusters, which only
+	// production built app from
+	for _, cluster := range []struct {
+		context      string
+		buildConfigs []buildv1.BuildConfig
+	}{
+		{context: "staging"},
+		{context: "production", buildConfigs: []buildv1.BuildConfig{newBuildConfig("app", "base:latest")}},
+	} {
+		images := &buildchaintesting.FakeImageStreamGetter{ImageStreamTags: []imagev1.ImageStreamTag{newImageStreamTag("base:latest", "sha256:0123456789abcdef0123")}}
+		options := o.clusterOptions()
+		options.BuildConfigs = &buildchaintesting.FakeBuildConfigLister{BuildConfigs: cluster.buildConfigs}
+		options.ImageStreams = images
+		options.Projects = &buildchaintesting.FakeProjectLister{}
+		options.Inspector = NewImageInspector(images)
+		o.clusters = append(o.clusters, chainCluster{context: cluster.context, options: options})
+	}
+
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.RunBuildChain(); err != nil {
+		t.Fatal(err)
+	}
+	chain := describe.ChainOutput{}
+	if err := json.Unmarshal(out.Bytes(), &chain); err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	if chain.Root != "ImageStreamTag|sha256:0123456789abcdef0123" {
+		t.Errorf("expected the root to be the image of base:latest, got %q", chain.Root)
+	}
+	ids := []string{}
+	for _, node := range chain.Nodes {
+		ids = append(ids, node.ID)
+	}
+	expected := []string{"BuildConfig|test/app@production", "ImageStreamTag|sha256:0123456789abcdef0123", "ImageStreamTag|test/app:latest@production"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected the nodes %v, got %v", expected, ids)
+	}
+
+	o.output = ""
+	if err := o.Validate(); err == nil || err.Error() != "--contexts requires -o json" {
+		t.Errorf("expected --contexts without -o json to be rejected, got %v", err)
+	}
+	o.output, o.merge = "json", false
+	if err := o.Validate(); err == nil || err.Error() != "--contexts requires --merge" {
+		t.Errorf("expected --contexts without --merge to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package buildchain
+
+import (
+	"context"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/klog/v2"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	projectv1 "github.com/openshift/api/project/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	projectv1client "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// ImageStreamGetter gets the image stream tags build-chain is run for, and
+// their image streams to look up the history of the tags. It lists the image
+// streams of the chain namespaces to resolve the outputs pushed to the
+// integrated registry.
+type ImageStreamGetter interface {
+	describe.ImageStreamLister
+	GetImageStreamTag(ctx context.Context, namespace, name string) (*imagev1.ImageStreamTag, error)
+	GetImageStream(ctx context.Context, namespace, name string) (*imagev1.ImageStream, error)
+}
+
+// ProjectLister lists the projects searched for build configurations with --all.
+type ProjectLister interface {
+	ListProjects(ctx context.Context) ([]projectv1.Project, error)
+}
+
+// AccessReviewer tells the namespaces the current user can edit the build
+// configs of, to limit the chain to them with --mine.
+type AccessReviewer interface {
+	CanEditBuildConfigs(ctx context.Context, namespace string) (bool, error)
+}
+
+// ChainWatcher tells when the build configs or the image streams of
+// namespaces change, to describe the chain again with --watch. The channel is
+// closed once ctx is done.
+type ChainWatcher interface {
+	Watch(ctx context.Context, namespaces []string) (<-chan struct{}, error)
+}
+
+// BuildStarter starts the builds of the build configs of the chain with
+// --start, and gets them to wait for them to finish.
+type BuildStarter interface {
+	StartBuild(ctx context.Context, namespace, name string) (*buildv1.Build, error)
+	GetBuild(ctx context.Context, namespace, name string) (*buildv1.Build, error)
+}
+
+// NewImageStreamGetter returns an ImageStreamGetter backed by the image API.
+func NewImageStreamGetter(c imagev1client.ImageV1Interface) ImageStreamGetter {
+	return &imageStreamGetter{ImageStreamLister: describe.NewImageStreamLister(c), c: c}
+}
+
+type imageStreamGetter struct {
+	describe.ImageStreamLister
+	c imagev1client.ImageV1Interface
+}
+
+func (g *imageStreamGetter) GetImageStreamTag(ctx context.Context, namespace, name string) (*imagev1.ImageStreamTag, error) {
+	return g.c.ImageStreamTags(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func (g *imageStreamGetter) GetImageStream(ctx context.Context, namespace, name string) (*imagev1.ImageStream, error) {
+	return g.c.ImageStreams(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// NewProjectLister returns a ProjectLister backed by the project API.
+func NewProjectLister(c projectv1client.ProjectV1Interface) ProjectLister {
+	return &projectLister{c: c}
+}
+
+type projectLister struct {
+	c projectv1client.ProjectV1Interface
+}
+
+func (l *projectLister) ListProjects(ctx context.Context) ([]projectv1.Project, error) {
+	list, err := l.c.Projects().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// NewAccessReviewer returns an AccessReviewer sending self subject access
+// reviews.
+func NewAccessReviewer(c authorizationv1client.AuthorizationV1Interface) AccessReviewer {
+	return &accessReviewer{c: c}
+}
+
+type accessReviewer struct {
+	c authorizationv1client.AuthorizationV1Interface
+}
+
+func (r *accessReviewer) CanEditBuildConfigs(ctx context.Context, namespace string) (bool, error) {
+	review, err := r.c.SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "update",
+				Group:     "build.openshift.io",
+				Resource:  "buildconfigs",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// NewBuildStarter returns a BuildStarter instantiating build configs through
+// the build API.
+func NewBuildStarter(c buildv1client.BuildV1Interface) BuildStarter {
+	return &buildStarter{c: c}
+}
+
+type buildStarter struct {
+	c buildv1client.BuildV1Interface
+}
+
+func (s *buildStarter) StartBuild(ctx context.Context, namespace, name string) (*buildv1.Build, error) {
+	return s.c.BuildConfigs(namespace).Instantiate(ctx, name, &buildv1.BuildRequest{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		TriggeredBy: []buildv1.BuildTriggerCause{{Message: "Started by oc adm build-chain --start"}},
+	}, metav1.CreateOptions{})
+}
+
+func (s *buildStarter) GetBuild(ctx context.Context, namespace, name string) (*buildv1.Build, error) {
+	return s.c.Builds(namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+// NewChainWatcher returns a ChainWatcher watching the build configs matching
+// selector and the image streams through the build and image APIs.
+func NewChainWatcher(build buildv1client.BuildV1Interface, image imagev1client.ImageV1Interface, selector labels.Selector) ChainWatcher {
+	return &chainWatcher{build: build, image: image, selector: selector}
+}
+
+type chainWatcher struct {
+	build    buildv1client.BuildV1Interface
+	image    imagev1client.ImageV1Interface
+	selector labels.Selector
+}
+
+// watchRetryPeriod is how long a watch that failed or was closed by the
+// server waits before being opened again.
+const watchRetryPeriod = 5 * time.Second
+
+func (w *chainWatcher) Watch(ctx context.Context, namespaces []string) (<-chan struct{}, error) {
+	changes := make(chan struct{}, 1)
+	watchers := []func(context.Context) (watch.Interface, error){}
+	for _, namespace := range namespaces {
+		namespace := namespace
+		watchers = append(watchers,
+			func(ctx context.Context) (watch.Interface, error) {
+				opts := metav1.ListOptions{}
+				if !w.selector.Empty() {
+					opts.LabelSelector = w.selector.String()
+				}
+				return w.build.BuildConfigs(namespace).Watch(ctx, opts)
+			},
+			func(ctx context.Context) (watch.Interface, error) {
+				return w.image.ImageStreams(namespace).Watch(ctx, metav1.ListOptions{})
+			},
+		)
+	}
+
+	done := make(chan struct{})
+	for _, open := range watchers {
+		open := open
+		go func() {
+			defer func() { done <- struct{}{} }()
+			wait.UntilWithContext(ctx, func(ctx context.Context) {
+				watcher, err := open(ctx)
+				if err != nil {
+					klog.V(2).Infof("Unable to watch the build chain, retrying: %v", err)
+					return
+				}
+				defer watcher.Stop()
+				for event := range watcher.ResultChan() {
+					if event.Type == watch.Error {
+						klog.V(2).Infof("Watch of the build chain failed, retrying: %v", event.Object)
+						return
+					}
+					// a pending change is enough
+					select {
+					case changes <- struct{}{}:
+					default:
+					}
+				}
+			}, watchRetryPeriod)
+		}()
+	}
+	go func() {
+		for range watchers {
+			<-done
+		}
+		close(changes)
+	}()
+	return changes, nil
+}
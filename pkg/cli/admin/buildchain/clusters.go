@@ -0,0 +1,121 @@
+package buildchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// chainCluster is a cluster of --contexts whose build chain is merged with
+// the build chains of the other clusters.
+type chainCluster struct {
+	// context is the name of the kubeconfig context of the cluster.
+	context string
+	// options describe the build chain of the cluster in json, with the
+	// images of its image stream tags.
+	options *BuildChainOptions
+}
+
+// completeClusters sets o.clusters to the options of the contexts of
+// --contexts, reading from the clusters of those contexts in the kubeconfig
+// instead of the current one.
+func (o *BuildChainOptions) completeClusters(f kcmdutil.Factory) error {
+	if len(o.contexts) == 0 || len(o.clusters) > 0 {
+		return nil
+	}
+	loader := f.ToRawKubeConfigLoader()
+	rawConfig, err := loader.RawConfig()
+	if err != nil {
+		return err
+	}
+	for _, name := range o.contexts {
+		if _, ok := rawConfig.Contexts[name]; !ok {
+			return fmt.Errorf("context %q not found in the kubeconfig", name)
+		}
+		clientConfig, err := clientcmd.NewNonInteractiveClientConfig(rawConfig, name, &clientcmd.ConfigOverrides{}, loader.ConfigAccess()).ClientConfig()
+		if err != nil {
+			return err
+		}
+		cluster := o.clusterOptions()
+		if err := cluster.completeClientsFromConfig(clientConfig); err != nil {
+			return err
+		}
+		cluster.Inspector = NewImageInspector(cluster.ImageStreams)
+		if o.showVulnerabilities {
+			cluster.Scanner = NewHTTPScanner(o.scannerURL, cluster.ImageStreams, &http.Client{Timeout: 30 * time.Second})
+		}
+		if o.allNamespaces {
+			if err := cluster.completeAllNamespaces(context.TODO()); err != nil {
+				return fmt.Errorf("unable to list the projects of context %q: %v", name, err)
+			}
+		}
+		o.clusters = append(o.clusters, chainCluster{context: name, options: cluster})
+	}
+	return nil
+}
+
+// clusterOptions returns a copy of o describing the merged build chain of a
+// cluster in json, with the images of its image stream tags so that the tags
+// of the clusters pointing to the same image can be told. Its clients are
+// left for the caller to set.
+func (o *BuildChainOptions) clusterOptions() *BuildChainOptions {
+	cluster := *o
+	cluster.contexts, cluster.clusters = nil, nil
+	cluster.namespaces = sets.NewString(o.namespaces.List()...)
+	cluster.merge = true
+	cluster.output = "json"
+	cluster.outputFile = ""
+	cluster.provenance = nil
+	cluster.showImageDetails = true
+	cluster.BuildConfigs = nil
+	cluster.ImageStreams = nil
+	cluster.Projects = nil
+	cluster.DeploymentConfigs = nil
+	cluster.AccessReviewer = nil
+	cluster.Inspector = nil
+	cluster.Scanner = nil
+	return &cluster
+}
+
+// runClusters describes the build chain of every cluster of --contexts and
+// outputs them merged into a single graph, where the image stream tags
+// pointing to the same image are a single node.
+func (o *BuildChainOptions) runClusters() error {
+	chains := []describe.ClusterChain{}
+	for _, cluster := range o.clusters {
+		out := &bytes.Buffer{}
+		cluster.options.Out = out
+		if err := cluster.options.RunBuildChain(); err != nil {
+			return fmt.Errorf("unable to describe the build chain of context %q: %v", cluster.context, err)
+		}
+		if !bytes.HasPrefix(out.Bytes(), []byte("{")) {
+			// none of the image stream tags have dependencies in the cluster
+			klog.V(4).Infof("Skipping context %q: %s", cluster.context, bytes.TrimSpace(out.Bytes()))
+			continue
+		}
+		chain := &describe.ChainOutput{}
+		if err := json.Unmarshal(out.Bytes(), chain); err != nil {
+			return fmt.Errorf("unable to read the build chain of context %q: %v", cluster.context, err)
+		}
+		chains = append(chains, describe.ClusterChain{Cluster: cluster.context, Output: chain})
+	}
+	if len(chains) == 0 {
+		fmt.Fprintln(o.Out, "None of the image stream tags have any dependencies.")
+		return nil
+	}
+	desc, err := describe.MergeClusterChains(chains)
+	if err != nil {
+		return err
+	}
+	return o.writeOutput(desc)
+}
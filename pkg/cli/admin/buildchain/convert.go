@@ -0,0 +1,182 @@
+package buildchain
+
+import (
+	"encoding/json"
+	"strings"
+
+	buildchainv1 "github.com/openshift/oc/pkg/cli/admin/buildchain/api/v1"
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// versionedOutput returns the json or ndjson output of the describer
+// converted into the types of the current version of the output, which leave
+// out whatever the describer outputs that isn't part of that version. The
+// other outputs are returned unchanged.
+func (o *BuildChainOptions) versionedOutput(output string) (string, error) {
+	switch {
+	case len(o.criticalPath) > 0 || o.simulate || o.start:
+		// the critical path and the simulation aren't versioned
+		return output, nil
+	case o.output == "json" && !strings.HasPrefix(output, "{"):
+		// cycles are reported instead of the groups
+		return output, nil
+	case o.output == "json" && len(o.groupByLabel) > 0:
+		in := &describe.ChainGroups{}
+		if err := json.Unmarshal([]byte(output), in); err != nil {
+			return "", err
+		}
+		return marshalIndent(convertChainGroups(in))
+	case o.output == "json":
+		in := &describe.ChainOutput{}
+		if err := json.Unmarshal([]byte(output), in); err != nil {
+			return "", err
+		}
+		return marshalIndent(convertChainOutput(in))
+	case o.output == "ndjson":
+		lines := []string{}
+		for _, line := range strings.Split(output, "\n") {
+			if len(line) == 0 {
+				continue
+			}
+			in := &describe.ChainRecord{}
+			if err := json.Unmarshal([]byte(line), in); err != nil {
+				return "", err
+			}
+			data, err := json.Marshal(convertChainRecord(in))
+			if err != nil {
+				return "", err
+			}
+			lines = append(lines, string(data))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	return output, nil
+}
+
+// marshalIndent returns v in json, indented as the describer does.
+func marshalIndent(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// convertChainOutput converts a build chain of the describer into a v1
+// ChainOutput.
+func convertChainOutput(in *describe.ChainOutput) *buildchainv1.ChainOutput {
+	out := &buildchainv1.ChainOutput{
+		Root:       in.Root,
+		Roots:      in.Roots,
+		Nodes:      []buildchainv1.ChainNode{},
+		Edges:      convertChainEdges(in.Edges),
+		Provenance: convertChainProvenance(in.Provenance),
+	}
+	for _, node := range in.Nodes {
+		out.Nodes = append(out.Nodes, convertChainNode(node))
+	}
+	for _, warning := range in.Warnings {
+		out.Warnings = append(out.Warnings, buildchainv1.ChainWarning{
+			Code:        buildchainv1.WarningCode(warning.Code),
+			Namespace:   warning.Namespace,
+			BuildConfig: warning.BuildConfig,
+			Message:     warning.Message,
+			Cluster:     warning.Cluster,
+		})
+	}
+	return out
+}
+
+// convertChainRecord converts a line of the ndjson output of the describer
+// into a v1 ChainRecord.
+func convertChainRecord(in *describe.ChainRecord) *buildchainv1.ChainRecord {
+	return &buildchainv1.ChainRecord{
+		Node:  convertChainNode(in.Node),
+		Root:  in.Root,
+		Edges: convertChainEdges(in.Edges),
+	}
+}
+
+// convertChainGroups converts the groups of a build chain of the describer
+// into v1 ChainGroups.
+func convertChainGroups(in *describe.ChainGroups) *buildchainv1.ChainGroups {
+	out := &buildchainv1.ChainGroups{
+		Label:        in.Label,
+		Groups:       in.Groups,
+		Dependencies: []buildchainv1.GroupDependency{},
+		Provenance:   convertChainProvenance(in.Provenance),
+	}
+	for _, dependency := range in.Dependencies {
+		out.Dependencies = append(out.Dependencies, buildchainv1.GroupDependency{From: dependency.From, To: dependency.To, Count: dependency.Count})
+	}
+	return out
+}
+
+func convertChainNode(in describe.ChainNode) buildchainv1.ChainNode {
+	out := buildchainv1.ChainNode{
+		ID:           in.ID,
+		Kind:         in.Kind,
+		Namespace:    in.Namespace,
+		Name:         in.Name,
+		Environments: in.Environments,
+		Truncated:    in.Truncated,
+		Metadata:     in.Metadata,
+		Custom:       in.Custom,
+		Cluster:      in.Cluster,
+	}
+	if in.Vulnerabilities != nil {
+		out.Vulnerabilities = &buildchainv1.VulnerabilityCounts{
+			Critical: in.Vulnerabilities.Critical,
+			High:     in.Vulnerabilities.High,
+			Medium:   in.Vulnerabilities.Medium,
+			Low:      in.Vulnerabilities.Low,
+		}
+	}
+	if in.Image != nil {
+		out.Image = &buildchainv1.ImageDetails{Digest: in.Image.Digest, Created: in.Image.Created, Size: in.Image.Size}
+	}
+	for _, alias := range in.Aliases {
+		out.Aliases = append(out.Aliases, buildchainv1.TagAlias{Cluster: alias.Cluster, Namespace: alias.Namespace, Name: alias.Name})
+	}
+	return out
+}
+
+func convertChainEdges(in []describe.ChainEdge) []buildchainv1.ChainEdge {
+	out := []buildchainv1.ChainEdge{}
+	for _, edge := range in {
+		converted := buildchainv1.ChainEdge{
+			From:           edge.From,
+			To:             edge.To,
+			Kinds:          edge.Kinds,
+			Tag:            edge.Tag,
+			Cycle:          edge.Cycle,
+			CrossNamespace: edge.CrossNamespace,
+		}
+		if bc := edge.BuildConfig; bc != nil {
+			converted.BuildConfig = &buildchainv1.EdgeBuildConfig{
+				Strategy:   bc.Strategy,
+				GitURI:     bc.GitURI,
+				GitRef:     bc.GitRef,
+				OutputKind: bc.OutputKind,
+				Output:     bc.Output,
+			}
+		}
+		out = append(out, converted)
+	}
+	return out
+}
+
+func convertChainProvenance(in *describe.ChainProvenance) *buildchainv1.ChainProvenance {
+	if in == nil {
+		return nil
+	}
+	return &buildchainv1.ChainProvenance{
+		ClientVersion: in.ClientVersion,
+		ServerVersion: in.ServerVersion,
+		User:          in.User,
+		Namespaces:    in.Namespaces,
+		AllNamespaces: in.AllNamespaces,
+		Flags:         in.Flags,
+		ContentHash:   in.ContentHash,
+	}
+}
@@ -0,0 +1,116 @@
+package buildchain
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	buildchainv1 "github.com/openshift/oc/pkg/cli/admin/buildchain/api/v1"
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// TestConvertedTypes checks that the v1 types hold every field of the types
+// of the describer, so that nothing the describer outputs is left out of the
+// v1 output without a decision to.
+func TestConvertedTypes(t *testing.T) {
+	for _, types := range [][2]interface{}{
+		{describe.ChainOutput{}, buildchainv1.ChainOutput{}},
+		{describe.ChainNode{}, buildchainv1.ChainNode{}},
+		{describe.TagAlias{}, buildchainv1.TagAlias{}},
+		{describe.VulnerabilityCounts{}, buildchainv1.VulnerabilityCounts{}},
+		{describe.ImageDetails{}, buildchainv1.ImageDetails{}},
+		{describe.ChainEdge{}, buildchainv1.ChainEdge{}},
+		{describe.EdgeBuildConfig{}, buildchainv1.EdgeBuildConfig{}},
+		{describe.ChainRecord{}, buildchainv1.ChainRecord{}},
+		{describe.ChainGroups{}, buildchainv1.ChainGroups{}},
+		{describe.GroupDependency{}, buildchainv1.GroupDependency{}},
+		{describe.ChainProvenance{}, buildchainv1.ChainProvenance{}},
+		{describe.ChainWarning{}, buildchainv1.ChainWarning{}},
+	} {
+		from, to := reflect.TypeOf(types[0]), reflect.TypeOf(types[1])
+		if fields, expected := jsonFields(from), jsonFields(to); !reflect.DeepEqual(fields, expected) {
+			t.Errorf("expected the fields of describe.%s to be those of v1.%s %v, got %v", from.Name(), to.Name(), expected, fields)
+		}
+	}
+}
+
+func TestVersionedOutput(t *testing.T) {
+	chain := &describe.ChainOutput{
+		Root: "ImageStreamTag|test/base:latest",
+		Nodes: []describe.ChainNode{
+			{
+				ID:              "ImageStreamTag|test/base:latest",
+				Kind:            "ImageStreamTag",
+				Namespace:       "test",
+				Name:            "base:latest",
+				Environments:    []string{"prod"},
+				Truncated:       true,
+				Metadata:        map[string]string{"owner": "web"},
+				Vulnerabilities: &describe.VulnerabilityCounts{Critical: 1, High: 2, Medium: 3, Low: 4},
+				Image:           &describe.ImageDetails{Digest: "sha256:0123456789abcdef0123", Created: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), Size: 100},
+				Cluster:         "staging",
+				Aliases:         []describe.TagAlias{{Cluster: "staging", Namespace: "test", Name: "base:latest"}},
+			},
+			{ID: "BuildConfig|test/app", Kind: "BuildConfig", Namespace: "test", Name: "app", Custom: true},
+		},
+		Edges: []describe.ChainEdge{{
+			From:           "ImageStreamTag|test/base:latest",
+			To:             "BuildConfig|test/app",
+			Kinds:          []string{"BuildInputImage"},
+			Tag:            "test/base:latest",
+			BuildConfig:    &describe.EdgeBuildConfig{Strategy: "Docker", GitURI: "https://example.com/app.git", GitRef: "main", OutputKind: "ImageStreamTag", Output: "test/app:latest"},
+			Cycle:          true,
+			CrossNamespace: true,
+		}},
+		Provenance: &describe.ChainProvenance{ClientVersion: "v4.20.0", ServerVersion: "v1.33.0", User: "developer", Namespaces: []string{"test"}, AllNamespaces: true, Flags: []string{"--all"}, ContentHash: "sha256:0123"},
+		Warnings:   []describe.ChainWarning{{Code: describe.WarningMissingTag, Namespace: "test", BuildConfig: "app", Message: "<missing tag>", Cluster: "staging"}},
+	}
+	groups := &describe.ChainGroups{
+		Label:        "team",
+		Groups:       []string{"base", "web"},
+		Dependencies: []describe.GroupDependency{{From: "base", To: "web", Count: 2}},
+		Provenance:   chain.Provenance,
+	}
+	marshal := func(v interface{}) string {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	records := []string{}
+	for _, record := range []describe.ChainRecord{{Node: chain.Nodes[0], Root: true, Edges: chain.Edges}, {Node: chain.Nodes[1], Edges: []describe.ChainEdge{}}} {
+		data, err := json.Marshal(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, string(data))
+	}
+
+	// the v1 output is the describer's as long as their types hold the same
+	// fields
+	for _, test := range []struct {
+		name   string
+		o      *BuildChainOptions
+		output string
+	}{
+		{name: "json", o: &BuildChainOptions{output: "json"}, output: marshal(chain)},
+		{name: "groups", o: &BuildChainOptions{output: "json", groupByLabel: "team"}, output: marshal(groups)},
+		{name: "ndjson", o: &BuildChainOptions{output: "ndjson"}, output: strings.Join(records, "\n")},
+		{name: "cycle instead of groups", o: &BuildChainOptions{output: "json", groupByLabel: "team"}, output: "Cycle detected in build configurations: bc/a -> bc/b"},
+		{name: "simulation", o: &BuildChainOptions{output: "json", simulate: true}, output: `{"stages": []}`},
+		{name: "dot", o: &BuildChainOptions{output: "dot"}, output: "digraph {}"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := test.o.versionedOutput(test.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if output != test.output {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.output, output)
+			}
+		})
+	}
+}
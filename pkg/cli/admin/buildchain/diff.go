@@ -0,0 +1,174 @@
+package buildchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+var (
+	buildChainDiffLong = templates.LongDesc(`
+		Compare two build chains saved with 'build-chain -o json'.
+
+		Added nodes and edges are prefixed with '+', removed ones with '-' and edges
+		whose kinds or tag changed with '~'. Nothing is printed when the build chains
+		are identical.
+	`)
+
+	buildChainDiffExample = templates.Examples(`
+		# Review the changes to the build chain of an image stream
+		oc adm build-chain <image-stream> -o json > old.json
+		oc apply -f buildconfigs/
+		oc adm build-chain <image-stream> -o json > new.json
+		oc adm build-chain diff old.json new.json
+
+		# Compare the saved build chain with the current one directly
+		oc adm build-chain <image-stream> --diff-against=old.json
+	`)
+)
+
+// BuildChainDiffOptions contains all the options needed for build-chain diff
+type BuildChainDiffOptions struct {
+	OldPath string
+	NewPath string
+
+	genericiooptions.IOStreams
+}
+
+// NewCmdBuildChainDiff implements the build-chain diff command
+func NewCmdBuildChainDiff(streams genericiooptions.IOStreams) *cobra.Command {
+	o := &BuildChainDiffOptions{IOStreams: streams}
+	cmd := &cobra.Command{
+		Use:     "diff OLD.json NEW.json",
+		Short:   "Compare two build chains saved in json",
+		Long:    buildChainDiffLong,
+		Example: buildChainDiffExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				kcmdutil.CheckErr(kcmdutil.UsageErrorf(cmd, "two build chain files are required"))
+			}
+			o.OldPath, o.NewPath = args[0], args[1]
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	return cmd
+}
+
+func (o *BuildChainDiffOptions) Run() error {
+	oldChain, err := readChain(o.OldPath)
+	if err != nil {
+		return err
+	}
+	newChain, err := readChain(o.NewPath)
+	if err != nil {
+		return err
+	}
+	for _, line := range diffChains(oldChain, newChain) {
+		fmt.Fprintln(o.Out, line)
+	}
+	return nil
+}
+
+func readChain(path string) (*describe.ChainOutput, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	chain := &describe.ChainOutput{}
+	if err := decoder.Decode(chain); err != nil {
+		return nil, fmt.Errorf("%s is not a build chain saved with -o json: %v", path, err)
+	}
+	return chain, nil
+}
+
+// diffChains returns the sorted lines describing the nodes and edges added,
+// removed or changed from oldChain to newChain.
+func diffChains(oldChain, newChain *describe.ChainOutput) []string {
+	lines := []string{}
+
+	oldNodes, newNodes := map[string]bool{}, map[string]bool{}
+	for _, n := range oldChain.Nodes {
+		oldNodes[n.ID] = true
+	}
+	for _, n := range newChain.Nodes {
+		newNodes[n.ID] = true
+		if !oldNodes[n.ID] {
+			lines = append(lines, "+ "+n.ID)
+		}
+	}
+	for _, n := range oldChain.Nodes {
+		if !newNodes[n.ID] {
+			lines = append(lines, "- "+n.ID)
+		}
+	}
+
+	key := func(e describe.ChainEdge) string { return e.From + " -> " + e.To }
+	oldEdges, newEdges := map[string]describe.ChainEdge{}, map[string]describe.ChainEdge{}
+	for _, e := range oldChain.Edges {
+		oldEdges[key(e)] = e
+	}
+	for _, e := range newChain.Edges {
+		newEdges[key(e)] = e
+		old, ok := oldEdges[key(e)]
+		switch {
+		case !ok:
+			lines = append(lines, "+ "+edgeString(e))
+		case edgeString(old) != edgeString(e):
+			lines = append(lines, fmt.Sprintf("~ %s (was %s)", edgeString(e), edgeDetails(old)))
+		}
+	}
+	for _, e := range oldChain.Edges {
+		if _, ok := newEdges[key(e)]; !ok {
+			lines = append(lines, "- "+edgeString(e))
+		}
+	}
+
+	// sort on what follows the prefix so that additions and removals of the
+	// same node or edge are printed next to each other
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i][2:] < lines[j][2:] })
+	return lines
+}
+
+func edgeString(e describe.ChainEdge) string {
+	return fmt.Sprintf("%s -> %s %s", e.From, e.To, edgeDetails(e))
+}
+
+func edgeDetails(e describe.ChainEdge) string {
+	details := strings.Join(e.Kinds, ",")
+	if len(e.Tag) > 0 {
+		details += " (" + e.Tag + ")"
+	}
+	return "[" + details + "]"
+}
+
+// diffAgainstSaved returns the lines describing the changes from the build
+// chain saved in --diff-against to output, the current build chain in json.
+func (o *BuildChainOptions) diffAgainstSaved(output string) ([]string, error) {
+	saved, err := readChain(o.diffAgainst)
+	if err != nil {
+		return nil, err
+	}
+	current := &describe.ChainOutput{}
+	if err := json.Unmarshal([]byte(output), current); err != nil {
+		return nil, fmt.Errorf("unable to read the current build chain: %v", err)
+	}
+	return diffChains(saved, current), nil
+}
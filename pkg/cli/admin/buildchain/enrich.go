@@ -0,0 +1,59 @@
+package buildchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// NewCommandEnricher returns a describe.NodeEnricher running command for
+// every node, for --enrich-command. The node is written in json to the
+// standard input of the command, which prints the metadata of the node as a
+// json object on its standard output, or nothing when it has none. String
+// values are kept as they are and other values as their json text.
+func NewCommandEnricher(command string) describe.NodeEnricher {
+	return commandEnricher{command: command}
+}
+
+type commandEnricher struct {
+	command string
+}
+
+func (e commandEnricher) Enrich(node describe.ChainNode) (map[string]string, error) {
+	input, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(e.command)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("%s failed: %v: %s", e.command, err, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %v", e.command, err)
+	}
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return nil, nil
+	}
+
+	values := map[string]json.RawMessage{}
+	if err := json.Unmarshal(stdout.Bytes(), &values); err != nil {
+		return nil, fmt.Errorf("%s must print a json object: %v", e.command, err)
+	}
+	metadata := map[string]string{}
+	for key, value := range values {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		metadata[key] = s
+	}
+	return metadata, nil
+}
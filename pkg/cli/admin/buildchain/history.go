@@ -0,0 +1,91 @@
+package buildchain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+)
+
+// resolveAt sets the time the build chain is described at from --at-time or
+// --at-generation, and reports the image every image stream tag pointed to
+// at that time.
+func (o *BuildChainOptions) resolveAt(ctx context.Context) error {
+	switch {
+	case len(o.atTime) > 0:
+		at, err := time.Parse(time.RFC3339, o.atTime)
+		if err != nil {
+			return err
+		}
+		o.at = &at
+	case o.atGeneration > 0:
+		entry := o.entries[0]
+		history, err := o.tagHistory(ctx, entry)
+		if err != nil {
+			return err
+		}
+		for _, event := range history {
+			if event.Generation == o.atGeneration {
+				at := event.Created.Time
+				o.at = &at
+				break
+			}
+		}
+		if o.at == nil {
+			return fmt.Errorf("generation %d of image stream tag %q in %q is not in the history of the tag", o.atGeneration, entry.name, entry.namespace)
+		}
+	default:
+		return nil
+	}
+
+	for _, entry := range o.entries {
+		history, err := o.tagHistory(ctx, entry)
+		if err != nil {
+			return err
+		}
+		event, ok := eventAt(history, *o.at)
+		if !ok {
+			fmt.Fprintf(o.ErrOut, "Image stream tag %q in %q pointed to no image at %s\n", entry.name, entry.namespace, o.formatTime(*o.at))
+			continue
+		}
+		fmt.Fprintf(o.ErrOut, "Image stream tag %q in %q pointed to %s (generation %d) at %s\n", entry.name, entry.namespace, event.Image, event.Generation, o.formatTime(*o.at))
+	}
+	return nil
+}
+
+// formatTime returns t as an RFC3339 time in the local time zone, or in UTC
+// with --utc, so that the times reported read the same whatever the zone of
+// the objects they come from.
+func (o *BuildChainOptions) formatTime(t time.Time) string {
+	if o.utc {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return t.Local().Format(time.RFC3339)
+}
+
+// tagHistory returns the events of the tag of entry, the latest first.
+func (o *BuildChainOptions) tagHistory(ctx context.Context, entry chainEntry) ([]imagev1.TagEvent, error) {
+	name, tag, _ := imageutil.SplitImageStreamTag(entry.name)
+	stream, err := o.ImageStreams.GetImageStream(ctx, entry.namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, history := range stream.Status.Tags {
+		if history.Tag == tag {
+			return history.Items, nil
+		}
+	}
+	return nil, nil
+}
+
+// eventAt returns the event of history the tag pointed to at the given time.
+func eventAt(history []imagev1.TagEvent, at time.Time) (imagev1.TagEvent, bool) {
+	for _, event := range history {
+		if !event.Created.Time.After(at) {
+			return event, true
+		}
+	}
+	return imagev1.TagEvent{}, false
+}
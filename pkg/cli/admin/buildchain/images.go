@@ -0,0 +1,50 @@
+package buildchain
+
+import (
+	"context"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	dockerv10 "github.com/openshift/api/image/docker10"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// NewImageInspector returns a describe.ImageInspector looking up the images of
+// the image stream tags with images, for --show-image-details. The creation
+// time of an image is when its tag was last updated, as recorded in the
+// status of the image stream.
+func NewImageInspector(images ImageStreamGetter) describe.ImageInspector {
+	return &imageInspector{images: images}
+}
+
+type imageInspector struct {
+	images ImageStreamGetter
+}
+
+func (i *imageInspector) InspectImageStreamTag(ctx context.Context, namespace, name string) (*describe.ImageDetails, error) {
+	var details *describe.ImageDetails
+	err := describe.RetryTransient(func() error {
+		ist, err := i.images.GetImageStreamTag(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		if len(ist.Image.Name) == 0 {
+			return nil
+		}
+		// the server sets the creation time of an image stream tag to the
+		// time of its latest event in the status of the image stream
+		details = &describe.ImageDetails{Digest: ist.Image.Name, Created: ist.CreationTimestamp.Time}
+		if err := imageutil.ImageWithMetadata(&ist.Image); err == nil {
+			if metadata, ok := ist.Image.DockerImageMetadata.Object.(*dockerv10.DockerImage); ok {
+				details.Size = metadata.Size
+			}
+		}
+		return nil
+	})
+	if kerrors.IsNotFound(err) {
+		// the image stream tag wasn't pushed to yet
+		return nil, nil
+	}
+	return details, err
+}
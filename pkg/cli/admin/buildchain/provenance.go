@@ -0,0 +1,70 @@
+package buildchain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/oc/pkg/helpers/describe"
+	"github.com/openshift/oc/pkg/version"
+)
+
+// completeProvenance records how the chain is computed in the json output:
+// the versions of oc and of the cluster, the user, the namespaces and the
+// flags. The server version and the user are left out when they can't be
+// read, so that the chain is still output.
+func (o *BuildChainOptions) completeProvenance(f kcmdutil.Factory, cmd *cobra.Command) {
+	o.provenance = &describe.ChainProvenance{
+		ClientVersion: version.Get().GitVersion,
+		Namespaces:    o.namespaces.List(),
+		AllNamespaces: o.allNamespaces,
+		Flags:         changedFlags(cmd.LocalFlags()),
+	}
+
+	if discoveryClient, err := f.ToDiscoveryClient(); err != nil {
+		klog.V(4).Infof("Unable to read the server version: %v", err)
+	} else if serverVersion, err := discoveryClient.ServerVersion(); err != nil {
+		klog.V(4).Infof("Unable to read the server version: %v", err)
+	} else {
+		o.provenance.ServerVersion = serverVersion.GitVersion
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		klog.V(4).Infof("Unable to read the current user: %v", err)
+		return
+	}
+	userClient, err := userv1client.NewForConfig(clientConfig)
+	if err != nil {
+		klog.V(4).Infof("Unable to read the current user: %v", err)
+		return
+	}
+	me, err := userClient.Users().Get(context.TODO(), "~", metav1.GetOptions{})
+	if err != nil {
+		klog.V(4).Infof("Unable to read the current user: %v", err)
+		return
+	}
+	o.provenance.User = me.Name
+}
+
+// changedFlags returns the flags of flags set on the command line, as
+// --name=value sorted by name, list values being comma separated.
+func changedFlags(flags *pflag.FlagSet) []string {
+	changed := []string{}
+	flags.Visit(func(flag *pflag.Flag) {
+		value := flag.Value.String()
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			value = strings.Join(slice.GetSlice(), ",")
+		}
+		changed = append(changed, fmt.Sprintf("--%s=%s", flag.Name, value))
+	})
+	return changed
+}
@@ -0,0 +1,23 @@
+package buildchain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestChangedFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("build-chain", pflag.ContinueOnError)
+	flags.StringP("output", "o", "", "")
+	flags.StringSlice("exclude-namespace", nil, "")
+	flags.Bool("all", false, "")
+	flags.Int("max-depth", 0, "")
+	if err := flags.Parse([]string{"-o", "json", "--exclude-namespace=openshift,default", "--all"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"--all=true", "--exclude-namespace=openshift,default", "--output=json"}
+	if changed := changedFlags(flags); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected %v, got %v", expected, changed)
+	}
+}
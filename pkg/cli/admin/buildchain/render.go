@@ -0,0 +1,40 @@
+package buildchain
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Renderer lays out a graph in DOT format into an image, for --render.
+type Renderer interface {
+	Render(format string, dot []byte) ([]byte, error)
+}
+
+// NewGraphvizRenderer returns a Renderer running the dot command of graphviz
+// found in the PATH.
+func NewGraphvizRenderer() Renderer {
+	return graphvizRenderer{}
+}
+
+type graphvizRenderer struct{}
+
+func (graphvizRenderer) Render(format string, dot []byte) ([]byte, error) {
+	path, err := exec.LookPath("dot")
+	if err != nil {
+		return nil, fmt.Errorf("--render requires the dot command of graphviz, install graphviz or use -o dot: %v", err)
+	}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.Command(path, "-T"+format)
+	cmd.Stdin = bytes.NewReader(dot)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return nil, fmt.Errorf("unable to render the build chain as %s: %v: %s", format, err, msg)
+		}
+		return nil, fmt.Errorf("unable to render the build chain as %s: %v", format, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package buildchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/openshift/oc/pkg/helpers/describe"
+)
+
+// NewHTTPScanner returns a describe.VulnerabilityScanner asking the image
+// scanner at endpoint about the images of the image stream tags, for
+// --show-vulnerabilities. The image of an image stream tag is looked up with
+// images and requested as GET <endpoint>/<image digest>. The scanner answers
+// with the counts of vulnerabilities by severity as a json object, e.g.
+// {"critical": 1, "high": 3}, or with 404 when it didn't scan the image.
+func NewHTTPScanner(endpoint string, images ImageStreamGetter, client *http.Client) describe.VulnerabilityScanner {
+	return &httpScanner{endpoint: strings.TrimSuffix(endpoint, "/"), images: images, client: client}
+}
+
+type httpScanner struct {
+	endpoint string
+	images   ImageStreamGetter
+	client   *http.Client
+}
+
+func (s *httpScanner) ScanImageStreamTag(ctx context.Context, namespace, name string) (*describe.VulnerabilityCounts, error) {
+	var digest string
+	err := describe.RetryTransient(func() error {
+		ist, err := s.images.GetImageStreamTag(ctx, namespace, name)
+		if err != nil {
+			return err
+		}
+		digest = ist.Image.Name
+		return nil
+	})
+	if kerrors.IsNotFound(err) {
+		// the image stream tag wasn't pushed to yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(digest) == 0 {
+		return nil, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint+"/"+url.PathEscape(digest), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("the scanner answered %s for %s: %s", resp.Status, digest, strings.TrimSpace(string(body)))
+	}
+	counts := &describe.VulnerabilityCounts{}
+	if err := json.NewDecoder(resp.Body).Decode(counts); err != nil {
+		return nil, fmt.Errorf("unable to read the vulnerabilities of %s: %v", digest, err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,32 @@
+package buildchain
+
+import (
+	_ "embed"
+	"fmt"
+
+	buildchainv1 "github.com/openshift/oc/pkg/cli/admin/buildchain/api/v1"
+)
+
+// OutputVersion is the version of the json output of build-chain. It changes
+// whenever a change to the output would break its consumers.
+const OutputVersion = buildchainv1.Version
+
+var (
+	//go:embed schema/buildchain.schema.json
+	jsonSchema []byte
+	//go:embed schema/buildchain.proto
+	protoSchema []byte
+)
+
+// PrintSchema prints the schema of the json output in the requested format.
+func (o *BuildChainOptions) PrintSchema() error {
+	switch o.printSchema {
+	case "json":
+		_, err := o.Out.Write(jsonSchema)
+		return err
+	case "proto":
+		_, err := o.Out.Write(protoSchema)
+		return err
+	}
+	return fmt.Errorf("--print-schema must be either 'json' or 'proto'")
+}
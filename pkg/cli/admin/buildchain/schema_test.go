@@ -0,0 +1,197 @@
+package buildchain
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	buildchainv1 "github.com/openshift/oc/pkg/cli/admin/buildchain/api/v1"
+)
+
+// jsonFields returns the json names of the fields of the struct t.
+func jsonFields(t reflect.Type) []string {
+	fields := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+var outputTypes = []reflect.Type{
+	reflect.TypeOf(buildchainv1.ChainOutput{}),
+	reflect.TypeOf(buildchainv1.ChainNode{}),
+	reflect.TypeOf(buildchainv1.TagAlias{}),
+	reflect.TypeOf(buildchainv1.VulnerabilityCounts{}),
+	reflect.TypeOf(buildchainv1.ImageDetails{}),
+	reflect.TypeOf(buildchainv1.ChainEdge{}),
+	reflect.TypeOf(buildchainv1.EdgeBuildConfig{}),
+	reflect.TypeOf(buildchainv1.ChainRecord{}),
+	reflect.TypeOf(buildchainv1.ChainGroups{}),
+	reflect.TypeOf(buildchainv1.GroupDependency{}),
+	reflect.TypeOf(buildchainv1.ChainProvenance{}),
+	reflect.TypeOf(buildchainv1.ChainWarning{}),
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := struct {
+		ID   string `json:"$id"`
+		Defs map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"$defs"`
+	}{}
+	if err := json.Unmarshal(jsonSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(schema.ID, "/"+OutputVersion+"/") {
+		t.Errorf("expected the schema id %q to hold the output version %s", schema.ID, OutputVersion)
+	}
+	if len(schema.Defs) != len(outputTypes) {
+		t.Errorf("expected %d definitions, got %d", len(outputTypes), len(schema.Defs))
+	}
+	for _, typ := range outputTypes {
+		properties := []string{}
+		for name := range schema.Defs[typ.Name()].Properties {
+			properties = append(properties, name)
+		}
+		sort.Strings(properties)
+		if expected := jsonFields(typ); !reflect.DeepEqual(properties, expected) {
+			t.Errorf("expected the properties of %s to be %v, got %v", typ.Name(), expected, properties)
+		}
+	}
+}
+
+func TestProtoSchema(t *testing.T) {
+	proto := string(protoSchema)
+	if !strings.Contains(proto, "package openshift.oc.buildchain."+OutputVersion+";") {
+		t.Errorf("expected the proto package to hold the output version %s", OutputVersion)
+	}
+	messages := regexp.MustCompile(`(?s)message (\w+) \{(.*?)\n\}`).FindAllStringSubmatch(proto, -1)
+	if len(messages) != len(outputTypes) {
+		t.Errorf("expected %d messages, got %d", len(outputTypes), len(messages))
+	}
+	field := regexp.MustCompile(`(?m)^\s+(?:repeated )?(?:map<\w+, \w+>|\w+) (\w+) = \d+;`)
+	for _, typ := range outputTypes {
+		fields := []string{}
+		for _, message := range messages {
+			if message[1] != typ.Name() {
+				continue
+			}
+			for _, match := range field.FindAllStringSubmatch(message[2], -1) {
+				fields = append(fields, match[1])
+			}
+		}
+		sort.Strings(fields)
+		if expected := jsonFields(typ); !reflect.DeepEqual(fields, expected) {
+			t.Errorf("expected the fields of %s to be %v, got %v", typ.Name(), expected, fields)
+		}
+	}
+}
+
+// schemaDefinitions are the definitions of a JSON schema.
+type schemaDefinitions struct {
+	Defs map[string]struct {
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Required   []string                          `json:"required"`
+	} `json:"$defs"`
+}
+
+// TestJSONSchemaBackwardCompatible checks the schema of the json output
+// against the one v1 was released with: definitions and properties may be
+// added, and enums extended, but nothing may be removed, retyped or become
+// required.
+func TestJSONSchemaBackwardCompatible(t *testing.T) {
+	released, err := os.ReadFile("test/buildchain." + OutputVersion + ".schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, current := schemaDefinitions{}, schemaDefinitions{}
+	if err := json.Unmarshal(released, &old); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(jsonSchema, &current); err != nil {
+		t.Fatal(err)
+	}
+	for name, def := range old.Defs {
+		currentDef, ok := current.Defs[name]
+		if !ok {
+			t.Errorf("%s was removed", name)
+			continue
+		}
+		for property, oldSchema := range def.Properties {
+			currentSchema, ok := currentDef.Properties[property]
+			if !ok {
+				t.Errorf("%s.%s was removed", name, property)
+				continue
+			}
+			if !reflect.DeepEqual(comparableSchema(oldSchema), comparableSchema(currentSchema)) {
+				t.Errorf("%s.%s changed from %v to %v", name, property, oldSchema, currentSchema)
+			}
+			enum := map[interface{}]bool{}
+			if values, ok := currentSchema["enum"].([]interface{}); ok {
+				for _, value := range values {
+					enum[value] = true
+				}
+			}
+			if values, ok := oldSchema["enum"].([]interface{}); ok {
+				for _, value := range values {
+					if !enum[value] {
+						t.Errorf("%s.%s no longer accepts %v", name, property, value)
+					}
+				}
+			}
+		}
+		required := map[string]bool{}
+		for _, property := range def.Required {
+			required[property] = true
+		}
+		for _, property := range currentDef.Required {
+			if !required[property] {
+				t.Errorf("%s.%s became required", name, property)
+			}
+		}
+	}
+}
+
+// comparableSchema returns the schema of a property without what may change
+// compatibly: its description and enum.
+func comparableSchema(schema map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, value := range schema {
+		if key != "description" && key != "enum" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// TestProtoSchemaBackwardCompatible checks the protocol buffers messages of
+// the json output against the ones v1 was released with: fields may be added,
+// but never removed, renamed, retyped or renumbered.
+func TestProtoSchemaBackwardCompatible(t *testing.T) {
+	released, err := os.ReadFile("test/buildchain." + OutputVersion + ".proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := func(proto string) map[string]string {
+		out := map[string]string{}
+		field := regexp.MustCompile(`(?m)^\s+((?:repeated )?(?:map<\w+, \w+>|\w+)) (\w+) = (\d+);`)
+		for _, message := range regexp.MustCompile(`(?s)message (\w+) \{(.*?)\n\}`).FindAllStringSubmatch(proto, -1) {
+			for _, match := range field.FindAllStringSubmatch(message[2], -1) {
+				out[message[1]+"."+match[3]] = match[1] + " " + match[2]
+			}
+		}
+		return out
+	}
+	current := fields(string(protoSchema))
+	for number, oldField := range fields(string(released)) {
+		if currentField, ok := current[number]; !ok || currentField != oldField {
+			t.Errorf("field %s of %s was changed to %q", number, oldField, currentField)
+		}
+	}
+}
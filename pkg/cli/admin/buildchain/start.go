@@ -0,0 +1,98 @@
+package buildchain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildhelpers "github.com/openshift/oc/pkg/helpers/build"
+	"github.com/openshift/oc/pkg/helpers/describe"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+)
+
+// startBuilds starts the builds of output, the json simulation of the chain,
+// stage by stage: the builds of a stage are started together and the next
+// stage is only started once they all completed. It stops at the first stage
+// with builds that didn't complete.
+func (o *BuildChainOptions) startBuilds(ctx context.Context, output string) error {
+	simulation := &describe.Simulation{}
+	if err := json.Unmarshal([]byte(output), simulation); err != nil {
+		return fmt.Errorf("unable to read the builds to start: %v", err)
+	}
+	if simulation.Builds == 0 {
+		fmt.Fprintln(o.Out, "No builds to start.")
+		return nil
+	}
+
+	for i, stage := range simulation.Stages {
+		builds := "builds"
+		if len(stage.Builds) == 1 {
+			builds = "build"
+		}
+		fmt.Fprintf(o.Out, "Stage %d/%d: starting %d %s\n", i+1, len(simulation.Stages), len(stage.Builds), builds)
+		started := []*buildv1.Build{}
+		for _, build := range stage.Builds {
+			namespace, name, err := parseBuildConfigID(build.ID)
+			if err != nil {
+				return err
+			}
+			b, err := o.BuildStarter.StartBuild(ctx, namespace, name)
+			if err != nil {
+				return fmt.Errorf("unable to start a build of build config %q in %q: %v", name, namespace, err)
+			}
+			fmt.Fprintf(o.Out, "build.build.openshift.io/%s started\n", b.Name)
+			started = append(started, b)
+		}
+
+		failed := []string{}
+		for _, b := range started {
+			phase, err := o.waitForBuild(ctx, b)
+			if err != nil {
+				return fmt.Errorf("unable to wait for build %q in %q: %v", b.Name, b.Namespace, err)
+			}
+			fmt.Fprintf(o.Out, "build.build.openshift.io/%s %s\n", b.Name, strings.ToLower(string(phase)))
+			if phase != buildv1.BuildPhaseComplete {
+				failed = append(failed, fmt.Sprintf("%s/%s", b.Namespace, b.Name))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("builds of stage %d didn't complete, the next stages aren't started: %s", i+1, strings.Join(failed, ", "))
+		}
+	}
+	return nil
+}
+
+// waitForBuild polls build until it is in a terminal phase and returns the
+// phase.
+func (o *BuildChainOptions) waitForBuild(ctx context.Context, build *buildv1.Build) (buildv1.BuildPhase, error) {
+	phase := build.Status.Phase
+	err := wait.PollUntilContextCancel(ctx, o.startPollInterval, true, func(ctx context.Context) (bool, error) {
+		var latest *buildv1.Build
+		err := describe.RetryTransient(func() error {
+			var err error
+			latest, err = o.BuildStarter.GetBuild(ctx, build.Namespace, build.Name)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+		phase = latest.Status.Phase
+		return buildhelpers.IsTerminalPhase(phase), nil
+	})
+	return phase, err
+}
+
+// parseBuildConfigID returns the namespace and the name of the build config
+// of id, as in the json outputs.
+func parseBuildConfigID(id string) (string, string, error) {
+	kind, namespacedName, _ := strings.Cut(id, "|")
+	namespace, name, ok := strings.Cut(namespacedName, "/")
+	if kind != buildgraph.BuildConfigNodeKind || !ok {
+		return "", "", fmt.Errorf("invalid build config %q", id)
+	}
+	return namespace, name, nil
+}
@@ -0,0 +1,128 @@
+package buildchain
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildchaintesting "github.com/openshift/oc/pkg/cli/admin/buildchain/testing"
+)
+
+func TestRunBuildChainStart(t *testing.T) {
+	newBuildConfig := func(name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	buildConfigs := &buildchaintesting.FakeBuildConfigLister{BuildConfigs: []buildv1.BuildConfig{
+		newBuildConfig("app", "base:latest"),
+		newBuildConfig("tools", "base:latest"),
+		newBuildConfig("web", "app:latest"),
+	}}
+
+	for _, tc := range []struct {
+		name     string
+		failing  sets.String
+		started  []string
+		expected string
+		err      string
+	}{
+		{
+			name:    "all stages complete",
+			started: []string{"test/app", "test/tools", "test/web"},
+			expected: `Stage 1/2: starting 2 builds
+build.build.openshift.io/app-1 started
+build.build.openshift.io/tools-2 started
+build.build.openshift.io/app-1 complete
+build.build.openshift.io/tools-2 complete
+Stage 2/2: starting 1 build
+build.build.openshift.io/web-3 started
+build.build.openshift.io/web-3 complete
+`,
+		},
+		{
+			name:    "a failed build stops the next stages",
+			failing: sets.NewString("test/tools"),
+			started: []string{"test/app", "test/tools"},
+			expected: `Stage 1/2: starting 2 builds
+build.build.openshift.io/app-1 started
+build.build.openshift.io/tools-2 started
+build.build.openshift.io/app-1 complete
+build.build.openshift.io/tools-2 failed
+`,
+			err: "builds of stage 1 didn't complete, the next stages aren't started: test/tools-2",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			starter := &buildchaintesting.FakeBuildStarter{Failing: tc.failing}
+			out := &bytes.Buffer{}
+			o := &BuildChainOptions{
+				entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+				defaultNamespace: "test",
+				namespaces:       sets.NewString("test"),
+				triggerOnly:      true,
+				start:            true,
+				output:           "json",
+				BuildConfigs:     buildConfigs,
+				ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+				Projects:         &buildchaintesting.FakeProjectLister{},
+				BuildStarter:     starter,
+				IOStreams:        genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+			}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			err := o.RunBuildChain()
+			if len(tc.err) == 0 && err != nil {
+				t.Fatal(err)
+			}
+			if len(tc.err) > 0 && (err == nil || err.Error() != tc.err) {
+				t.Errorf("expected error %q, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(starter.Started, tc.started) {
+				t.Errorf("expected builds of %v to be started, got %v", tc.started, starter.Started)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		start:            true,
+		output:           "dot",
+		BuildConfigs:     buildConfigs,
+		ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+		Projects:         &buildchaintesting.FakeProjectLister{},
+		BuildStarter:     &buildchaintesting.FakeBuildStarter{},
+	}
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--start can't be combined with --output=dot") {
+		t.Errorf("expected the dot output to be rejected, got %v", err)
+	}
+	o.output, o.reverse = "json", true
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--start can't be combined with --simulate, --reverse") {
+		t.Errorf("expected --reverse to be rejected, got %v", err)
+	}
+
+	if _, _, err := parseBuildConfigID("ImageStreamTag|test/app:latest"); err == nil {
+		t.Errorf("expected an image stream tag to be rejected")
+	}
+}
@@ -0,0 +1,155 @@
+// Package testing provides in-memory implementations of the clients
+// build-chain reads from, so that build chains can be described without a
+// cluster.
+package testing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	projectv1 "github.com/openshift/api/project/v1"
+)
+
+// FakeBuildConfigLister implements describe.BuildConfigLister.
+type FakeBuildConfigLister struct {
+	BuildConfigs []buildv1.BuildConfig
+	Builds       []buildv1.Build
+	// Err, when set, is returned by every call.
+	Err error
+}
+
+func (l *FakeBuildConfigLister) ListBuildConfigs(ctx context.Context, namespace string) ([]buildv1.BuildConfig, error) {
+	if l.Err != nil {
+		return nil, l.Err
+	}
+	items := []buildv1.BuildConfig{}
+	for _, bc := range l.BuildConfigs {
+		if bc.Namespace == namespace {
+			items = append(items, bc)
+		}
+	}
+	return items, nil
+}
+
+func (l *FakeBuildConfigLister) ListBuilds(ctx context.Context, namespace string) ([]buildv1.Build, error) {
+	if l.Err != nil {
+		return nil, l.Err
+	}
+	items := []buildv1.Build{}
+	for _, build := range l.Builds {
+		if build.Namespace == namespace {
+			items = append(items, build)
+		}
+	}
+	return items, nil
+}
+
+// FakeImageStreamGetter implements buildchain.ImageStreamGetter.
+type FakeImageStreamGetter struct {
+	ImageStreamTags []imagev1.ImageStreamTag
+	ImageStreams    []imagev1.ImageStream
+	// Err, when set, is returned by GetImageStreamTag.
+	Err error
+}
+
+func (g *FakeImageStreamGetter) GetImageStreamTag(ctx context.Context, namespace, name string) (*imagev1.ImageStreamTag, error) {
+	if g.Err != nil {
+		return nil, g.Err
+	}
+	for i := range g.ImageStreamTags {
+		if ist := &g.ImageStreamTags[i]; ist.Namespace == namespace && ist.Name == name {
+			return ist, nil
+		}
+	}
+	return nil, kerrors.NewNotFound(imagev1.Resource("imagestreamtags"), name)
+}
+
+func (g *FakeImageStreamGetter) GetImageStream(ctx context.Context, namespace, name string) (*imagev1.ImageStream, error) {
+	for i := range g.ImageStreams {
+		if is := &g.ImageStreams[i]; is.Namespace == namespace && is.Name == name {
+			return is, nil
+		}
+	}
+	return nil, kerrors.NewNotFound(imagev1.Resource("imagestreams"), name)
+}
+
+func (g *FakeImageStreamGetter) ListImageStreams(ctx context.Context, namespace string) ([]imagev1.ImageStream, error) {
+	items := []imagev1.ImageStream{}
+	for _, is := range g.ImageStreams {
+		if is.Namespace == namespace {
+			items = append(items, is)
+		}
+	}
+	return items, nil
+}
+
+// FakeProjectLister implements buildchain.ProjectLister.
+type FakeProjectLister struct {
+	Projects []projectv1.Project
+	// Err, when set, is returned by every call.
+	Err error
+}
+
+func (l *FakeProjectLister) ListProjects(ctx context.Context) ([]projectv1.Project, error) {
+	if l.Err != nil {
+		return nil, l.Err
+	}
+	return l.Projects, nil
+}
+
+// FakeAccessReviewer implements buildchain.AccessReviewer.
+type FakeAccessReviewer struct {
+	// Editable are the namespaces the build configs of can be edited.
+	Editable sets.String
+}
+
+func (r *FakeAccessReviewer) CanEditBuildConfigs(ctx context.Context, namespace string) (bool, error) {
+	return r.Editable.Has(namespace), nil
+}
+
+// FakeBuildStarter implements buildchain.BuildStarter. Builds are started in
+// the New phase and get Complete, or Failed for the build configs of Failing,
+// once they are read.
+type FakeBuildStarter struct {
+	// Failing are the namespace/name of the build configs whose builds fail.
+	Failing sets.String
+	// Started are the namespace/name of the build configs builds were
+	// started for, in order.
+	Started []string
+	// Err, when set, is returned by StartBuild.
+	Err error
+}
+
+func (s *FakeBuildStarter) StartBuild(ctx context.Context, namespace, name string) (*buildv1.Build, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	s.Started = append(s.Started, namespace+"/"+name)
+	return &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%d", name, len(s.Started)), Namespace: namespace, Labels: map[string]string{buildv1.BuildConfigLabel: name}},
+		Status:     buildv1.BuildStatus{Phase: buildv1.BuildPhaseNew},
+	}, nil
+}
+
+func (s *FakeBuildStarter) GetBuild(ctx context.Context, namespace, name string) (*buildv1.Build, error) {
+	for i, started := range s.Started {
+		if bcNamespace, bcName, _ := strings.Cut(started, "/"); bcNamespace == namespace && fmt.Sprintf("%s-%d", bcName, i+1) == name {
+			phase := buildv1.BuildPhaseComplete
+			if s.Failing.Has(started) {
+				phase = buildv1.BuildPhaseFailed
+			}
+			return &buildv1.Build{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{buildv1.BuildConfigLabel: bcName}},
+				Status:     buildv1.BuildStatus{Phase: phase},
+			}, nil
+		}
+	}
+	return nil, kerrors.NewNotFound(buildv1.Resource("builds"), name)
+}
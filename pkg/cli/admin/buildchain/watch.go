@@ -0,0 +1,77 @@
+package buildchain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WatchBuildChain describes the build chain as RunBuildChain does, then again
+// every time the build configs or the image streams of its namespaces change,
+// until ctx is done. A description is only output when it differs from the
+// previous one, and failures are reported without ending the watch.
+func (o *BuildChainOptions) WatchBuildChain(ctx context.Context) error {
+	namespaces := o.namespaces.List()
+	if o.allNamespaces {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	changes, err := o.Watcher.Watch(ctx, namespaces)
+	if err != nil {
+		return err
+	}
+
+	out := o.Out
+	defer func() { o.Out = out }()
+	last := ""
+	for {
+		buf := &bytes.Buffer{}
+		o.Out = buf
+		if err := o.RunBuildChain(); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: unable to describe the build chain, retrying on the next change: %v\n", err)
+		} else if buf.String() != last {
+			last = buf.String()
+			if _, err := out.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+		}
+		if !o.settle(ctx, changes) {
+			return nil
+		}
+	}
+}
+
+// settle waits for changes to stop coming in for watchSettle, so that a burst
+// of changes, e.g. a new application, is described once. It returns false
+// when the watch is over.
+func (o *BuildChainOptions) settle(ctx context.Context, changes <-chan struct{}) bool {
+	if o.watchSettle <= 0 {
+		return true
+	}
+	timer := time.NewTimer(o.watchSettle)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case _, ok := <-changes:
+			if !ok {
+				return false
+			}
+			timer.Reset(o.watchSettle)
+		case <-timer.C:
+			return true
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package buildchain
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildchaintesting "github.com/openshift/oc/pkg/cli/admin/buildchain/testing"
+)
+
+// changingBuildConfigLister returns the next build configs of BuildConfigs
+// every time it is listed, then the last ones.
+type changingBuildConfigLister struct {
+	buildchaintesting.FakeBuildConfigLister
+	BuildConfigs [][]buildv1.BuildConfig
+}
+
+func (l *changingBuildConfigLister) ListBuildConfigs(ctx context.Context, namespace string) ([]buildv1.BuildConfig, error) {
+	bcs := l.BuildConfigs[0]
+	if len(l.BuildConfigs) > 1 {
+		l.BuildConfigs = l.BuildConfigs[1:]
+	}
+	return bcs, nil
+}
+
+// fakeWatcher reports the changes queued in its channel.
+type fakeWatcher struct {
+	changes    chan struct{}
+	namespaces []string
+}
+
+func (w *fakeWatcher) Watch(ctx context.Context, namespaces []string) (<-chan struct{}, error) {
+	w.namespaces = namespaces
+	return w.changes, nil
+}
+
+func TestWatchBuildChain(t *testing.T) {
+	newBuildConfig := func(name, from string) buildv1.BuildConfig {
+		return buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	app := newBuildConfig("app", "base:latest")
+	web := newBuildConfig("web", "app:latest")
+
+	// three changes, the second one leaving the chain as it was
+	watcher := &fakeWatcher{changes: make(chan struct{}, 3)}
+	for i := 0; i < 3; i++ {
+		watcher.changes <- struct{}{}
+	}
+	close(watcher.changes)
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		triggerOnly:      true,
+		watch:            true,
+		BuildConfigs: &changingBuildConfigLister{BuildConfigs: [][]buildv1.BuildConfig{
+			{app},
+			{app, web},
+			{app, web},
+			{app},
+		}},
+		ImageStreams: &buildchaintesting.FakeImageStreamGetter{},
+		Projects:     &buildchaintesting.FakeProjectLister{},
+		Watcher:      watcher,
+		IOStreams:    genericiooptions.IOStreams{Out: out, ErrOut: errOut},
+	}
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.WatchBuildChain(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(watcher.namespaces, ",") != "test" {
+		t.Errorf("expected the namespace of the chain to be watched, got %v", watcher.namespaces)
+	}
+	if count := strings.Count(out.String(), "istag/base:latest"); count != 3 {
+		t.Errorf("expected the chain to be output on every change but the one leaving it as it was, got:\n%s", out.String())
+	}
+	if count := strings.Count(out.String(), "bc/web"); count != 1 {
+		t.Errorf("expected the chain with web once, got:\n%s", out.String())
+	}
+	if o.Out != out {
+		t.Errorf("expected the output to be restored")
+	}
+}
+
+func TestValidateWatch(t *testing.T) {
+	o := &BuildChainOptions{
+		entries:          []chainEntry{{namespace: "test", name: "base:latest"}},
+		defaultNamespace: "test",
+		namespaces:       sets.NewString("test"),
+		watch:            true,
+		BuildConfigs:     &buildchaintesting.FakeBuildConfigLister{},
+		ImageStreams:     &buildchaintesting.FakeImageStreamGetter{},
+		Projects:         &buildchaintesting.FakeProjectLister{},
+		Watcher:          &fakeWatcher{},
+	}
+	o.atTime = "2024-05-01T12:00:00Z"
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--at-time") {
+		t.Errorf("expected --watch to be rejected with --at-time, got %v", err)
+	}
+	o.atTime, o.Watcher = "", nil
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "watcher") {
+		t.Errorf("expected a missing watcher to be rejected, got %v", err)
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/openshift/oc/pkg/cli/image/info"
 	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
 	imgmirror "github.com/openshift/oc/pkg/cli/image/mirror"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 )
 
 var (
@@ -169,8 +170,7 @@ func NewMirrorCatalog(f kcmdutil.Factory, streams genericiooptions.IOStreams) *c
 
 	// the old flag name is kept for backwards-compatibility.
 	// if both old and new are specified, the value of the flag coming later will be used.
-	flags.StringVar(&o.FilterOptions.FilterByOS, "filter-by-os", o.FilterOptions.FilterByOS, "Use --index-filter-by-os instead. A regular expression to control which index image is picked when multiple variants are available. Images will be passed as '<platform>/<architecture>[/<variant>]'. This does not apply to images referenced by the index.")
-	_ = flags.MarkDeprecated("filter-by-os", "use --index-filter-by-os instead")
+	kcmdutil.CheckErr(cmdutil.DeprecatedFlagAlias(flags, "filter-by-os", cmdutil.Deprecation{Replacement: "index-filter-by-os"}))
 
 	flags.StringVar(&o.ManifestDir, "to-manifests", "", "Local path to store manifests.")
 	flags.StringVar(&o.IndexPath, "path", "", "Specify an in-container to local path mapping for the index file(s).")
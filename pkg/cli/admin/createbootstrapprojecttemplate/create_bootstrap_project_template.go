@@ -1,33 +1,71 @@
 package createbootstrapprojecttemplate
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/cli-runtime/pkg/printers"
 	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/templates"
+
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	templatev1client "github.com/openshift/client-go/template/clientset/versioned/typed/template/v1"
 )
 
+// TemplateNamespace is the namespace the project request template is installed in.
+const TemplateNamespace = "openshift-config"
+
 type CreateBootstrapProjectTemplateOptions struct {
 	PrintFlags *genericclioptions.PrintFlags
 
 	Name string
 	Args []string
 
-	Printer printers.ResourcePrinter
+	Quota            map[string]string
+	DefaultLimits    map[string]string
+	DefaultRequests  map[string]string
+	NetworkIsolation bool
+	Install          bool
+
+	Defaults ProjectDefaults
+	Printer  printers.ResourcePrinter
+
+	TemplateClient templatev1client.TemplatesGetter
+	ConfigClient   configv1client.ProjectsGetter
 
 	genericiooptions.IOStreams
 }
 
-var createBootstrapProjectTemplateExample = templates.Examples(`
-	# Output a bootstrap project template in YAML format to stdout
-	oc adm create-bootstrap-project-template -o yaml
-`)
+var (
+	createBootstrapProjectTemplateLong = templates.LongDesc(`
+		Create a bootstrap project template.
+
+		The template creates the projects users request, and makes the requesting user the
+		admin of the project. For clusters shared by several tenants, the template can also
+		give every project a quota, default container limits and requests, and network
+		policies isolating its pods from the other projects.
+
+		With --install, the template is created in the openshift-config namespace and the
+		cluster is configured to create requested projects from it.
+	`)
+
+	createBootstrapProjectTemplateExample = templates.Examples(`
+		# Output a bootstrap project template in YAML format to stdout
+		oc adm create-bootstrap-project-template -o yaml
+
+		# Install a template giving every project a quota and isolating its network
+		oc adm create-bootstrap-project-template --quota=requests.cpu=4,requests.memory=8Gi,pods=20 \
+		  --default-limits=cpu=500m,memory=512Mi --network-isolation --install
+	`)
+)
 
 func NewCreateBootstrapProjectTemplateOptions(streams genericiooptions.IOStreams) *CreateBootstrapProjectTemplateOptions {
 	return &CreateBootstrapProjectTemplateOptions{
@@ -42,23 +80,57 @@ func NewCommandCreateBootstrapProjectTemplate(f kcmdutil.Factory, streams generi
 	cmd := &cobra.Command{
 		Use:     "create-bootstrap-project-template",
 		Short:   "Create a bootstrap project template",
+		Long:    createBootstrapProjectTemplateLong,
 		Example: createBootstrapProjectTemplateExample,
 		Run: func(cmd *cobra.Command, args []string) {
-			kcmdutil.CheckErr(o.Complete(args))
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
 			kcmdutil.CheckErr(o.Validate())
 			kcmdutil.CheckErr(o.Run())
 		},
 	}
 
 	cmd.Flags().StringVar(&o.Name, "name", o.Name, "The name of the template to output.")
+	cmd.Flags().StringToStringVar(&o.Quota, "quota", o.Quota, "Hard limits of the quota of every project, as resource=quantity pairs, e.g. requests.cpu=4,pods=20.")
+	cmd.Flags().StringToStringVar(&o.DefaultLimits, "default-limits", o.DefaultLimits, "Limits of the containers that don't set their own, as resource=quantity pairs, e.g. cpu=500m,memory=512Mi.")
+	cmd.Flags().StringToStringVar(&o.DefaultRequests, "default-requests", o.DefaultRequests, "Requests of the containers that don't set their own, as resource=quantity pairs, e.g. cpu=100m,memory=256Mi.")
+	cmd.Flags().BoolVar(&o.NetworkIsolation, "network-isolation", o.NetworkIsolation, "If true, only let the pods of a project be reached from the same project, the ingress controllers and the cluster monitoring.")
+	cmd.Flags().BoolVar(&o.Install, "install", o.Install, "If true, create the template in the openshift-config namespace and configure the cluster to create requested projects from it.")
 	o.PrintFlags.AddFlags(cmd)
 
 	return cmd
 }
 
-func (o *CreateBootstrapProjectTemplateOptions) Complete(args []string) error {
+func (o *CreateBootstrapProjectTemplateOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
 	o.Args = args
 	var err error
+	if o.Defaults.Quota, err = parseResourceList("quota", o.Quota); err != nil {
+		return err
+	}
+	if o.Defaults.DefaultLimits, err = parseResourceList("default-limits", o.DefaultLimits); err != nil {
+		return err
+	}
+	if o.Defaults.DefaultRequests, err = parseResourceList("default-requests", o.DefaultRequests); err != nil {
+		return err
+	}
+	o.Defaults.NetworkIsolation = o.NetworkIsolation
+
+	if o.Install {
+		// report what was created rather than dumping it, unless asked to
+		if !cmd.Flags().Changed("output") {
+			*o.PrintFlags.OutputFormat = ""
+		}
+		clientConfig, err := f.ToRESTConfig()
+		if err != nil {
+			return err
+		}
+		if o.TemplateClient, err = templatev1client.NewForConfig(clientConfig); err != nil {
+			return err
+		}
+		if o.ConfigClient, err = configv1client.NewForConfig(clientConfig); err != nil {
+			return err
+		}
+	}
+
 	o.Printer, err = o.PrintFlags.ToPrinter()
 	if err != nil {
 		return err
@@ -80,6 +152,22 @@ func (o *CreateBootstrapProjectTemplateOptions) Validate() error {
 func (o *CreateBootstrapProjectTemplateOptions) Run() error {
 	template := DefaultTemplate()
 	template.Name = o.Name
+	if err := o.Defaults.AddTo(template); err != nil {
+		return err
+	}
 
-	return o.Printer.PrintObj(template, o.Out)
+	if !o.Install {
+		return o.Printer.PrintObj(template, o.Out)
+	}
+
+	ctx := context.TODO()
+	created, err := o.TemplateClient.Templates(TemplateNamespace).Create(ctx, template, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	patch := fmt.Sprintf(`{"spec":{"projectRequestTemplate":{"name":%q}}}`, created.Name)
+	if _, err := o.ConfigClient.Projects().Patch(ctx, "cluster", types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("template %q was created but the cluster could not be configured to use it: %v", created.Name, err)
+	}
+	return o.Printer.PrintObj(created, o.Out)
 }
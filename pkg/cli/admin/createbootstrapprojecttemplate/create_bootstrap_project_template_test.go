@@ -0,0 +1,122 @@
+package createbootstrapprojecttemplate
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/scheme"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	templatefake "github.com/openshift/client-go/template/clientset/versioned/fake"
+	schemehelper "github.com/openshift/oc/pkg/helpers/scheme"
+)
+
+func init() {
+	// as oc does, for the printers to know the OpenShift types
+	schemehelper.InstallSchemes(scheme.Scheme)
+}
+
+func decodeObjects(t *testing.T, raws []runtime.RawExtension) map[string]runtime.Object {
+	t.Helper()
+	decoder := scheme.Codecs.UniversalDeserializer()
+	objs := map[string]runtime.Object{}
+	for _, raw := range raws {
+		obj, gvk, err := decoder.Decode(raw.Raw, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		objs[gvk.Kind+"/"+obj.(metav1.Object).GetName()] = obj
+	}
+	return objs
+}
+
+func TestProjectDefaultsAddTo(t *testing.T) {
+	quota, err := parseResourceList("quota", map[string]string{"pods": "20", "requests.cpu": "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limits, err := parseResourceList("default-limits", map[string]string{"memory": "512Mi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := DefaultTemplate()
+	objects := len(template.Objects)
+	if err := (ProjectDefaults{Quota: quota, DefaultLimits: limits, NetworkIsolation: true}).AddTo(template); err != nil {
+		t.Fatal(err)
+	}
+	if added := len(template.Objects) - objects; added != 5 {
+		t.Fatalf("expected 5 objects to be added, got %d", added)
+	}
+
+	objs := decodeObjects(t, template.Objects)
+	q, ok := objs["ResourceQuota/"+QuotaName].(*corev1.ResourceQuota)
+	if !ok {
+		t.Fatalf("missing quota in %v", objs)
+	}
+	if q.Namespace != "${PROJECT_NAME}" || q.Spec.Hard.Pods().String() != "20" || q.Spec.Hard.Name("requests.cpu", "").String() != "4" {
+		t.Errorf("unexpected quota: %#v", q)
+	}
+	lr, ok := objs["LimitRange/"+LimitRangeName].(*corev1.LimitRange)
+	if !ok {
+		t.Fatalf("missing limit range in %v", objs)
+	}
+	if got := lr.Spec.Limits[0].Default.Memory().String(); got != "512Mi" {
+		t.Errorf("unexpected default memory limit %s", got)
+	}
+	for _, name := range []string{"allow-same-namespace", "allow-from-openshift-ingress", "allow-from-openshift-monitoring"} {
+		if _, ok := objs["NetworkPolicy/"+name]; !ok {
+			t.Errorf("missing network policy %s", name)
+		}
+	}
+}
+
+func TestParseResourceList(t *testing.T) {
+	if list, err := parseResourceList("quota", nil); err != nil || list != nil {
+		t.Errorf("expected no resources, got %v, %v", list, err)
+	}
+	_, err := parseResourceList("quota", map[string]string{"cpu": "lots"})
+	if err == nil || !strings.Contains(err.Error(), "--quota") {
+		t.Errorf("expected an invalid quantity error, got %v", err)
+	}
+}
+
+func TestRunInstall(t *testing.T) {
+	templateClient := templatefake.NewSimpleClientset()
+	configClient := configfake.NewSimpleClientset(&configv1.Project{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}})
+
+	out := &bytes.Buffer{}
+	o := NewCreateBootstrapProjectTemplateOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	*o.PrintFlags.OutputFormat = ""
+	o.Install = true
+	o.TemplateClient = templateClient.TemplateV1()
+	o.ConfigClient = configClient.ConfigV1()
+	var err error
+	if o.Printer, err = o.PrintFlags.ToPrinter(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := templateClient.TemplateV1().Templates(TemplateNamespace).Get(context.TODO(), DefaultTemplateName, metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the template to be created: %v", err)
+	}
+	project, err := configClient.ConfigV1().Projects().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Spec.ProjectRequestTemplate.Name != DefaultTemplateName {
+		t.Errorf("expected the cluster to use the template, got %q", project.Spec.ProjectRequestTemplate.Name)
+	}
+	if !strings.Contains(out.String(), DefaultTemplateName+" created") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
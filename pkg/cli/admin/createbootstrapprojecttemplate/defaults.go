@@ -0,0 +1,125 @@
+package createbootstrapprojecttemplate
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	templatev1 "github.com/openshift/api/template/v1"
+)
+
+const (
+	QuotaName      = "default-quota"
+	LimitRangeName = "default-limits"
+)
+
+// ProjectDefaults are the objects every project requested through the
+// template starts with, for clusters shared by several tenants.
+type ProjectDefaults struct {
+	// Quota is the hard limit of the resources of a project.
+	Quota corev1.ResourceList
+	// DefaultLimits and DefaultRequests apply to the containers that don't
+	// set their own limits and requests.
+	DefaultLimits   corev1.ResourceList
+	DefaultRequests corev1.ResourceList
+	// NetworkIsolation only lets the pods of a project be reached from the
+	// same project, the ingress controllers and the cluster monitoring.
+	NetworkIsolation bool
+}
+
+// AddTo appends the objects of the defaults to template.
+func (d ProjectDefaults) AddTo(template *templatev1.Template) error {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	codec := serializer.NewCodecFactory(scheme).LegacyCodec(scheme.PrioritizedVersionsAllGroups()...)
+
+	ns := "${" + ProjectNameParam + "}"
+
+	objs := []runtime.Object{}
+	if len(d.Quota) > 0 {
+		objs = append(objs, &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: QuotaName, Namespace: ns},
+			Spec:       corev1.ResourceQuotaSpec{Hard: d.Quota},
+		})
+	}
+	if len(d.DefaultLimits) > 0 || len(d.DefaultRequests) > 0 {
+		objs = append(objs, &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: LimitRangeName, Namespace: ns},
+			Spec: corev1.LimitRangeSpec{
+				Limits: []corev1.LimitRangeItem{{
+					Type:           corev1.LimitTypeContainer,
+					Default:        d.DefaultLimits,
+					DefaultRequest: d.DefaultRequests,
+				}},
+			},
+		})
+	}
+	if d.NetworkIsolation {
+		objs = append(objs,
+			networkPolicy("allow-same-namespace", ns, networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{}}),
+			networkPolicy("allow-from-openshift-ingress", ns, policyGroupPeer("ingress")),
+			networkPolicy("allow-from-openshift-monitoring", ns, policyGroupPeer("monitoring")),
+		)
+	}
+
+	for _, obj := range objs {
+		objBytes, err := runtime.Encode(codec, obj)
+		if err != nil {
+			return err
+		}
+		template.Objects = append(template.Objects, runtime.RawExtension{Raw: objBytes})
+	}
+	return nil
+}
+
+// networkPolicy returns a policy letting the pods of namespace be reached from peer.
+func networkPolicy(name, namespace string, peer networkingv1.NetworkPolicyPeer) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{From: []networkingv1.NetworkPolicyPeer{peer}}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// policyGroupPeer selects the namespaces of an OpenShift policy group, e.g. the
+// namespaces of the ingress controllers.
+func policyGroupPeer(group string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"network.openshift.io/policy-group": group},
+		},
+	}
+}
+
+// parseResourceList parses resources given as name=quantity pairs.
+func parseResourceList(flag string, values map[string]string) (corev1.ResourceList, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	names := []string{}
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := corev1.ResourceList{}
+	for _, name := range names {
+		quantity, err := resource.ParseQuantity(values[name])
+		if err != nil {
+			return nil, fmt.Errorf("--%s: invalid quantity %q for %s: %v", flag, values[name], name, err)
+		}
+		list[corev1.ResourceName(name)] = quantity
+	}
+	return list, nil
+}
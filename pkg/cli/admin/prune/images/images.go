@@ -40,6 +40,8 @@ import (
 	"github.com/openshift/library-go/pkg/network/networkutils"
 
 	"github.com/openshift/oc/pkg/cli/admin/prune/imageprune"
+	"github.com/openshift/oc/pkg/client/cache"
+	"github.com/openshift/oc/pkg/client/paging"
 	"github.com/openshift/oc/pkg/version"
 )
 
@@ -62,7 +64,8 @@ var (
 		By default, the prune operation performs a dry run making no changes to internal registry. A
 		--confirm flag is needed for changes to be effective. The flag requires a valid route to the
 		integrated container image registry. If this command is run outside of the cluster network, the route
-		needs to be provided using --registry-url.
+		needs to be provided using --registry-url. Lists cached with --cache-ttl are only reused
+		by dry runs: with --confirm, build configs and image streams are always listed again.
 
 		Only a user with a cluster role %s or higher who is logged-in will be able to actually
 		delete the images.
@@ -124,6 +127,8 @@ type PruneImagesOptions struct {
 	PruneRegistry       *bool
 	IgnoreInvalidRefs   bool
 	NumWorkers          *int
+	CacheOptions        *cache.Options
+	ChunkSize           int64
 
 	ClientConfig       *restclient.Config
 	AppsClient         appsv1client.AppsV1Interface
@@ -148,6 +153,8 @@ func NewCmdPruneImages(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 		PruneRegistry:      &defaultPruneRegistry,
 		AllImages:          &allImages,
 		NumWorkers:         &defaultNumWorkers,
+		CacheOptions:       cache.NewOptions(),
+		ChunkSize:          paging.DefaultChunkSize,
 	}
 
 	cmd := &cobra.Command{
@@ -173,6 +180,8 @@ func NewCmdPruneImages(f kcmdutil.Factory, streams genericiooptions.IOStreams) *
 	cmd.Flags().BoolVar(opts.PruneRegistry, "prune-registry", *opts.PruneRegistry, "If false, the prune operation will clean up image API objects, but the none of the associated content in the registry is removed.  Note, if only image API objects are cleaned up through use of this flag, the only means for subsequently cleaning up registry data corresponding to those image API objects is to employ the 'hard prune' administrative task.")
 	cmd.Flags().BoolVar(&opts.IgnoreInvalidRefs, "ignore-invalid-refs", opts.IgnoreInvalidRefs, "If true, the pruning process will ignore all errors while parsing image references. This means that the pruning process will ignore the intended connection between the object and the referenced image. As a result an image may be incorrectly deleted as unused.")
 	cmd.Flags().IntVar(opts.NumWorkers, "num-workers", *opts.NumWorkers, "Specify the number of parallel workers to use when running prune operations.")
+	opts.CacheOptions.AddFlags(cmd.Flags())
+	kcmdutil.AddChunkSizeFlag(cmd, &opts.ChunkSize)
 
 	return cmd
 }
@@ -326,13 +335,20 @@ func (o PruneImagesOptions) Run() error {
 		return err
 	}
 
-	allBCs, err := o.BuildClient.BuildConfigs(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	// stale lists must not decide what gets deleted
+	var listCache *cache.Cache
+	if !o.Confirm {
+		listCache = o.CacheOptions.ToCache(o.ClientConfig)
+	}
+
+	buildClient := paging.BuildV1(o.BuildClient, o.ChunkSize)
+	allBCs, err := cache.BuildV1(listCache, buildClient).BuildConfigs(o.Namespace).List(context.TODO(), metav1.ListOptions{})
 	// We need to tolerate 'not found' errors for buildConfigs since they may be disabled in Atomic
 	if err != nil && !kerrors.IsNotFound(err) {
 		return err
 	}
 
-	allBuilds, err := o.BuildClient.Builds(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	allBuilds, err := buildClient.Builds(o.Namespace).List(context.TODO(), metav1.ListOptions{})
 	// We need to tolerate 'not found' errors for builds since they may be disabled in Atomic
 	if err != nil && !kerrors.IsNotFound(err) {
 		return err
@@ -400,7 +416,7 @@ func (o PruneImagesOptions) Run() error {
 		limitRangesMap[limit.Namespace] = limits
 	}
 
-	allStreams, err := o.ImageClient.ImageStreams(o.Namespace).List(context.TODO(), metav1.ListOptions{})
+	allStreams, err := cache.ImageV1(listCache, paging.ImageV1(o.ImageClient, o.ChunkSize)).ImageStreams(o.Namespace).List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
 		return err
 	}
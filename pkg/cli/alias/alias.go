@@ -0,0 +1,160 @@
+package alias
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alessio/shellescape"
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var (
+	aliasLong = templates.LongDesc(`
+		Manage command aliases
+
+		Aliases are shortcuts for frequently used command lines. When the first
+		argument given to oc is the name of an alias, it is replaced by the command
+		line of the alias before the command is run. Built-in commands always take
+		precedence over aliases.
+
+		Aliases are stored in $XDG_CONFIG_HOME/oc/aliases, or ~/.config/oc/aliases,
+		one 'name = command line' per line.`)
+
+	aliasExample = templates.Examples(`
+		# Define an alias printing the build chain of an image stream tag in dot format
+		oc alias set bc-graph 'adm build-chain -o dot'
+
+		# Use the alias
+		oc bc-graph ruby:latest -n myproject
+
+		# List the defined aliases
+		oc alias list
+
+		# Remove an alias
+		oc alias rm bc-graph`)
+)
+
+// AliasOptions contains the options shared by the alias subcommands
+type AliasOptions struct {
+	Path string
+
+	genericiooptions.IOStreams
+}
+
+func NewAliasOptions(streams genericiooptions.IOStreams) *AliasOptions {
+	return &AliasOptions{
+		Path:      DefaultPath(),
+		IOStreams: streams,
+	}
+}
+
+// NewCmdAlias implements the OpenShift cli alias command
+func NewCmdAlias(streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewAliasOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "alias",
+		Short:   "Manage command aliases",
+		Long:    aliasLong,
+		Example: aliasExample,
+		Run:     kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the defined aliases",
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.RunList())
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set NAME COMMAND...",
+		Short: "Define or replace an alias",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) < 2 {
+				kcmdutil.CheckErr(kcmdutil.UsageErrorf(cmd, "an alias name and a command line are required"))
+			}
+			kcmdutil.CheckErr(o.RunSet(cmd.Root(), args[0], args[1:]))
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "rm NAME...",
+		Short: "Remove aliases",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				kcmdutil.CheckErr(kcmdutil.UsageErrorf(cmd, "at least one alias name is required"))
+			}
+			kcmdutil.CheckErr(o.RunRemove(args))
+		},
+	})
+
+	return cmd
+}
+
+func (o *AliasOptions) RunList() error {
+	aliases, err := Load(o.Path)
+	if err != nil {
+		return err
+	}
+	for _, name := range aliases.Names() {
+		fmt.Fprintf(o.Out, "%s = %s\n", name, aliases[name])
+	}
+	return nil
+}
+
+// RunSet stores the alias. A single command argument is stored as is, so that
+// it can hold a quoted command line, multiple arguments are quoted and joined.
+func (o *AliasOptions) RunSet(root *cobra.Command, name string, command []string) error {
+	if err := ValidateName(root, name); err != nil {
+		return err
+	}
+	aliases, err := Load(o.Path)
+	if err != nil {
+		return err
+	}
+
+	expansion := command[0]
+	if len(command) > 1 {
+		quoted := make([]string, 0, len(command))
+		for _, arg := range command {
+			quoted = append(quoted, shellescape.Quote(arg))
+		}
+		expansion = strings.Join(quoted, " ")
+	}
+	if len(strings.TrimSpace(expansion)) == 0 {
+		return fmt.Errorf("the command line of alias %q can't be empty", name)
+	}
+	if strings.Contains(expansion, "\n") {
+		return fmt.Errorf("the command line of alias %q can't span multiple lines", name)
+	}
+
+	aliases[name] = expansion
+	if err := aliases.Save(o.Path); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "alias %q set\n", name)
+	return nil
+}
+
+func (o *AliasOptions) RunRemove(names []string) error {
+	aliases, err := Load(o.Path)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, ok := aliases[name]; !ok {
+			return fmt.Errorf("alias %q not found", name)
+		}
+		delete(aliases, name)
+	}
+	if err := aliases.Save(o.Path); err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Fprintf(o.Out, "alias %q removed\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package alias
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/oc/pkg/helpers/cliconfig"
+)
+
+// Aliases maps alias names to the command line they expand to.
+type Aliases map[string]string
+
+// DefaultPath returns the location of the aliases file, next to the oc config.
+func DefaultPath() string {
+	return filepath.Join(cliconfig.Dir(), "aliases")
+}
+
+// Load reads the aliases stored in path. A missing file holds no aliases.
+// Every non empty line of the file has the form "name = command line",
+// lines starting with '#' are ignored.
+func Load(path string) (Aliases, error) {
+	aliases := Aliases{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, expansion, ok := strings.Cut(text, "=")
+		name, expansion = strings.TrimSpace(name), strings.TrimSpace(expansion)
+		if !ok || len(name) == 0 || len(expansion) == 0 {
+			return nil, fmt.Errorf("%s:%d: expected 'name = command', got %q", path, line, text)
+		}
+		aliases[name] = expansion
+	}
+	return aliases, scanner.Err()
+}
+
+// Save writes the aliases to path, creating its parent directory if needed.
+// Aliases may hold namespaces, servers or tokens, so both are only readable
+// by the user.
+func (a Aliases) Save(path string) error {
+	buf := &bytes.Buffer{}
+	for _, name := range a.Names() {
+		fmt.Fprintf(buf, "%s = %s\n", name, a[name])
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// Names returns the sorted names of the aliases.
+func (a Aliases) Names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateName checks that name can be used as an alias of the root command.
+func ValidateName(root *cobra.Command, name string) error {
+	if len(name) == 0 || strings.HasPrefix(name, "-") || strings.ContainsAny(name, "= \t\n") {
+		return fmt.Errorf("invalid alias name %q", name)
+	}
+	if isBuiltin(root, name) {
+		return fmt.Errorf("%q is a built-in command and can't be used as an alias", name)
+	}
+	return nil
+}
+
+// Expand replaces the first non-flag argument of args, args[0] being the
+// binary, with its expansion if it is an alias. The persistent flags of root
+// are skipped along with their values, as in "oc -n test <alias>". Built-in
+// commands always take precedence over aliases and expansions are not
+// expanded again. It returns whether args were expanded.
+func Expand(root *cobra.Command, args []string, aliases Aliases) ([]string, bool, error) {
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--" {
+			return args, false, nil
+		}
+		if strings.HasPrefix(args[i], "-") {
+			if takesValue(root, args[i]) {
+				i++
+			}
+			continue
+		}
+		expansion, ok := aliases[args[i]]
+		if !ok || isBuiltin(root, args[i]) {
+			return args, false, nil
+		}
+		words, err := shlex.Split(expansion)
+		if err != nil {
+			return args, false, fmt.Errorf("invalid alias %q: %v", args[i], err)
+		}
+		expanded := make([]string, 0, len(args)+len(words)-1)
+		expanded = append(expanded, args[:i]...)
+		expanded = append(expanded, words...)
+		expanded = append(expanded, args[i+1:]...)
+		return expanded, true, nil
+	}
+	return args, false, nil
+}
+
+// takesValue returns whether arg is a persistent flag of root whose value is
+// the next argument, as opposed to a boolean flag or a flag given with its
+// value, as in --namespace=test or -ntest.
+func takesValue(root *cobra.Command, arg string) bool {
+	var flag *pflag.Flag
+	if name := strings.TrimPrefix(arg, "--"); name != arg {
+		if strings.Contains(name, "=") {
+			return false
+		}
+		flag = root.PersistentFlags().Lookup(name)
+	} else {
+		shorthand := strings.TrimPrefix(arg, "-")
+		if len(shorthand) != 1 {
+			return false
+		}
+		flag = root.PersistentFlags().ShorthandLookup(shorthand)
+	}
+	return flag != nil && len(flag.NoOptDefVal) == 0
+}
+
+func isBuiltin(root *cobra.Command, name string) bool {
+	switch name {
+	case "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package alias
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func testRoot() *cobra.Command {
+	root := &cobra.Command{Use: "oc"}
+	root.AddCommand(&cobra.Command{Use: "get"}, &cobra.Command{Use: "adm"})
+	root.PersistentFlags().StringP("namespace", "n", "", "")
+	root.PersistentFlags().String("context", "", "")
+	root.PersistentFlags().Bool("insecure-skip-tls-verify", false, "")
+	return root
+}
+
+func TestExpand(t *testing.T) {
+	aliases := Aliases{
+		"bc-graph": "adm build-chain -o dot --all",
+		"get":      "get pods",
+		"quoted":   `adm build-chain "my stream"`,
+	}
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		expanded bool
+	}{
+		{
+			name:     "alias with arguments and flags",
+			args:     []string{"oc", "--v=4", "bc-graph", "ruby:latest", "-n", "test"},
+			expected: []string{"oc", "--v=4", "adm", "build-chain", "-o", "dot", "--all", "ruby:latest", "-n", "test"},
+			expanded: true,
+		},
+		{
+			name:     "namespace flag before the alias",
+			args:     []string{"oc", "-n", "test", "bc-graph", "ruby:latest"},
+			expected: []string{"oc", "-n", "test", "adm", "build-chain", "-o", "dot", "--all", "ruby:latest"},
+			expanded: true,
+		},
+		{
+			name:     "namespace flag with its value before the alias",
+			args:     []string{"oc", "--namespace=test", "bc-graph"},
+			expected: []string{"oc", "--namespace=test", "adm", "build-chain", "-o", "dot", "--all"},
+			expanded: true,
+		},
+		{
+			name:     "flag value matching an alias",
+			args:     []string{"oc", "--context", "quoted", "bc-graph"},
+			expected: []string{"oc", "--context", "quoted", "adm", "build-chain", "-o", "dot", "--all"},
+			expanded: true,
+		},
+		{
+			name:     "boolean flag before the alias",
+			args:     []string{"oc", "--insecure-skip-tls-verify", "bc-graph"},
+			expected: []string{"oc", "--insecure-skip-tls-verify", "adm", "build-chain", "-o", "dot", "--all"},
+			expanded: true,
+		},
+		{
+			name:     "flag value matching a builtin",
+			args:     []string{"oc", "-n", "get", "bc-graph"},
+			expected: []string{"oc", "-n", "get", "adm", "build-chain", "-o", "dot", "--all"},
+			expanded: true,
+		},
+		{
+			name:     "builtin takes precedence",
+			args:     []string{"oc", "get", "bc"},
+			expected: []string{"oc", "get", "bc"},
+		},
+		{
+			name:     "quoted expansion",
+			args:     []string{"oc", "quoted"},
+			expected: []string{"oc", "adm", "build-chain", "my stream"},
+			expanded: true,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"oc", "unknown", "bc-graph"},
+			expected: []string{"oc", "unknown", "bc-graph"},
+		},
+		{
+			name:     "no command",
+			args:     []string{"oc"},
+			expected: []string{"oc"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, expanded, err := Expand(testRoot(), test.args, aliases)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expanded != test.expanded || !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %v (%t), got %v (%t)", test.expected, test.expanded, got, expanded)
+			}
+		})
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oc", "aliases")
+
+	aliases, err := Load(path)
+	if err != nil || len(aliases) != 0 {
+		t.Fatalf("expected no aliases from a missing file, got %v, %v", aliases, err)
+	}
+
+	aliases = Aliases{"b": "adm build-chain", "a": "get pods -o wide"}
+	if err := aliases.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "a = get pods -o wide\nb = adm build-chain\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, string(data))
+	}
+	for path, expected := range map[string]os.FileMode{path: 0600, filepath.Dir(path): 0700} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != expected {
+			t.Errorf("expected %s to have mode %v, got %v", path, expected, info.Mode().Perm())
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("# comment\n\nx = get = pods\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, Aliases{"x": "get = pods"}) {
+		t.Errorf("unexpected aliases: %v", loaded)
+	}
+
+	if err := os.WriteFile(path, []byte("broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Errorf("expected an error for a malformed line")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"bc-graph": true,
+		"get":      false,
+		"help":     false,
+		"-x":       false,
+		"a b":      false,
+		"a=b":      false,
+		"":         false,
+	} {
+		if err := ValidateName(testRoot(), name); (err == nil) != valid {
+			t.Errorf("%q: expected valid=%t, got %v", name, valid, err)
+		}
+	}
+}
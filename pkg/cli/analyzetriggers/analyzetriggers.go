@@ -0,0 +1,393 @@
+package analyzetriggers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	buildhelpers "github.com/openshift/oc/pkg/helpers/build"
+)
+
+var (
+	analyzeTriggersLong = templates.LongDesc(`
+		Audit the image change triggers of build configs and deployment configs.
+
+		Every image change trigger is checked and reported when:
+
+		* the image stream or the tag it references doesn't exist
+		* it references an image stream in another namespace that the service account
+		  acting on the trigger is not allowed to watch
+		* the same object has another trigger for the same image stream tag
+
+		The service account checked is the one of the build config, 'builder' by default,
+		and the one of the pods of the deployment config, 'default' by default. The
+		findings are followed by a summary of every namespace audited. The command fails
+		if any problem is found.
+	`)
+
+	analyzeTriggersExample = templates.Examples(`
+		# Audit the image change triggers of the current namespace
+		oc ex analyze-triggers
+
+		# Audit the image change triggers of all namespaces
+		oc ex analyze-triggers --all-namespaces
+	`)
+)
+
+const (
+	problemMissingStream = "missing image stream"
+	problemMissingTag    = "missing tag"
+	problemForbidden     = "forbidden"
+	problemDuplicate     = "duplicate"
+)
+
+// AnalyzeTriggersOptions contains all the options needed to audit image change triggers
+type AnalyzeTriggersOptions struct {
+	Namespace     string
+	AllNamespaces bool
+
+	KubeClient  kubernetes.Interface
+	AppsClient  appsv1client.AppsV1Interface
+	BuildClient buildv1client.BuildV1Interface
+	ImageClient imagev1client.ImageV1Interface
+
+	genericiooptions.IOStreams
+}
+
+// trigger is an image change trigger of a build config or a deployment config.
+type trigger struct {
+	namespace      string
+	owner          string
+	serviceAccount string
+	from           corev1.ObjectReference
+}
+
+// finding is a problem found with a trigger.
+type finding struct {
+	trigger
+	problem string
+	details string
+}
+
+func NewAnalyzeTriggersOptions(streams genericiooptions.IOStreams) *AnalyzeTriggersOptions {
+	return &AnalyzeTriggersOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdAnalyzeTriggers implements the OpenShift experimental analyze-triggers command
+func NewCmdAnalyzeTriggers(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewAnalyzeTriggersOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "analyze-triggers",
+		Short:   "Audit the image change triggers of build and deployment configs",
+		Long:    analyzeTriggersLong,
+		Example: analyzeTriggersExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, audit the triggers of all namespaces.")
+
+	return cmd
+}
+
+func (o *AnalyzeTriggersOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.AppsClient, err = appsv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.BuildClient, err = buildv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.ImageClient, err = imagev1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *AnalyzeTriggersOptions) Run() error {
+	ctx := context.TODO()
+
+	triggers, err := o.listTriggers(ctx)
+	if err != nil {
+		return err
+	}
+
+	findings := []finding{}
+	streams := map[string]*imagev1.ImageStream{}
+	watchable := map[string]bool{}
+	seen := map[string]bool{}
+	for _, t := range triggers {
+		key := t.namespace + "/" + t.owner + "/" + t.from.Namespace + "/" + t.from.Name
+		if seen[key] {
+			findings = append(findings, finding{trigger: t, problem: problemDuplicate})
+			continue
+		}
+		seen[key] = true
+
+		if t.from.Namespace != t.namespace {
+			allowed, err := o.canWatch(ctx, t, watchable)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				findings = append(findings, finding{trigger: t, problem: problemForbidden, details: fmt.Sprintf("system:serviceaccount:%s:%s can't watch image streams in %q", t.namespace, t.serviceAccount, t.from.Namespace)})
+			}
+		}
+
+		if f, ok, err := o.checkReference(ctx, t, streams); err != nil {
+			return err
+		} else if !ok {
+			findings = append(findings, f)
+		}
+	}
+
+	printFindings(o.Out, findings)
+	if len(findings) > 0 {
+		fmt.Fprintln(o.Out)
+	}
+	printSummary(o.Out, triggers, findings)
+
+	if len(findings) > 0 {
+		return kcmdutil.ErrExit
+	}
+	return nil
+}
+
+// listTriggers returns the image change triggers referencing image stream
+// tags of the build configs and deployment configs audited.
+func (o *AnalyzeTriggersOptions) listTriggers(ctx context.Context) ([]trigger, error) {
+	triggers := []trigger{}
+
+	bcs, err := o.BuildClient.BuildConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, bc := range bcs.Items {
+		serviceAccount := bc.Spec.ServiceAccount
+		if len(serviceAccount) == 0 {
+			serviceAccount = "builder"
+		}
+		for _, policy := range bc.Spec.Triggers {
+			if policy.Type != buildv1.ImageChangeBuildTriggerType || policy.ImageChange == nil {
+				continue
+			}
+			from := policy.ImageChange.From
+			if from == nil {
+				from = buildhelpers.GetInputReference(bc.Spec.Strategy)
+			}
+			if from == nil || from.Kind != "ImageStreamTag" {
+				continue
+			}
+			triggers = append(triggers, newTrigger(bc.Namespace, "bc/"+bc.Name, serviceAccount, *from))
+		}
+	}
+
+	dcs, err := o.AppsClient.DeploymentConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, dc := range dcs.Items {
+		serviceAccount := "default"
+		if dc.Spec.Template != nil && len(dc.Spec.Template.Spec.ServiceAccountName) > 0 {
+			serviceAccount = dc.Spec.Template.Spec.ServiceAccountName
+		}
+		for _, policy := range dc.Spec.Triggers {
+			if policy.Type != appsv1.DeploymentTriggerOnImageChange || policy.ImageChangeParams == nil {
+				continue
+			}
+			from := policy.ImageChangeParams.From
+			if from.Kind != "ImageStreamTag" {
+				continue
+			}
+			triggers = append(triggers, newTrigger(dc.Namespace, "dc/"+dc.Name, serviceAccount, from))
+		}
+	}
+
+	return triggers, nil
+}
+
+func newTrigger(namespace, owner, serviceAccount string, from corev1.ObjectReference) trigger {
+	if len(from.Namespace) == 0 {
+		from.Namespace = namespace
+	}
+	from.Name = normalizeImageStreamTag(from.Name)
+	return trigger{namespace: namespace, owner: owner, serviceAccount: serviceAccount, from: from}
+}
+
+// normalizeImageStreamTag defaults to the 'latest' tag when none is specified.
+func normalizeImageStreamTag(name string) string {
+	stream, tag, _ := imageutil.SplitImageStreamTag(name)
+	return imageutil.JoinImageStreamTag(stream, tag)
+}
+
+// checkReference returns a finding when the image stream tag t references
+// doesn't exist. Image streams are cached in streams, nil if missing.
+func (o *AnalyzeTriggersOptions) checkReference(ctx context.Context, t trigger, streams map[string]*imagev1.ImageStream) (finding, bool, error) {
+	name, tag, _ := imageutil.SplitImageStreamTag(t.from.Name)
+	key := t.from.Namespace + "/" + name
+	stream, ok := streams[key]
+	if !ok {
+		var err error
+		stream, err = o.ImageClient.ImageStreams(t.from.Namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case kerrors.IsNotFound(err):
+			stream = nil
+		case kerrors.IsForbidden(err):
+			klog.V(2).Infof("Unable to check image stream %s: %v", key, err)
+			return finding{}, true, nil
+		case err != nil:
+			return finding{}, false, err
+		}
+		streams[key] = stream
+	}
+
+	if stream == nil {
+		return finding{trigger: t, problem: problemMissingStream}, false, nil
+	}
+	for _, specTag := range stream.Spec.Tags {
+		if specTag.Name == tag {
+			return finding{}, true, nil
+		}
+	}
+	for _, statusTag := range stream.Status.Tags {
+		if statusTag.Tag == tag {
+			return finding{}, true, nil
+		}
+	}
+	return finding{trigger: t, problem: problemMissingTag}, false, nil
+}
+
+// canWatch returns whether the service account of t is allowed to watch the
+// image streams of the namespace it references. Answers are cached in watchable.
+func (o *AnalyzeTriggersOptions) canWatch(ctx context.Context, t trigger, watchable map[string]bool) (bool, error) {
+	user := serviceaccount.MakeUsername(t.namespace, t.serviceAccount)
+	key := user + "/" + t.from.Namespace
+	if allowed, ok := watchable[key]; ok {
+		return allowed, nil
+	}
+
+	review, err := o.KubeClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user,
+			Groups: serviceaccount.MakeGroupNames(t.namespace),
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: t.from.Namespace,
+				Verb:      "watch",
+				Group:     imagev1.GroupName,
+				Resource:  "imagestreams",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("unable to check the permissions of %s: %v", user, err)
+	}
+	watchable[key] = review.Status.Allowed
+	return review.Status.Allowed, nil
+}
+
+func printFindings(out io.Writer, findings []finding) {
+	if len(findings) == 0 {
+		return
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].namespace != findings[j].namespace {
+			return findings[i].namespace < findings[j].namespace
+		}
+		return findings[i].owner < findings[j].owner
+	})
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tOBJECT\tIMAGE STREAM TAG\tPROBLEM")
+	for _, f := range findings {
+		problem := f.problem
+		if len(f.details) > 0 {
+			problem += ": " + f.details
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s/%s\t%s\n", f.namespace, f.owner, f.from.Namespace, f.from.Name, problem)
+	}
+	w.Flush()
+}
+
+func printSummary(out io.Writer, triggers []trigger, findings []finding) {
+	type summary struct {
+		triggers int
+		problems map[string]int
+	}
+	summaries := map[string]*summary{}
+	get := func(namespace string) *summary {
+		s, ok := summaries[namespace]
+		if !ok {
+			s = &summary{problems: map[string]int{}}
+			summaries[namespace] = s
+		}
+		return s
+	}
+	for _, t := range triggers {
+		get(t.namespace).triggers++
+	}
+	for _, f := range findings {
+		get(f.namespace).problems[f.problem]++
+	}
+	if len(summaries) == 0 {
+		fmt.Fprintln(out, "No image change triggers found.")
+		return
+	}
+
+	namespaces := []string{}
+	for namespace := range summaries {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tTRIGGERS\tMISSING\tFORBIDDEN\tDUPLICATE")
+	for _, namespace := range namespaces {
+		s := summaries[namespace]
+		missing := s.problems[problemMissingStream] + s.problems[problemMissingTag]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", namespace, s.triggers, missing, s.problems[problemForbidden], s.problems[problemDuplicate])
+	}
+	w.Flush()
+}
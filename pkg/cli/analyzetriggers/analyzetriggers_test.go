@@ -0,0 +1,114 @@
+package analyzetriggers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeappsclient "github.com/openshift/client-go/apps/clientset/versioned/fake"
+	fakebuildclient "github.com/openshift/client-go/build/clientset/versioned/fake"
+	fakeimageclient "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+func imageChangeTrigger(from *corev1.ObjectReference) buildv1.BuildTriggerPolicy {
+	return buildv1.BuildTriggerPolicy{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{From: from}}
+}
+
+func TestRun(t *testing.T) {
+	app := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{SourceStrategy: &buildv1.SourceBuildStrategy{
+					From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+				}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{
+				imageChangeTrigger(nil),
+				imageChangeTrigger(&corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base"}),
+			},
+		},
+	}
+	other := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			Triggers: []buildv1.BuildTriggerPolicy{
+				imageChangeTrigger(&corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "shared", Name: "missing:1"}),
+				imageChangeTrigger(&corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/base"}),
+			},
+		},
+	}
+	web := &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec: appsv1.DeploymentConfigSpec{
+			Triggers: appsv1.DeploymentTriggerPolicies{{
+				Type: appsv1.DeploymentTriggerOnImageChange,
+				ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{
+					From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:v2"},
+				},
+			}},
+		},
+	}
+	base := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "test"},
+		Status:     imagev1.ImageStreamStatus{Tags: []imagev1.NamedTagEventList{{Tag: "latest"}}},
+	}
+
+	kubeClient := fakekubeclient.NewSimpleClientset()
+	reviews := 0
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		reviews++
+		review := action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		if review.Spec.User != "system:serviceaccount:test:builder" || review.Spec.ResourceAttributes.Namespace != "shared" {
+			t.Errorf("unexpected review: %#v", review.Spec)
+		}
+		return true, review, nil
+	})
+
+	out := &bytes.Buffer{}
+	o := &AnalyzeTriggersOptions{
+		Namespace:   "test",
+		KubeClient:  kubeClient,
+		AppsClient:  fakeappsclient.NewSimpleClientset(web).AppsV1(),
+		BuildClient: fakebuildclient.NewSimpleClientset(app, other).BuildV1(),
+		ImageClient: fakeimageclient.NewSimpleClientset(base).ImageV1(),
+		IOStreams:   genericiooptions.IOStreams{Out: out},
+	}
+	if err := o.Run(); err != kcmdutil.ErrExit {
+		t.Fatalf("expected the problems found to fail the command, got %v", err)
+	}
+	if reviews != 1 {
+		t.Errorf("expected a single access review, got %d", reviews)
+	}
+
+	lines := strings.Split(out.String(), "\n")
+	for _, expected := range [][]string{
+		{"bc/app", "test/base:latest", "duplicate"},
+		{"bc/other", "shared/missing:1", "forbidden: system:serviceaccount:test:builder can't watch image streams in \"shared\""},
+		{"bc/other", "shared/missing:1", "missing image stream"},
+		{"dc/web", "test/base:v2", "missing tag"},
+		{"4", "2", "1", "1"},
+	} {
+		found := false
+		for _, line := range lines {
+			if strings.Join(strings.Fields(line), " ") == "test "+strings.Join(expected, " ") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected a line with %q in output:\n%s", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package checkdrift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	templatev1client "github.com/openshift/client-go/template/clientset/versioned/typed/template/v1"
+	"github.com/openshift/library-go/pkg/template/templateprocessing"
+)
+
+var (
+	checkDriftLong = templates.LongDesc(`
+		Compare build configs with the template they were instantiated from.
+
+		The template of a build config is found from the template instance it belongs to,
+		through the template.openshift.io/template-instance-owner label. The template is
+		processed again with the parameters of the instance and every field it sets is
+		compared with the live build config. Fields set by the server or by defaults, that
+		the template doesn't set, aren't reported. Fields depending on generated parameters
+		can't be compared and are skipped.
+
+		With --all, every build config of the project created from a template instance is
+		checked.
+	`)
+
+	checkDriftExample = templates.Examples(`
+		# Show how the build config 'frontend' differs from its template
+		oc ex check-drift bc/frontend
+
+		# Check every build config of the current project created from a template
+		oc ex check-drift --all
+	`)
+)
+
+// generatedValue replaces the generated parameters, whose values aren't
+// recorded, when processing the template again.
+const generatedValue = "\x00generated\x00"
+
+// Difference is a field of a build config that differs from its template.
+type Difference struct {
+	// Path is the path of the field, e.g. spec.output.to.name.
+	Path string
+	// Expected is the value set by the template.
+	Expected interface{}
+	// Actual is the live value, nil when the field is missing.
+	Actual  interface{}
+	Missing bool
+}
+
+// CheckDriftOptions contains all the options needed to compare build configs with their template
+type CheckDriftOptions struct {
+	Namespace string
+	Name      string
+	All       bool
+
+	BuildClient    buildv1client.BuildV1Interface
+	TemplateClient templatev1client.TemplateV1Interface
+	CoreClient     corev1client.CoreV1Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewCheckDriftOptions(streams genericiooptions.IOStreams) *CheckDriftOptions {
+	return &CheckDriftOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdCheckDrift implements the OpenShift experimental check-drift command
+func NewCmdCheckDrift(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewCheckDriftOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "check-drift (bc/NAME | --all)",
+		Short:   "Compare build configs with the template they were instantiated from",
+		Long:    checkDriftLong,
+		Example: checkDriftExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, check every build config of the project created from a template instance.")
+
+	return cmd
+}
+
+func (o *CheckDriftOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	switch {
+	case o.All && len(args) > 0:
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed with --all")
+	case !o.All && len(args) != 1:
+		return kcmdutil.UsageErrorf(cmd, "a build config, or --all, is required")
+	case len(args) == 1:
+		o.Name = args[0]
+		if resource, name, ok := strings.Cut(args[0], "/"); ok {
+			switch resource {
+			case "bc", "buildconfig", "buildconfigs":
+			default:
+				return kcmdutil.UsageErrorf(cmd, "only build configs can be checked, got %q", resource)
+			}
+			o.Name = name
+		}
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.BuildClient, err = buildv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.TemplateClient, err = templatev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.CoreClient, err = corev1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *CheckDriftOptions) Validate() error {
+	if !o.All && len(o.Name) == 0 {
+		return fmt.Errorf("build config name cannot be empty")
+	}
+	return nil
+}
+
+func (o *CheckDriftOptions) Run() error {
+	ctx := context.TODO()
+	buildConfigs := []buildv1.BuildConfig{}
+	if o.All {
+		list, err := o.BuildClient.BuildConfigs(o.Namespace).List(ctx, metav1.ListOptions{LabelSelector: templatev1.TemplateInstanceOwner})
+		if err != nil {
+			return err
+		}
+		buildConfigs = list.Items
+		if len(buildConfigs) == 0 {
+			fmt.Fprintln(o.Out, "No build configs were created from a template instance.")
+			return nil
+		}
+	} else {
+		bc, err := o.BuildClient.BuildConfigs(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if _, ok := bc.Labels[templatev1.TemplateInstanceOwner]; !ok {
+			return fmt.Errorf("build config %q wasn't created from a template instance", bc.Name)
+		}
+		buildConfigs = append(buildConfigs, *bc)
+	}
+	sort.Slice(buildConfigs, func(i, j int) bool { return buildConfigs[i].Name < buildConfigs[j].Name })
+
+	for i := range buildConfigs {
+		bc := &buildConfigs[i]
+		instance, err := o.templateInstance(ctx, bc.Labels[templatev1.TemplateInstanceOwner])
+		if err != nil {
+			return err
+		}
+		if instance == nil {
+			fmt.Fprintf(o.ErrOut, "warning: the template instance of build config %q doesn't exist anymore\n", bc.Name)
+			continue
+		}
+
+		expected, err := o.templateObject(ctx, instance, bc.Name)
+		if err != nil {
+			return err
+		}
+		if expected == nil {
+			fmt.Fprintf(o.ErrOut, "warning: the template of instance %q doesn't define build config %q\n", instance.Name, bc.Name)
+			continue
+		}
+		live, err := runtime.DefaultUnstructuredConverter.ToUnstructured(bc)
+		if err != nil {
+			return err
+		}
+		printDifferences(o.Out, bc.Name, instance.Name, Compare(expected, live))
+	}
+	return nil
+}
+
+// templateInstance returns the template instance of the given UID in the
+// namespace, nil if there is none.
+func (o *CheckDriftOptions) templateInstance(ctx context.Context, uid string) (*templatev1.TemplateInstance, error) {
+	list, err := o.TemplateClient.TemplateInstances(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if string(list.Items[i].UID) == uid {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// templateObject processes the template of instance with its parameters and
+// returns the build config named name it defines, nil if there is none.
+func (o *CheckDriftOptions) templateObject(ctx context.Context, instance *templatev1.TemplateInstance, name string) (map[string]interface{}, error) {
+	template := instance.Spec.Template.DeepCopy()
+	values := map[string]string{}
+	if instance.Spec.Secret != nil {
+		secret, err := o.CoreClient.Secrets(o.Namespace).Get(ctx, instance.Spec.Secret.Name, metav1.GetOptions{})
+		switch {
+		case kerrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		default:
+			for key, value := range secret.Data {
+				values[key] = string(value)
+			}
+		}
+	}
+	for i := range template.Parameters {
+		param := &template.Parameters[i]
+		if value, ok := values[param.Name]; ok {
+			param.Value = value
+			param.Generate = ""
+		} else if len(param.Generate) > 0 {
+			param.Value = generatedValue
+			param.Generate = ""
+		}
+	}
+
+	processor := templateprocessing.NewProcessor(nil)
+	if errs := processor.Process(template); len(errs) > 0 {
+		return nil, fmt.Errorf("unable to process the template of instance %q: %v", instance.Name, errs.ToAggregate())
+	}
+	for _, item := range template.Objects {
+		obj, ok := item.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if obj.GetKind() == "BuildConfig" && obj.GetName() == name {
+			// round trip through json so that numbers compare like the live object's
+			data, err := json.Marshal(obj.Object)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := runtime.Decode(unstructured.UnstructuredJSONScheme, data)
+			if err != nil {
+				return nil, err
+			}
+			return decoded.(*unstructured.Unstructured).Object, nil
+		}
+	}
+	return nil, nil
+}
+
+// Compare returns the fields set in expected, the object defined by a
+// template, that differ in live. Only the labels and annotations of the
+// metadata are compared, and the status is ignored.
+func Compare(expected, live map[string]interface{}) []Difference {
+	differences := []Difference{}
+	for _, key := range sortedKeys(expected) {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			expectedMeta, _ := expected[key].(map[string]interface{})
+			liveMeta, _ := live[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				if value, ok := expectedMeta[field]; ok {
+					differences = append(differences, compare("metadata."+field, value, liveMeta[field], liveMeta != nil && liveMeta[field] != nil)...)
+				}
+			}
+			continue
+		}
+		value, ok := live[key]
+		differences = append(differences, compare(key, expected[key], value, ok)...)
+	}
+	return differences
+}
+
+func compare(path string, expected, live interface{}, found bool) []Difference {
+	if s, ok := expected.(string); ok && strings.Contains(s, generatedValue) {
+		return nil
+	}
+	if !found {
+		if isEmpty(expected) {
+			return nil
+		}
+		return []Difference{{Path: path, Expected: expected, Missing: true}}
+	}
+
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		differences := []Difference{}
+		for _, key := range sortedKeys(e) {
+			value, ok := l[key]
+			differences = append(differences, compare(path+"."+key, e[key], value, ok)...)
+		}
+		return differences
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(e) {
+			break
+		}
+		differences := []Difference{}
+		for i := range e {
+			differences = append(differences, compare(fmt.Sprintf("%s[%d]", path, i), e[i], l[i], true)...)
+		}
+		return differences
+	default:
+		if reflect.DeepEqual(expected, live) {
+			return nil
+		}
+	}
+	return []Difference{{Path: path, Expected: expected, Actual: live}}
+}
+
+// isEmpty returns whether value is the zero value the server drops.
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	case string:
+		return len(v) == 0
+	case bool:
+		return !v
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printDifferences(out io.Writer, name, instance string, differences []Difference) {
+	if len(differences) == 0 {
+		fmt.Fprintf(out, "buildconfig/%s matches template instance %q\n", name, instance)
+		return
+	}
+	fmt.Fprintf(out, "buildconfig/%s differs from template instance %q:\n", name, instance)
+	for _, difference := range differences {
+		if difference.Missing {
+			fmt.Fprintf(out, "  %s: expected %s, missing\n", difference.Path, formatValue(difference.Expected))
+			continue
+		}
+		fmt.Fprintf(out, "  %s: expected %s, got %s\n", difference.Path, formatValue(difference.Expected), formatValue(difference.Actual))
+	}
+}
+
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
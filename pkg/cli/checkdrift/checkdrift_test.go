@@ -0,0 +1,131 @@
+package checkdrift
+
+import (
+	"bytes"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+	templatefake "github.com/openshift/client-go/template/clientset/versioned/fake"
+)
+
+const templateBuildConfig = `{
+	"apiVersion": "build.openshift.io/v1",
+	"kind": "BuildConfig",
+	"metadata": {"name": "${NAME}", "labels": {"app": "${NAME}"}},
+	"spec": {
+		"source": {"git": {"uri": "https://github.com/example/app.git", "ref": "${REF}"}},
+		"strategy": {"type": "Docker", "dockerStrategy": {}},
+		"output": {"to": {"kind": "ImageStreamTag", "name": "${NAME}:latest"}},
+		"triggers": [
+			{"type": "GitHub", "github": {"secret": "${WEBHOOK_SECRET}"}},
+			{"type": "ConfigChange"}
+		],
+		"successfulBuildsHistoryLimit": 5
+	}
+}`
+
+func newBuildConfig(name, ref, output string) *buildv1.BuildConfig {
+	return &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      name,
+			Labels:    map[string]string{"app": name, templatev1.TemplateInstanceOwner: "instance-uid"},
+		},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Source:   buildv1.BuildSource{Type: buildv1.BuildSourceGit, Git: &buildv1.GitBuildSource{URI: "https://github.com/example/app.git", Ref: ref}},
+				Strategy: buildv1.BuildStrategy{Type: buildv1.DockerBuildStrategyType, DockerStrategy: &buildv1.DockerBuildStrategy{}},
+				Output:   buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: output}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{
+				{Type: buildv1.GitHubWebHookBuildTriggerType, GitHubWebHook: &buildv1.WebHookTrigger{Secret: "generated-secret"}},
+				{Type: buildv1.ConfigChangeBuildTriggerType},
+			},
+			SuccessfulBuildsHistoryLimit: func(i int32) *int32 { return &i }(5),
+			RunPolicy:                    buildv1.BuildRunPolicySerial,
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	instance := &templatev1.TemplateInstance{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app", UID: "instance-uid"},
+		Spec: templatev1.TemplateInstanceSpec{
+			Template: templatev1.Template{
+				Parameters: []templatev1.Parameter{
+					{Name: "NAME", Value: "app"},
+					{Name: "REF"},
+					{Name: "WEBHOOK_SECRET", Generate: "expression", From: "[a-z]{10}"},
+				},
+				Objects: []runtime.RawExtension{{Raw: []byte(templateBuildConfig)}},
+			},
+			Secret: &corev1.LocalObjectReference{Name: "app-parameters"},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-parameters"},
+		Data:       map[string][]byte{"REF": []byte("main")},
+	}
+
+	tests := []struct {
+		name     string
+		bc       *buildv1.BuildConfig
+		expected string
+	}{
+		{
+			name:     "unchanged",
+			bc:       newBuildConfig("app", "main", "app:latest"),
+			expected: "buildconfig/app matches template instance \"app\"\n",
+		},
+		{
+			name: "drifted",
+			bc:   newBuildConfig("app", "hotfix", "app:v2"),
+			expected: "buildconfig/app differs from template instance \"app\":\n" +
+				"  spec.output.to.name: expected \"app:latest\", got \"app:v2\"\n" +
+				"  spec.source.git.ref: expected \"main\", got \"hotfix\"\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := NewCheckDriftOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+			o.Namespace = "test"
+			o.All = true
+			o.BuildClient = buildfake.NewSimpleClientset(test.bc).BuildV1()
+			o.TemplateClient = templatefake.NewSimpleClientset(instance).TemplateV1()
+			o.CoreClient = kubefake.NewSimpleClientset(secret).CoreV1()
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestRunNotFromTemplate(t *testing.T) {
+	bc := newBuildConfig("app", "main", "app:latest")
+	delete(bc.Labels, templatev1.TemplateInstanceOwner)
+
+	o := NewCheckDriftOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.Namespace = "test"
+	o.Name = "app"
+	o.BuildClient = buildfake.NewSimpleClientset(bc).BuildV1()
+	o.TemplateClient = templatefake.NewSimpleClientset().TemplateV1()
+	o.CoreClient = kubefake.NewSimpleClientset().CoreV1()
+	if err := o.Run(); err == nil {
+		t.Errorf("expected an error for a build config not created from a template")
+	}
+}
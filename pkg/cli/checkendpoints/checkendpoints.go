@@ -0,0 +1,358 @@
+package checkendpoints
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	kexec "k8s.io/kubectl/pkg/cmd/exec"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	checkEndpointsLong = templates.LongDesc(`
+		Check that the endpoints behind a service or a route are responding.
+
+		The endpoints of the service, or of the service a route points to, are listed
+		with the pod backing them and whether that pod is ready. With --probe, every
+		endpoint is additionally probed over TCP or HTTP and its reachability reported.
+
+		Pod addresses are usually not reachable from outside the cluster. Use --via-pod
+		to run the probes from inside a pod of the cluster instead; the pod must provide
+		'bash' for TCP probes and 'curl' for HTTP probes.
+
+		The command fails if any of the probed endpoints is not reachable.
+	`)
+
+	checkEndpointsExample = templates.Examples(`
+		# List the endpoints of the 'frontend' service and whether their pods are ready
+		oc ex check-endpoints svc/frontend
+
+		# Open a TCP connection to every endpoint of the service targeted by the 'frontend' route
+		oc ex check-endpoints route/frontend --probe=tcp
+
+		# Send an HTTP request to /healthz on every endpoint from inside the 'debug' pod
+		oc ex check-endpoints svc/frontend --probe=http --path=/healthz --via-pod=debug
+	`)
+)
+
+const (
+	probeNone = ""
+	probeTCP  = "tcp"
+	probeHTTP = "http"
+)
+
+// CheckEndpointsOptions contains all the options needed to check the endpoints of a service
+type CheckEndpointsOptions struct {
+	Resource schema.GroupResource
+	Name     string
+
+	Port      string
+	ProbeType string
+	Path      string
+	Timeout   time.Duration
+	ViaPod    string
+	Container string
+
+	Namespace   string
+	Config      *restclient.Config
+	KubeClient  kubernetes.Interface
+	RouteClient routev1client.RouteV1Interface
+
+	// Probe checks whether address is reachable, it defaults to probing
+	// from the client or from ViaPod.
+	Probe func(ctx context.Context, address string) error
+
+	genericiooptions.IOStreams
+}
+
+func NewCheckEndpointsOptions(streams genericiooptions.IOStreams) *CheckEndpointsOptions {
+	return &CheckEndpointsOptions{
+		Path:      "/",
+		Timeout:   5 * time.Second,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdCheckEndpoints implements the OpenShift experimental check-endpoints command
+func NewCmdCheckEndpoints(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewCheckEndpointsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "check-endpoints (svc/NAME | route/NAME)",
+		Short:   "Check that the endpoints of a service or route are responding",
+		Long:    checkEndpointsLong,
+		Example: checkEndpointsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Port, "port", o.Port, "Name or number of the endpoint port to check. Defaults to the target port of the route, or all the ports.")
+	cmd.Flags().StringVar(&o.ProbeType, "probe", o.ProbeType, "Probe every endpoint. One of: (tcp, http)")
+	cmd.Flags().StringVar(&o.Path, "path", o.Path, "Path requested by HTTP probes.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "Time to wait for every probe to succeed.")
+	cmd.Flags().StringVar(&o.ViaPod, "via-pod", o.ViaPod, "Run the probes from inside this pod instead of from the client.")
+	cmd.Flags().StringVarP(&o.Container, "container", "c", o.Container, "Container of --via-pod to run the probes in. Defaults to the first container.")
+
+	return cmd
+}
+
+func (o *CheckEndpointsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "a single service or route is required")
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	o.Resource, o.Name, err = osutil.ResolveResource(corev1.Resource("services"), args[0], mapper)
+	if err != nil {
+		return err
+	}
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Config, err = f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+	o.RouteClient, err = routev1client.NewForConfig(o.Config)
+	if err != nil {
+		return err
+	}
+
+	if o.Probe == nil {
+		if len(o.ViaPod) > 0 {
+			o.Probe = o.probeFromPod
+		} else {
+			o.Probe = o.probeFromClient
+		}
+	}
+	return nil
+}
+
+func (o *CheckEndpointsOptions) Validate() error {
+	switch o.Resource {
+	case corev1.Resource("services"), schema.GroupResource{Group: "route.openshift.io", Resource: "routes"}:
+	default:
+		return fmt.Errorf("only services and routes are supported, got %s", o.Resource)
+	}
+	switch o.ProbeType {
+	case probeNone, probeTCP, probeHTTP:
+	default:
+		return fmt.Errorf("--probe must be either 'tcp' or 'http'")
+	}
+	if len(o.ViaPod) > 0 && o.ProbeType == probeNone {
+		return fmt.Errorf("--via-pod requires --probe")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be a positive duration")
+	}
+	return nil
+}
+
+func (o *CheckEndpointsOptions) Run() error {
+	ctx := context.TODO()
+
+	service, port := o.Name, o.Port
+	if o.Resource.Resource == "routes" {
+		route, err := o.RouteClient.Routes(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if route.Spec.To.Kind != "Service" {
+			return fmt.Errorf("route %s does not point to a service", o.Name)
+		}
+		service = route.Spec.To.Name
+		if len(port) == 0 && route.Spec.Port != nil {
+			port = route.Spec.Port.TargetPort.String()
+		}
+	}
+
+	endpoints, err := o.KubeClient.CoreV1().Endpoints(o.Namespace).Get(ctx, service, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	results := collectEndpoints(endpoints, port)
+	if len(results) == 0 {
+		return fmt.Errorf("service %s has no endpoints matching the port %q", service, port)
+	}
+
+	failed := 0
+	for i := range results {
+		if o.ProbeType == probeNone {
+			continue
+		}
+		if err := o.Probe(ctx, results[i].address()); err != nil {
+			results[i].result = err.Error()
+			failed++
+			continue
+		}
+		results[i].result = "ok"
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "POD\tADDRESS\tREADY\tRESULT")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", r.pod, r.address(), r.ready, r.result)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d endpoints of service %s are not reachable", failed, len(results), service)
+	}
+	return nil
+}
+
+type endpointResult struct {
+	pod    string
+	ip     string
+	port   int32
+	ready  bool
+	result string
+}
+
+func (r endpointResult) address() string {
+	return net.JoinHostPort(r.ip, strconv.Itoa(int(r.port)))
+}
+
+// collectEndpoints lists every address and port of endpoints, keeping only
+// the ports whose name or number is port when it is set.
+func collectEndpoints(endpoints *corev1.Endpoints, port string) []endpointResult {
+	var results []endpointResult
+	for _, subset := range endpoints.Subsets {
+		for _, p := range subset.Ports {
+			if len(port) > 0 && !matchesPort(p, port) {
+				continue
+			}
+			for _, addr := range subset.Addresses {
+				results = append(results, endpointResult{pod: podName(addr), ip: addr.IP, port: p.Port, ready: true, result: "-"})
+			}
+			for _, addr := range subset.NotReadyAddresses {
+				results = append(results, endpointResult{pod: podName(addr), ip: addr.IP, port: p.Port, ready: false, result: "-"})
+			}
+		}
+	}
+	return results
+}
+
+func matchesPort(p corev1.EndpointPort, port string) bool {
+	target := intstr.Parse(port)
+	if target.Type == intstr.Int {
+		return p.Port == target.IntVal
+	}
+	return p.Name == port
+}
+
+func podName(addr corev1.EndpointAddress) string {
+	if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+		return addr.TargetRef.Name
+	}
+	return "<none>"
+}
+
+func (o *CheckEndpointsOptions) probeFromClient(ctx context.Context, address string) error {
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	if o.ProbeType == probeTCP {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.probeURL(address), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return nil
+}
+
+// probeFromPod runs the probe inside ViaPod using a remote exec.
+func (o *CheckEndpointsOptions) probeFromPod(ctx context.Context, address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	seconds := strconv.Itoa(int(o.Timeout.Seconds() + 0.5))
+
+	var command []string
+	if o.ProbeType == probeTCP {
+		command = []string{"timeout", seconds, "bash", "-c", `exec 3<>"/dev/tcp/$0/$1"`, host, port}
+	} else {
+		command = []string{"curl", "-sSf", "-o", "/dev/null", "--max-time", seconds, o.probeURL(address)}
+	}
+
+	errOut := &bytes.Buffer{}
+	execOptions := &kexec.ExecOptions{
+		StreamOptions: kexec.StreamOptions{
+			Namespace:     o.Namespace,
+			PodName:       o.ViaPod,
+			ContainerName: o.Container,
+			Quiet:         true,
+			IOStreams:     genericiooptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut},
+		},
+		Executor:  &kexec.DefaultRemoteExecutor{},
+		PodClient: o.KubeClient.CoreV1(),
+		Config:    o.Config,
+		Command:   command,
+	}
+	if err := execOptions.Validate(); err != nil {
+		return err
+	}
+	if err := execOptions.Run(); err != nil {
+		if msg := strings.TrimSpace(errOut.String()); len(msg) > 0 {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+func (o *CheckEndpointsOptions) probeURL(address string) string {
+	path := o.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("http://%s%s", address, path)
+}
@@ -0,0 +1,110 @@
+package checkendpoints
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+
+	routev1 "github.com/openshift/api/route/v1"
+	fakerouteclient "github.com/openshift/client-go/route/clientset/versioned/fake"
+)
+
+func testEndpoints() *corev1.Endpoints {
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "test"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "frontend-1"}},
+			},
+			NotReadyAddresses: []corev1.EndpointAddress{
+				{IP: "10.0.0.2", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "frontend-2"}},
+			},
+			Ports: []corev1.EndpointPort{{Name: "http", Port: 8080}, {Name: "metrics", Port: 9090}},
+		}},
+	}
+}
+
+func TestCollectEndpoints(t *testing.T) {
+	tests := []struct {
+		port     string
+		expected []string
+	}{
+		{port: "", expected: []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.1:9090", "10.0.0.2:9090"}},
+		{port: "metrics", expected: []string{"10.0.0.1:9090", "10.0.0.2:9090"}},
+		{port: "8080", expected: []string{"10.0.0.1:8080", "10.0.0.2:8080"}},
+		{port: "unknown", expected: nil},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, r := range collectEndpoints(testEndpoints(), test.port) {
+			got = append(got, r.address())
+		}
+		if strings.Join(got, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("port %q: expected %v, got %v", test.port, test.expected, got)
+		}
+	}
+}
+
+func TestRunRoute(t *testing.T) {
+	route := &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "www", Namespace: "test"},
+		Spec: routev1.RouteSpec{
+			To:   routev1.RouteTargetReference{Kind: "Service", Name: "frontend"},
+			Port: &routev1.RoutePort{TargetPort: intstr.FromString("http")},
+		},
+	}
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewCheckEndpointsOptions(streams)
+	o.Resource = schema.GroupResource{Group: "route.openshift.io", Resource: "routes"}
+	o.Name = "www"
+	o.Namespace = "test"
+	o.ProbeType = probeTCP
+	o.KubeClient = fakekubeclient.NewSimpleClientset(testEndpoints())
+	o.RouteClient = fakerouteclient.NewSimpleClientset(route).RouteV1()
+	probed := []string{}
+	o.Probe = func(_ context.Context, address string) error {
+		probed = append(probed, address)
+		if address == "10.0.0.2:8080" {
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	err := o.Run()
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 endpoints of service frontend are not reachable") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if strings.Join(probed, ",") != "10.0.0.1:8080,10.0.0.2:8080" {
+		t.Errorf("unexpected probes: %v", probed)
+	}
+	for _, expected := range []string{"frontend-1  10.0.0.1:8080  true   ok", "frontend-2  10.0.0.2:8080  false  connection refused"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	o := NewCheckEndpointsOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.Resource = corev1.Resource("pods")
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected pods to be rejected")
+	}
+	o.Resource = corev1.Resource("services")
+	o.ViaPod = "debug"
+	if err := o.Validate(); err == nil {
+		t.Errorf("expected --via-pod without --probe to be rejected")
+	}
+}
@@ -0,0 +1,296 @@
+package cleanupfailedbuilds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"github.com/openshift/oc/pkg/client/paging"
+	buildhelpers "github.com/openshift/oc/pkg/helpers/build"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+// DisabledTriggersAnnotation holds the triggers removed from a build config
+// by cleanup-failed-builds, so that they can be restored.
+const DisabledTriggersAnnotation = "oc.openshift.io/disabled-triggers"
+
+var (
+	cleanupFailedBuildsLong = templates.LongDesc(`
+		Find the build configs whose latest builds keep failing.
+
+		A build config is reported when its last --failures finished builds failed. Cancelled
+		builds are ignored. With --disable-triggers, the image change and config change
+		triggers of the reported build configs are removed so that they stop consuming build
+		capacity; webhook triggers are kept so that pushing a fix still starts a build. The
+		removed triggers are saved in the oc.openshift.io/disabled-triggers annotation.
+
+		With --webhook-url, the report is also posted as JSON to that URL when build configs
+		are reported. It is printed the same way with -o json.
+	`)
+
+	cleanupFailedBuildsExample = templates.Examples(`
+		# Report the build configs of the current namespace whose last 3 builds failed
+		oc ex cleanup-failed-builds
+
+		# Disable the triggers of the build configs of all namespaces whose last 5 builds failed
+		oc ex cleanup-failed-builds -A --failures=5 --disable-triggers --webhook-url=https://chat.example.com/hooks/builds
+	`)
+)
+
+// Report lists the build configs whose latest builds keep failing.
+type Report struct {
+	FailingBuildConfigs []FailingBuildConfig `json:"failingBuildConfigs"`
+}
+
+// FailingBuildConfig is a build config whose latest builds failed.
+type FailingBuildConfig struct {
+	Namespace           string `json:"namespace"`
+	Name                string `json:"name"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	// LastBuild is the name of the latest failed build.
+	LastBuild        string `json:"lastBuild"`
+	Reason           string `json:"reason,omitempty"`
+	TriggersDisabled bool   `json:"triggersDisabled"`
+}
+
+// CleanupFailedBuildsOptions contains all the options needed to find failing build configs
+type CleanupFailedBuildsOptions struct {
+	Namespace       string
+	AllNamespaces   bool
+	Failures        int
+	DisableTriggers bool
+	WebhookURL      string
+	ChunkSize       int64
+
+	PrintFlags *osutil.ReportPrintFlags
+
+	BuildClient buildv1client.BuildV1Interface
+	HTTPClient  *http.Client
+
+	genericiooptions.IOStreams
+}
+
+func NewCleanupFailedBuildsOptions(streams genericiooptions.IOStreams) *CleanupFailedBuildsOptions {
+	return &CleanupFailedBuildsOptions{
+		Failures:   3,
+		ChunkSize:  paging.DefaultChunkSize,
+		PrintFlags: osutil.NewReportPrintFlags(),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdCleanupFailedBuilds implements the OpenShift experimental cleanup-failed-builds command
+func NewCmdCleanupFailedBuilds(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewCleanupFailedBuildsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "cleanup-failed-builds",
+		Short:   "Find and disable the build configs whose builds keep failing",
+		Long:    cleanupFailedBuildsLong,
+		Example: cleanupFailedBuildsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, check the build configs of all namespaces.")
+	cmd.Flags().IntVar(&o.Failures, "failures", o.Failures, "Number of consecutive failed builds a build config is reported after.")
+	cmd.Flags().BoolVar(&o.DisableTriggers, "disable-triggers", o.DisableTriggers, "If true, remove the image change and config change triggers of the reported build configs.")
+	cmd.Flags().StringVar(&o.WebhookURL, "webhook-url", o.WebhookURL, "If set, post the report as JSON to this URL when build configs are reported.")
+	kcmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *CleanupFailedBuildsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.BuildClient, err = buildv1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *CleanupFailedBuildsOptions) Validate() error {
+	if o.Failures < 1 {
+		return fmt.Errorf("--failures must be greater than 0")
+	}
+	if len(o.WebhookURL) > 0 {
+		if u, err := url.Parse(o.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("--webhook-url must be an http or https URL")
+		}
+	}
+	return o.PrintFlags.Validate()
+}
+
+func (o *CleanupFailedBuildsOptions) Run() error {
+	ctx := context.TODO()
+	client := paging.BuildV1(o.BuildClient, o.ChunkSize)
+	buildConfigs, err := client.BuildConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	builds, err := client.Builds(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	report := Report{FailingBuildConfigs: []FailingBuildConfig{}}
+	for i := range buildConfigs.Items {
+		bc := &buildConfigs.Items[i]
+		configBuilds := buildhelpers.FilterBuilds(builds.Items, func(arg interface{}) bool {
+			return arg.(buildv1.Build).Namespace == bc.Namespace && buildhelpers.ByBuildConfigPredicate(bc.Name)(arg)
+		})
+		failing, ok := consecutiveFailures(bc, configBuilds)
+		if !ok || failing.ConsecutiveFailures < o.Failures {
+			continue
+		}
+		if o.DisableTriggers {
+			if failing.TriggersDisabled, err = o.disableTriggers(ctx, bc); err != nil {
+				return err
+			}
+		}
+		report.FailingBuildConfigs = append(report.FailingBuildConfigs, failing)
+	}
+
+	if o.PrintFlags.Structured() {
+		if err := o.PrintFlags.PrintStructured(o.Out, report); err != nil {
+			return err
+		}
+	} else {
+		printReport(o.Out, o.PrintFlags, report, o.AllNamespaces)
+	}
+
+	if len(o.WebhookURL) == 0 || len(report.FailingBuildConfigs) == 0 {
+		return nil
+	}
+	return o.notify(report)
+}
+
+// consecutiveFailures returns how many of the latest finished builds of bc
+// failed in a row. It returns false if bc has no failed build.
+func consecutiveFailures(bc *buildv1.BuildConfig, builds []buildv1.Build) (FailingBuildConfig, bool) {
+	sort.Sort(sort.Reverse(buildhelpers.BuildSliceByCreationTimestamp(builds)))
+
+	failing := FailingBuildConfig{Namespace: bc.Namespace, Name: bc.Name}
+	for _, build := range builds {
+		phase := build.Status.Phase
+		if !buildhelpers.IsTerminalPhase(phase) || phase == buildv1.BuildPhaseCancelled {
+			continue
+		}
+		if phase != buildv1.BuildPhaseFailed && phase != buildv1.BuildPhaseError {
+			break
+		}
+		if failing.ConsecutiveFailures == 0 {
+			failing.LastBuild = build.Name
+			failing.Reason = string(build.Status.Reason)
+		}
+		failing.ConsecutiveFailures++
+	}
+	return failing, failing.ConsecutiveFailures > 0
+}
+
+// disableTriggers removes the image change and config change triggers of bc,
+// saving them in an annotation. It returns false if bc has none.
+func (o *CleanupFailedBuildsOptions) disableTriggers(ctx context.Context, bc *buildv1.BuildConfig) (bool, error) {
+	kept, disabled := []buildv1.BuildTriggerPolicy{}, []buildv1.BuildTriggerPolicy{}
+	for _, trigger := range bc.Spec.Triggers {
+		switch trigger.Type {
+		case buildv1.ImageChangeBuildTriggerType, buildv1.ConfigChangeBuildTriggerType:
+			disabled = append(disabled, trigger)
+		default:
+			kept = append(kept, trigger)
+		}
+	}
+	if len(disabled) == 0 {
+		return false, nil
+	}
+
+	value, err := json.Marshal(disabled)
+	if err != nil {
+		return false, err
+	}
+	if bc.Annotations == nil {
+		bc.Annotations = map[string]string{}
+	}
+	bc.Annotations[DisabledTriggersAnnotation] = string(value)
+	bc.Spec.Triggers = kept
+	if _, err := o.BuildClient.BuildConfigs(bc.Namespace).Update(ctx, bc, metav1.UpdateOptions{}); err != nil {
+		return false, fmt.Errorf("unable to disable the triggers of build config %q in %q: %v", bc.Name, bc.Namespace, err)
+	}
+	return true, nil
+}
+
+func (o *CleanupFailedBuildsOptions) notify(report Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	resp, err := o.HTTPClient.Post(o.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to post the report: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unable to post the report: %s", resp.Status)
+	}
+	return nil
+}
+
+func printReport(out io.Writer, printFlags *osutil.ReportPrintFlags, report Report, allNamespaces bool) {
+	if len(report.FailingBuildConfigs) == 0 {
+		fmt.Fprintln(out, "No build configs are failing.")
+		return
+	}
+	headers := []string{"BUILD CONFIG", "FAILURES", "LAST BUILD", "REASON", "TRIGGERS"}
+	if allNamespaces {
+		headers = append([]string{"NAMESPACE"}, headers...)
+	}
+	w := printFlags.NewTable(out, headers...)
+	defer w.Flush()
+	for _, failing := range report.FailingBuildConfigs {
+		if allNamespaces {
+			fmt.Fprintf(w, "%s\t", failing.Namespace)
+		}
+		triggers := "-"
+		if failing.TriggersDisabled {
+			triggers = "disabled"
+		}
+		reason := failing.Reason
+		if len(reason) == 0 {
+			reason = "<unknown>"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", printFlags.Name("buildconfig", failing.Name), failing.ConsecutiveFailures, failing.LastBuild, reason, triggers)
+	}
+}
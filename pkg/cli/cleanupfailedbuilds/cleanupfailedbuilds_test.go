@@ -0,0 +1,128 @@
+package cleanupfailedbuilds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+)
+
+func newBuildConfig(name string, triggers ...buildv1.BuildTriggerType) *buildv1.BuildConfig {
+	bc := &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name}}
+	for _, trigger := range triggers {
+		bc.Spec.Triggers = append(bc.Spec.Triggers, buildv1.BuildTriggerPolicy{Type: trigger})
+	}
+	return bc
+}
+
+func newBuild(config string, number int, phase buildv1.BuildPhase) *buildv1.Build {
+	return &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "test",
+			Name:              fmt.Sprintf("%s-%d", config, number),
+			Annotations:       map[string]string{buildv1.BuildConfigAnnotation: config},
+			CreationTimestamp: metav1.NewTime(time.Date(2024, 1, 1, number, 0, 0, 0, time.UTC)),
+		},
+		Status: buildv1.BuildStatus{Phase: phase, Reason: "DockerBuildFailed"},
+	}
+}
+
+func TestRun(t *testing.T) {
+	objs := []runtime.Object{
+		newBuildConfig("broken", buildv1.ImageChangeBuildTriggerType, buildv1.ConfigChangeBuildTriggerType, buildv1.GitHubWebHookBuildTriggerType),
+		newBuild("broken", 1, buildv1.BuildPhaseComplete),
+		newBuild("broken", 2, buildv1.BuildPhaseFailed),
+		newBuild("broken", 3, buildv1.BuildPhaseError),
+		newBuild("broken", 4, buildv1.BuildPhaseCancelled),
+		newBuild("broken", 5, buildv1.BuildPhaseFailed),
+		newBuild("broken", 6, buildv1.BuildPhaseRunning),
+		// recovered after failing
+		newBuildConfig("flaky", buildv1.ImageChangeBuildTriggerType),
+		newBuild("flaky", 1, buildv1.BuildPhaseFailed),
+		newBuild("flaky", 2, buildv1.BuildPhaseFailed),
+		newBuild("flaky", 3, buildv1.BuildPhaseFailed),
+		newBuild("flaky", 4, buildv1.BuildPhaseComplete),
+	}
+	fakeClient := buildfake.NewSimpleClientset(objs...)
+
+	var posted Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &posted); err != nil {
+			t.Errorf("invalid report posted: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	out := &bytes.Buffer{}
+	o := NewCleanupFailedBuildsOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "test"
+	o.DisableTriggers = true
+	o.WebhookURL = server.URL
+	o.BuildClient = fakeClient.BuildV1()
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Report{FailingBuildConfigs: []FailingBuildConfig{{
+		Namespace:           "test",
+		Name:                "broken",
+		ConsecutiveFailures: 3,
+		LastBuild:           "broken-5",
+		Reason:              "DockerBuildFailed",
+		TriggersDisabled:    true,
+	}}}
+	if !reflect.DeepEqual(posted, expected) {
+		t.Errorf("expected the report %#v to be posted, got %#v", expected, posted)
+	}
+	if !strings.Contains(out.String(), "broken") || strings.Contains(out.String(), "flaky") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	bc, err := fakeClient.BuildV1().BuildConfigs("test").Get(context.TODO(), "broken", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bc.Spec.Triggers) != 1 || bc.Spec.Triggers[0].Type != buildv1.GitHubWebHookBuildTriggerType {
+		t.Errorf("expected only the webhook trigger to be kept, got %#v", bc.Spec.Triggers)
+	}
+	disabled := []buildv1.BuildTriggerPolicy{}
+	if err := json.Unmarshal([]byte(bc.Annotations[DisabledTriggersAnnotation]), &disabled); err != nil {
+		t.Fatal(err)
+	}
+	if len(disabled) != 2 {
+		t.Errorf("expected the disabled triggers to be saved, got %#v", disabled)
+	}
+}
+
+func TestRunNothingFailing(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := NewCleanupFailedBuildsOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "test"
+	// never reached: nothing to report
+	o.WebhookURL = "http://127.0.0.1:0"
+	o.BuildClient = buildfake.NewSimpleClientset(newBuildConfig("app"), newBuild("app", 1, buildv1.BuildPhaseComplete)).BuildV1()
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No build configs are failing.\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
@@ -21,15 +21,25 @@ import (
 	kterm "k8s.io/kubectl/pkg/util/term"
 
 	"github.com/openshift/oc/pkg/cli/admin"
+	"github.com/openshift/oc/pkg/cli/alias"
+	"github.com/openshift/oc/pkg/cli/analyzetriggers"
 	"github.com/openshift/oc/pkg/cli/cancelbuild"
+	"github.com/openshift/oc/pkg/cli/checkdrift"
+	"github.com/openshift/oc/pkg/cli/checkendpoints"
+	"github.com/openshift/oc/pkg/cli/cleanupfailedbuilds"
 	"github.com/openshift/oc/pkg/cli/debug"
 	"github.com/openshift/oc/pkg/cli/deployer"
+	"github.com/openshift/oc/pkg/cli/deployreport"
 	"github.com/openshift/oc/pkg/cli/expose"
 	"github.com/openshift/oc/pkg/cli/extract"
+	"github.com/openshift/oc/pkg/cli/gc"
 	"github.com/openshift/oc/pkg/cli/idle"
 	"github.com/openshift/oc/pkg/cli/image"
+	"github.com/openshift/oc/pkg/cli/imagemirror"
 	"github.com/openshift/oc/pkg/cli/importimage"
+	"github.com/openshift/oc/pkg/cli/importregistry"
 	"github.com/openshift/oc/pkg/cli/kubectlwrappers"
+	"github.com/openshift/oc/pkg/cli/layergraph"
 	"github.com/openshift/oc/pkg/cli/login"
 	"github.com/openshift/oc/pkg/cli/logout"
 	"github.com/openshift/oc/pkg/cli/logs"
@@ -44,18 +54,28 @@ import (
 	"github.com/openshift/oc/pkg/cli/recycle"
 	"github.com/openshift/oc/pkg/cli/registry"
 	"github.com/openshift/oc/pkg/cli/requestproject"
+	"github.com/openshift/oc/pkg/cli/retargetoutput"
 	"github.com/openshift/oc/pkg/cli/rollback"
 	"github.com/openshift/oc/pkg/cli/rollout"
+	"github.com/openshift/oc/pkg/cli/routegraph"
 	"github.com/openshift/oc/pkg/cli/rsh"
 	"github.com/openshift/oc/pkg/cli/rsync"
 	"github.com/openshift/oc/pkg/cli/secrets"
 	"github.com/openshift/oc/pkg/cli/serviceaccounts"
 	"github.com/openshift/oc/pkg/cli/set"
+	"github.com/openshift/oc/pkg/cli/signimage"
+	"github.com/openshift/oc/pkg/cli/simulateupdate"
 	"github.com/openshift/oc/pkg/cli/startbuild"
 	"github.com/openshift/oc/pkg/cli/status"
+	"github.com/openshift/oc/pkg/cli/supportbundle"
 	"github.com/openshift/oc/pkg/cli/tag"
+	"github.com/openshift/oc/pkg/cli/tokens"
+	"github.com/openshift/oc/pkg/cli/triggers"
+	"github.com/openshift/oc/pkg/cli/verifyregistry"
 	"github.com/openshift/oc/pkg/cli/version"
 	"github.com/openshift/oc/pkg/cli/whoami"
+	"github.com/openshift/oc/pkg/helpers/cliconfig"
+	"github.com/openshift/oc/pkg/helpers/kubeconfig"
 )
 
 const productName = `OpenShift`
@@ -104,6 +124,19 @@ func defaultConfigFlags() *genericclioptions.ConfigFlags {
 func NewDefaultOcCommand(o kubecmd.KubectlOptions) *cobra.Command {
 	cmd := NewOcCommand(o)
 
+	// aliases are expanded once the global flags are known, so that their
+	// values aren't taken for an alias
+	AddLogFlags(cmd.PersistentFlags())
+	if aliases, err := alias.Load(alias.DefaultPath()); err != nil {
+		fmt.Fprintf(o.IOStreams.ErrOut, "warning: ignoring aliases: %v\n", err)
+	} else if expanded, ok, err := alias.Expand(cmd, o.Arguments, aliases); err != nil {
+		fmt.Fprintf(o.IOStreams.ErrOut, "error: %v\n", err)
+		os.Exit(1)
+	} else if ok {
+		o.Arguments = expanded
+		cmd.SetArgs(expanded[1:])
+	}
+
 	if o.PluginHandler == nil {
 		return cmd
 	}
@@ -188,6 +221,14 @@ func NewOcCommand(o kubecmd.KubectlOptions) *cobra.Command {
 				plugin.SetupPluginCompletion(cmd, args)
 			}
 
+			if config, err := cliconfig.Load(cliconfig.DefaultPath()); err != nil {
+				fmt.Fprintf(o.IOStreams.ErrOut, "warning: ignoring config: %v\n", err)
+			} else if cmd.Name() != cobra.ShellCompRequestCmd && cmd.Name() != cobra.ShellCompNoDescRequestCmd {
+				if err := config.ApplyDefaults(cmd, os.Getenv); err != nil {
+					return err
+				}
+			}
+
 			return initProfiling()
 		},
 		PersistentPostRunE: func(*cobra.Command, []string) error {
@@ -212,6 +253,7 @@ func NewOcCommand(o kubecmd.KubectlOptions) *cobra.Command {
 	flags := cmds.PersistentFlags()
 
 	addProfilingFlags(flags)
+	addDebugHTTPFlags(flags)
 
 	flags.BoolVar(&warningsAsErrors, "warnings-as-errors", warningsAsErrors, "Treat warnings received from the server as errors and exit with a non-zero exit code")
 
@@ -219,8 +261,9 @@ func NewOcCommand(o kubecmd.KubectlOptions) *cobra.Command {
 	if kubeConfigFlags == nil {
 		kubeConfigFlags = defaultConfigFlags()
 	}
+	kubeConfigFlags.WrapConfigFn = wrapDebugHTTP(o.IOStreams.ErrOut, kubeConfigFlags.WrapConfigFn)
 	kubeConfigFlags.AddFlags(flags)
-	matchVersionKubeConfigFlags := kcmdutil.NewMatchVersionFlags(kubeConfigFlags)
+	matchVersionKubeConfigFlags := kcmdutil.NewMatchVersionFlags(kubeconfig.NewContextClientGetter(kubeConfigFlags))
 	matchVersionKubeConfigFlags.AddFlags(cmds.PersistentFlags())
 	cmds.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	f := kcmdutil.NewFactory(matchVersionKubeConfigFlags)
@@ -316,6 +359,7 @@ func NewOcCommand(o kubecmd.KubectlOptions) *cobra.Command {
 				logout.NewCmdLogout(f, o.IOStreams),
 				kubectlwrappers.NewCmdConfig(f, o.IOStreams),
 				whoami.NewCmdWhoAmI(f, o.IOStreams),
+				alias.NewCmdAlias(o.IOStreams),
 				kubectlwrappers.NewCmdCompletion(o.IOStreams),
 			},
 		},
@@ -381,8 +425,26 @@ func newExperimentalCommand(f kcmdutil.Factory, ioStreams genericiooptions.IOStr
 		},
 	}
 
-	// remove this line, when adding experimental commands
-	experimental.Hidden = true
+	experimental.AddCommand(
+		importregistry.NewCmdImportRegistry(f, ioStreams),
+		checkendpoints.NewCmdCheckEndpoints(f, ioStreams),
+		deployreport.NewCmdDeployReport(f, ioStreams),
+		analyzetriggers.NewCmdAnalyzeTriggers(f, ioStreams),
+		layergraph.NewCmdLayerGraph(f, ioStreams),
+		supportbundle.NewCmdSupportBundle(f, ioStreams),
+		signimage.NewCmdSignImage(f, ioStreams),
+		cleanupfailedbuilds.NewCmdCleanupFailedBuilds(f, ioStreams),
+		triggers.NewCmdTriggers(f, ioStreams),
+		simulateupdate.NewCmdSimulateUpdate(f, ioStreams),
+		checkdrift.NewCmdCheckDrift(f, ioStreams),
+		gc.NewCmdGC(f, ioStreams),
+		routegraph.NewCmdRouteGraph(f, ioStreams),
+		set.NewCmdExperimentalSet(f, ioStreams),
+		tokens.NewCmdTokens(f, ioStreams),
+		verifyregistry.NewCmdVerifyRegistry(f, ioStreams),
+		imagemirror.NewCmdImageMirror(f, ioStreams),
+		retargetoutput.NewCmdRetargetOutput(f, ioStreams),
+	)
 
 	return experimental
 }
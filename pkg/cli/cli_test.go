@@ -28,6 +28,8 @@ import (
 	"k8s.io/kubectl/pkg/cmd/plugin"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	"github.com/openshift/oc/pkg/cli/alias"
 )
 
 func TestOCSubcommandShadowPlugin(t *testing.T) {
@@ -293,6 +295,34 @@ func TestOCCommandHandlesPlugins(t *testing.T) {
 	}
 }
 
+func TestOCCommandExpandsAliases(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	if err := (alias.Aliases{"clusters": "config get-clusters"}).Save(alias.DefaultPath()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the value of --loglevel, added to oc along with the other logging
+	// flags, must not be taken for the alias
+	args := []string{"oc", "--loglevel", "0", "clusters"}
+	shimKubectlForOc()
+	pluginsHandler := &testPluginHandler{
+		pluginsDirectory: "./../../testdata/plugin",
+		validPrefixes:    plugin.ValidPluginFilenamePrefixes,
+	}
+	root := NewDefaultOcCommand(cmd.KubectlOptions{PluginHandler: pluginsHandler, Arguments: args, IOStreams: NewTestIOStreamsDiscard()})
+	if pluginsHandler.lookedup || pluginsHandler.executed {
+		t.Fatalf("unexpected plugin lookup: %v", pluginsHandler.lookupErr)
+	}
+	executed, err := root.ExecuteC()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if executed.CommandPath() != "oc config get-clusters" {
+		t.Errorf("expected the alias to run %q, got %q", "oc config get-clusters", executed.CommandPath())
+	}
+}
+
 type testPluginHandler struct {
 	pluginsDirectory string
 	validPrefixes    []string
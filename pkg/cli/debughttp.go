@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/client-go/rest"
+
+	"github.com/openshift/oc/pkg/helpers/debughttp"
+)
+
+var debugHTTP debughttp.Level
+
+// debugHTTPValue is the value of --debug-http, rejecting unknown levels when
+// the flag is parsed.
+type debugHTTPValue struct {
+	level *debughttp.Level
+}
+
+func (v debugHTTPValue) String() string {
+	return string(*v.level)
+}
+
+func (v debugHTTPValue) Set(value string) error {
+	level, err := debughttp.ParseLevel(value)
+	if err != nil {
+		return err
+	}
+	*v.level = level
+	return nil
+}
+
+func (v debugHTTPValue) Type() string {
+	return "level"
+}
+
+func addDebugHTTPFlags(flags *pflag.FlagSet) {
+	flags.Var(debugHTTPValue{level: &debugHTTP}, "debug-http", "If set, dump every API request and response to stderr with credentials redacted. One of (headers|body), headers when no level is given")
+	flags.Lookup("debug-http").NoOptDefVal = string(debughttp.LevelHeaders)
+}
+
+// wrapDebugHTTP returns a config wrapper that installs the --debug-http
+// round tripper on top of the provided one, if any.
+func wrapDebugHTTP(out io.Writer, wrapped func(*rest.Config) *rest.Config) func(*rest.Config) *rest.Config {
+	return func(config *rest.Config) *rest.Config {
+		if wrapped != nil {
+			config = wrapped(config)
+		}
+		if debugHTTP == debughttp.LevelNone {
+			return config
+		}
+		level := debugHTTP
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return debughttp.NewRoundTripper(rt, level, out)
+		})
+		return config
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/oc/pkg/helpers/debughttp"
+)
+
+func TestDebugHTTPFlag(t *testing.T) {
+	defer func() { debugHTTP = "" }()
+
+	tests := []struct {
+		args     []string
+		expected debughttp.Level
+		rest     []string
+		err      bool
+	}{
+		{args: []string{"--debug-http=body", "get", "pods"}, expected: debughttp.LevelBody, rest: []string{"get", "pods"}},
+		{args: []string{"get", "pods", "--debug-http"}, expected: debughttp.LevelHeaders, rest: []string{"get", "pods"}},
+		// the value is optional so that it's never taken from the arguments
+		{args: []string{"--debug-http", "body"}, expected: debughttp.LevelHeaders, rest: []string{"body"}},
+		{args: []string{"--debug-http=bodies", "get", "pods"}, err: true},
+	}
+	for _, test := range tests {
+		debugHTTP = ""
+		flags := pflag.NewFlagSet("oc", pflag.ContinueOnError)
+		addDebugHTTPFlags(flags)
+		err := flags.Parse(test.args)
+		if test.err {
+			if err == nil {
+				t.Errorf("%v: expected an error", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", test.args, err)
+			continue
+		}
+		if debugHTTP != test.expected {
+			t.Errorf("%v: expected %q, got %q", test.args, test.expected, debugHTTP)
+		}
+		if args := flags.Args(); len(args) != len(test.rest) || args[0] != test.rest[0] {
+			t.Errorf("%v: expected the arguments %v, got %v", test.args, test.rest, args)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package deployreport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/api/apps"
+	appsv1 "github.com/openshift/api/apps/v1"
+	"github.com/openshift/library-go/pkg/apps/appsutil"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	deployReportLong = templates.LongDesc(`
+		Report on the latest deployments of a deployment config.
+
+		For each of the latest deployments the status, the reason of that status, the
+		time the deployment took and the outcome of its deployer pod are shown, followed
+		by the end of the deployer pod logs. This gathers in one place what is needed to
+		understand a failed rollout.
+	`)
+
+	deployReportExample = templates.Examples(`
+		# Report on the last 3 deployments of the 'frontend' deployment config
+		oc ex deploy-report dc/frontend
+
+		# Report on the last deployment only, with the last 100 lines of the deployer logs
+		oc ex deploy-report dc/frontend --last=1 --tail=100
+	`)
+)
+
+// DeployReportOptions contains all the options needed to report on deployments
+type DeployReportOptions struct {
+	Name      string
+	Namespace string
+	Last      int
+	Tail      int64
+
+	KubeClient kubernetes.Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewDeployReportOptions(streams genericiooptions.IOStreams) *DeployReportOptions {
+	return &DeployReportOptions{
+		Last:      3,
+		Tail:      20,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdDeployReport implements the OpenShift experimental deploy-report command
+func NewCmdDeployReport(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewDeployReportOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "deploy-report dc/NAME",
+		Short:   "Report on the latest deployments of a deployment config",
+		Long:    deployReportLong,
+		Example: deployReportExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().IntVar(&o.Last, "last", o.Last, "Number of deployments to report on, starting from the latest one.")
+	cmd.Flags().Int64Var(&o.Tail, "tail", o.Tail, "Lines of the deployer logs to show for each deployment, -1 shows all of them.")
+
+	return cmd
+}
+
+func (o *DeployReportOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "a deployment config is required")
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	resource, name, err := osutil.ResolveResource(apps.Resource("deploymentconfigs"), args[0], mapper)
+	if err != nil {
+		return err
+	}
+	if resource != apps.Resource("deploymentconfigs") {
+		return fmt.Errorf("only deployment configs are supported, got %s", resource)
+	}
+	o.Name = name
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *DeployReportOptions) Validate() error {
+	if len(o.Name) == 0 {
+		return fmt.Errorf("a deployment config name is required")
+	}
+	if o.Last < 1 {
+		return fmt.Errorf("--last must be greater than 0")
+	}
+	if o.Tail < -1 {
+		return fmt.Errorf("--tail must be -1 or greater")
+	}
+	return nil
+}
+
+func (o *DeployReportOptions) Run() error {
+	ctx := context.TODO()
+
+	list, err := o.KubeClient.CoreV1().ReplicationControllers(o.Namespace).List(ctx, metav1.ListOptions{LabelSelector: appsutil.ConfigSelector(o.Name).String()})
+	if err != nil {
+		return err
+	}
+	if len(list.Items) == 0 {
+		return fmt.Errorf("no deployments found for deploymentconfig %q", o.Name)
+	}
+
+	deployments := make([]*corev1.ReplicationController, 0, len(list.Items))
+	for i := range list.Items {
+		deployments = append(deployments, &list.Items[i])
+	}
+	sort.Sort(appsutil.ByLatestVersionDesc(deployments))
+	if len(deployments) > o.Last {
+		deployments = deployments[:o.Last]
+	}
+
+	for i, rc := range deployments {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		if err := o.report(ctx, rc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// report prints the report of a single deployment.
+func (o *DeployReportOptions) report(ctx context.Context, rc *corev1.ReplicationController) error {
+	status := appsutil.DeploymentStatusFor(rc)
+	fmt.Fprintf(o.Out, "Deployment #%d (%s): %s\n", appsutil.DeploymentVersionFor(rc), rc.Name, status)
+	if reason := appsutil.DeploymentStatusReasonFor(rc); len(reason) > 0 {
+		fmt.Fprintf(o.Out, "  Reason: %s\n", reason)
+	}
+
+	podName := appsutil.DeployerPodNameFor(rc)
+	pod, err := o.KubeClient.CoreV1().Pods(o.Namespace).Get(ctx, podName, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		fmt.Fprintf(o.Out, "  Started: %s\n", rc.CreationTimestamp.Format(time.RFC1123Z))
+		fmt.Fprintf(o.Out, "  Deployer: %s (deleted)\n", podName)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	start, end, outcome := deployerOutcome(pod)
+	if start.IsZero() {
+		start = rc.CreationTimestamp.Time
+	}
+	fmt.Fprintf(o.Out, "  Started: %s\n", start.Format(time.RFC1123Z))
+	if !end.IsZero() {
+		fmt.Fprintf(o.Out, "  Duration: %s\n", duration.HumanDuration(end.Sub(start)))
+	} else if status != appsv1.DeploymentStatusComplete && status != appsv1.DeploymentStatusFailed {
+		fmt.Fprintf(o.Out, "  Duration: %s (in progress)\n", duration.HumanDuration(time.Since(start)))
+	}
+	fmt.Fprintf(o.Out, "  Deployer: %s (%s)\n", podName, outcome)
+
+	if o.Tail == 0 {
+		return nil
+	}
+	logOptions := &corev1.PodLogOptions{}
+	if o.Tail > 0 {
+		logOptions.TailLines = &o.Tail
+	}
+	logs, err := o.KubeClient.CoreV1().Pods(o.Namespace).GetLogs(podName, logOptions).DoRaw(ctx)
+	if err != nil {
+		fmt.Fprintf(o.Out, "  Logs: unavailable: %v\n", err)
+		return nil
+	}
+	fmt.Fprintln(o.Out, "  Logs:")
+	writeIndented(o.Out, "    ", string(logs))
+	return nil
+}
+
+// deployerOutcome returns when the deployer pod started and terminated, and
+// a summary of its outcome including the termination message of a failure.
+func deployerOutcome(pod *corev1.Pod) (time.Time, time.Time, string) {
+	var start, end time.Time
+	if pod.Status.StartTime != nil {
+		start = pod.Status.StartTime.Time
+	}
+	outcome := string(pod.Status.Phase)
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			continue
+		}
+		end = terminated.FinishedAt.Time
+		if terminated.ExitCode != 0 {
+			outcome = fmt.Sprintf("%s, exit code %d", outcome, terminated.ExitCode)
+			if msg := strings.TrimSpace(terminated.Message); len(msg) > 0 {
+				outcome = fmt.Sprintf("%s: %s", outcome, msg)
+			} else if len(terminated.Reason) > 0 {
+				outcome = fmt.Sprintf("%s: %s", outcome, terminated.Reason)
+			}
+		}
+	}
+	return start, end, outcome
+}
+
+func writeIndented(out io.Writer, indent, text string) {
+	text = strings.TrimRight(text, "\n")
+	if len(text) == 0 {
+		fmt.Fprintf(out, "%s<empty>\n", indent)
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(out, "%s%s\n", indent, line)
+	}
+}
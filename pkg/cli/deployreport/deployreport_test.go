@@ -0,0 +1,85 @@
+package deployreport
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+)
+
+func deployment(version, status, reason string) *corev1.ReplicationController {
+	return &corev1.ReplicationController{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "frontend-" + version,
+			Namespace: "test",
+			Labels:    map[string]string{appsv1.DeploymentConfigAnnotation: "frontend"},
+			Annotations: map[string]string{
+				appsv1.DeploymentVersionAnnotation:       version,
+				appsv1.DeploymentStatusAnnotation:        status,
+				appsv1.DeploymentStatusReasonAnnotation:  reason,
+				appsv1.DeploymentPodAnnotation:           "frontend-" + version + "-deploy",
+				appsv1.DeploymentConfigAnnotation:        "frontend",
+				appsv1.DeploymentEncodedConfigAnnotation: "",
+			},
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	start := time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC)
+	failedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-3-deploy", Namespace: "test"},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodFailed,
+			StartTime: &metav1.Time{Time: start},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   1,
+					Message:    "timed out waiting for pods to become ready",
+					FinishedAt: metav1.Time{Time: start.Add(10 * time.Minute)},
+				}},
+			}},
+		},
+	}
+	client := fakekubeclient.NewSimpleClientset(
+		deployment("1", "Complete", ""),
+		deployment("2", "Complete", "config change"),
+		deployment("3", "Failed", "image change"),
+		failedPod,
+	)
+
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	o := NewDeployReportOptions(streams)
+	o.Name = "frontend"
+	o.Namespace = "test"
+	o.Last = 2
+	o.KubeClient = client
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"Deployment #3 (frontend-3): Failed\n  Reason: image change\n",
+		"  Duration: 10m\n",
+		"  Deployer: frontend-3-deploy (Failed, exit code 1: timed out waiting for pods to become ready)\n",
+		"  Logs:\n    fake logs\n",
+		"Deployment #2 (frontend-2): Complete\n",
+		"  Deployer: frontend-2-deploy (deleted)\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "frontend-1") {
+		t.Errorf("expected only the last 2 deployments:\n%s", out.String())
+	}
+}
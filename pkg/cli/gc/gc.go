@@ -0,0 +1,28 @@
+package gc
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+)
+
+var gcLong = templates.LongDesc(`
+	Remove objects nothing uses anymore.
+
+	By default, the commands perform a dry run listing what would be removed. Add --confirm
+	to remove it.
+`)
+
+// NewCmdGC implements the OpenShift experimental gc command
+func NewCmdGC(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove objects nothing uses anymore",
+		Long:  gcLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(NewCmdGCImageStreamTags(f, streams))
+	return cmd
+}
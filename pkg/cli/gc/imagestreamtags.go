@@ -0,0 +1,377 @@
+package gc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/client/paging"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+// ProtectedAnnotation, set to "true" on an image stream or on a tag of its
+// spec, keeps gc imagestreamtags from removing its tags.
+const ProtectedAnnotation = "oc.openshift.io/gc-protected"
+
+var (
+	gcImageStreamTagsLong = templates.LongDesc(`
+		Remove the image stream tags whose image nothing used for --older-than.
+
+		The image of a tag is used by the builds taking it as input, by the pods and
+		replication controllers running it, and by the deployment configs and deployments
+		whose pod template refers to it. Tags updated within --older-than are kept, as are
+		the tags of image streams, or the spec tags, annotated with
+		oc.openshift.io/gc-protected=true.
+
+		Only the objects of the namespaces of the image streams are searched for uses of their
+		images, add --all-namespaces when images are shared between namespaces.
+
+		By default, the command performs a dry run listing the tags that would be removed. Add
+		--confirm to remove them.
+	`)
+
+	gcImageStreamTagsExample = templates.Examples(`
+		# List the image stream tags of the current namespace whose image wasn't used for 30 days
+		oc ex gc imagestreamtags --older-than=720h
+
+		# Remove the image stream tags of all namespaces whose image wasn't used for a week
+		oc ex gc imagestreamtags -A --older-than=168h --confirm
+	`)
+)
+
+// Candidate is an image stream tag whose image wasn't used for --older-than.
+type Candidate struct {
+	Namespace string `json:"namespace"`
+	// Tag is the name:tag of the image stream tag.
+	Tag   string `json:"tag"`
+	Image string `json:"image"`
+	// LastUsed is when the image was last used, zero when it never was.
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// GCImageStreamTagsOptions contains all the options needed to remove unused image stream tags
+type GCImageStreamTagsOptions struct {
+	Namespace     string
+	AllNamespaces bool
+	OlderThan     time.Duration
+	Confirm       bool
+	ChunkSize     int64
+	PrintFlags    *osutil.ReportPrintFlags
+
+	ImageClient imagev1client.ImageV1Interface
+	BuildClient buildv1client.BuildV1Interface
+	AppsClient  appsv1client.AppsV1Interface
+	KubeClient  kubernetes.Interface
+
+	// Now is the time tags are compared with, it defaults to the current time.
+	Now time.Time
+
+	genericiooptions.IOStreams
+}
+
+func NewGCImageStreamTagsOptions(streams genericiooptions.IOStreams) *GCImageStreamTagsOptions {
+	return &GCImageStreamTagsOptions{
+		OlderThan:  30 * 24 * time.Hour,
+		ChunkSize:  paging.DefaultChunkSize,
+		PrintFlags: osutil.NewReportPrintFlags(),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdGCImageStreamTags implements the OpenShift experimental gc imagestreamtags command
+func NewCmdGCImageStreamTags(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewGCImageStreamTagsOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "imagestreamtags",
+		Aliases: []string{"istag", "istags"},
+		Short:   "Remove the image stream tags whose image isn't used anymore",
+		Long:    gcImageStreamTagsLong,
+		Example: gcImageStreamTagsExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, remove the image stream tags of all namespaces, and search all namespaces for uses of their images.")
+	cmd.Flags().DurationVar(&o.OlderThan, "older-than", o.OlderThan, "Remove the image stream tags whose image wasn't used, and that weren't updated, for this long.")
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, remove the image stream tags. Defaults to false, listing the tags that would be removed.")
+	kcmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
+	o.PrintFlags.AddFlags(cmd)
+
+	return cmd
+}
+
+func (o *GCImageStreamTagsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.BuildClient, err = buildv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.AppsClient, err = appsv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.KubeClient, err = kubernetes.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *GCImageStreamTagsOptions) Validate() error {
+	if o.OlderThan <= 0 {
+		return fmt.Errorf("--older-than must be positive")
+	}
+	return o.PrintFlags.Validate()
+}
+
+func (o *GCImageStreamTagsOptions) Run() error {
+	ctx := context.TODO()
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+
+	candidates, err := o.Candidates(ctx)
+	if err != nil {
+		return err
+	}
+	if !o.Confirm && len(candidates) > 0 {
+		fmt.Fprintln(o.ErrOut, "Dry run enabled - no modifications will be made. Add --confirm to remove image stream tags")
+	}
+	if o.PrintFlags.Structured() {
+		if err := o.PrintFlags.PrintStructured(o.Out, candidates); err != nil {
+			return err
+		}
+	} else {
+		printCandidates(o.Out, o.PrintFlags, candidates, o.Now, o.AllNamespaces)
+	}
+	if !o.Confirm {
+		return nil
+	}
+
+	for _, candidate := range candidates {
+		err := o.ImageClient.ImageStreamTags(candidate.Namespace).Delete(ctx, candidate.Tag, metav1.DeleteOptions{})
+		if err != nil && !kerrors.IsNotFound(err) {
+			return fmt.Errorf("unable to remove image stream tag %q in %q: %v", candidate.Tag, candidate.Namespace, err)
+		}
+	}
+	return nil
+}
+
+// Candidates returns the image stream tags to remove, sorted by namespace
+// and name.
+func (o *GCImageStreamTagsOptions) Candidates(ctx context.Context) ([]Candidate, error) {
+	streams, err := paging.ImageV1(o.ImageClient, o.ChunkSize).ImageStreams(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	used, err := o.lastUses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := o.Now.Add(-o.OlderThan)
+	candidates := []Candidate{}
+	for i := range streams.Items {
+		stream := &streams.Items[i]
+		if stream.Annotations[ProtectedAnnotation] == "true" {
+			continue
+		}
+		protected := map[string]bool{}
+		for _, tag := range stream.Spec.Tags {
+			protected[tag.Name] = tag.Annotations[ProtectedAnnotation] == "true"
+		}
+		for _, history := range stream.Status.Tags {
+			if protected[history.Tag] || len(history.Items) == 0 {
+				continue
+			}
+			latest := history.Items[0]
+			if latest.Created.Time.After(cutoff) {
+				continue
+			}
+			lastUsed := used.last(tagKeys(stream, history.Tag, latest)...)
+			if lastUsed.After(cutoff) {
+				continue
+			}
+			candidates = append(candidates, Candidate{
+				Namespace: stream.Namespace,
+				Tag:       imageutil.JoinImageStreamTag(stream.Name, history.Tag),
+				Image:     latest.Image,
+				LastUsed:  lastUsed,
+			})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Namespace != candidates[j].Namespace {
+			return candidates[i].Namespace < candidates[j].Namespace
+		}
+		return candidates[i].Tag < candidates[j].Tag
+	})
+	return candidates, nil
+}
+
+// uses records when images were last used, by digest or by pull spec when
+// they are referred to by tag.
+type uses map[string]time.Time
+
+func (u uses) add(image string, at time.Time) {
+	key := image
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		key = digest
+	}
+	if len(key) > 0 && at.After(u[key]) {
+		u[key] = at
+	}
+}
+
+func (u uses) last(keys ...string) time.Time {
+	last := time.Time{}
+	for _, key := range keys {
+		if at := u[key]; at.After(last) {
+			last = at
+		}
+	}
+	return last
+}
+
+// tagKeys returns the keys the image of the tag is recorded under in uses.
+func tagKeys(stream *imagev1.ImageStream, tag string, event imagev1.TagEvent) []string {
+	keys := []string{event.Image}
+	for _, repository := range []string{stream.Status.DockerImageRepository, stream.Status.PublicDockerImageRepository} {
+		if len(repository) > 0 {
+			keys = append(keys, repository+":"+tag)
+		}
+	}
+	return keys
+}
+
+// lastUses returns when the images were last used by builds, pods,
+// replication controllers, deployment configs and deployments. Running pods
+// and current pod templates use their images now.
+func (o *GCImageStreamTagsOptions) lastUses(ctx context.Context) (uses, error) {
+	used := uses{}
+
+	builds, err := paging.BuildV1(o.BuildClient, o.ChunkSize).Builds(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, build := range builds.Items {
+		if from := buildutil.GetInputReference(build.Spec.Strategy); from != nil && from.Kind == "DockerImage" {
+			used.add(from.Name, build.CreationTimestamp.Time)
+		}
+	}
+
+	pods, err := o.KubeClient.CoreV1().Pods(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		at := pod.CreationTimestamp.Time
+		if pod.Status.Phase == corev1.PodRunning || pod.Status.Phase == corev1.PodPending {
+			at = o.Now
+		}
+		addPodSpec(used, &pod.Spec, at)
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			used.add(status.ImageID, at)
+		}
+	}
+
+	rcs, err := o.KubeClient.CoreV1().ReplicationControllers(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, rc := range rcs.Items {
+		if rc.Spec.Template == nil {
+			continue
+		}
+		at := rc.CreationTimestamp.Time
+		if rc.Spec.Replicas != nil && *rc.Spec.Replicas > 0 {
+			at = o.Now
+		}
+		addPodSpec(used, &rc.Spec.Template.Spec, at)
+	}
+
+	dcs, err := o.AppsClient.DeploymentConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, dc := range dcs.Items {
+		if dc.Spec.Template != nil {
+			addPodSpec(used, &dc.Spec.Template.Spec, o.Now)
+		}
+	}
+
+	deployments, err := o.KubeClient.AppsV1().Deployments(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		addPodSpec(used, &deployment.Spec.Template.Spec, o.Now)
+	}
+	return used, nil
+}
+
+func addPodSpec(used uses, spec *corev1.PodSpec, at time.Time) {
+	for _, container := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		used.add(container.Image, at)
+	}
+}
+
+func printCandidates(out io.Writer, printFlags *osutil.ReportPrintFlags, candidates []Candidate, now time.Time, allNamespaces bool) {
+	if len(candidates) == 0 {
+		fmt.Fprintln(out, "No image stream tags to remove.")
+		return
+	}
+	headers := []string{"IMAGE STREAM TAG", "IMAGE", "LAST USED"}
+	if allNamespaces {
+		headers = append([]string{"NAMESPACE"}, headers...)
+	}
+	w := printFlags.NewTable(out, headers...)
+	defer w.Flush()
+	for _, candidate := range candidates {
+		if allNamespaces {
+			fmt.Fprintf(w, "%s\t", candidate.Namespace)
+		}
+		lastUsed := "never"
+		if !candidate.LastUsed.IsZero() {
+			lastUsed = duration.HumanDuration(now.Sub(candidate.LastUsed)) + " ago"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", printFlags.Name("imagestreamtag", candidate.Tag), candidate.Image, lastUsed)
+	}
+}
@@ -0,0 +1,145 @@
+package gc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	appsfake "github.com/openshift/client-go/apps/clientset/versioned/fake"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+const repository = "image-registry.openshift-image-registry.svc:5000/test/app"
+
+var now = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func daysAgo(days int) metav1.Time {
+	return metav1.NewTime(now.Add(-time.Duration(days) * 24 * time.Hour))
+}
+
+func newImageStream() *imagev1.ImageStream {
+	tag := func(name, image string, days int) imagev1.NamedTagEventList {
+		return imagev1.NamedTagEventList{Tag: name, Items: []imagev1.TagEvent{{Image: image, Created: daysAgo(days)}}}
+	}
+	return &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+		Spec: imagev1.ImageStreamSpec{Tags: []imagev1.TagReference{
+			{Name: "keep", Annotations: map[string]string{ProtectedAnnotation: "true"}},
+		}},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository: repository,
+			Tags: []imagev1.NamedTagEventList{
+				tag("unused", "sha256:unused", 90),
+				tag("recent", "sha256:recent", 2),
+				tag("keep", "sha256:keep", 90),
+				tag("running", "sha256:running", 90),
+				tag("built-from", "sha256:built", 90),
+				tag("old-build", "sha256:oldbuild", 90),
+				tag("by-tag", "sha256:bytag", 90),
+			},
+		},
+	}
+}
+
+func TestGCImageStreamTags(t *testing.T) {
+	imageClient := imagefake.NewSimpleClientset(newImageStream())
+	buildClient := buildfake.NewSimpleClientset(
+		&buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "recent-build", CreationTimestamp: daysAgo(5)},
+			Spec: buildv1.BuildSpec{CommonSpec: buildv1.CommonSpec{Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+				From: &corev1.ObjectReference{Kind: "DockerImage", Name: repository + "@sha256:built"},
+			}}}},
+		},
+		&buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "old-build", CreationTimestamp: daysAgo(60)},
+			Spec: buildv1.BuildSpec{CommonSpec: buildv1.CommonSpec{Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+				From: &corev1.ObjectReference{Kind: "DockerImage", Name: repository + "@sha256:oldbuild"},
+			}}}},
+		},
+	)
+	kubeClient := kubefake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "running", CreationTimestamp: daysAgo(80)},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{ImageID: repository + "@sha256:running"}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "by-tag", CreationTimestamp: daysAgo(80)},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Image: repository + ":by-tag"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	o := NewGCImageStreamTagsOptions(genericiooptions.IOStreams{Out: out, ErrOut: errOut})
+	o.Namespace = "test"
+	o.Now = now
+	o.ImageClient = imageClient.ImageV1()
+	o.BuildClient = buildClient.BuildV1()
+	o.AppsClient = appsfake.NewSimpleClientset().AppsV1()
+	o.KubeClient = kubeClient
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "IMAGE STREAM TAG  IMAGE            LAST USED\n" +
+		"app:old-build     sha256:oldbuild  60d ago\n" +
+		"app:unused        sha256:unused    never\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+	for _, action := range imageClient.Actions() {
+		if action.GetVerb() == "delete" {
+			t.Errorf("expected a dry run not to remove anything, got %v", action)
+		}
+	}
+
+	o.Confirm = true
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	deleted := []string{}
+	for _, action := range imageClient.Actions() {
+		if action, ok := action.(clientgotesting.DeleteAction); ok {
+			deleted = append(deleted, action.GetName())
+		}
+	}
+	if len(deleted) != 2 || deleted[0] != "app:old-build" || deleted[1] != "app:unused" {
+		t.Errorf("expected app:old-build and app:unused to be removed, got %v", deleted)
+	}
+}
+
+func TestGCImageStreamTagsProtectedStream(t *testing.T) {
+	stream := newImageStream()
+	stream.Annotations = map[string]string{ProtectedAnnotation: "true"}
+
+	o := NewGCImageStreamTagsOptions(genericiooptions.NewTestIOStreamsDiscard())
+	o.Namespace = "test"
+	o.Now = now
+	o.ImageClient = imagefake.NewSimpleClientset(stream).ImageV1()
+	o.BuildClient = buildfake.NewSimpleClientset().BuildV1()
+	o.AppsClient = appsfake.NewSimpleClientset().AppsV1()
+	o.KubeClient = kubefake.NewSimpleClientset()
+	candidates, err := o.Candidates(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 0 {
+		t.Errorf("expected the tags of a protected image stream to be kept, got %v", candidates)
+	}
+}
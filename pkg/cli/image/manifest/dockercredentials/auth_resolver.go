@@ -21,8 +21,9 @@ type AuthResolver struct {
 
 // NewAuthResolver creates a new auth resolver that loads authFilePath file
 // (defaults to a docker locations) to find a valid
-// authentication for registry targets.
-func NewAuthResolver(authFilePath string) (*AuthResolver, error) {
+// authentication for registry targets. When credentialHelper is set, the
+// credentials stored by that docker credential helper take precedence.
+func NewAuthResolver(authFilePath, credentialHelper string) (*AuthResolver, error) {
 	var credentials map[string]containertypes.DockerAuthConfig
 	var err error
 
@@ -70,6 +71,19 @@ func NewAuthResolver(authFilePath string) (*AuthResolver, error) {
 		}
 	}
 
+	if len(credentialHelper) > 0 {
+		helperCreds, err := helperCredentials(credentialHelper)
+		if err != nil {
+			return nil, err
+		}
+		if credentials == nil {
+			credentials = map[string]containertypes.DockerAuthConfig{}
+		}
+		for registry, entry := range helperCreds {
+			credentials[registry] = entry
+		}
+	}
+
 	return &AuthResolver{
 		credentials: credentials,
 	}, nil
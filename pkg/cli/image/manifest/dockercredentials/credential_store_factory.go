@@ -11,8 +11,8 @@ import (
 )
 
 // NewCredentialStoreFactory returns an entity capable of creating a CredentialStore
-func NewCredentialStoreFactory(path string) (registryclient.CredentialStoreFactory, error) {
-	authResolver, err := NewAuthResolver(path)
+func NewCredentialStoreFactory(path, credentialHelper string) (registryclient.CredentialStoreFactory, error) {
+	authResolver, err := NewAuthResolver(path, credentialHelper)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,58 @@
+package dockercredentials
+
+import (
+	"fmt"
+
+	containertypes "github.com/containers/image/v5/types"
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// HelperPrefix is the prefix of the executables implementing the docker
+// credential helper protocol, such as docker-credential-osxkeychain.
+const HelperPrefix = "docker-credential-"
+
+// helperTokenUsername is the username credential helpers store identity
+// tokens with.
+const helperTokenUsername = "<token>"
+
+// newHelperProgram returns the program running the credential helper name.
+var newHelperProgram = func(name string) helperclient.ProgramFunc {
+	return helperclient.NewShellProgramFunc(HelperPrefix + name)
+}
+
+// helperCredentials returns the credentials stored by the credential helper
+// name, by registry.
+func helperCredentials(name string) (map[string]containertypes.DockerAuthConfig, error) {
+	program := newHelperProgram(name)
+	servers, err := helperclient.List(program)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the credentials of %s%s: %v", HelperPrefix, name, err)
+	}
+	all := make(map[string]containertypes.DockerAuthConfig, len(servers))
+	for server := range servers {
+		creds, err := helperclient.Get(program, server)
+		if credentials.IsErrCredentialsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the credentials of %s from %s%s: %v", server, HelperPrefix, name, err)
+		}
+		if creds.Username == helperTokenUsername {
+			all[server] = containertypes.DockerAuthConfig{IdentityToken: creds.Secret}
+			continue
+		}
+		all[server] = containertypes.DockerAuthConfig{Username: creds.Username, Password: creds.Secret}
+	}
+	return all, nil
+}
+
+// StoreInHelper saves the credentials of registry with the credential helper
+// name, e.g. in the keychain of the system.
+func StoreInHelper(name, registry, username, password string) error {
+	creds := &credentials.Credentials{ServerURL: registry, Username: username, Secret: password}
+	if err := helperclient.Store(newHelperProgram(name), creds); err != nil {
+		return fmt.Errorf("unable to store the credentials of %s with %s%s: %v", registry, HelperPrefix, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package dockercredentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	containertypes "github.com/containers/image/v5/types"
+	helperclient "github.com/docker/docker-credential-helpers/client"
+	"github.com/docker/docker-credential-helpers/credentials"
+)
+
+// fakeHelper implements the credential helper protocol over an in-memory store.
+type fakeHelper struct {
+	store map[string]credentials.Credentials
+}
+
+type fakeProgram struct {
+	helper *fakeHelper
+	action string
+	input  string
+}
+
+func (p *fakeProgram) Input(in io.Reader) {
+	data, _ := io.ReadAll(in)
+	p.input = strings.TrimSpace(string(data))
+}
+
+func (p *fakeProgram) Output() ([]byte, error) {
+	switch p.action {
+	case credentials.ActionList:
+		servers := map[string]string{}
+		for server, creds := range p.helper.store {
+			servers[server] = creds.Username
+		}
+		return json.Marshal(servers)
+	case credentials.ActionGet:
+		creds, ok := p.helper.store[p.input]
+		if !ok {
+			return []byte(credentials.NewErrCredentialsNotFound().Error()), fmt.Errorf("exit status 1")
+		}
+		return json.Marshal(creds)
+	case credentials.ActionStore:
+		creds := credentials.Credentials{}
+		if err := json.Unmarshal([]byte(p.input), &creds); err != nil {
+			return nil, err
+		}
+		p.helper.store[creds.ServerURL] = creds
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected action %q", p.action)
+}
+
+func withFakeHelper(t *testing.T, helper *fakeHelper) {
+	original := newHelperProgram
+	newHelperProgram = func(name string) helperclient.ProgramFunc {
+		if name != "fake" {
+			t.Fatalf("unexpected credential helper %q", name)
+		}
+		return func(args ...string) helperclient.Program {
+			return &fakeProgram{helper: helper, action: args[0]}
+		}
+	}
+	t.Cleanup(func() { newHelperProgram = original })
+}
+
+func TestHelperCredentials(t *testing.T) {
+	helper := &fakeHelper{store: map[string]credentials.Credentials{}}
+	withFakeHelper(t, helper)
+
+	if err := StoreInHelper("fake", "quay.io", "user", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreInHelper("fake", "registry.example.com", helperTokenUsername, "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := helperCredentials("fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]containertypes.DockerAuthConfig{
+		"quay.io":              {Username: "user", Password: "secret"},
+		"registry.example.com": {IdentityToken: "token"},
+	}
+	if !reflect.DeepEqual(creds, expected) {
+		t.Errorf("expected %#v, got %#v", expected, creds)
+	}
+
+	resolver := &AuthResolver{credentials: creds}
+	auth, err := resolver.findAuthentication(nil, "quay.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.Username != "user" || auth.Password != "secret" {
+		t.Errorf("unexpected credentials for quay.io: %#v", auth)
+	}
+}
@@ -43,6 +43,7 @@ func (o *ParallelOptions) Bind(flags *pflag.FlagSet) {
 
 type SecurityOptions struct {
 	RegistryConfig   string
+	CredentialHelper string
 	Insecure         bool
 	SkipVerification bool
 
@@ -52,6 +53,7 @@ type SecurityOptions struct {
 func (o *SecurityOptions) Bind(flags *pflag.FlagSet) {
 	// TODO: remove REGISTRY_AUTH_PREFERENCE env variable support and support only podman in 4.15
 	flags.StringVarP(&o.RegistryConfig, "registry-config", "a", o.RegistryConfig, "Path to your registry credentials. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Defaults to ${XDG_RUNTIME_DIR}/containers/auth.json, /run/containers/${UID}/auth.json, ${XDG_CONFIG_HOME}/containers/auth.json, ${DOCKER_CONFIG}, ~/.docker/config.json, ~/.dockercfg. The order can be changed via the REGISTRY_AUTH_PREFERENCE env variable (deprecated) to a \"docker\" value to prioritizes Docker credentials over Podman's.")
+	flags.StringVar(&o.CredentialHelper, "credential-helper", o.CredentialHelper, "Name of a docker credential helper, such as osxkeychain, secretservice, wincred or pass, to read registry credentials from. The docker-credential-<name> executable must be in your PATH. Its credentials take precedence over --registry-config.")
 	flags.BoolVar(&o.Insecure, "insecure", o.Insecure, "Allow push and pull operations to registries to be made over HTTP")
 	flags.BoolVar(&o.SkipVerification, "skip-verification", o.SkipVerification, "Skip verifying the integrity of the retrieved content. This is not recommended, but may be necessary when importing images from older image registries. Only bypass verification if the registry is known to be trustworthy.")
 }
@@ -124,8 +126,11 @@ func (o *SecurityOptions) NewContext() (*registryclient.Context, error) {
 	if err != nil {
 		return nil, err
 	}
-	credStoreFactory, err := dockercredentials.NewCredentialStoreFactory(o.RegistryConfig)
+	credStoreFactory, err := dockercredentials.NewCredentialStoreFactory(o.RegistryConfig, o.CredentialHelper)
 	if err != nil {
+		if len(o.CredentialHelper) > 0 {
+			return nil, fmt.Errorf("unable to load --credential-helper: %v", err)
+		}
 		if len(o.RegistryConfig) > 0 {
 			return nil, fmt.Errorf("unable to load --registry-config: %v", err)
 		}
@@ -0,0 +1,272 @@
+package imagemirror
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/library-go/pkg/image/reference"
+	"github.com/openshift/oc/pkg/cli/image/imagesource"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+	imagemirror "github.com/openshift/oc/pkg/cli/image/mirror"
+)
+
+var (
+	imageMirrorLong = templates.LongDesc(`
+		Mirror the images of image stream tags to an external registry.
+
+		The image each tag currently points to is pulled by digest from the integrated
+		registry and pushed to --to, under the name of its image stream and its tag. The
+		images are copied unchanged, manifest lists included, so that they keep their digests,
+		and the command fails if the target registry changes them.
+
+		Pulling from the integrated registry requires credentials for it, e.g. saved by
+		'oc registry login', and pushing requires credentials for the target registry. Both
+		are read from --registry-config or the default locations of 'oc image mirror'.
+
+		With --dry-run, the images that would be mirrored are printed without contacting
+		any registry.
+	`)
+
+	imageMirrorExample = templates.Examples(`
+		# Mirror the latest images of two image streams to quay.io/myorg
+		oc ex image-mirror ruby:latest nodejs:latest --to=quay.io/myorg
+
+		# Print what would be mirrored without pushing anything
+		oc ex image-mirror ruby:latest --to=quay.io/myorg --dry-run
+
+		# Mirror with the credentials of a file, transferring up to 10 layers at once
+		oc ex image-mirror ruby:3.1 --to=registry.example.com/mirror -a auth.json --max-per-registry=10
+	`)
+)
+
+// ImageMirrorOptions contains all the options needed to mirror image stream tags
+type ImageMirrorOptions struct {
+	To     string
+	DryRun bool
+
+	Namespace string
+	Tags      []string
+
+	ImageClient     imagev1client.ImageV1Interface
+	SecurityOptions imagemanifest.SecurityOptions
+	ParallelOptions imagemanifest.ParallelOptions
+
+	// Mirror copies the images of mappings, reporting the manifests whose
+	// digest the target registry changed to digestChanged. It defaults to
+	// 'oc image mirror'.
+	Mirror func(mappings []imagemirror.Mapping, digestChanged func(from, to digest.Digest)) error
+
+	genericiooptions.IOStreams
+}
+
+func NewImageMirrorOptions(streams genericiooptions.IOStreams) *ImageMirrorOptions {
+	return &ImageMirrorOptions{
+		ParallelOptions: imagemanifest.ParallelOptions{MaxPerRegistry: 6},
+		IOStreams:       streams,
+	}
+}
+
+// NewCmdImageMirror implements the OpenShift experimental image-mirror command
+func NewCmdImageMirror(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewImageMirrorOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "image-mirror IMAGESTREAM:TAG... --to=REGISTRY/NAMESPACE",
+		Short:   "Mirror the images of image stream tags to an external registry",
+		Long:    imageMirrorLong,
+		Example: imageMirrorExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.To, "to", o.To, "The registry and namespace to mirror the images to, e.g. quay.io/myorg. Images are pushed to <to>/<image stream>:<tag>.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the images that would be mirrored without contacting any registry.")
+	o.SecurityOptions.Bind(cmd.Flags())
+	o.ParallelOptions.Bind(cmd.Flags())
+
+	return cmd
+}
+
+func (o *ImageMirrorOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "at least one image stream tag is required")
+	}
+	o.Tags = args
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.ImageClient, err = imagev1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	if o.Mirror == nil {
+		o.Mirror = o.mirror
+	}
+	return nil
+}
+
+func (o *ImageMirrorOptions) Validate() error {
+	if len(o.To) == 0 {
+		return fmt.Errorf("--to is required")
+	}
+	ref, err := reference.Parse(o.To)
+	if err != nil || len(ref.Registry) == 0 || len(ref.Tag) > 0 || len(ref.ID) > 0 {
+		return fmt.Errorf("--to must be a registry and a namespace, e.g. quay.io/myorg: %s", o.To)
+	}
+	for _, tag := range o.Tags {
+		if _, _, err := imageutil.ParseImageStreamTagName(tag); err != nil {
+			return err
+		}
+	}
+	if o.ParallelOptions.MaxPerRegistry < 1 {
+		return fmt.Errorf("--max-per-registry must be positive")
+	}
+	return nil
+}
+
+func (o *ImageMirrorOptions) Run() error {
+	ctx := context.TODO()
+
+	mappings, err := o.plan(ctx)
+	if err != nil {
+		return err
+	}
+
+	if o.DryRun {
+		for _, mapping := range mappings {
+			fmt.Fprintf(o.Out, "%s -> %s\n", mapping.Source, mapping.Destination)
+		}
+		fmt.Fprintf(o.ErrOut, "info: %d images would be mirrored to %s (dry run)\n", len(mappings), o.To)
+		return nil
+	}
+
+	var lock sync.Mutex
+	changed := []string{}
+	err = o.Mirror(mappings, func(from, to digest.Digest) {
+		lock.Lock()
+		defer lock.Unlock()
+		changed = append(changed, fmt.Sprintf("%s became %s", from, to))
+	})
+	if err != nil {
+		return err
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return fmt.Errorf("%s changed the digests of the images mirrored to it:\n  %s", o.To, strings.Join(changed, "\n  "))
+	}
+	fmt.Fprintf(o.ErrOut, "info: Mirrored %d images to %s\n", len(mappings), o.To)
+	return nil
+}
+
+// plan returns the mappings from the images of the tags in the integrated
+// registry, by digest, to their names under --to. The image streams are read
+// once even when several of their tags are mirrored.
+func (o *ImageMirrorOptions) plan(ctx context.Context) ([]imagemirror.Mapping, error) {
+	streams := map[string]*imagev1.ImageStream{}
+	seen := map[string]bool{}
+	mappings := []imagemirror.Mapping{}
+	for _, istag := range o.Tags {
+		if seen[istag] {
+			continue
+		}
+		seen[istag] = true
+
+		name, tag, err := imageutil.ParseImageStreamTagName(istag)
+		if err != nil {
+			return nil, err
+		}
+		stream, ok := streams[name]
+		if !ok {
+			stream, err = o.ImageClient.ImageStreams(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			streams[name] = stream
+		}
+		mapping, err := mappingFor(stream, tag, o.To)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// mappingFor returns the mapping from the image tag of stream points to, in
+// the integrated registry, to <to>/<stream name>:<tag>. The public repository
+// of the stream is preferred, since the internal one is usually only
+// reachable from the cluster.
+func mappingFor(stream *imagev1.ImageStream, tag, to string) (imagemirror.Mapping, error) {
+	istag := imageutil.JoinImageStreamTag(stream.Name, tag)
+	repository := stream.Status.PublicDockerImageRepository
+	if len(repository) == 0 {
+		repository = stream.Status.DockerImageRepository
+	}
+	if len(repository) == 0 {
+		return imagemirror.Mapping{}, fmt.Errorf("%s can't be mirrored, the integrated registry is not configured", istag)
+	}
+	event := imageutil.LatestTaggedImage(stream, tag)
+	if event == nil || len(event.Image) == 0 {
+		return imagemirror.Mapping{}, fmt.Errorf("%s can't be mirrored, it doesn't point to an image", istag)
+	}
+	source, err := imagesource.ParseReference(repository + "@" + event.Image)
+	if err != nil {
+		return imagemirror.Mapping{}, fmt.Errorf("%s can't be mirrored: %v", istag, err)
+	}
+	destination, err := imagesource.ParseDestinationReference(fmt.Sprintf("%s/%s:%s", strings.TrimSuffix(to, "/"), stream.Name, tag))
+	if err != nil {
+		return imagemirror.Mapping{}, fmt.Errorf("%s can't be mirrored: %v", istag, err)
+	}
+	return imagemirror.Mapping{Source: source, Destination: destination, Name: istag}, nil
+}
+
+// mirror copies the images of mappings with 'oc image mirror', keeping their
+// manifest lists so that their digests don't change.
+func (o *ImageMirrorOptions) mirror(mappings []imagemirror.Mapping, digestChanged func(from, to digest.Digest)) error {
+	opts := imagemirror.NewMirrorImageOptions(o.IOStreams)
+	opts.Mappings = mappings
+	opts.SecurityOptions = o.SecurityOptions
+	opts.ParallelOptions = o.ParallelOptions
+	opts.FilterOptions = imagemanifest.FilterOptions{FilterByOS: ".*"}
+	opts.KeepManifestList = true
+	opts.ManifestUpdateCallback = func(registry string, manifests map[digest.Digest]digest.Digest) error {
+		for from, to := range manifests {
+			if from != to {
+				digestChanged(from, to)
+			}
+		}
+		return nil
+	}
+	if err := opts.FilterOptions.Validate(); err != nil {
+		return err
+	}
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("error configuring image mirroring: %v", err)
+	}
+	return opts.Run()
+}
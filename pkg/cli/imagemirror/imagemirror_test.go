@@ -0,0 +1,139 @@
+package imagemirror
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeimageclient "github.com/openshift/client-go/image/clientset/versioned/fake"
+	imagemirror "github.com/openshift/oc/pkg/cli/image/mirror"
+)
+
+const (
+	digest1 = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	digest2 = "sha256:456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123"
+	digest3 = "sha256:89abcdef0123456789abcdef0123456789abcdef0123456789abcdef01234567"
+)
+
+func newImageStream(name, public string, tags map[string]string) *imagev1.ImageStream {
+	stream := &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository:       "image-registry.openshift-image-registry.svc:5000/test/" + name,
+			PublicDockerImageRepository: public,
+		},
+	}
+	for tag, image := range tags {
+		stream.Status.Tags = append(stream.Status.Tags, imagev1.NamedTagEventList{Tag: tag, Items: []imagev1.TagEvent{{Image: image}}})
+	}
+	return stream
+}
+
+func TestMappingFor(t *testing.T) {
+	stream := newImageStream("ruby", "default-route-openshift-image-registry.apps.example.com/test/ruby", map[string]string{"latest": digest1})
+	mapping, err := mappingFor(stream, "latest", "quay.io/myorg/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "default-route-openshift-image-registry.apps.example.com/test/ruby@" + digest1; mapping.Source.String() != expected {
+		t.Errorf("expected the public repository to be pulled from, %s, got %s", expected, mapping.Source)
+	}
+	if expected := "quay.io/myorg/ruby:latest"; mapping.Destination.String() != expected {
+		t.Errorf("expected %s, got %s", expected, mapping.Destination)
+	}
+
+	stream = newImageStream("ruby", "", map[string]string{"latest": digest1})
+	mapping, err = mappingFor(stream, "latest", "quay.io/myorg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "image-registry.openshift-image-registry.svc:5000/test/ruby@" + digest1; mapping.Source.String() != expected {
+		t.Errorf("expected %s, got %s", expected, mapping.Source)
+	}
+
+	if _, err := mappingFor(stream, "3.1", "quay.io/myorg"); err == nil || !strings.Contains(err.Error(), "doesn't point to an image") {
+		t.Errorf("expected a tag without image to be rejected, got %v", err)
+	}
+	stream.Status.DockerImageRepository = ""
+	if _, err := mappingFor(stream, "latest", "quay.io/myorg"); err == nil || !strings.Contains(err.Error(), "integrated registry is not configured") {
+		t.Errorf("expected a stream without repository to be rejected, got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	client := fakeimageclient.NewSimpleClientset(
+		newImageStream("ruby", "", map[string]string{"latest": digest1, "3.1": digest2}),
+	)
+	newOptions := func(dryRun bool) (*ImageMirrorOptions, *bytes.Buffer, *strings.Builder) {
+		streams, _, out, _ := genericiooptions.NewTestIOStreams()
+		o := NewImageMirrorOptions(streams)
+		o.To = "quay.io/myorg"
+		o.DryRun = dryRun
+		o.Namespace = "test"
+		o.Tags = []string{"ruby:latest", "ruby:3.1", "ruby:latest"}
+		o.ImageClient = client.ImageV1()
+		builder := &strings.Builder{}
+		o.Mirror = func(mappings []imagemirror.Mapping, digestChanged func(from, to digest.Digest)) error {
+			for _, mapping := range mappings {
+				builder.WriteString(mapping.Source.String() + " " + mapping.Destination.String() + "\n")
+			}
+			return nil
+		}
+		if err := o.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return o, out, builder
+	}
+
+	o, out, mirrored := newOptions(true)
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := "image-registry.openshift-image-registry.svc:5000/test/ruby@" + digest1 + " -> quay.io/myorg/ruby:latest\n" +
+		"image-registry.openshift-image-registry.svc:5000/test/ruby@" + digest2 + " -> quay.io/myorg/ruby:3.1\n"
+	if out.String() != expected {
+		t.Errorf("expected the plan:\n%s\ngot:\n%s", expected, out)
+	}
+	if mirrored.Len() > 0 {
+		t.Errorf("expected nothing to be mirrored with --dry-run, got:\n%s", mirrored)
+	}
+
+	o, _, mirrored = newOptions(false)
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(mirrored.String(), "\n"); lines != 2 {
+		t.Errorf("expected the duplicate tag to be mirrored once, got:\n%s", mirrored)
+	}
+
+	o, _, _ = newOptions(false)
+	o.Mirror = func(mappings []imagemirror.Mapping, digestChanged func(from, to digest.Digest)) error {
+		digestChanged(digest1, digest3)
+		return nil
+	}
+	if err := o.Run(); err == nil || err.Error() != "quay.io/myorg changed the digests of the images mirrored to it:\n  "+digest1+" became "+digest3 {
+		t.Errorf("expected the changed digest to be reported, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for to, valid := range map[string]bool{
+		"quay.io/myorg":        true,
+		"registry:5000/mirror": true,
+		"":                     false,
+		"quay.io/myorg:latest": false,
+	} {
+		o := NewImageMirrorOptions(genericiooptions.IOStreams{})
+		o.To = to
+		o.Tags = []string{"ruby:latest"}
+		if err := o.Validate(); (err == nil) != valid {
+			t.Errorf("%q: expected valid %t, got %v", to, valid, err)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package importregistry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/distribution/distribution/v3/registry/client"
+	"github.com/distribution/distribution/v3/registry/client/auth"
+	"github.com/distribution/distribution/v3/registry/client/transport"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	imagemanifest "github.com/openshift/oc/pkg/cli/image/manifest"
+)
+
+var (
+	importRegistryLong = templates.LongDesc(`
+		Create or update image streams for the repositories of a container image registry.
+
+		The registry catalog is listed and every repository matching --filter is imported
+		into an image stream of the same name in the target namespace, importing all of
+		its tags. This is useful to seed shared namespaces holding builder images. Only
+		the image metadata is copied, not the image contents.
+
+		The registry must expose the catalog API (/v2/_catalog) to the provided credentials.
+	`)
+
+	importRegistryExample = templates.Examples(`
+		# Import all the repositories starting with 'rhel' into the 'openshift' namespace
+		oc ex import-registry --registry=registry.example.com --namespace=openshift --filter='rhel*'
+
+		# Preview the image streams that would be created without importing anything
+		oc ex import-registry --registry=registry.example.com --dry-run
+	`)
+)
+
+// catalogPageSize is the number of repositories requested per catalog call.
+const catalogPageSize = 100
+
+// ImportRegistryOptions contains all the options needed to import a registry catalog
+type ImportRegistryOptions struct {
+	Registry       string
+	Filter         string
+	RegistryConfig string
+	Insecure       bool
+	Scheduled      bool
+	DryRun         bool
+
+	Namespace string
+
+	ImageClient     imagev1client.ImageV1Interface
+	SecurityOptions imagemanifest.SecurityOptions
+
+	// ListRepositories returns the repositories found in the registry catalog.
+	ListRepositories func(ctx context.Context) ([]string, error)
+
+	genericiooptions.IOStreams
+}
+
+func NewImportRegistryOptions(streams genericiooptions.IOStreams) *ImportRegistryOptions {
+	return &ImportRegistryOptions{
+		Filter:    "*",
+		IOStreams: streams,
+	}
+}
+
+// NewCmdImportRegistry implements the OpenShift experimental import-registry command
+func NewCmdImportRegistry(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewImportRegistryOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "import-registry --registry=HOST",
+		Short:   "Create image streams for the repositories of a registry",
+		Long:    importRegistryLong,
+		Example: importRegistryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Registry, "registry", o.Registry, "The registry host (and optional port) to list repositories from.")
+	cmd.Flags().StringVar(&o.Filter, "filter", o.Filter, "Shell pattern matched against the repository path, or its last component, to select the repositories to import.")
+	cmd.Flags().StringVarP(&o.RegistryConfig, "registry-config", "a", o.RegistryConfig, "Path to your registry credentials. Defaults to the same locations as 'oc image mirror'.")
+	cmd.Flags().BoolVar(&o.Insecure, "insecure", o.Insecure, "If true, allow listing and importing from registries that have invalid HTTPS certificates or are hosted via HTTP.")
+	cmd.Flags().BoolVar(&o.Scheduled, "scheduled", o.Scheduled, "Set each imported repository to be periodically imported from the registry.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Fetch information about images without creating or updating image streams.")
+
+	return cmd
+}
+
+func (o *ImportRegistryOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed, use --registry to select the registry")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.ImageClient, err = imagev1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+
+	o.SecurityOptions = imagemanifest.SecurityOptions{
+		RegistryConfig: o.RegistryConfig,
+		Insecure:       o.Insecure,
+	}
+	if o.ListRepositories == nil {
+		o.ListRepositories = o.listCatalog
+	}
+	return nil
+}
+
+func (o *ImportRegistryOptions) Validate() error {
+	if len(o.Registry) == 0 {
+		return fmt.Errorf("--registry is required")
+	}
+	if strings.Contains(o.Registry, "/") {
+		return fmt.Errorf("--registry must be a registry host, not a repository: %s", o.Registry)
+	}
+	if _, err := path.Match(o.Filter, ""); err != nil {
+		return fmt.Errorf("--filter is not a valid pattern: %v", err)
+	}
+	return nil
+}
+
+func (o *ImportRegistryOptions) Run() error {
+	ctx := context.TODO()
+
+	repositories, err := o.ListRepositories(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to list the repositories of %s: %v", o.Registry, err)
+	}
+
+	streams, errs := streamsForRepositories(repositories, o.Filter)
+	if len(streams) == 0 && len(errs) == 0 {
+		fmt.Fprintf(o.ErrOut, "No repositories in %s match %q\n", o.Registry, o.Filter)
+		return nil
+	}
+
+	names := make([]string, 0, len(streams))
+	for name := range streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := o.importRepository(ctx, name, streams[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// streamsForRepositories maps the repositories matching filter to the name of
+// the image stream they will be imported to. Repositories whose names would
+// collide, or are not valid image stream names, are reported as errors.
+func streamsForRepositories(repositories []string, filter string) (map[string]string, []error) {
+	streams := map[string]string{}
+	var errs []error
+	for _, repository := range repositories {
+		name := path.Base(repository)
+		if !matches(filter, repository) && !matches(filter, name) {
+			continue
+		}
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("repository %s can't be imported as image stream %q: %s", repository, name, strings.Join(msgs, ", ")))
+			continue
+		}
+		if existing, ok := streams[name]; ok {
+			errs = append(errs, fmt.Errorf("repositories %s and %s both map to image stream %q, skipping %s", existing, repository, name, repository))
+			continue
+		}
+		streams[name] = repository
+	}
+	return streams, errs
+}
+
+func matches(pattern, name string) bool {
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
+func (o *ImportRegistryOptions) importRepository(ctx context.Context, name, repository string) error {
+	from := fmt.Sprintf("%s/%s", o.Registry, repository)
+	isi := &imagev1.ImageStreamImport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: o.Namespace,
+		},
+		Spec: imagev1.ImageStreamImportSpec{
+			Import: !o.DryRun,
+			Repository: &imagev1.RepositoryImportSpec{
+				From: corev1.ObjectReference{Kind: "DockerImage", Name: from},
+				ImportPolicy: imagev1.TagImportPolicy{
+					Insecure:  o.Insecure,
+					Scheduled: o.Scheduled,
+				},
+			},
+		},
+	}
+
+	result, err := o.ImageClient.ImageStreamImports(o.Namespace).Create(ctx, isi, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to import %s: %v", from, err)
+	}
+
+	status := result.Status.Repository
+	if status == nil {
+		return fmt.Errorf("unable to import %s: the server did not report the repository status", from)
+	}
+	if status.Status.Status == metav1.StatusFailure {
+		return fmt.Errorf("unable to import %s: %s", from, status.Status.Message)
+	}
+
+	imported, failed := 0, 0
+	for _, image := range status.Images {
+		if image.Image != nil {
+			imported++
+			continue
+		}
+		failed++
+		fmt.Fprintf(o.ErrOut, "error: repository tag %s:%s failed: %v\n", from, image.Tag, image.Status.Message)
+	}
+
+	message := "imported"
+	if o.DryRun {
+		message = "imported (dry run)"
+	}
+	fmt.Fprintf(o.Out, "imagestream.image.openshift.io/%s %s from %s: %d tags", name, message, from, imported)
+	if failed > 0 {
+		fmt.Fprintf(o.Out, ", %d failed", failed)
+	}
+	if len(status.AdditionalTags) > 0 {
+		fmt.Fprintf(o.Out, ", %d not imported", len(status.AdditionalTags))
+	}
+	fmt.Fprintln(o.Out)
+	return nil
+}
+
+// listCatalog returns all the repositories of the registry using the catalog API.
+func (o *ImportRegistryOptions) listCatalog(ctx context.Context) ([]string, error) {
+	registryContext, err := o.SecurityOptions.Context()
+	if err != nil {
+		return nil, err
+	}
+
+	rt, base, err := registryContext.Ping(ctx, &url.URL{Host: o.Registry}, o.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := registryContext.Credentials
+	if registryContext.CredentialsFactory != nil {
+		creds = registryContext.CredentialsFactory.CredentialStoreFor(o.Registry)
+	}
+	authorizer := auth.NewAuthorizer(
+		registryContext.Challenges,
+		auth.NewTokenHandlerWithOptions(auth.TokenHandlerOptions{
+			Transport:   rt,
+			Credentials: creds,
+			Scopes:      []auth.Scope{auth.RegistryScope{Name: "catalog", Actions: []string{"*"}}},
+		}),
+		auth.NewBasicHandler(creds),
+	)
+
+	registry, err := client.NewRegistry(base.String(), transport.NewTransport(rt, authorizer))
+	if err != nil {
+		return nil, err
+	}
+
+	var repositories []string
+	last := ""
+	for {
+		entries := make([]string, catalogPageSize)
+		n, err := registry.Repositories(ctx, entries, last)
+		repositories = append(repositories, entries[:n]...)
+		if err == io.EOF || (err == nil && n == 0) {
+			return repositories, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		last = entries[n-1]
+	}
+}
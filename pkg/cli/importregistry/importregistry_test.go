@@ -0,0 +1,84 @@
+package importregistry
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	clienttesting "k8s.io/client-go/testing"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeimageclient "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+func TestStreamsForRepositories(t *testing.T) {
+	repositories := []string{"rhel8", "ubi/rhel9", "team/rhel8", "fedora", "rhel_7"}
+
+	streams, errs := streamsForRepositories(repositories, "rhel*")
+	expected := map[string]string{"rhel8": "rhel8", "rhel9": "ubi/rhel9"}
+	if len(streams) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, streams)
+	}
+	for name, repository := range expected {
+		if streams[name] != repository {
+			t.Errorf("expected %s to map to %s, got %s", name, repository, streams[name])
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected a collision and an invalid name error, got %v", errs)
+	}
+}
+
+func TestRun(t *testing.T) {
+	client := fakeimageclient.NewSimpleClientset()
+	client.PrependReactor("create", "imagestreamimports", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		isi := action.(clienttesting.CreateAction).GetObject().(*imagev1.ImageStreamImport)
+		if !isi.Spec.Import {
+			t.Errorf("expected the import to be performed")
+		}
+		isi.Status.Repository = &imagev1.RepositoryImportStatus{
+			Status: metav1.Status{Status: metav1.StatusSuccess},
+			Images: []imagev1.ImageImportStatus{
+				{Tag: "latest", Image: &imagev1.Image{}},
+				{Tag: "broken", Status: metav1.Status{Message: "manifest unknown"}},
+			},
+		}
+		return true, isi, nil
+	})
+
+	streams, _, out, errOut := genericiooptions.NewTestIOStreams()
+	o := NewImportRegistryOptions(streams)
+	o.Registry = "registry.example.com"
+	o.Filter = "rhel*"
+	o.Namespace = "openshift"
+	o.ImageClient = client.ImageV1()
+	o.ListRepositories = func(context.Context) ([]string, error) {
+		return []string{"rhel8", "fedora"}, nil
+	}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "imagestream.image.openshift.io/rhel8 imported from registry.example.com/rhel8: 1 tags, 1 failed\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if !strings.Contains(errOut.String(), "broken failed: manifest unknown") {
+		t.Errorf("expected the failed tag to be reported, got %q", errOut.String())
+	}
+	if creates := countCreates(client.Actions()); creates != 1 {
+		t.Errorf("expected a single import, got %d", creates)
+	}
+}
+
+func countCreates(actions []clienttesting.Action) int {
+	count := 0
+	for _, action := range actions {
+		if action.GetVerb() == "create" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,319 @@
+package layergraph
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/api/image"
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+	dotutil "github.com/openshift/oc/pkg/helpers/dot"
+)
+
+var (
+	layerGraphLong = templates.LongDesc(`
+		Show the layers shared by the images of image streams.
+
+		The layers of the latest image of every tag of the image streams are gathered to
+		report, for every image stream, the storage used by all its layers and the storage
+		used by the layers no other image stream shares. Then every pair of image streams
+		sharing layers is listed with the number and the size of those layers.
+
+		With '-o dot', the image streams and the layers they share are output as a graph
+		that can be rendered with the dot utility.
+	`)
+
+	layerGraphExample = templates.Examples(`
+		# Show the layers shared by the 'ruby' and 'nodejs' image streams
+		oc ex layer-graph ruby nodejs
+
+		# Render the layers shared by all the image streams of the current namespace
+		oc ex layer-graph --all -o dot | dot -T svg -o layers.svg
+	`)
+)
+
+// LayerGraphOptions contains all the options needed to show the layers shared by image streams
+type LayerGraphOptions struct {
+	Names     []string
+	All       bool
+	Output    string
+	Namespace string
+
+	ImageClient imagev1client.ImageV1Interface
+
+	genericiooptions.IOStreams
+}
+
+// streamLayers holds the layers of the images of an image stream.
+type streamLayers struct {
+	name   string
+	images int
+	layers map[string]int64
+}
+
+// streamUsage is the storage used by an image stream.
+type streamUsage struct {
+	name   string
+	images int
+	layers int
+	total  int64
+	unique int64
+}
+
+// sharing holds the layers shared by two image streams.
+type sharing struct {
+	streams [2]string
+	layers  int
+	size    int64
+}
+
+func NewLayerGraphOptions(streams genericiooptions.IOStreams) *LayerGraphOptions {
+	return &LayerGraphOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdLayerGraph implements the OpenShift experimental layer-graph command
+func NewCmdLayerGraph(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewLayerGraphOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "layer-graph (IMAGESTREAM... | --all)",
+		Short:   "Show the layers shared by the images of image streams",
+		Long:    layerGraphLong,
+		Example: layerGraphExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, show the layers shared by all the image streams of the namespace.")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format. One of: (dot)")
+
+	return cmd
+}
+
+func (o *LayerGraphOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if o.All && len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "image streams can't be given with --all")
+	}
+	if !o.All && len(args) == 0 {
+		return kcmdutil.UsageErrorf(cmd, "at least one image stream or --all is required")
+	}
+
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	for _, arg := range args {
+		resource, name, err := osutil.ResolveResource(image.Resource("imagestreams"), arg, mapper)
+		if err != nil {
+			return err
+		}
+		if resource != image.Resource("imagestreams") {
+			return fmt.Errorf("only image streams are supported, got %s", resource)
+		}
+		o.Names = append(o.Names, name)
+	}
+
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.ImageClient, err = imagev1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *LayerGraphOptions) Validate() error {
+	if o.Output != "" && o.Output != "dot" {
+		return fmt.Errorf("output must be either empty or 'dot'")
+	}
+	return nil
+}
+
+func (o *LayerGraphOptions) Run() error {
+	ctx := context.TODO()
+
+	var imageStreams []imagev1.ImageStream
+	if o.All {
+		list, err := o.ImageClient.ImageStreams(o.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		imageStreams = list.Items
+	} else {
+		for _, name := range o.Names {
+			stream, err := o.ImageClient.ImageStreams(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			imageStreams = append(imageStreams, *stream)
+		}
+	}
+
+	streams := []streamLayers{}
+	for i := range imageStreams {
+		layers, err := o.gatherLayers(ctx, &imageStreams[i])
+		if err != nil {
+			return err
+		}
+		streams = append(streams, layers)
+	}
+	if len(streams) == 0 {
+		fmt.Fprintln(o.ErrOut, "No image streams found.")
+		return nil
+	}
+
+	usages, sharings := analyze(streams)
+	if o.Output == "dot" {
+		printDot(o.Out, usages, sharings)
+		return nil
+	}
+	printHumanReadable(o.Out, usages, sharings)
+	return nil
+}
+
+// gatherLayers returns the layers of the latest image of every tag of stream.
+// The layers of the images of a manifest list are those of its manifests.
+func (o *LayerGraphOptions) gatherLayers(ctx context.Context, stream *imagev1.ImageStream) (streamLayers, error) {
+	result := streamLayers{name: stream.Name, layers: map[string]int64{}}
+
+	digests := sets.NewString()
+	for _, tag := range stream.Status.Tags {
+		if len(tag.Items) > 0 {
+			digests.Insert(tag.Items[0].Image)
+		}
+	}
+	for len(digests) > 0 {
+		digest, _ := digests.PopAny()
+		isi, err := o.ImageClient.ImageStreamImages(stream.Namespace).Get(ctx, stream.Name+"@"+digest, metav1.GetOptions{})
+		if kerrors.IsNotFound(err) {
+			fmt.Fprintf(o.ErrOut, "warning: skipping image %s of image stream %q: %v\n", digest, stream.Name, err)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		for _, manifest := range isi.Image.DockerImageManifests {
+			digests.Insert(manifest.Digest)
+		}
+		if len(isi.Image.DockerImageManifests) > 0 {
+			continue
+		}
+		result.images++
+		for _, layer := range isi.Image.DockerImageLayers {
+			result.layers[layer.Name] = layer.LayerSize
+		}
+	}
+	return result, nil
+}
+
+// analyze returns the storage used by every stream, sorted by name, and the
+// layers shared by every pair of streams, sorted by decreasing size.
+func analyze(streams []streamLayers) ([]streamUsage, []sharing) {
+	owners := map[string]int{}
+	for _, stream := range streams {
+		for layer := range stream.layers {
+			owners[layer]++
+		}
+	}
+
+	usages := []streamUsage{}
+	for _, stream := range streams {
+		usage := streamUsage{name: stream.name, images: stream.images, layers: len(stream.layers)}
+		for layer, size := range stream.layers {
+			usage.total += size
+			if owners[layer] == 1 {
+				usage.unique += size
+			}
+		}
+		usages = append(usages, usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].name < usages[j].name })
+
+	sharings := []sharing{}
+	for i := range streams {
+		for j := i + 1; j < len(streams); j++ {
+			a, b := streams[i], streams[j]
+			if a.name > b.name {
+				a, b = b, a
+			}
+			shared := sharing{streams: [2]string{a.name, b.name}}
+			for layer, size := range a.layers {
+				if _, ok := b.layers[layer]; ok {
+					shared.layers++
+					shared.size += size
+				}
+			}
+			if shared.layers > 0 {
+				sharings = append(sharings, shared)
+			}
+		}
+	}
+	sort.Slice(sharings, func(i, j int) bool {
+		if sharings[i].size != sharings[j].size {
+			return sharings[i].size > sharings[j].size
+		}
+		return strings.Join(sharings[i].streams[:], ",") < strings.Join(sharings[j].streams[:], ",")
+	})
+
+	return usages, sharings
+}
+
+func humanSize(size int64) string {
+	return units.HumanSize(float64(size))
+}
+
+func printHumanReadable(out io.Writer, usages []streamUsage, sharings []sharing) {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "IMAGE STREAM\tIMAGES\tLAYERS\tTOTAL\tUNIQUE")
+	for _, usage := range usages {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", usage.name, usage.images, usage.layers, humanSize(usage.total), humanSize(usage.unique))
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	if len(sharings) == 0 {
+		fmt.Fprintln(out, "No layers are shared between the image streams.")
+		return
+	}
+	w = tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "SHARED BY\tLAYERS\tSIZE")
+	for _, shared := range sharings {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", strings.Join(shared.streams[:], ", "), shared.layers, humanSize(shared.size))
+	}
+	w.Flush()
+}
+
+func printDot(out io.Writer, usages []streamUsage, sharings []sharing) {
+	fmt.Fprintln(out, "graph \"layers\" {")
+	for _, usage := range usages {
+		label := fmt.Sprintf("%s\\n%s unique of %s", usage.name, humanSize(usage.unique), humanSize(usage.total))
+		fmt.Fprintf(out, "  %s [label=%s];\n", dotutil.Quote(usage.name), dotutil.Quote(label))
+	}
+	for _, shared := range sharings {
+		label := fmt.Sprintf("%d layers, %s", shared.layers, humanSize(shared.size))
+		fmt.Fprintf(out, "  %s -- %s [label=%s];\n", dotutil.Quote(shared.streams[0]), dotutil.Quote(shared.streams[1]), dotutil.Quote(label))
+	}
+	fmt.Fprintln(out, "}")
+}
@@ -0,0 +1,99 @@
+package layergraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeimageclient "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+func imageStream(name string, digests ...string) *imagev1.ImageStream {
+	stream := &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"}}
+	for i, digest := range digests {
+		stream.Status.Tags = append(stream.Status.Tags, imagev1.NamedTagEventList{
+			Tag:   string(rune('a' + i)),
+			Items: []imagev1.TagEvent{{Image: digest}},
+		})
+	}
+	return stream
+}
+
+func imageStreamImage(stream, digest string, layers ...imagev1.ImageLayer) *imagev1.ImageStreamImage {
+	return &imagev1.ImageStreamImage{
+		ObjectMeta: metav1.ObjectMeta{Name: stream + "@" + digest, Namespace: "test"},
+		Image: imagev1.Image{
+			ObjectMeta:        metav1.ObjectMeta{Name: digest},
+			DockerImageLayers: layers,
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	base := imagev1.ImageLayer{Name: "sha256:base", LayerSize: 100000000}
+	runtimeLayer := imagev1.ImageLayer{Name: "sha256:runtime", LayerSize: 50000000}
+
+	list := imageStreamImage("nodejs", "sha256:list")
+	list.Image.DockerImageManifests = []imagev1.ImageManifest{{Digest: "sha256:node-amd64"}, {Digest: "sha256:node-arm64"}}
+	objs := []runtime.Object{
+		imageStream("ruby", "sha256:ruby-1", "sha256:ruby-2"),
+		imageStream("nodejs", "sha256:list"),
+		imageStream("perl", "sha256:perl"),
+		imageStreamImage("ruby", "sha256:ruby-1", base, runtimeLayer),
+		imageStreamImage("ruby", "sha256:ruby-2", base, runtimeLayer, imagev1.ImageLayer{Name: "sha256:ruby", LayerSize: 20000000}),
+		list,
+		imageStreamImage("nodejs", "sha256:node-amd64", base, imagev1.ImageLayer{Name: "sha256:node-amd64", LayerSize: 30000000}),
+		imageStreamImage("nodejs", "sha256:node-arm64", imagev1.ImageLayer{Name: "sha256:node-arm64", LayerSize: 30000000}),
+		imageStreamImage("perl", "sha256:perl", imagev1.ImageLayer{Name: "sha256:perl", LayerSize: 10000000}),
+	}
+
+	for _, output := range []string{"", "dot"} {
+		out := &bytes.Buffer{}
+		o := &LayerGraphOptions{
+			All:         true,
+			Output:      output,
+			Namespace:   "test",
+			ImageClient: fakeimageclient.NewSimpleClientset(objs...).ImageV1(),
+			IOStreams:   genericiooptions.IOStreams{Out: out, ErrOut: out},
+		}
+		if err := o.Run(); err != nil {
+			t.Fatal(err)
+		}
+
+		var expected []string
+		switch output {
+		case "":
+			expected = []string{
+				"nodejs 2 3 160MB 60MB",
+				"perl 1 1 10MB 10MB",
+				"ruby 2 3 170MB 70MB",
+				"nodejs, ruby 1 100MB",
+			}
+		case "dot":
+			expected = []string{
+				`"ruby" [label="ruby\n70MB unique of 170MB"];`,
+				`"nodejs" -- "ruby" [label="1 layers, 100MB"];`,
+			}
+		}
+		lines := strings.Split(out.String(), "\n")
+		for _, e := range expected {
+			found := false
+			for _, line := range lines {
+				if strings.Join(strings.Fields(line), " ") == e {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected %q in output:\n%s", e, out.String())
+			}
+		}
+		if strings.Contains(out.String(), "perl, ") || strings.Contains(out.String(), `"perl" --`) {
+			t.Errorf("expected perl not to share any layer:\n%s", out.String())
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"github.com/spf13/pflag"
+
+	"k8s.io/component-base/logs"
+)
+
+// AddLogFlags adds the logging flags, along with --loglevel, an alias of --v,
+// to flags. Flags already added are left alone.
+func AddLogFlags(flags *pflag.FlagSet) {
+	logs.AddFlags(flags)
+	if vFlag := flags.Lookup("v"); vFlag != nil && flags.Lookup("loglevel") == nil {
+		flags.Var(&flagValueWrapper{vFlag.Value}, "loglevel", "Set the level of log output (0-10)")
+	}
+}
+
+// flagValueWrapper delegates all functionality to inner pflag.Value
+type flagValueWrapper struct {
+	inner pflag.Value
+}
+
+var _ pflag.Value = new(flagValueWrapper)
+
+func (l *flagValueWrapper) String() string {
+	return l.inner.String()
+}
+
+func (l *flagValueWrapper) Set(value string) error {
+	return l.inner.Set(value)
+}
+
+func (l *flagValueWrapper) Type() string {
+	return l.inner.Type()
+}
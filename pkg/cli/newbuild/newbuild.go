@@ -18,6 +18,7 @@ import (
 	buildv1 "github.com/openshift/api/build/v1"
 	ocnewapp "github.com/openshift/oc/pkg/cli/newapp"
 	configcmd "github.com/openshift/oc/pkg/helpers/bulk"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 	newapp "github.com/openshift/oc/pkg/helpers/newapp/app"
 	newcmd "github.com/openshift/oc/pkg/helpers/newapp/cmd"
 )
@@ -129,8 +130,7 @@ func NewCmdNewBuild(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	cmd.Flags().StringSliceVar(&o.Config.SourceRepositories, "code", o.Config.SourceRepositories, "Source code in the build configuration.")
 	cmd.Flags().StringSliceVarP(&o.Config.ImageStreams, "image-stream", "i", o.Config.ImageStreams, "Name of an image stream to to use as a builder.")
 	cmd.Flags().StringSliceVar(&o.Config.DockerImages, "image", o.Config.DockerImages, "Name of a container image to use as a builder.")
-	cmd.Flags().StringSliceVar(&o.Config.DockerImages, "docker-image", o.Config.DockerImages, "Name of a container image to use as a builder.")
-	cmd.Flags().MarkDeprecated("docker-image", "Deprecated flag use --image")
+	kcmdutil.CheckErr(cmdutil.DeprecatedFlagAlias(cmd.Flags(), "docker-image", cmdutil.Deprecation{Replacement: "image"}))
 	cmd.Flags().StringSliceVar(&o.Config.ConfigMaps, "build-config-map", o.Config.ConfigMaps, "ConfigMap and destination to use as an input for the build.")
 	cmd.Flags().StringSliceVar(&o.Config.Secrets, "build-secret", o.Config.Secrets, "Secret and destination to use as an input for the build.")
 	cmd.Flags().StringVar(&o.Config.SourceSecret, "source-secret", o.Config.SourceSecret, "The name of an existing secret that should be used for cloning a private git repository.")
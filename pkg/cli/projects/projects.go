@@ -24,6 +24,7 @@ import (
 
 type ProjectsOptions struct {
 	Config      clientcmdapi.Config
+	Context     cliconfig.Context
 	RESTConfig  *rest.Config
 	Client      projectv1client.ProjectV1Interface
 	KubeClient  corev1client.CoreV1Interface
@@ -97,6 +98,11 @@ func (o *ProjectsOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args
 	if err != nil {
 		return err
 	}
+	o.Context, err = cliconfig.CurrentContext(f)
+	if err != nil {
+		return err
+	}
+	o.Config.CurrentContext = o.Context.Name
 	o.RESTConfig, err = f.ToRESTConfig()
 	if err != nil {
 		return err
@@ -143,7 +149,7 @@ func (o ProjectsOptions) Run() error {
 
 	var defaultContextName string
 	if currentContext != nil {
-		defaultContextName = cliconfig.GetContextNickname(currentContext.Namespace, currentContext.Cluster, currentContext.AuthInfo)
+		defaultContextName = cliconfig.GetContextNickname(currentContext.Namespace, o.Context.Cluster, o.Context.User)
 	}
 
 	var msg string
@@ -119,7 +119,9 @@ func (i *RegistryInfo) HostPort() (string, bool) {
 	return i.Internal, false
 }
 
-func findRegistryInfo(client imageclient.Interface, namespaces ...string) (*RegistryInfo, error) {
+// FindRegistryInfo returns the hostnames of the integrated registry, as
+// recorded in the first image stream found in namespaces.
+func FindRegistryInfo(client imageclient.Interface, namespaces ...string) (*RegistryInfo, error) {
 	for _, ns := range namespaces {
 		imageStreams, err := client.ImageV1().ImageStreams(ns).List(context.TODO(), metav1.ListOptions{})
 		if err != nil || len(imageStreams.Items) == 0 {
@@ -158,7 +160,7 @@ func (o *Options) Validate() error {
 }
 
 func (o *Options) Run() error {
-	info, err := findRegistryInfo(o.Client, o.Namespaces...)
+	info, err := FindRegistryInfo(o.Client, o.Namespaces...)
 	if err != nil {
 		return err
 	}
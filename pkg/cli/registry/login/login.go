@@ -27,6 +27,7 @@ import (
 	imageclient "github.com/openshift/client-go/image/clientset/versioned"
 	"github.com/openshift/library-go/pkg/image/reference"
 	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/image/manifest/dockercredentials"
 	"github.com/openshift/oc/pkg/helpers/image"
 )
 
@@ -43,7 +44,9 @@ var (
 		with USER:PASSWORD.
 
 		You may specify an alternate file to write credentials to with --to instead of
-		.docker/config.json in your home directory.
+		.docker/config.json in your home directory, or store them in the keychain of your
+		system with --credential-helper, which runs the docker-credential-<name> executable
+		of the given docker credential helper.
 
 		To detect the registry hostname the client will attempt to find an image stream in
 		the current namespace or the openshift namespace and use the status fields that
@@ -63,6 +66,9 @@ var (
 
 		# Log in to different registry using BASIC auth credentials
 		oc registry login --registry quay.io/myregistry --auth-basic=USER:PASS
+
+		# Log in to the integrated registry, storing the credentials in the macOS keychain
+		oc registry login --credential-helper=osxkeychain
 	`)
 )
 
@@ -85,11 +91,12 @@ func (c Credentials) Empty() bool {
 }
 
 type LoginOptions struct {
-	ConfigFile  string
-	Credentials Credentials
-	HostPort    string
-	SkipCheck   bool
-	Insecure    bool
+	ConfigFile       string
+	CredentialHelper string
+	Credentials      Credentials
+	HostPort         string
+	SkipCheck        bool
+	Insecure         bool
 
 	AuthBasic      string
 	ServiceAccount string
@@ -125,6 +132,7 @@ func NewRegistryLoginCmd(f kcmdutil.Factory, streams genericiooptions.IOStreams)
 	flag.StringVarP(&o.ConfigFile, "registry-config", "a", o.ConfigFile, "The location of the file your credentials will be stored in. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Defaults to ${XDG_RUNTIME_DIR}/containers/auth.json or /run/containers/${UID}/auth.json. Default can be changed via the REGISTRY_AUTH_PREFERENCE env variable (deprecated) to a \"docker\" value to prioritizes Docker credentials over Podman's.")
 	// TODO: remove REGISTRY_AUTH_PREFERENCE env variable support and support only podman in 4.15
 	flag.StringVar(&o.ConfigFile, "to", o.ConfigFile, "The location of the file your credentials will be stored in. Alternatively REGISTRY_AUTH_FILE env variable can be also specified. Defaults to ${XDG_RUNTIME_DIR}/containers/auth.json or /run/containers/${UID}/auth.json. Default can be changed via the REGISTRY_AUTH_PREFERENCE env variable (deprecated) to a \"docker\" value to prioritizes Docker credentials over Podman's.")
+	flag.StringVar(&o.CredentialHelper, "credential-helper", o.CredentialHelper, "Name of a docker credential helper, such as osxkeychain, secretservice, wincred or pass, to store the credentials with instead of a file. The docker-credential-<name> executable must be in your PATH.")
 	flag.StringVarP(&o.ServiceAccount, "service-account", "z", o.ServiceAccount, "Log in as the specified service account name in the specified namespace.")
 	flag.MarkDeprecated("service-account", "and will be removed in the future version. Use oc create token instead.")
 	flag.StringVar(&o.HostPort, "registry", o.HostPort, "An alternate domain name and port to use for the registry, defaults to the cluster's configured external hostname.")
@@ -233,7 +241,7 @@ func (o *LoginOptions) Complete(f kcmdutil.Factory, args []string) error {
 		}
 	}
 
-	if len(o.ConfigFile) == 0 {
+	if len(o.ConfigFile) == 0 && len(o.CredentialHelper) == 0 {
 		if authFile := os.Getenv("REGISTRY_AUTH_FILE"); authFile != "" {
 			o.ConfigFile = authFile
 		} else {
@@ -277,6 +285,9 @@ func (o *LoginOptions) Validate() error {
 	if o.Credentials.Empty() {
 		return fmt.Errorf("Unable to determine registry credentials, please log into the cluster.")
 	}
+	if len(o.CredentialHelper) > 0 && len(o.ConfigFile) > 0 {
+		return fmt.Errorf("--credential-helper can't be combined with --registry-config")
+	}
 	return nil
 }
 
@@ -296,6 +307,14 @@ func (o *LoginOptions) Run() error {
 		}
 	}
 
+	if len(o.CredentialHelper) > 0 {
+		if err := dockercredentials.StoreInHelper(o.CredentialHelper, o.HostPort, o.Credentials.Username, o.Credentials.Password); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.Out, "Saved credentials for %s with %s%s\n", o.HostPort, dockercredentials.HelperPrefix, o.CredentialHelper)
+		return nil
+	}
+
 	ctx := &containertypes.SystemContext{AuthFilePath: o.ConfigFile}
 	credentialLocation, err := dockerconfig.SetCredentials(ctx, o.HostPort, o.Credentials.Username, o.Credentials.Password)
 	if err != nil {
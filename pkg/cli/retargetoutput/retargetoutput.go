@@ -0,0 +1,235 @@
+package retargetoutput
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/client/paging"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	retargetOutputLong = templates.LongDesc(`
+		Rewrite the output image stream tags of build configs in bulk.
+
+		The build configs of the current namespace, or of all namespaces with --all-namespaces,
+		matching --selector are updated so that the images they build are pushed elsewhere:
+		with --to-namespace, to the image stream of the same name in that namespace, and with
+		--tag-format, to a tag named after the format, where {tag} stands for the current tag.
+		--from-namespace limits the rewrite to the outputs pushed to that namespace. Build
+		configs pushing to a docker image reference are left alone.
+
+		By default, the command performs a dry run printing a unified diff of every build config
+		that would be updated. Add --confirm to update them.
+	`)
+
+	retargetOutputExample = templates.Examples(`
+		# Show how the build configs of the current namespace pushing to team-a would push to team-b
+		oc ex retarget-output --from-namespace=team-a --to-namespace=team-b
+
+		# Push the images of the build configs labeled app=web to <tag>-rc tags
+		oc ex retarget-output -l app=web --tag-format={tag}-rc --confirm
+
+		# Move the outputs of the build configs of all namespaces from staging to production
+		oc ex retarget-output -A --from-namespace=staging --to-namespace=production --confirm
+	`)
+)
+
+// tagPattern is the pattern the tags the outputs are retargeted to must match.
+var tagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+// RetargetOutputOptions contains all the options needed to rewrite the outputs of build configs
+type RetargetOutputOptions struct {
+	Namespace     string
+	AllNamespaces bool
+	Selector      string
+	FromNamespace string
+	ToNamespace   string
+	TagFormat     string
+	Confirm       bool
+	ChunkSize     int64
+
+	BuildClient buildv1client.BuildV1Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewRetargetOutputOptions(streams genericiooptions.IOStreams) *RetargetOutputOptions {
+	return &RetargetOutputOptions{
+		ChunkSize: paging.DefaultChunkSize,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRetargetOutput implements the OpenShift experimental retarget-output command
+func NewCmdRetargetOutput(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewRetargetOutputOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "retarget-output",
+		Short:   "Rewrite the output image stream tags of build configs in bulk",
+		Long:    retargetOutputLong,
+		Example: retargetOutputExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, rewrite the outputs of the build configs of all namespaces.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) the build configs must match.")
+	cmd.Flags().StringVar(&o.FromNamespace, "from-namespace", o.FromNamespace, "If set, only rewrite the outputs pushed to image streams of this namespace.")
+	cmd.Flags().StringVar(&o.ToNamespace, "to-namespace", o.ToNamespace, "If set, push the outputs to the image streams of the same name in this namespace.")
+	cmd.Flags().StringVar(&o.TagFormat, "tag-format", o.TagFormat, "If set, push the outputs to tags named after this format, where {tag} stands for the current tag, e.g. {tag}-rc.")
+	cmd.Flags().BoolVar(&o.Confirm, "confirm", o.Confirm, "If true, update the build configs. Defaults to false, printing the diffs of the build configs that would be updated.")
+	kcmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
+
+	return cmd
+}
+
+func (o *RetargetOutputOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.BuildClient, err = buildv1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *RetargetOutputOptions) Validate() error {
+	if len(o.ToNamespace) == 0 && len(o.TagFormat) == 0 {
+		return fmt.Errorf("at least one of --to-namespace or --tag-format is required")
+	}
+	for flag, namespace := range map[string]string{"--from-namespace": o.FromNamespace, "--to-namespace": o.ToNamespace} {
+		if len(namespace) == 0 {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return fmt.Errorf("%s must be a valid namespace: %s", flag, strings.Join(errs, ", "))
+		}
+	}
+	if len(o.TagFormat) > 0 && !tagPattern.MatchString(formatTag(o.TagFormat, imageutil.DefaultImageTag)) {
+		return fmt.Errorf("--tag-format must produce valid tags, made of letters, digits, '_', '.' and '-': %s", o.TagFormat)
+	}
+	if _, err := labels.Parse(o.Selector); err != nil {
+		return fmt.Errorf("invalid --selector: %v", err)
+	}
+	return nil
+}
+
+func (o *RetargetOutputOptions) Run() error {
+	ctx := context.TODO()
+	buildConfigs, err := paging.BuildV1(o.BuildClient, o.ChunkSize).BuildConfigs(o.Namespace).List(ctx, metav1.ListOptions{LabelSelector: o.Selector})
+	if err != nil {
+		return err
+	}
+
+	updated := []*buildv1.BuildConfig{}
+	for i := range buildConfigs.Items {
+		bc := &buildConfigs.Items[i]
+		retargeted, ok := o.retarget(bc)
+		if !ok {
+			continue
+		}
+		before, err := json.Marshal(bc)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(retargeted)
+		if err != nil {
+			return err
+		}
+		name := "buildconfigs/" + bc.Name
+		if o.AllNamespaces {
+			name = bc.Namespace + "/" + name
+		}
+		if err := cmdutil.PrintDiff(o.Out, name, before, after); err != nil {
+			return err
+		}
+		updated = append(updated, retargeted)
+	}
+
+	if len(updated) == 0 {
+		fmt.Fprintln(o.ErrOut, "No build config outputs to retarget.")
+		return nil
+	}
+	if !o.Confirm {
+		fmt.Fprintln(o.ErrOut, "Dry run enabled - no modifications will be made. Add --confirm to update build configs")
+		return nil
+	}
+	for _, bc := range updated {
+		if _, err := o.BuildClient.BuildConfigs(bc.Namespace).Update(ctx, bc, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("unable to retarget the output of build config %q in %q: %v", bc.Name, bc.Namespace, err)
+		}
+	}
+	fmt.Fprintf(o.ErrOut, "info: Retargeted the outputs of %d build configs\n", len(updated))
+	return nil
+}
+
+// retarget returns a copy of bc pushing to the image stream tag its output is
+// rewritten to, or false if it doesn't push to an image stream tag of
+// --from-namespace or its output is left unchanged.
+func (o *RetargetOutputOptions) retarget(bc *buildv1.BuildConfig) (*buildv1.BuildConfig, bool) {
+	to := bc.Spec.Output.To
+	if to == nil || to.Kind != "ImageStreamTag" {
+		return nil, false
+	}
+	namespace := to.Namespace
+	if len(namespace) == 0 {
+		namespace = bc.Namespace
+	}
+	if len(o.FromNamespace) > 0 && namespace != o.FromNamespace {
+		return nil, false
+	}
+
+	stream, tag, _ := imageutil.SplitImageStreamTag(to.Name)
+	name := to.Name
+	if len(o.TagFormat) > 0 {
+		name = imageutil.JoinImageStreamTag(stream, formatTag(o.TagFormat, tag))
+	}
+	targetNamespace := to.Namespace
+	if len(o.ToNamespace) > 0 && o.ToNamespace != namespace {
+		targetNamespace = o.ToNamespace
+	}
+	if name == to.Name && targetNamespace == to.Namespace {
+		return nil, false
+	}
+
+	retargeted := bc.DeepCopy()
+	retargeted.Spec.Output.To.Name = name
+	retargeted.Spec.Output.To.Namespace = targetNamespace
+	return retargeted, true
+}
+
+// formatTag returns the tag named after format, replacing {tag} with tag.
+func formatTag(format, tag string) string {
+	return strings.ReplaceAll(format, "{tag}", tag)
+}
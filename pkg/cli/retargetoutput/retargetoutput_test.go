@@ -0,0 +1,201 @@
+package retargetoutput
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+)
+
+func newBuildConfig(name string, labels map[string]string, to *corev1.ObjectReference) *buildv1.BuildConfig {
+	bc := &buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name, Labels: labels}}
+	bc.Spec.Output.To = to
+	return bc
+}
+
+func TestRetarget(t *testing.T) {
+	tests := []struct {
+		name          string
+		fromNamespace string
+		toNamespace   string
+		tagFormat     string
+		to            *corev1.ObjectReference
+		expected      *corev1.ObjectReference
+	}{
+		{
+			name:        "moves an output of the namespace of the build config",
+			toNamespace: "other",
+			to:          &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+			expected:    &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "other", Name: "app:latest"},
+		},
+		{
+			name:          "moves an output of --from-namespace",
+			fromNamespace: "shared",
+			toNamespace:   "other",
+			to:            &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "shared", Name: "app:latest"},
+			expected:      &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "other", Name: "app:latest"},
+		},
+		{
+			name:          "leaves the outputs of other namespaces",
+			fromNamespace: "shared",
+			toNamespace:   "other",
+			to:            &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+		},
+		{
+			name:      "renames the tag",
+			tagFormat: "{tag}-rc",
+			to:        &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "shared", Name: "app:v1"},
+			expected:  &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "shared", Name: "app:v1-rc"},
+		},
+		{
+			name:      "renames the default tag",
+			tagFormat: "stable",
+			to:        &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app"},
+			expected:  &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:stable"},
+		},
+		{
+			name:        "leaves outputs already pushed to --to-namespace",
+			toNamespace: "test",
+			to:          &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"},
+		},
+		{
+			name:        "leaves docker image outputs",
+			toNamespace: "other",
+			to:          &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/test/app:latest"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			o := &RetargetOutputOptions{FromNamespace: test.fromNamespace, ToNamespace: test.toNamespace, TagFormat: test.tagFormat}
+			bc := newBuildConfig("app", nil, test.to)
+			retargeted, ok := o.retarget(bc)
+			if test.expected == nil {
+				if ok {
+					t.Errorf("expected the output to be left unchanged, got %#v", retargeted.Spec.Output.To)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected the output to be retargeted")
+			}
+			if *retargeted.Spec.Output.To != *test.expected {
+				t.Errorf("expected %#v, got %#v", test.expected, retargeted.Spec.Output.To)
+			}
+			if *bc.Spec.Output.To != *test.to {
+				t.Errorf("expected the build config to be left untouched, got %#v", bc.Spec.Output.To)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	newOptions := func(confirm bool) (*RetargetOutputOptions, *buildfake.Clientset, *bytes.Buffer, *bytes.Buffer) {
+		fakeClient := buildfake.NewSimpleClientset(
+			newBuildConfig("web", map[string]string{"app": "web"}, &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "team-a", Name: "web:latest"}),
+			newBuildConfig("api", map[string]string{"app": "api"}, &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "team-a", Name: "api:latest"}),
+			newBuildConfig("docs", map[string]string{"app": "web"}, &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "docs:latest"}),
+		)
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		o := NewRetargetOutputOptions(genericiooptions.IOStreams{Out: out, ErrOut: errOut})
+		o.Namespace = "test"
+		o.Selector = "app=web"
+		o.FromNamespace = "team-a"
+		o.ToNamespace = "team-b"
+		o.Confirm = confirm
+		o.BuildClient = fakeClient.BuildV1()
+		return o, fakeClient, out, errOut
+	}
+
+	o, fakeClient, out, errOut := newOptions(false)
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"--- a/buildconfigs/web", "+++ b/buildconfigs/web", "-      namespace: team-a", "+      namespace: team-b"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the diff:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out.String(), "buildconfigs/api") || strings.Contains(out.String(), "buildconfigs/docs") {
+		t.Errorf("expected only web to be retargeted:\n%s", out)
+	}
+	if !strings.Contains(errOut.String(), "Dry run enabled") {
+		t.Errorf("expected a dry run, got %q", errOut)
+	}
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() == "update" {
+			t.Errorf("expected no update during a dry run, got %#v", action)
+		}
+	}
+
+	o, fakeClient, _, errOut = newOptions(true)
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	web, err := fakeClient.BuildV1().BuildConfigs("test").Get(context.TODO(), "web", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if web.Spec.Output.To.Namespace != "team-b" || web.Spec.Output.To.Name != "web:latest" {
+		t.Errorf("expected web to push to team-b/web:latest, got %#v", web.Spec.Output.To)
+	}
+	if !strings.Contains(errOut.String(), "Retargeted the outputs of 1 build configs") {
+		t.Errorf("unexpected output %q", errOut)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  RetargetOutputOptions
+		expected string
+	}{
+		{
+			name:     "nothing to rewrite",
+			options:  RetargetOutputOptions{FromNamespace: "team-a"},
+			expected: "at least one of --to-namespace or --tag-format is required",
+		},
+		{
+			name:     "invalid namespace",
+			options:  RetargetOutputOptions{ToNamespace: "Team_B"},
+			expected: "--to-namespace must be a valid namespace",
+		},
+		{
+			name:     "invalid tag format",
+			options:  RetargetOutputOptions{TagFormat: "{tag}:rc"},
+			expected: "--tag-format must produce valid tags",
+		},
+		{
+			name:     "invalid selector",
+			options:  RetargetOutputOptions{ToNamespace: "team-b", Selector: "app in"},
+			expected: "invalid --selector",
+		},
+		{
+			name:    "valid",
+			options: RetargetOutputOptions{FromNamespace: "team-a", ToNamespace: "team-b", TagFormat: "{tag}-rc", Selector: "app=web"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.options.Validate()
+			if len(test.expected) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+				t.Errorf("expected an error starting with %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package routegraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	dotutil "github.com/openshift/oc/pkg/helpers/dot"
+	appsedges "github.com/openshift/oc/pkg/helpers/graph/appsgraph"
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+	kubeedges "github.com/openshift/oc/pkg/helpers/graph/kubegraph"
+	kubegraph "github.com/openshift/oc/pkg/helpers/graph/kubegraph/nodes"
+	routeedges "github.com/openshift/oc/pkg/helpers/graph/routegraph"
+	routenodes "github.com/openshift/oc/pkg/helpers/graph/routegraph/nodes"
+)
+
+var (
+	routeGraphLong = templates.LongDesc(`
+		Output the services, deployment configs and image stream tags behind your routes.
+
+		Every route is followed to the services it sends traffic to, the deployment configs
+		whose pods these services select and the image stream tags that trigger these
+		deployment configs, so that an ingress can be traced back to the image it serves.
+		Services referenced but not found, e.g. the service of a route that was deleted, are
+		shown as missing.
+
+		Supported formats for the generated graph are dot, json and mermaid, as in build-chain,
+		and a human-readable output. Pipe your dot output to graphviz to create an image file.
+	`)
+
+	routeGraphExample = templates.Examples(`
+		# Trace every route of the current project to its images
+		oc ex route-graph
+
+		# Trace the route 'frontend' only
+		oc ex route-graph frontend
+
+		# Output the graph of the routes in DOT format
+		oc ex route-graph -o dot | dot -T svg -o routes.svg
+	`)
+)
+
+// mermaidEscaper replaces the characters that end a Mermaid label with their
+// entity codes.
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "#", "#35;")
+
+// Node is an object of a route graph.
+type Node struct {
+	// ID is unique in the graph, e.g. Route|myproject/frontend.
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Missing is set for services that are referenced but don't exist.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Edge is a relationship between two nodes of a route graph, from the route
+// towards the images.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RouteGraph is the graph of the routes of a project, with its nodes and
+// edges sorted by ID.
+type RouteGraph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// RouteGraphOptions contains all the options needed to graph routes
+type RouteGraphOptions struct {
+	Namespace string
+	Names     []string
+	Output    string
+
+	RouteClient routev1client.RouteV1Interface
+	CoreClient  corev1client.CoreV1Interface
+	AppsClient  appsv1client.AppsV1Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewRouteGraphOptions(streams genericiooptions.IOStreams) *RouteGraphOptions {
+	return &RouteGraphOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRouteGraph implements the OpenShift experimental route-graph command
+func NewCmdRouteGraph(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewRouteGraphOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "route-graph [ROUTE...]",
+		Short:   "Output the services, deployment configs and images behind your routes",
+		Long:    routeGraphLong,
+		Example: routeGraphExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "Output format of the graph. One of: (dot, json, mermaid)")
+
+	return cmd
+}
+
+func (o *RouteGraphOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	for _, arg := range args {
+		name := arg
+		if resource, n, ok := strings.Cut(arg, "/"); ok {
+			switch resource {
+			case "route", "routes":
+			default:
+				return kcmdutil.UsageErrorf(cmd, "only routes can be graphed, got %q", resource)
+			}
+			name = n
+		}
+		o.Names = append(o.Names, name)
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.RouteClient, err = routev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.CoreClient, err = corev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.AppsClient, err = appsv1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *RouteGraphOptions) Validate() error {
+	switch o.Output {
+	case "", "dot", "json", "mermaid":
+	default:
+		return fmt.Errorf("output must be either empty, 'dot', 'json' or 'mermaid'")
+	}
+	for _, name := range o.Names {
+		if len(name) == 0 {
+			return fmt.Errorf("route name cannot be empty")
+		}
+	}
+	return nil
+}
+
+func (o *RouteGraphOptions) Run() error {
+	rg, err := o.graph(context.TODO())
+	if err != nil {
+		return err
+	}
+	if len(rg.Nodes) == 0 {
+		fmt.Fprintf(o.ErrOut, "No routes found in %s namespace.\n", o.Namespace)
+		return nil
+	}
+
+	switch o.Output {
+	case "dot":
+		fmt.Fprintln(o.Out, rg.dot(o.Namespace))
+	case "json":
+		data, err := json.MarshalIndent(rg, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.Out, string(data))
+	case "mermaid":
+		fmt.Fprintln(o.Out, rg.mermaid(o.Namespace))
+	default:
+		fmt.Fprintln(o.Out, rg.humanReadable(o.Namespace))
+	}
+	return nil
+}
+
+// graph lists the routes, services and deployment configs of the namespace
+// and returns the route graph of the requested routes.
+func (o *RouteGraphOptions) graph(ctx context.Context) (*RouteGraph, error) {
+	routes, err := o.RouteClient.Routes(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	services, err := o.CoreClient.Services(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	dcs, err := o.AppsClient.DeploymentConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	g := osgraph.New()
+	wanted := sets.New[string](o.Names...)
+	found := sets.New[string]()
+	for i := range routes.Items {
+		if wanted.Len() > 0 && !wanted.Has(routes.Items[i].Name) {
+			continue
+		}
+		found.Insert(routes.Items[i].Name)
+		routenodes.EnsureRouteNode(g, &routes.Items[i])
+	}
+	if missing := wanted.Difference(found); missing.Len() > 0 {
+		return nil, fmt.Errorf("routes not found: %s", strings.Join(sets.List(missing), ", "))
+	}
+	for i := range services.Items {
+		kubegraph.EnsureServiceNode(g, &services.Items[i])
+	}
+	for i := range dcs.Items {
+		appsgraph.EnsureDeploymentConfigNode(g, &dcs.Items[i])
+	}
+	osgraph.AddEdges(g,
+		routeedges.RouteProvider{},
+		osgraph.EdgeProviderFunc(kubeedges.AddAllExposedPodTemplateSpecEdges),
+		appsedges.DeploymentTriggerProvider{},
+	)
+
+	return trace(g), nil
+}
+
+// trace returns the route graph of the routes of g, skipping the pod
+// templates between services and deployment configs and the objects that
+// aren't reached from a route.
+func trace(g osgraph.Graph) *RouteGraph {
+	rg := &RouteGraph{Nodes: []Node{}, Edges: []Edge{}}
+	seen := map[int]bool{}
+	visit := func(n graph.Node) string {
+		node := newNode(n)
+		if !seen[n.ID()] {
+			seen[n.ID()] = true
+			rg.Nodes = append(rg.Nodes, node)
+		}
+		return node.ID
+	}
+	edges := sets.New[Edge]()
+	for _, route := range g.NodesByKind(routenodes.RouteNodeKind) {
+		routeID := visit(route)
+		for _, e := range g.OutboundEdges(route, routeedges.ExposedThroughRouteEdgeKind) {
+			svc := e.To()
+			edges.Insert(Edge{From: routeID, To: visit(svc)})
+			for _, exposed := range g.InboundEdges(svc, kubeedges.ExposedThroughServiceEdgeKind) {
+				for _, contains := range g.InboundEdges(exposed.From(), osgraph.ContainsEdgeKind) {
+					dc, ok := contains.From().(*appsgraph.DeploymentConfigNode)
+					if !ok {
+						continue
+					}
+					edges.Insert(Edge{From: newNode(svc).ID, To: visit(dc)})
+					for _, trigger := range g.InboundEdges(dc, appsedges.TriggersDeploymentEdgeKind) {
+						if _, ok := trigger.From().(*imagegraph.ImageStreamTagNode); !ok {
+							continue
+						}
+						edges.Insert(Edge{From: newNode(dc).ID, To: visit(trigger.From())})
+					}
+				}
+			}
+		}
+	}
+
+	rg.Edges = edges.UnsortedList()
+	sort.Slice(rg.Nodes, func(i, j int) bool { return rg.Nodes[i].ID < rg.Nodes[j].ID })
+	sort.Slice(rg.Edges, func(i, j int) bool {
+		if rg.Edges[i].From != rg.Edges[j].From {
+			return rg.Edges[i].From < rg.Edges[j].From
+		}
+		return rg.Edges[i].To < rg.Edges[j].To
+	})
+	return rg
+}
+
+// newNode returns the route graph node of n, one of the nodes added by graph.
+func newNode(n graph.Node) Node {
+	node := Node{ID: n.(fmt.Stringer).String()}
+	switch t := n.(type) {
+	case *routenodes.RouteNode:
+		node.Kind, node.Namespace, node.Name = routenodes.RouteNodeKind, t.Namespace, t.Name
+	case *kubegraph.ServiceNode:
+		node.Kind, node.Namespace, node.Name, node.Missing = kubegraph.ServiceNodeKind, t.Namespace, t.Name, !t.Found()
+	case *appsgraph.DeploymentConfigNode:
+		node.Kind, node.Namespace, node.Name = appsgraph.DeploymentConfigNodeKind, t.DeploymentConfig.Namespace, t.DeploymentConfig.Name
+	case *imagegraph.ImageStreamTagNode:
+		node.Kind, node.Namespace, node.Name = imagegraph.ImageStreamTagNodeKind, t.Namespace, t.Name
+	}
+	return node
+}
+
+// label returns the name of n as used on the command line, qualified with its
+// namespace when it isn't ns.
+func (n Node) label(ns string) string {
+	resource := map[string]string{
+		routenodes.RouteNodeKind:           "route",
+		kubegraph.ServiceNodeKind:          "svc",
+		appsgraph.DeploymentConfigNodeKind: "dc",
+		imagegraph.ImageStreamTagNodeKind:  "istag",
+	}[n.Kind]
+	name := n.Name
+	if n.Namespace != ns {
+		name = n.Namespace + "/" + name
+	}
+	label := resource + "/" + name
+	if n.Missing {
+		label += " (missing)"
+	}
+	return label
+}
+
+// humanReadable returns a tree for each route of rg.
+func (rg *RouteGraph) humanReadable(ns string) string {
+	nodes := map[string]Node{}
+	for _, n := range rg.Nodes {
+		nodes[n.ID] = n
+	}
+	children := map[string][]string{}
+	for _, e := range rg.Edges {
+		children[e.From] = append(children[e.From], e.To)
+	}
+
+	var lines []string
+	var walk func(id string, depth int)
+	walk = func(id string, depth int) {
+		lines = append(lines, strings.Repeat("\t", depth)+nodes[id].label(ns))
+		for _, child := range children[id] {
+			walk(child, depth+1)
+		}
+	}
+	trees := []string{}
+	for _, n := range rg.Nodes {
+		if n.Kind != routenodes.RouteNodeKind {
+			continue
+		}
+		lines = nil
+		walk(n.ID, 0)
+		trees = append(trees, strings.Join(lines, "\n"))
+	}
+	return strings.Join(trees, "\n\n")
+}
+
+// dot returns rg in DOT format, with missing services dashed.
+func (rg *RouteGraph) dot(ns string) string {
+	lines := []string{fmt.Sprintf("digraph %s {", dotutil.Quote(ns))}
+	for _, n := range rg.Nodes {
+		attrs := fmt.Sprintf("label=%s", dotutil.Quote(n.label(ns)))
+		if n.Missing {
+			attrs += " style=dashed"
+		}
+		lines = append(lines, fmt.Sprintf("  %s [%s];", dotutil.Quote(n.ID), attrs))
+	}
+	for _, e := range rg.Edges {
+		lines = append(lines, fmt.Sprintf("  %s -> %s;", dotutil.Quote(e.From), dotutil.Quote(e.To)))
+	}
+	return strings.Join(append(lines, "}"), "\n")
+}
+
+// mermaid returns rg as a Mermaid flowchart, with routes as stadiums, image
+// stream tags as rounded boxes and missing services dashed.
+func (rg *RouteGraph) mermaid(ns string) string {
+	lines := []string{"flowchart LR"}
+	ids := map[string]string{}
+	missing := []string{}
+	for i, n := range rg.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.ID] = id
+		label := mermaidEscaper.Replace(n.label(ns))
+		switch n.Kind {
+		case routenodes.RouteNodeKind:
+			lines = append(lines, fmt.Sprintf("  %s([\"%s\"])", id, label))
+		case imagegraph.ImageStreamTagNodeKind:
+			lines = append(lines, fmt.Sprintf("  %s(\"%s\")", id, label))
+		default:
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", id, label))
+		}
+		if n.Missing {
+			missing = append(missing, id)
+		}
+	}
+	for _, e := range rg.Edges {
+		lines = append(lines, fmt.Sprintf("  %s --> %s", ids[e.From], ids[e.To]))
+	}
+	if len(missing) > 0 {
+		lines = append(lines, "  classDef missing stroke-dasharray:5 5", fmt.Sprintf("  class %s missing", strings.Join(missing, ",")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,185 @@
+package routegraph
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	appsfake "github.com/openshift/client-go/apps/clientset/versioned/fake"
+	routefake "github.com/openshift/client-go/route/clientset/versioned/fake"
+)
+
+func newRoute(name, service string) *routev1.Route {
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec:       routev1.RouteSpec{To: routev1.RouteTargetReference{Kind: "Service", Name: service}},
+	}
+}
+
+func newService(name string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": name}},
+	}
+}
+
+func newDeploymentConfig(name, from string) *appsv1.DeploymentConfig {
+	return &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: appsv1.DeploymentConfigSpec{
+			Template: &corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "image"}}},
+			},
+			Triggers: []appsv1.DeploymentTriggerPolicy{{
+				Type: appsv1.DeploymentTriggerOnImageChange,
+				ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{
+					ContainerNames: []string{"web"},
+					From:           corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+				},
+			}},
+		},
+	}
+}
+
+func TestRunRouteGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		output   string
+		expected string
+		err      string
+	}{
+		{
+			name: "human readable",
+			expected: `route/backend
+	svc/gone (missing)
+
+route/frontend
+	svc/frontend
+		dc/frontend
+			istag/frontend:latest
+`,
+		},
+		{
+			name:   "single route as json",
+			names:  []string{"frontend"},
+			output: "json",
+			expected: `{
+  "nodes": [
+    {
+      "id": "DeploymentConfig|test/frontend",
+      "kind": "DeploymentConfig",
+      "namespace": "test",
+      "name": "frontend"
+    },
+    {
+      "id": "ImageStreamTag|test/frontend:latest",
+      "kind": "ImageStreamTag",
+      "namespace": "test",
+      "name": "frontend:latest"
+    },
+    {
+      "id": "Route|test/frontend",
+      "kind": "Route",
+      "namespace": "test",
+      "name": "frontend"
+    },
+    {
+      "id": "Service|test/frontend",
+      "kind": "Service",
+      "namespace": "test",
+      "name": "frontend"
+    }
+  ],
+  "edges": [
+    {
+      "from": "DeploymentConfig|test/frontend",
+      "to": "ImageStreamTag|test/frontend:latest"
+    },
+    {
+      "from": "Route|test/frontend",
+      "to": "Service|test/frontend"
+    },
+    {
+      "from": "Service|test/frontend",
+      "to": "DeploymentConfig|test/frontend"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:   "dot",
+			names:  []string{"backend"},
+			output: "dot",
+			expected: `digraph "test" {
+  "Route|test/backend" [label="route/backend"];
+  "Service|test/gone" [label="svc/gone (missing)" style=dashed];
+  "Route|test/backend" -> "Service|test/gone";
+}
+`,
+		},
+		{
+			name:   "mermaid",
+			names:  []string{"backend"},
+			output: "mermaid",
+			expected: `flowchart LR
+  n0(["route/backend"])
+  n1["svc/gone (missing)"]
+  n0 --> n1
+  classDef missing stroke-dasharray:5 5
+  class n1 missing
+`,
+		},
+		{
+			name:  "unknown route",
+			names: []string{"frontend", "other"},
+			err:   "routes not found: other",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &RouteGraphOptions{
+				Namespace:   "test",
+				Names:       tc.names,
+				Output:      tc.output,
+				RouteClient: routefake.NewSimpleClientset(newRoute("frontend", "frontend"), newRoute("backend", "gone")).RouteV1(),
+				CoreClient:  kubefake.NewSimpleClientset(newService("frontend")).CoreV1(),
+				AppsClient:  appsfake.NewSimpleClientset(newDeploymentConfig("frontend", "frontend:latest"), newDeploymentConfig("unexposed", "other:latest")).AppsV1(),
+				IOStreams:   genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}},
+			}
+			err := o.Run()
+			if len(tc.err) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (&RouteGraphOptions{Output: "spdx"}).Validate(); err == nil {
+		t.Errorf("expected the spdx output to be rejected")
+	}
+	if err := (&RouteGraphOptions{Names: []string{""}}).Validate(); err == nil {
+		t.Errorf("expected an empty route name to be rejected")
+	}
+}
@@ -94,6 +94,7 @@ type EnvOptions struct {
 	List           bool
 	Local          bool
 	Overwrite      bool
+	ShowDiff       bool
 	DryRunStrategy kcmdutil.DryRunStrategy
 	FieldManager   string
 
@@ -154,6 +155,7 @@ func NewCmdEnv(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, select all resources in the namespace of the specified resource types")
 	cmd.Flags().BoolVar(&o.Overwrite, "overwrite", o.Overwrite, "If true, allow environment to be overwritten, otherwise reject updates that overwrite existing environment.")
 	cmd.Flags().StringVar(&o.ResourceVersion, "resource-version", o.ResourceVersion, "If non-empty, the labels update will only succeed if this is the current resource-version for the object. Only valid when specifying a single resource.")
+	cmd.Flags().BoolVar(&o.ShowDiff, "show-diff", o.ShowDiff, "If true, print a unified diff of each object before and after the change instead of the object.")
 
 	kcmdutil.AddDryRunFlag(cmd)
 	kcmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-set")
@@ -230,6 +232,9 @@ func (o *EnvOptions) Validate() error {
 	if o.List && o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0 {
 		return fmt.Errorf("--list and --output may not be specified together")
 	}
+	if o.ShowDiff && o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0 {
+		return fmt.Errorf("--show-diff and --output may not be specified together")
+	}
 	if o.Local && o.DryRunStrategy == kcmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
@@ -475,18 +480,23 @@ updates:
 			}
 		}
 
-		if o.Local || o.DryRunStrategy == kcmdutil.DryRunClient {
-			if err := o.Printer.PrintObj(info.Object, o.Out); err != nil {
-				allErrs = append(allErrs, err)
-			}
-			continue
-		}
-
 		newData, err := json.Marshal(infos[i].Object)
 		if err != nil {
 			allErrs = append(allErrs, err)
 			continue
 		}
+
+		if o.Local || o.DryRunStrategy == kcmdutil.DryRunClient {
+			if o.ShowDiff {
+				err = cmdutil.PrintDiff(o.Out, getObjectName(info), oldData[i], newData)
+			} else {
+				err = o.Printer.PrintObj(info.Object, o.Out)
+			}
+			if err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
 		patchBytes, err := strategicpatch.CreateTwoWayMergePatch(oldData[i], newData, infos[i].Object)
 		if err != nil {
 			allErrs = append(allErrs, err)
@@ -508,7 +518,12 @@ updates:
 			return fmt.Errorf("at least one environment variable must be provided")
 		}
 
-		if err := o.Printer.PrintObj(actual, o.Out); err != nil {
+		if o.ShowDiff {
+			err = printPatchedDiff(o.Out, getObjectName(info), oldData[i], actual)
+		} else {
+			err = o.Printer.PrintObj(actual, o.Out)
+		}
+		if err != nil {
 			allErrs = append(allErrs, err)
 		}
 	}
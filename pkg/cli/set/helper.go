@@ -1,7 +1,9 @@
 package set
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -9,6 +11,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/cli-runtime/pkg/resource"
+
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 )
 
 func selectContainers(containers []corev1.Container, spec string) ([]*corev1.Container, []*corev1.Container) {
@@ -137,6 +141,16 @@ func CalculatePatchesExternal(encoder runtime.Encoder, infos []*resource.Info, m
 	return patches
 }
 
+// printPatchedDiff prints the diff between the JSON of an object before it was
+// patched and the object the server returned.
+func printPatchedDiff(out io.Writer, name string, before []byte, actual runtime.Object) error {
+	after, err := json.Marshal(actual)
+	if err != nil {
+		return err
+	}
+	return cmdutil.PrintDiff(out, name, before, after)
+}
+
 func getObjectName(info *resource.Info) string {
 	if info.Mapping != nil {
 		return fmt.Sprintf("%s/%s", info.Mapping.Resource.Resource, info.Name)
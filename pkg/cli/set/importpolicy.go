@@ -0,0 +1,281 @@
+package set
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/cli-runtime/pkg/resource"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+var (
+	importPolicyLong = templates.LongDesc(`
+		Set the import policy of the tags of image streams.
+
+		Scheduled tags are imported again periodically from their source, so that they follow
+		the changes of the image they point to. Insecure tags are imported over HTTP or HTTPS
+		without verifying certificates. Only the policies given as flags are changed, on every
+		tag of the image streams imported from another image, or on the tags given as
+		STREAMNAME:TAG.
+
+		Without any policy flag, or with --list, the import policies of the tags are displayed.`)
+
+	importPolicyExample = templates.Examples(`
+		# Print the import policies of the tags of all the image streams
+		oc ex set import-policy
+
+		# Import every tag of the image stream mysql periodically
+		oc ex set import-policy mysql --scheduled
+
+		# Stop importing the tag 8.0 of mysql periodically
+		oc ex set import-policy mysql:8.0 --scheduled=false
+
+		# Import the tags of the image streams labeled team=web periodically, over insecure connections
+		oc ex set import-policy -l team=web --scheduled --insecure`)
+)
+
+// ImportPolicyOptions contains all the options needed to set the import
+// policy of image stream tags
+type ImportPolicyOptions struct {
+	PrintFlags *genericclioptions.PrintFlags
+
+	Selector  string
+	All       bool
+	List      bool
+	Scheduled *bool
+	Insecure  *bool
+
+	Printer           printers.ResourcePrinter
+	Builder           func() *resource.Builder
+	Namespace         string
+	ExplicitNamespace bool
+	DryRunStrategy    kcmdutil.DryRunStrategy
+	FieldManager      string
+	// Tags are the tags to change by image stream, all of them when empty.
+	Tags map[string]sets.Set[string]
+
+	genericiooptions.IOStreams
+}
+
+func NewImportPolicyOptions(streams genericiooptions.IOStreams) *ImportPolicyOptions {
+	return &ImportPolicyOptions{
+		PrintFlags: genericclioptions.NewPrintFlags("import policy updated").WithTypeSetter(setCmdScheme),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdImportPolicy implements the set import-policy command
+func NewCmdImportPolicy(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewImportPolicyOptions(streams)
+	var scheduled, insecure bool
+	cmd := &cobra.Command{
+		Use:     "import-policy [STREAMNAME[:TAG] ...] [--scheduled] [--insecure]",
+		Short:   "Set the import policy of image stream tags",
+		Long:    importPolicyLong,
+		Example: importPolicyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if cmd.Flags().Changed("scheduled") {
+				o.Scheduled = &scheduled
+			}
+			if cmd.Flags().Changed("insecure") {
+				o.Insecure = &insecure
+			}
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) to filter on.")
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, select all image streams in the namespace.")
+	cmd.Flags().BoolVar(&o.List, "list", o.List, "Display the current import policies of the requested image stream tags.")
+	cmd.Flags().BoolVar(&scheduled, "scheduled", scheduled, "If true, import the tags periodically. If false, only import them when requested.")
+	cmd.Flags().BoolVar(&insecure, "insecure", insecure, "If true, import the tags over HTTP or HTTPS without verifying certificates.")
+
+	o.PrintFlags.AddFlags(cmd)
+	kcmdutil.AddDryRunFlag(cmd)
+	kcmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-set")
+
+	return cmd
+}
+
+// Complete takes command line information to fill out ImportPolicyOptions or returns an error.
+func (o *ImportPolicyOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	var err error
+	o.Namespace, o.ExplicitNamespace, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+
+	o.Tags = map[string]sets.Set[string]{}
+	for _, arg := range args {
+		stream, tag, hasTag := strings.Cut(arg, ":")
+		if len(stream) == 0 || (hasTag && len(tag) == 0) {
+			return kcmdutil.UsageErrorf(cmd, "image streams must be given as STREAMNAME or STREAMNAME:TAG, got %q", arg)
+		}
+		tags, ok := o.Tags[stream]
+		switch {
+		case !hasTag:
+			o.Tags[stream] = sets.New[string]()
+		case !ok:
+			o.Tags[stream] = sets.New(tag)
+		case tags.Len() > 0:
+			tags.Insert(tag)
+		}
+	}
+	if o.Scheduled == nil && o.Insecure == nil {
+		o.List = true
+	}
+
+	o.DryRunStrategy, err = kcmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.Builder = f.NewBuilder
+
+	kcmdutil.PrintFlagsWithDryRunStrategy(o.PrintFlags, o.DryRunStrategy)
+	o.Printer, err = o.PrintFlags.ToPrinter()
+	return err
+}
+
+func (o *ImportPolicyOptions) Validate() error {
+	if !o.List && len(o.Tags) == 0 && !o.All && len(o.Selector) == 0 {
+		return fmt.Errorf("image streams must be given as arguments, or with --all or --selector")
+	}
+	if len(o.Tags) > 0 && o.All {
+		return fmt.Errorf("image streams can't be given with --all")
+	}
+	return nil
+}
+
+// Run executes the ImportPolicyOptions or returns an error.
+func (o *ImportPolicyOptions) Run() error {
+	b := o.Builder().
+		WithScheme(setCmdScheme, setCmdScheme.PrioritizedVersionsAllGroups()...).
+		ContinueOnError().
+		NamespaceParam(o.Namespace).DefaultNamespace().
+		LabelSelectorParam(o.Selector).
+		Flatten()
+	if len(o.Tags) > 0 {
+		b = b.ResourceNames("imagestreams", sets.List(sets.KeySet(o.Tags))...).Latest()
+	} else {
+		b = b.SelectAllParam(true).ResourceTypes("imagestreams")
+	}
+
+	infos, err := b.Do().Infos()
+	if err != nil {
+		return err
+	}
+
+	if o.List {
+		return o.printImportPolicies(infos)
+	}
+
+	patches := CalculatePatchesExternal(setCmdJSONEncoder(), infos, func(info *resource.Info) (bool, error) {
+		stream, ok := info.Object.(*imagev1.ImageStream)
+		if !ok {
+			return true, fmt.Errorf("the resource %s does not have an import policy", getObjectName(info))
+		}
+		o.updateImportPolicies(stream)
+		return true, nil
+	})
+
+	allErrs := []error{}
+	for _, patch := range patches {
+		info := patch.Info
+		name := getObjectName(info)
+		if patch.Err != nil {
+			allErrs = append(allErrs, fmt.Errorf("error: %s %v\n", name, patch.Err))
+			continue
+		}
+
+		if string(patch.Patch) == "{}" || len(patch.Patch) == 0 {
+			klog.V(1).Infof("info: %s was not changed\n", name)
+			continue
+		}
+
+		if o.DryRunStrategy == kcmdutil.DryRunClient {
+			if err := o.Printer.PrintObj(info.Object, o.Out); err != nil {
+				allErrs = append(allErrs, err)
+			}
+			continue
+		}
+
+		actual, err := resource.NewHelper(info.Client, info.Mapping).
+			DryRun(o.DryRunStrategy == kcmdutil.DryRunServer).
+			WithFieldManager(o.FieldManager).
+			Patch(info.Namespace, info.Name, types.StrategicMergePatchType, patch.Patch, &metav1.PatchOptions{})
+		if err != nil {
+			allErrs = append(allErrs, fmt.Errorf("failed to patch import policy: %v\n", err))
+			continue
+		}
+
+		if err := o.Printer.PrintObj(actual, o.Out); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return utilerrors.NewAggregate(allErrs)
+}
+
+// selects returns whether the import policy of tag of the image stream named
+// stream is changed or listed. Tags without a source image aren't imported.
+func (o *ImportPolicyOptions) selects(stream string, tag *imagev1.TagReference) bool {
+	if tag.From == nil || tag.Reference {
+		return false
+	}
+	tags := o.Tags[stream]
+	return tags.Len() == 0 || tags.Has(tag.Name)
+}
+
+// updateImportPolicies sets the requested import policies on the selected
+// tags of stream.
+func (o *ImportPolicyOptions) updateImportPolicies(stream *imagev1.ImageStream) {
+	for i := range stream.Spec.Tags {
+		tag := &stream.Spec.Tags[i]
+		if !o.selects(stream.Name, tag) {
+			continue
+		}
+		if o.Scheduled != nil {
+			tag.ImportPolicy.Scheduled = *o.Scheduled
+		}
+		if o.Insecure != nil {
+			tag.ImportPolicy.Insecure = *o.Insecure
+		}
+	}
+}
+
+// printImportPolicies displays a tabular output of the import policy of the
+// tags of each image stream.
+func (o *ImportPolicyOptions) printImportPolicies(infos []*resource.Info) error {
+	w := tabwriter.NewWriter(o.Out, 0, 2, 2, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintf(w, "NAME\tFROM\tSCHEDULED\tINSECURE\n")
+	for _, info := range infos {
+		stream, ok := info.Object.(*imagev1.ImageStream)
+		if !ok {
+			continue
+		}
+		for i := range stream.Spec.Tags {
+			tag := &stream.Spec.Tags[i]
+			if !o.selects(stream.Name, tag) {
+				continue
+			}
+			fmt.Fprintf(w, "%s:%s\t%s\t%t\t%t\n", stream.Name, tag.Name, tag.From.Name, tag.ImportPolicy.Scheduled, tag.ImportPolicy.Insecure)
+		}
+	}
+	return nil
+}
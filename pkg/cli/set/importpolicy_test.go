@@ -0,0 +1,93 @@
+package set
+
+import (
+	"bytes"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	imagev1 "github.com/openshift/api/image/v1"
+)
+
+func newImportPolicyStream() *imagev1.ImageStream {
+	return &imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "mysql"},
+		Spec: imagev1.ImageStreamSpec{
+			Tags: []imagev1.TagReference{
+				{Name: "8.0", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "mysql:8.0"}},
+				{Name: "latest", From: &corev1.ObjectReference{Kind: "DockerImage", Name: "mysql:latest"}, ImportPolicy: imagev1.TagImportPolicy{Insecure: true}},
+				{Name: "stable", From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "mysql:8.0"}, Reference: true},
+				{Name: "local"},
+			},
+		},
+	}
+}
+
+func TestUpdateImportPolicies(t *testing.T) {
+	scheduled, insecure := true, false
+	tests := []struct {
+		name     string
+		tags     map[string]sets.Set[string]
+		expected map[string]imagev1.TagImportPolicy
+	}{
+		{
+			name: "all tags",
+			expected: map[string]imagev1.TagImportPolicy{
+				"8.0":    {Scheduled: true},
+				"latest": {Scheduled: true},
+			},
+		},
+		{
+			name: "single tag",
+			tags: map[string]sets.Set[string]{"mysql": sets.New("latest")},
+			expected: map[string]imagev1.TagImportPolicy{
+				"8.0":    {},
+				"latest": {Scheduled: true},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &ImportPolicyOptions{Tags: tc.tags, Scheduled: &scheduled, Insecure: &insecure}
+			stream := newImportPolicyStream()
+			o.updateImportPolicies(stream)
+			for _, tag := range stream.Spec.Tags {
+				if expected := tc.expected[tag.Name]; tag.ImportPolicy != expected {
+					t.Errorf("tag %s: expected %#v, got %#v", tag.Name, expected, tag.ImportPolicy)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintImportPolicies(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := &ImportPolicyOptions{IOStreams: genericiooptions.IOStreams{Out: out}}
+	if err := o.printImportPolicies([]*resource.Info{{Object: newImportPolicyStream()}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `NAME          FROM          SCHEDULED  INSECURE
+mysql:8.0     mysql:8.0     false      false
+mysql:latest  mysql:latest  false      true
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestValidateImportPolicy(t *testing.T) {
+	scheduled := true
+	if err := (&ImportPolicyOptions{Scheduled: &scheduled}).Validate(); err == nil {
+		t.Errorf("expected an error without image streams")
+	}
+	if err := (&ImportPolicyOptions{Scheduled: &scheduled, All: true, Tags: map[string]sets.Set[string]{"mysql": nil}}).Validate(); err == nil {
+		t.Errorf("expected an error with image streams and --all")
+	}
+	if err := (&ImportPolicyOptions{Scheduled: &scheduled, Selector: "team=web"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -81,6 +81,21 @@ func NewCmdSet(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	return set
 }
 
+// NewCmdExperimentalSet exposes the experimental commands for modifying
+// objects, under oc ex.
+func NewCmdExperimentalSet(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	set := &cobra.Command{
+		Use:   "set COMMAND",
+		Short: "Experimental commands that help set specific features on objects",
+		Long:  setLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	set.AddCommand(
+		NewCmdImportPolicy(f, streams),
+	)
+	return set
+}
+
 var (
 	setImageLong = ktemplates.LongDesc(`
 Update existing container image(s) of resources.`)
@@ -36,6 +36,7 @@ import (
 	"github.com/openshift/library-go/pkg/image/reference"
 	ometa "github.com/openshift/library-go/pkg/image/referencemutator"
 	triggerutil "github.com/openshift/library-go/pkg/image/trigger"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 	"github.com/openshift/oc/pkg/helpers/newapp/app"
 )
 
@@ -109,6 +110,7 @@ type TriggersOptions struct {
 	FromImageNamespace string
 
 	PrintTable        bool
+	ShowDiff          bool
 	Printer           printers.ResourcePrinter
 	Builder           func() *resource.Builder
 	Namespace         string
@@ -160,6 +162,8 @@ func NewCmdTriggers(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cob
 	o.FromGitLab = cmd.Flags().Bool("from-gitlab", false, "If true, a GitLab webhook - a secret value will be generated automatically")
 	o.FromBitbucket = cmd.Flags().Bool("from-bitbucket", false, "If true, a Bitbucket webhook - a secret value will be generated automatically")
 
+	cmd.Flags().BoolVar(&o.ShowDiff, "show-diff", o.ShowDiff, "If true, print a unified diff of each object before and after the change instead of the object.")
+
 	o.PrintFlags.AddFlags(cmd)
 	kcmdutil.AddDryRunFlag(cmd)
 	kcmdutil.AddFieldManagerFlagVar(cmd, &o.FieldManager, "kubectl-set")
@@ -275,6 +279,9 @@ func (o *TriggersOptions) Validate() error {
 	if o.Local && o.DryRunStrategy == kcmdutil.DryRunServer {
 		return fmt.Errorf("cannot specify --local and --dry-run=server - did you mean --dry-run=client?")
 	}
+	if o.ShowDiff && o.PrintFlags.OutputFormat != nil && len(*o.PrintFlags.OutputFormat) > 0 {
+		return fmt.Errorf("--show-diff and --output may not be specified together")
+	}
 
 	return nil
 }
@@ -331,7 +338,13 @@ func (o *TriggersOptions) Run() error {
 		}
 
 		if o.Local || o.DryRunStrategy == kcmdutil.DryRunClient {
-			if err := o.Printer.PrintObj(info.Object, o.Out); err != nil {
+			var err error
+			if o.ShowDiff {
+				err = cmdutil.PrintDiff(o.Out, name, patch.Before, patch.After)
+			} else {
+				err = o.Printer.PrintObj(info.Object, o.Out)
+			}
+			if err != nil {
 				allErrs = append(allErrs, err)
 			}
 			continue
@@ -346,7 +359,12 @@ func (o *TriggersOptions) Run() error {
 			continue
 		}
 
-		if err := o.Printer.PrintObj(actual, o.Out); err != nil {
+		if o.ShowDiff {
+			err = printPatchedDiff(o.Out, name, patch.Before, actual)
+		} else {
+			err = o.Printer.PrintObj(actual, o.Out)
+		}
+		if err != nil {
 			allErrs = append(allErrs, err)
 		}
 	}
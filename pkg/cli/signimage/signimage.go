@@ -0,0 +1,183 @@
+package signimage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	"github.com/openshift/api/image"
+	imagev1 "github.com/openshift/api/image/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	userv1client "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+// ApprovalAnnotation holds the approval recorded on an image stream tag.
+const ApprovalAnnotation = "oc.openshift.io/approval"
+
+var (
+	signImageLong = templates.LongDesc(`
+		Approve the image an image stream tag points to.
+
+		The approval records who approved the image, when, and the digest of the image
+		in an annotation of the image stream tag. 'verify' checks that the image the tag
+		points to is the one that was approved, so that a promotion pipeline can wait for
+		an approval before tagging the image into the stream deployments are triggered by.
+
+		Approvals are not cryptographic signatures: anyone allowed to update the image
+		stream can record one. Use them to track reviews, not to enforce them against
+		users who can edit image streams.
+	`)
+
+	signImageExample = templates.Examples(`
+		# Approve the image the 'app:candidate' image stream tag points to
+		oc ex sign-image app:candidate
+
+		# Check that the image 'app:candidate' points to was approved by alice or bob
+		oc ex sign-image verify app:candidate --signer=alice --signer=bob
+	`)
+)
+
+// Approval is the approval of an image recorded on an image stream tag.
+type Approval struct {
+	Signer    string    `json:"signer"`
+	Timestamp time.Time `json:"timestamp"`
+	Digest    string    `json:"digest"`
+}
+
+// SignImageOptions contains all the options needed to approve an image
+type SignImageOptions struct {
+	Namespace string
+	Name      string
+	Signer    string
+
+	ImageClient imagev1client.ImageV1Interface
+	// Now returns the time the approval is recorded at.
+	Now func() time.Time
+
+	genericiooptions.IOStreams
+}
+
+func NewSignImageOptions(streams genericiooptions.IOStreams) *SignImageOptions {
+	return &SignImageOptions{
+		Now:       time.Now,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSignImage implements the OpenShift experimental sign-image command
+func NewCmdSignImage(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewSignImageOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "sign-image IMAGESTREAMTAG",
+		Short:   "Approve the image an image stream tag points to",
+		Long:    signImageLong,
+		Example: signImageExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.AddCommand(NewCmdVerify(f, streams))
+
+	return cmd
+}
+
+func (o *SignImageOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one image stream tag is required")
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	if o.Name, err = parseImageStreamTag(args[0], mapper); err != nil {
+		return err
+	}
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.ImageClient, err = imagev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	userClient, err := userv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	me, err := userClient.Users().Get(context.TODO(), "~", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to determine the signer: %v", err)
+	}
+	o.Signer = me.Name
+	return nil
+}
+
+func (o *SignImageOptions) Run() error {
+	ctx := context.TODO()
+	ist, err := o.ImageClient.ImageStreamTags(o.Namespace).Get(ctx, o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if len(ist.Image.Name) == 0 {
+		return fmt.Errorf("image stream tag %q doesn't point to an image", o.Name)
+	}
+
+	approval := Approval{Signer: o.Signer, Timestamp: o.Now().UTC().Truncate(time.Second), Digest: ist.Image.Name}
+	value, err := json.Marshal(approval)
+	if err != nil {
+		return err
+	}
+	if ist.Annotations == nil {
+		ist.Annotations = map[string]string{}
+	}
+	ist.Annotations[ApprovalAnnotation] = string(value)
+	if _, err := o.ImageClient.ImageStreamTags(o.Namespace).Update(ctx, ist, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(o.Out, "Image %s of image stream tag %q approved by %s\n", approval.Digest, o.Name, approval.Signer)
+	return nil
+}
+
+// parseImageStreamTag returns the name of the image stream tag arg refers to,
+// with the latest tag when arg doesn't have any.
+func parseImageStreamTag(arg string, mapper meta.RESTMapper) (string, error) {
+	resource, name, err := osutil.ResolveResource(image.Resource("imagestreamtags"), arg, mapper)
+	if err != nil {
+		return "", err
+	}
+	if resource != image.Resource("imagestreamtags") {
+		return "", fmt.Errorf("only image stream tags are supported, got %s", resource)
+	}
+	stream, tag, _ := imageutil.SplitImageStreamTag(name)
+	return imageutil.JoinImageStreamTag(stream, tag), nil
+}
+
+// approvalOf returns the approval recorded on ist, or nil if there is none.
+func approvalOf(ist *imagev1.ImageStreamTag) (*Approval, error) {
+	value, ok := ist.Annotations[ApprovalAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	approval := &Approval{}
+	if err := json.Unmarshal([]byte(value), approval); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation on image stream tag %q: %v", ApprovalAnnotation, ist.Name, err)
+	}
+	return approval, nil
+}
@@ -0,0 +1,108 @@
+package signimage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+func newImageStreamTag(digest string) *imagev1.ImageStreamTag {
+	return &imagev1.ImageStreamTag{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app:candidate"},
+		Image:      imagev1.Image{ObjectMeta: metav1.ObjectMeta{Name: digest}},
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	fakeClient := imagefake.NewSimpleClientset(newImageStreamTag("sha256:aaa"))
+
+	out := &bytes.Buffer{}
+	sign := NewSignImageOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	sign.Namespace, sign.Name, sign.Signer = "test", "app:candidate", "alice"
+	sign.ImageClient = fakeClient.ImageV1()
+	sign.Now = func() time.Time { return now }
+	if err := sign.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	ist, err := fakeClient.ImageV1().ImageStreamTags("test").Get(context.TODO(), "app:candidate", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approval, err := approvalOf(ist)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Approval{Signer: "alice", Timestamp: now, Digest: "sha256:aaa"}); approval == nil || *approval != expected {
+		t.Fatalf("expected approval %#v, got %#v", expected, approval)
+	}
+
+	tests := []struct {
+		name     string
+		signers  []string
+		maxAge   time.Duration
+		moved    bool
+		expected string
+	}{
+		{name: "approved"},
+		{name: "accepted signer", signers: []string{"bob", "alice"}},
+		{name: "other signer", signers: []string{"bob"}, expected: "not an accepted signer"},
+		{name: "fresh", maxAge: 2 * time.Hour},
+		{name: "too old", maxAge: 30 * time.Minute, expected: "older than 30m0s"},
+		{name: "moved tag", moved: true, expected: "the approval is for image sha256:aaa but the tag points to sha256:bbb"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tagged := ist.DeepCopy()
+			if tc.moved {
+				tagged.Image.Name = "sha256:bbb"
+			}
+			out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+			verify := NewVerifyOptions(genericiooptions.IOStreams{Out: out, ErrOut: errOut})
+			verify.Namespace, verify.Name = "test", "app:candidate"
+			verify.Signers, verify.MaxAge = tc.signers, tc.maxAge
+			verify.ImageClient = imagefake.NewSimpleClientset(tagged).ImageV1()
+			verify.Now = func() time.Time { return now.Add(time.Hour) }
+
+			err := verify.Run()
+			if len(tc.expected) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error %v: %s", err, errOut)
+				}
+				if !strings.Contains(out.String(), "approved by alice") {
+					t.Errorf("unexpected output %q", out)
+				}
+				return
+			}
+			if err != kcmdutil.ErrExit {
+				t.Fatalf("expected the verification to fail, got %v", err)
+			}
+			if !strings.Contains(errOut.String(), tc.expected) {
+				t.Errorf("expected %q in %q", tc.expected, errOut)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutApproval(t *testing.T) {
+	errOut := &bytes.Buffer{}
+	verify := NewVerifyOptions(genericiooptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut})
+	verify.Namespace, verify.Name = "test", "app:candidate"
+	verify.ImageClient = imagefake.NewSimpleClientset(newImageStreamTag("sha256:aaa")).ImageV1()
+	if err := verify.Run(); err != kcmdutil.ErrExit {
+		t.Fatalf("expected the verification to fail, got %v", err)
+	}
+	if !strings.Contains(errOut.String(), "no approval was recorded") {
+		t.Errorf("unexpected output %q", errOut)
+	}
+}
@@ -0,0 +1,140 @@
+package signimage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+)
+
+var (
+	verifyLong = templates.LongDesc(`
+		Verify that the image an image stream tag points to was approved.
+
+		The command fails if the image stream tag holds no approval, if the approval is for
+		another image than the one the tag points to, or if it doesn't satisfy --signer or
+		--max-age.
+	`)
+
+	verifyExample = templates.Examples(`
+		# Check that the image 'app:candidate' points to was approved
+		oc ex sign-image verify app:candidate
+
+		# Check that it was approved by alice within the last day
+		oc ex sign-image verify app:candidate --signer=alice --max-age=24h
+	`)
+)
+
+// VerifyOptions contains all the options needed to verify the approval of an image
+type VerifyOptions struct {
+	Namespace string
+	Name      string
+	Signers   []string
+	MaxAge    time.Duration
+
+	ImageClient imagev1client.ImageV1Interface
+	// Now returns the time approvals are aged at.
+	Now func() time.Time
+
+	genericiooptions.IOStreams
+}
+
+func NewVerifyOptions(streams genericiooptions.IOStreams) *VerifyOptions {
+	return &VerifyOptions{
+		Now:       time.Now,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdVerify implements the verify subcommand of sign-image
+func NewCmdVerify(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewVerifyOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "verify IMAGESTREAMTAG",
+		Short:   "Verify that the image an image stream tag points to was approved",
+		Long:    verifyLong,
+		Example: verifyExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&o.Signers, "signer", o.Signers, "If set, only accept approvals from these users.")
+	cmd.Flags().DurationVar(&o.MaxAge, "max-age", o.MaxAge, "If positive, only accept approvals recorded within this duration.")
+
+	return cmd
+}
+
+func (o *VerifyOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one image stream tag is required")
+	}
+	mapper, err := f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	if o.Name, err = parseImageStreamTag(args[0], mapper); err != nil {
+		return err
+	}
+	if o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace(); err != nil {
+		return err
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.ImageClient, err = imagev1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *VerifyOptions) Validate() error {
+	if o.MaxAge < 0 {
+		return fmt.Errorf("--max-age must be greater than or equal to 0")
+	}
+	return nil
+}
+
+func (o *VerifyOptions) Run() error {
+	ist, err := o.ImageClient.ImageStreamTags(o.Namespace).Get(context.TODO(), o.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	approval, err := approvalOf(ist)
+	if err != nil {
+		return err
+	}
+
+	if problem := o.check(approval, ist.Image.Name); len(problem) > 0 {
+		fmt.Fprintf(o.ErrOut, "Image stream tag %q is not approved: %s\n", o.Name, problem)
+		return kcmdutil.ErrExit
+	}
+	fmt.Fprintf(o.Out, "Image %s of image stream tag %q was approved by %s at %s\n", approval.Digest, o.Name, approval.Signer, approval.Timestamp.Format(time.RFC3339))
+	return nil
+}
+
+// check returns why approval doesn't approve the image digest, if it doesn't.
+func (o *VerifyOptions) check(approval *Approval, digest string) string {
+	switch {
+	case approval == nil:
+		return "no approval was recorded"
+	case approval.Digest != digest:
+		return fmt.Sprintf("the approval is for image %s but the tag points to %s", approval.Digest, digest)
+	case len(o.Signers) > 0 && !sets.NewString(o.Signers...).Has(approval.Signer):
+		return fmt.Sprintf("approved by %s, who is not an accepted signer", approval.Signer)
+	case o.MaxAge > 0 && o.Now().Sub(approval.Timestamp) > o.MaxAge:
+		return fmt.Sprintf("the approval recorded at %s is older than %s", approval.Timestamp.Format(time.RFC3339), o.MaxAge)
+	}
+	return ""
+}
@@ -0,0 +1,554 @@
+package simulateupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/client/paging"
+	buildhelpers "github.com/openshift/oc/pkg/helpers/build"
+	"github.com/openshift/oc/pkg/helpers/describe"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+var (
+	simulateUpdateLong = templates.LongDesc(`
+		Predict what updating an image stream tag would set off, without triggering anything.
+
+		The builds are found from the build chain of the image stream tag, following the image
+		change triggers that aren't paused. The deployments are those of the deployment configs
+		automatically triggered by any image stream tag of the chain, and the affected routes
+		those exposing the services of their pods.
+
+		The duration of every build and deployment is estimated from the average of its last
+		--history successful runs. The estimated propagation time is the duration of the
+		longest sequence of builds and deployments the update sets off, its critical path.
+		Build configs and deployment configs without any successful run are counted as
+		instantaneous and reported as unknown.
+
+		Build configs and deployment configs are looked up in the namespace of the image
+		stream tag.
+	`)
+
+	simulateUpdateExample = templates.Examples(`
+		# Predict what updating the 'latest' tag of the 'base' image stream would set off
+		oc ex simulate-update base:latest
+
+		# Predict what updating an image stream tag of another namespace would set off
+		oc ex simulate-update shared/base:latest
+	`)
+)
+
+// deploymentConfigNodeKind is the kind of the deployment configs added to
+// the nodes of the build chain.
+const deploymentConfigNodeKind = "DeploymentConfig"
+
+// rolloutSuffix matches the version suffix of the replication controllers,
+// and so of the deployer pods, of a deployment config.
+var rolloutSuffix = regexp.MustCompile(`-[0-9]+$`)
+
+// Step is a build or deployment the update would start.
+type Step struct {
+	Namespace string
+	Name      string
+	// Estimate is the average duration of the last successful runs, zero if
+	// there is none.
+	Estimate time.Duration
+}
+
+// Simulation is what updating an image stream tag would set off.
+type Simulation struct {
+	Builds      []Step
+	Deployments []Step
+	// Routes are the namespace/name of the routes exposing the services of
+	// the pods of the deployments.
+	Routes []string
+	// CriticalPath is the longest sequence of builds and deployments, as the
+	// IDs of the build chain nodes, starting from the updated tag.
+	CriticalPath []string
+	Duration     time.Duration
+}
+
+// SimulateUpdateOptions contains all the options needed to simulate the update of an image stream tag
+type SimulateUpdateOptions struct {
+	Namespace string
+	// Tag is the name:tag of the updated image stream tag.
+	Tag       string
+	History   int
+	ChunkSize int64
+
+	BuildClient buildv1client.BuildV1Interface
+	AppsClient  appsv1client.AppsV1Interface
+	CoreClient  corev1client.CoreV1Interface
+	RouteClient routev1client.RouteV1Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewSimulateUpdateOptions(streams genericiooptions.IOStreams) *SimulateUpdateOptions {
+	return &SimulateUpdateOptions{
+		History:   5,
+		ChunkSize: paging.DefaultChunkSize,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSimulateUpdate implements the OpenShift experimental simulate-update command
+func NewCmdSimulateUpdate(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewSimulateUpdateOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "simulate-update [NAMESPACE/]IMAGESTREAM[:TAG]",
+		Short:   "Predict the builds and deployments updating an image stream tag would start",
+		Long:    simulateUpdateLong,
+		Example: simulateUpdateExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().IntVar(&o.History, "history", o.History, "Number of past successful runs the duration of every build and deployment is estimated from.")
+	kcmdutil.AddChunkSizeFlag(cmd, &o.ChunkSize)
+
+	return cmd
+}
+
+func (o *SimulateUpdateOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return kcmdutil.UsageErrorf(cmd, "exactly one image stream tag is required")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	o.Tag = args[0]
+	if parts := strings.SplitN(args[0], "/", 2); len(parts) == 2 {
+		o.Namespace, o.Tag = parts[0], parts[1]
+	}
+	name, tag, _ := imageutil.SplitImageStreamTag(o.Tag)
+	o.Tag = imageutil.JoinImageStreamTag(name, tag)
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.BuildClient, err = buildv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.AppsClient, err = appsv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	if o.CoreClient, err = corev1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.RouteClient, err = routev1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *SimulateUpdateOptions) Validate() error {
+	if len(o.Namespace) == 0 {
+		return fmt.Errorf("namespace cannot be empty")
+	}
+	if name, _, _ := imageutil.SplitImageStreamTag(o.Tag); len(name) == 0 {
+		return fmt.Errorf("image stream tag cannot be empty")
+	}
+	if o.History < 1 {
+		return fmt.Errorf("--history must be greater than 0")
+	}
+	return nil
+}
+
+func (o *SimulateUpdateOptions) Run() error {
+	simulation, err := o.Simulate(context.TODO())
+	if err != nil {
+		return err
+	}
+	printSimulation(o.Out, o.Namespace+"/"+o.Tag, simulation)
+	return nil
+}
+
+// chainGraph is the build chain of the updated tag, extended with the
+// deployment configs its image stream tags trigger. Nodes are identified by
+// their build chain ID, Kind|namespace/name.
+type chainGraph struct {
+	root     string
+	children map[string][]string
+	steps    map[string]*Step
+}
+
+// Simulate computes what updating the image stream tag would set off.
+func (o *SimulateUpdateOptions) Simulate(ctx context.Context) (*Simulation, error) {
+	g, err := o.chain()
+	if err != nil {
+		return nil, err
+	}
+
+	buildClient := paging.BuildV1(o.BuildClient, o.ChunkSize)
+	buildConfigs, err := buildClient.BuildConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	builds, err := buildClient.Builds(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	paused := sets.NewString()
+	for i := range buildConfigs.Items {
+		bc := &buildConfigs.Items[i]
+		if triggersPaused(bc) {
+			paused.Insert(nodeID(buildgraph.BuildConfigNodeKind, bc.Namespace, bc.Name))
+			continue
+		}
+		configBuilds := buildhelpers.FilterBuilds(builds.Items, buildhelpers.ByBuildConfigPredicate(bc.Name))
+		if step, ok := g.steps[nodeID(buildgraph.BuildConfigNodeKind, bc.Namespace, bc.Name)]; ok {
+			step.Estimate = buildEstimate(configBuilds, o.History)
+		}
+	}
+
+	dcs, err := o.AppsClient.DeploymentConfigs(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	deployers, err := o.CoreClient.Pods(o.Namespace).List(ctx, metav1.ListOptions{LabelSelector: appsv1.DeployerPodForDeploymentLabel})
+	if err != nil {
+		return nil, err
+	}
+	for i := range dcs.Items {
+		dc := &dcs.Items[i]
+		if dc.Spec.Paused {
+			continue
+		}
+		id := nodeID(deploymentConfigNodeKind, dc.Namespace, dc.Name)
+		for _, trigger := range dc.Spec.Triggers {
+			params := trigger.ImageChangeParams
+			if params == nil || !params.Automatic {
+				continue
+			}
+			namespace := params.From.Namespace
+			if len(namespace) == 0 {
+				namespace = dc.Namespace
+			}
+			name, tag, _ := imageutil.SplitImageStreamTag(params.From.Name)
+			from := nodeID(imagegraph.ImageStreamTagNodeKind, namespace, imageutil.JoinImageStreamTag(name, tag))
+			if _, ok := g.children[from]; ok {
+				g.children[from] = append(g.children[from], id)
+				g.steps[id] = &Step{Namespace: dc.Namespace, Name: dc.Name, Estimate: rolloutEstimate(dc.Name, deployers.Items, o.History)}
+			}
+		}
+	}
+
+	simulation := &Simulation{}
+	reached := g.reachable(paused)
+	deployed := []*appsv1.DeploymentConfig{}
+	for i := range dcs.Items {
+		if reached.Has(nodeID(deploymentConfigNodeKind, dcs.Items[i].Namespace, dcs.Items[i].Name)) {
+			deployed = append(deployed, &dcs.Items[i])
+		}
+	}
+	for _, id := range reached.List() {
+		step, ok := g.steps[id]
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(id, deploymentConfigNodeKind+"|") {
+			simulation.Deployments = append(simulation.Deployments, *step)
+		} else {
+			simulation.Builds = append(simulation.Builds, *step)
+		}
+	}
+	simulation.CriticalPath, simulation.Duration = g.criticalPath(reached)
+
+	if len(deployed) > 0 {
+		if simulation.Routes, err = o.routes(ctx, deployed); err != nil {
+			return nil, err
+		}
+	}
+	return simulation, nil
+}
+
+// chain returns the build chain of the updated tag.
+func (o *SimulateUpdateOptions) chain() (*chainGraph, error) {
+	name, tag, _ := imageutil.SplitImageStreamTag(o.Tag)
+	root := nodeID(imagegraph.ImageStreamTagNodeKind, o.Namespace, o.Tag)
+	g := &chainGraph{root: root, children: map[string][]string{root: nil}, steps: map[string]*Step{}}
+
+	describer := describe.NewChainDescriber(describe.NewBuildConfigLister(o.BuildClient), sets.NewString(o.Namespace), "json")
+	desc, err := describer.Describe(imagegraph.MakeImageStreamTagObjectMeta(o.Namespace, name, tag), false, false)
+	if _, isNotFoundErr := err.(describe.NotFoundErr); isNotFoundErr {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	chain := &describe.ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), chain); err != nil {
+		return nil, fmt.Errorf("unable to read the build chain of %q: %v", o.Tag, err)
+	}
+	for _, node := range chain.Nodes {
+		if _, ok := g.children[node.ID]; !ok {
+			g.children[node.ID] = nil
+		}
+		if node.Kind == buildgraph.BuildConfigNodeKind {
+			g.steps[node.ID] = &Step{Namespace: node.Namespace, Name: node.Name}
+		}
+	}
+	for _, edge := range chain.Edges {
+		g.children[edge.From] = append(g.children[edge.From], edge.To)
+	}
+	return g, nil
+}
+
+// reachable returns the nodes reached from the root without going through
+// the stopped ones.
+func (g *chainGraph) reachable(stopped sets.String) sets.String {
+	reached := sets.NewString(g.root)
+	queue := []string{g.root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, child := range g.children[id] {
+			if reached.Has(child) || stopped.Has(child) {
+				continue
+			}
+			reached.Insert(child)
+			queue = append(queue, child)
+		}
+	}
+	return reached
+}
+
+// criticalPath returns the longest sequence of steps among the reached nodes,
+// as the IDs of the nodes from the root, and its estimated duration.
+func (g *chainGraph) criticalPath(reached sets.String) ([]string, time.Duration) {
+	longest := map[string]time.Duration{}
+	next := map[string]string{}
+	visiting := sets.NewString()
+	var walk func(id string) time.Duration
+	walk = func(id string) time.Duration {
+		if d, ok := longest[id]; ok {
+			return d
+		}
+		// a cycle doesn't start the same build twice
+		if visiting.Has(id) {
+			return 0
+		}
+		visiting.Insert(id)
+		defer visiting.Delete(id)
+
+		children := append([]string{}, g.children[id]...)
+		sort.Strings(children)
+		var best time.Duration
+		for _, child := range children {
+			if !reached.Has(child) {
+				continue
+			}
+			if d := walk(child); d > best || len(next[id]) == 0 {
+				best, next[id] = d, child
+			}
+		}
+		if step, ok := g.steps[id]; ok {
+			best += step.Estimate
+		}
+		longest[id] = best
+		return best
+	}
+	duration := walk(g.root)
+
+	path := []string{g.root}
+	seen := sets.NewString(g.root)
+	for id := next[g.root]; len(id) > 0 && !seen.Has(id); id = next[id] {
+		path = append(path, id)
+		seen.Insert(id)
+	}
+	return path, duration
+}
+
+// routes returns the namespace/name of the routes exposing the services that
+// select the pods of dcs.
+func (o *SimulateUpdateOptions) routes(ctx context.Context, dcs []*appsv1.DeploymentConfig) ([]string, error) {
+	services, err := o.CoreClient.Services(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	exposed := sets.NewString()
+	for _, service := range services.Items {
+		if len(service.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(service.Spec.Selector)
+		for _, dc := range dcs {
+			if dc.Spec.Template != nil && selector.Matches(labels.Set(dc.Spec.Template.Labels)) {
+				exposed.Insert(service.Name)
+			}
+		}
+	}
+	if exposed.Len() == 0 {
+		return nil, nil
+	}
+
+	routes, err := o.RouteClient.Routes(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	affected := sets.NewString()
+	for _, route := range routes.Items {
+		if exposed.Has(route.Spec.To.Name) {
+			affected.Insert(route.Namespace + "/" + route.Name)
+		}
+		for _, backend := range route.Spec.AlternateBackends {
+			if exposed.Has(backend.Name) {
+				affected.Insert(route.Namespace + "/" + route.Name)
+			}
+		}
+	}
+	return affected.List(), nil
+}
+
+// triggersPaused returns whether bc has image change triggers and all of them
+// are paused.
+func triggersPaused(bc *buildv1.BuildConfig) bool {
+	found := false
+	for _, trigger := range bc.Spec.Triggers {
+		if trigger.ImageChange == nil {
+			continue
+		}
+		if !trigger.ImageChange.Paused {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// buildEstimate returns the average duration of the last history complete
+// builds.
+func buildEstimate(builds []buildv1.Build, history int) time.Duration {
+	sort.Sort(sort.Reverse(buildhelpers.BuildSliceByCreationTimestamp(builds)))
+	durations := []time.Duration{}
+	for _, build := range builds {
+		if len(durations) == history {
+			break
+		}
+		if build.Status.Phase != buildv1.BuildPhaseComplete {
+			continue
+		}
+		duration := build.Status.Duration
+		if duration == 0 && build.Status.StartTimestamp != nil && build.Status.CompletionTimestamp != nil {
+			duration = build.Status.CompletionTimestamp.Sub(build.Status.StartTimestamp.Time)
+		}
+		durations = append(durations, duration)
+	}
+	return average(durations)
+}
+
+// rolloutEstimate returns the average duration of the last history successful
+// deployer pods of the deployment config name.
+func rolloutEstimate(name string, deployers []corev1.Pod, history int) time.Duration {
+	pods := []corev1.Pod{}
+	for _, pod := range deployers {
+		rollout := pod.Labels[appsv1.DeployerPodForDeploymentLabel]
+		if rolloutSuffix.ReplaceAllString(rollout, "") == name && pod.Status.Phase == corev1.PodSucceeded && pod.Status.StartTime != nil {
+			pods = append(pods, pod)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[j].Status.StartTime.Before(pods[i].Status.StartTime) })
+
+	durations := []time.Duration{}
+	for _, pod := range pods {
+		if len(durations) == history {
+			break
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if terminated := status.State.Terminated; terminated != nil {
+				durations = append(durations, terminated.FinishedAt.Sub(pod.Status.StartTime.Time))
+				break
+			}
+		}
+	}
+	return average(durations)
+}
+
+func average(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return (total / time.Duration(len(durations))).Round(time.Second)
+}
+
+func nodeID(kind, namespace, name string) string {
+	return kind + "|" + namespace + "/" + name
+}
+
+func printSimulation(out io.Writer, tag string, simulation *Simulation) {
+	if len(simulation.Builds) == 0 && len(simulation.Deployments) == 0 {
+		fmt.Fprintf(out, "Updating %s wouldn't start any build or deployment.\n", tag)
+		return
+	}
+
+	fmt.Fprintf(out, "Updating %s would start:\n\n", tag)
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tESTIMATED DURATION")
+	for _, step := range simulation.Builds {
+		fmt.Fprintf(w, "build\t%s/%s\t%s\n", step.Namespace, step.Name, estimate(step.Estimate))
+	}
+	for _, step := range simulation.Deployments {
+		fmt.Fprintf(w, "deployment\t%s/%s\t%s\n", step.Namespace, step.Name, estimate(step.Estimate))
+	}
+	w.Flush()
+
+	if len(simulation.Routes) > 0 {
+		fmt.Fprintf(out, "\nAffected routes: %s\n", strings.Join(simulation.Routes, ", "))
+	}
+	path := []string{}
+	for _, id := range simulation.CriticalPath {
+		kind, name, _ := strings.Cut(id, "|")
+		switch kind {
+		case buildgraph.BuildConfigNodeKind:
+			name = "bc/" + name
+		case deploymentConfigNodeKind:
+			name = "dc/" + name
+		default:
+			name = "istag/" + name
+		}
+		path = append(path, name)
+	}
+	fmt.Fprintf(out, "\nEstimated propagation time: %s (%s)\n", simulation.Duration, strings.Join(path, " -> "))
+}
+
+func estimate(d time.Duration) string {
+	if d == 0 {
+		return "<unknown>"
+	}
+	return d.String()
+}
@@ -0,0 +1,174 @@
+package simulateupdate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	appsfake "github.com/openshift/client-go/apps/clientset/versioned/fake"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+	routefake "github.com/openshift/client-go/route/clientset/versioned/fake"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newBuildConfig(name, from string, paused bool) *buildv1.BuildConfig {
+	return &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{
+					DockerStrategy: &buildv1.DockerBuildStrategy{From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from}},
+				},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{
+				{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{Paused: paused}},
+			},
+		},
+	}
+}
+
+func newBuild(config string, number int, phase buildv1.BuildPhase, duration time.Duration) *buildv1.Build {
+	return &buildv1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "test",
+			Name:              fmt.Sprintf("%s-%d", config, number),
+			Labels:            map[string]string{buildv1.BuildConfigLabel: config},
+			Annotations:       map[string]string{buildv1.BuildConfigAnnotation: config},
+			CreationTimestamp: metav1.NewTime(start.Add(time.Duration(number) * time.Hour)),
+		},
+		Status: buildv1.BuildStatus{Phase: phase, Duration: duration},
+	}
+}
+
+func newDeploymentConfig(name, from string) *appsv1.DeploymentConfig {
+	return &appsv1.DeploymentConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: appsv1.DeploymentConfigSpec{
+			Template: &corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}}},
+			Triggers: []appsv1.DeploymentTriggerPolicy{{
+				Type: appsv1.DeploymentTriggerOnImageChange,
+				ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{
+					Automatic: true,
+					From:      corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+				},
+			}},
+		},
+	}
+}
+
+func newDeployerPod(config string, version int, duration time.Duration) *corev1.Pod {
+	rollout := fmt.Sprintf("%s-%d", config, version)
+	started := start.Add(time.Duration(version) * time.Hour)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: rollout + "-deploy", Labels: map[string]string{appsv1.DeployerPodForDeploymentLabel: rollout}},
+		Status: corev1.PodStatus{
+			Phase:     corev1.PodSucceeded,
+			StartTime: &metav1.Time{Time: started},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{FinishedAt: metav1.NewTime(started.Add(duration))}},
+			}},
+		},
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	buildClient := buildfake.NewSimpleClientset(
+		newBuildConfig("app", "base:latest", false),
+		newBuild("app", 1, buildv1.BuildPhaseComplete, 2*time.Minute),
+		newBuild("app", 2, buildv1.BuildPhaseFailed, 10*time.Minute),
+		newBuild("app", 3, buildv1.BuildPhaseComplete, 4*time.Minute),
+		newBuildConfig("bundle", "app:latest", false),
+		newBuild("bundle", 1, buildv1.BuildPhaseComplete, time.Minute),
+		newBuildConfig("tool", "base:latest", false),
+		newBuild("tool", 1, buildv1.BuildPhaseComplete, 20*time.Minute),
+		// paused, so neither it nor what it triggers would run
+		newBuildConfig("frozen", "base:latest", true),
+	)
+	appsClient := appsfake.NewSimpleClientset(
+		newDeploymentConfig("web", "app:latest"),
+		newDeploymentConfig("legacy", "frozen:latest"),
+	)
+	kubeClient := kubefake.NewSimpleClientset(
+		newDeployerPod("web", 1, 5*time.Minute),
+		newDeployerPod("web", 2, 15*time.Minute),
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "web"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "web"}}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "legacy"}, Spec: corev1.ServiceSpec{Selector: map[string]string{"app": "legacy"}}},
+	)
+	routeClient := routefake.NewSimpleClientset(
+		&routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "web"}, Spec: routev1.RouteSpec{To: routev1.RouteTargetReference{Kind: "Service", Name: "web"}}},
+		&routev1.Route{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "legacy"}, Spec: routev1.RouteSpec{To: routev1.RouteTargetReference{Kind: "Service", Name: "legacy"}}},
+	)
+
+	out := &bytes.Buffer{}
+	o := NewSimulateUpdateOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "test"
+	o.Tag = "base:latest"
+	o.BuildClient = buildClient.BuildV1()
+	o.AppsClient = appsClient.AppsV1()
+	o.CoreClient = kubeClient.CoreV1()
+	o.RouteClient = routeClient.RouteV1()
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	simulation, err := o.Simulate(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Simulation{
+		Builds: []Step{
+			{Namespace: "test", Name: "app", Estimate: 3 * time.Minute},
+			{Namespace: "test", Name: "bundle", Estimate: time.Minute},
+			{Namespace: "test", Name: "tool", Estimate: 20 * time.Minute},
+		},
+		Deployments: []Step{{Namespace: "test", Name: "web", Estimate: 10 * time.Minute}},
+		Routes:      []string{"test/web"},
+		CriticalPath: []string{
+			"ImageStreamTag|test/base:latest",
+			"BuildConfig|test/tool",
+			"ImageStreamTag|test/tool:latest",
+		},
+		Duration: 20 * time.Minute,
+	}
+	if !reflect.DeepEqual(simulation, expected) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, simulation)
+	}
+
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Estimated propagation time: 20m0s (istag/test/base:latest -> bc/test/tool -> istag/test/tool:latest)") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+}
+
+func TestSimulateNothing(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := NewSimulateUpdateOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "test"
+	o.Tag = "base:latest"
+	o.BuildClient = buildfake.NewSimpleClientset().BuildV1()
+	o.AppsClient = appsfake.NewSimpleClientset().AppsV1()
+	o.CoreClient = kubefake.NewSimpleClientset().CoreV1()
+	o.RouteClient = routefake.NewSimpleClientset().RouteV1()
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Updating test/base:latest wouldn't start any build or deployment.\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
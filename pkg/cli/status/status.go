@@ -20,6 +20,8 @@ import (
 	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	projectv1client "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
 	routev1client "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
+	"github.com/openshift/oc/pkg/client/cache"
+	"github.com/openshift/oc/pkg/client/paging"
 	"github.com/openshift/oc/pkg/helpers/describe"
 	dotutil "github.com/openshift/oc/pkg/helpers/dot"
 	loginutil "github.com/openshift/oc/pkg/helpers/project"
@@ -55,6 +57,8 @@ type StatusOptions struct {
 	outputFormat  string
 	describer     *describe.ProjectStatusDescriber
 	suggest       bool
+	cacheOptions  *cache.Options
+	chunkSize     int64
 
 	logsCommandName             string
 	securityPolicyCommandFormat string
@@ -66,7 +70,9 @@ type StatusOptions struct {
 
 func NewStatusOptions(streams genericiooptions.IOStreams) *StatusOptions {
 	return &StatusOptions{
-		IOStreams: streams,
+		cacheOptions: cache.NewOptions(),
+		chunkSize:    paging.DefaultChunkSize,
+		IOStreams:    streams,
 	}
 }
 
@@ -88,6 +94,8 @@ func NewCmdStatus(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 	cmd.Flags().StringVarP(&o.outputFormat, "output", "o", o.outputFormat, "Output format. One of: dot.")
 	cmd.Flags().BoolVar(&o.suggest, "suggest", o.suggest, "See details for resolving issues.")
 	cmd.Flags().BoolVarP(&o.allNamespaces, "all-namespaces", "A", o.allNamespaces, "If true, display status for all namespaces (must have cluster admin)")
+	o.cacheOptions.AddFlags(cmd.Flags())
+	kcmdutil.AddChunkSizeFlag(cmd, &o.chunkSize)
 
 	return cmd
 }
@@ -165,12 +173,13 @@ func (o *StatusOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []
 	nsFlag := kcmdutil.GetFlagString(cmd, "namespace")
 	canRequestProjects, _ := loginutil.CanRequestProjects(clientConfig, o.namespace)
 
+	listCache := o.cacheOptions.ToCache(clientConfig)
 	o.describer = &describe.ProjectStatusDescriber{
 		KubeClient:    kclientset,
 		RESTMapper:    restMapper,
-		ProjectClient: projectClient,
-		BuildClient:   buildClient,
-		ImageClient:   imageClient,
+		ProjectClient: paging.ProjectV1(projectClient, o.chunkSize),
+		BuildClient:   cache.BuildV1(listCache, paging.BuildV1(buildClient, o.chunkSize)),
+		ImageClient:   cache.ImageV1(listCache, paging.ImageV1(imageClient, o.chunkSize)),
 		AppsClient:    appsClient,
 		RouteClient:   routeClient,
 		Suggest:       o.suggest,
@@ -0,0 +1,254 @@
+package supportbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	buildmanualclientv1 "github.com/openshift/oc/pkg/helpers/build/client/v1"
+)
+
+var (
+	supportBundleLong = templates.LongDesc(`
+		Collect the builds, build configs, image streams, deployments, deployment configs
+		and events of namespaces into a tarball to attach to support tickets.
+
+		The current namespace is collected unless namespaces are given. Values that may
+		hold credentials are redacted: environment variables whose name refers to a
+		password, a secret, a token or a key, credentials in URLs and the last applied
+		configuration of objects. Secrets are never collected. With --build-logs, the
+		logs of the builds are collected as well.
+
+		Review the content of the tarball before sharing it.
+	`)
+
+	supportBundleExample = templates.Examples(`
+		# Collect the current namespace
+		oc ex support-bundle
+
+		# Collect the 'web' and 'base' namespaces with the logs of their builds
+		oc ex support-bundle web base --build-logs --dest-file=bundle.tar.gz
+	`)
+)
+
+// collectedResources are the resources collected from every namespace.
+var collectedResources = []schema.GroupVersionResource{
+	{Group: "build.openshift.io", Version: "v1", Resource: "builds"},
+	{Group: "build.openshift.io", Version: "v1", Resource: "buildconfigs"},
+	{Group: "image.openshift.io", Version: "v1", Resource: "imagestreams"},
+	{Group: "apps.openshift.io", Version: "v1", Resource: "deploymentconfigs"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "", Version: "v1", Resource: "events"},
+}
+
+const redacted = "<redacted>"
+
+var (
+	// sensitiveName matches the names of environment variables that may hold credentials.
+	sensitiveName = regexp.MustCompile(`(?i)pass|secret|token|key|credential`)
+	// urlCredentials matches the credentials of URLs.
+	urlCredentials = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+)
+
+// SupportBundleOptions contains all the options needed to collect a support bundle
+type SupportBundleOptions struct {
+	Namespaces []string
+	DestFile   string
+	BuildLogs  bool
+
+	DynamicClient dynamic.Interface
+	// GetBuildLogs returns the logs of a build.
+	GetBuildLogs func(ctx context.Context, namespace, name string) ([]byte, error)
+
+	genericiooptions.IOStreams
+}
+
+func NewSupportBundleOptions(streams genericiooptions.IOStreams) *SupportBundleOptions {
+	return &SupportBundleOptions{
+		DestFile:  fmt.Sprintf("support-bundle-%s.tar.gz", time.Now().Format("20060102-150405")),
+		IOStreams: streams,
+	}
+}
+
+// NewCmdSupportBundle implements the OpenShift experimental support-bundle command
+func NewCmdSupportBundle(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewSupportBundleOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "support-bundle [NAMESPACE...]",
+		Short:   "Collect the build and deployment resources of namespaces for support",
+		Long:    supportBundleLong,
+		Example: supportBundleExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.DestFile, "dest-file", o.DestFile, "Tarball to write the collected resources to. It must not exist.")
+	cmd.Flags().BoolVar(&o.BuildLogs, "build-logs", o.BuildLogs, "If true, collect the logs of the builds.")
+
+	return cmd
+}
+
+func (o *SupportBundleOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Namespaces = args
+	if len(o.Namespaces) == 0 {
+		namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return err
+		}
+		o.Namespaces = []string{namespace}
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.DynamicClient, err = dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	buildClient, err := buildv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.GetBuildLogs = func(ctx context.Context, namespace, name string) ([]byte, error) {
+		return buildmanualclientv1.NewBuildLogClient(buildClient.RESTClient(), namespace, scheme.Scheme).Logs(name, buildv1.BuildLogOptions{}).DoRaw(ctx)
+	}
+	return nil
+}
+
+func (o *SupportBundleOptions) Validate() error {
+	if len(o.DestFile) == 0 {
+		return fmt.Errorf("--dest-file is required")
+	}
+	return nil
+}
+
+func (o *SupportBundleOptions) Run() error {
+	file, err := os.OpenFile(o.DestFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	if err := o.collect(context.TODO(), tw); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.Out, "Support bundle written to %s\n", o.DestFile)
+	return nil
+}
+
+// collect writes the resources of every namespace, and the logs of their
+// builds, to tw. Resources that can't be listed are skipped with a warning.
+func (o *SupportBundleOptions) collect(ctx context.Context, tw *tar.Writer) error {
+	now := time.Now()
+	for _, namespace := range o.Namespaces {
+		for _, gvr := range collectedResources {
+			list, err := o.DynamicClient.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				fmt.Fprintf(o.ErrOut, "warning: unable to collect %s in %q: %v\n", gvr.GroupResource(), namespace, err)
+				continue
+			}
+			for i := range list.Items {
+				redact(list.Items[i].Object)
+			}
+			data, err := yaml.Marshal(list.UnstructuredContent())
+			if err != nil {
+				return err
+			}
+			if err := writeFile(tw, path.Join(namespace, gvr.GroupResource().String()+".yaml"), data, now); err != nil {
+				return err
+			}
+
+			if !o.BuildLogs || gvr.Resource != "builds" {
+				continue
+			}
+			if err := o.collectBuildLogs(ctx, tw, namespace, list, now); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (o *SupportBundleOptions) collectBuildLogs(ctx context.Context, tw *tar.Writer, namespace string, builds *unstructured.UnstructuredList, now time.Time) error {
+	for _, build := range builds.Items {
+		logs, err := o.GetBuildLogs(ctx, namespace, build.GetName())
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: unable to collect the logs of build %q in %q: %v\n", build.GetName(), namespace, err)
+			continue
+		}
+		logs = urlCredentials.ReplaceAll(logs, []byte("${1}"+redacted+"@"))
+		if err := writeFile(tw, path.Join(namespace, "logs", build.GetName()+".log"), logs, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// redact removes the values that may hold credentials from obj.
+func redact(obj map[string]interface{}) {
+	unstructured.RemoveNestedField(obj, "metadata", "managedFields")
+	unstructured.RemoveNestedField(obj, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	redactValue(obj)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch t := value.(type) {
+	case map[string]interface{}:
+		name, isEnvVar := t["name"].(string)
+		if _, hasValue := t["value"].(string); isEnvVar && hasValue && sensitiveName.MatchString(name) {
+			t["value"] = redacted
+		}
+		for k, v := range t {
+			t[k] = redactValue(v)
+		}
+	case []interface{}:
+		for i, v := range t {
+			t[i] = redactValue(v)
+		}
+	case string:
+		return urlCredentials.ReplaceAllString(t, "${1}"+redacted+"@")
+	}
+	return value
+}
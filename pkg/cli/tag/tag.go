@@ -2,6 +2,7 @@ package tag
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -23,6 +24,7 @@ import (
 	imagev1 "github.com/openshift/api/image/v1"
 	imagev1typedclient "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
 	"github.com/openshift/library-go/pkg/image/imageutil"
+	cmdutil "github.com/openshift/oc/pkg/helpers/cmd"
 	imagehelpers "github.com/openshift/oc/pkg/helpers/image"
 )
 
@@ -35,6 +37,7 @@ type TagOptions struct {
 	scheduleTag  bool
 	insecureTag  bool
 	referenceTag bool
+	showDiff     bool
 	namespace    string
 
 	referencePolicy string
@@ -117,6 +120,7 @@ func NewCmdTag(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Co
 	cmd.Flags().BoolVar(&o.insecureTag, "insecure", o.insecureTag, "Set to true if importing the specified container image requires HTTP or has a self-signed certificate. Defaults to false.")
 	cmd.Flags().StringVar(&o.referencePolicy, "reference-policy", SourceReferencePolicy, "Allow to request pullthrough for external image when set to 'local'. Defaults to 'source'.")
 	cmd.Flags().StringVar(&o.importMode, "import-mode", o.importMode, "Imports the full manifest list of a tag when set to 'PreserveOriginal'. Defaults to 'Legacy'.")
+	cmd.Flags().BoolVar(&o.showDiff, "show-diff", o.showDiff, "If true, print a unified diff of each destination image stream before and after the change.")
 
 	return cmd
 }
@@ -404,6 +408,14 @@ func (o TagOptions) Run() error {
 			return fmt.Errorf("%q must be of the form <stream_name>:<tag>", destNameAndTag)
 		}
 
+		var before []byte
+		if o.showDiff {
+			var err error
+			if before, err = o.imageStreamJSON(o.destNamespace[i], destName); err != nil {
+				return err
+			}
+		}
+
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			isc := o.client.ImageStreams(o.destNamespace[i])
 
@@ -606,7 +618,30 @@ func (o TagOptions) Run() error {
 		if err != nil {
 			return err
 		}
+
+		if o.showDiff {
+			after, err := o.imageStreamJSON(o.destNamespace[i], destName)
+			if err != nil {
+				return err
+			}
+			if err := cmdutil.PrintDiff(o.Out, "imagestreams/"+destName, before, after); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+// imageStreamJSON returns the image stream in JSON, or nothing if it doesn't
+// exist.
+func (o TagOptions) imageStreamJSON(namespace, name string) ([]byte, error) {
+	stream, err := o.client.ImageStreams(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	switch {
+	case kerrors.IsNotFound(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+	return json.Marshal(stream)
+}
@@ -2,6 +2,7 @@ package tag
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
@@ -351,6 +352,37 @@ func TestRunTag_AddNew(t *testing.T) {
 	}
 }
 
+func TestRunTag_ShowDiff(t *testing.T) {
+	client := fakeimagev1client.NewSimpleClientset(testData()[0])
+	// force the fallback to updating the image stream
+	client.PrependReactor("*", "imagestreamtags", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		return true, nil, kapierrors.NewMethodNotSupported(image.Resource("imagestreamtags"), action.GetVerb())
+	})
+	streams, _, out, _ := genericiooptions.NewTestIOStreams()
+	opts := &TagOptions{
+		IOStreams: streams,
+		client:    client.ImageV1(),
+		ref: imagev1.DockerImageReference{
+			Namespace: "openshift",
+			Name:      "ruby",
+			Tag:       "2.0",
+		},
+		sourceKind:     "ImageStreamTag",
+		destNamespace:  []string{"yourproject"},
+		destNameAndTag: []string{"rails:tip"},
+		showDiff:       true,
+	}
+
+	if err := opts.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"--- a/imagestreams/rails\n", "+++ b/imagestreams/rails\n", "+      name: ruby:2.0\n", "+    name: tip\n"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out.String())
+		}
+	}
+}
+
 func TestRunTag_AddRestricted(t *testing.T) {
 	client := fakeimagev1client.NewSimpleClientset()
 	client.PrependReactor("create", "imagestreamtags", func(action clientgotesting.Action) (handled bool, ret runtime.Object, err error) {
@@ -0,0 +1,165 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	osutil "github.com/openshift/oc/pkg/helpers/cmd"
+)
+
+var (
+	listLong = templates.LongDesc(`
+		List the OAuth access tokens of the current user.
+
+		Tokens are listed from the oldest to the newest, along with the OAuth client they were
+		requested by, when they expire and their scopes. The token of the current context is
+		marked with a '*'.
+	`)
+
+	listExample = templates.Examples(`
+		# List your access tokens
+		oc ex tokens list
+
+		# List the names of your access tokens, e.g. to revoke them
+		oc ex tokens list --no-headers --show-kind
+	`)
+)
+
+// ListOptions contains all the options needed to list the access tokens of the current user
+type ListOptions struct {
+	// Current is the name of the token of the current context.
+	Current string
+	Client  oauthv1client.UserOAuthAccessTokenInterface
+
+	PrintFlags *osutil.ReportPrintFlags
+
+	// Now is the time expiries are compared with, it defaults to the current time.
+	Now time.Time
+
+	genericiooptions.IOStreams
+}
+
+func NewListOptions(streams genericiooptions.IOStreams) *ListOptions {
+	return &ListOptions{
+		PrintFlags: osutil.NewReportPrintFlags(),
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdList implements the OpenShift experimental tokens list command
+func NewCmdList(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewListOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List your OAuth access tokens",
+		Long:    listLong,
+		Example: listExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+	o.PrintFlags.AddFlags(cmd)
+	return cmd
+}
+
+func (o *ListOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	if o.Current, err = currentTokenName(f); err != nil {
+		return err
+	}
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	client, err := oauthv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.Client = client.UserOAuthAccessTokens()
+	return nil
+}
+
+func (o *ListOptions) Validate() error {
+	return o.PrintFlags.Validate()
+}
+
+func (o *ListOptions) Run() error {
+	if o.Now.IsZero() {
+		o.Now = time.Now()
+	}
+	tokens, err := listTokens(context.TODO(), o.Client)
+	if err != nil {
+		return err
+	}
+	if o.PrintFlags.Structured() {
+		return o.PrintFlags.PrintStructured(o.Out, tokens)
+	}
+	if len(tokens) == 0 {
+		fmt.Fprintln(o.ErrOut, "No access tokens found.")
+		return nil
+	}
+	printTokens(o.Out, o.PrintFlags, tokens, o.Current, o.Now)
+	return nil
+}
+
+// listTokens returns the access tokens of the current user, oldest first.
+func listTokens(ctx context.Context, client oauthv1client.UserOAuthAccessTokenInterface) ([]oauthv1.UserOAuthAccessToken, error) {
+	list, err := client.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list your access tokens: %v", err)
+	}
+	tokens := list.Items
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if !tokens[i].CreationTimestamp.Equal(&tokens[j].CreationTimestamp) {
+			return tokens[i].CreationTimestamp.Before(&tokens[j].CreationTimestamp)
+		}
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens, nil
+}
+
+func printTokens(out io.Writer, printFlags *osutil.ReportPrintFlags, tokens []oauthv1.UserOAuthAccessToken, current string, now time.Time) {
+	w := printFlags.NewTable(out, "CURRENT", "NAME", "CLIENT", "AGE", "EXPIRES", "SCOPES")
+	defer w.Flush()
+	for i := range tokens {
+		token := &tokens[i]
+		mark := ""
+		if len(current) > 0 && token.Name == current {
+			mark = "*"
+		}
+		expires := "never"
+		switch at := expiry(token); {
+		case at.IsZero():
+		case at.After(now):
+			expires = "in " + duration.HumanDuration(at.Sub(now))
+		default:
+			expires = "expired"
+		}
+		scopes := strings.Join(token.Scopes, ",")
+		if len(scopes) == 0 {
+			scopes = "<none>"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", mark, printFlags.Name("useroauthaccesstoken", token.Name), token.ClientName, duration.HumanDuration(now.Sub(token.CreationTimestamp.Time)), expires, scopes)
+	}
+}
@@ -0,0 +1,142 @@
+package tokens
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	oauthv1client "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+)
+
+var (
+	revokeLong = templates.LongDesc(`
+		Revoke OAuth access tokens of the current user.
+
+		Revoked tokens can't be used anymore, the sessions using them must log in again. With
+		--all, every token but the one of the current context is revoked, so that you stay
+		logged in here. Add --include-current to revoke it as well.
+	`)
+
+	revokeExample = templates.Examples(`
+		# Revoke an access token listed by 'oc ex tokens list'
+		oc ex tokens revoke sha256~XXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXXX
+
+		# Revoke all your access tokens but the current one, e.g. after losing a laptop
+		oc ex tokens revoke --all
+	`)
+)
+
+// RevokeOptions contains all the options needed to revoke access tokens of the current user
+type RevokeOptions struct {
+	Names          []string
+	All            bool
+	IncludeCurrent bool
+
+	// Current is the name of the token of the current context.
+	Current string
+	Client  oauthv1client.UserOAuthAccessTokenInterface
+
+	genericiooptions.IOStreams
+}
+
+func NewRevokeOptions(streams genericiooptions.IOStreams) *RevokeOptions {
+	return &RevokeOptions{
+		IOStreams: streams,
+	}
+}
+
+// NewCmdRevoke implements the OpenShift experimental tokens revoke command
+func NewCmdRevoke(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewRevokeOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "revoke (NAME... | --all)",
+		Short:   "Revoke your OAuth access tokens",
+		Long:    revokeLong,
+		Example: revokeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVar(&o.All, "all", o.All, "If true, revoke all your access tokens but the one of the current context.")
+	cmd.Flags().BoolVar(&o.IncludeCurrent, "include-current", o.IncludeCurrent, "If true, with --all, also revoke the access token of the current context, logging it out.")
+
+	return cmd
+}
+
+func (o *RevokeOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	o.Names = args
+
+	var err error
+	if o.Current, err = currentTokenName(f); err != nil {
+		return err
+	}
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	client, err := oauthv1client.NewForConfig(clientConfig)
+	if err != nil {
+		return err
+	}
+	o.Client = client.UserOAuthAccessTokens()
+	return nil
+}
+
+func (o *RevokeOptions) Validate() error {
+	switch {
+	case len(o.Names) > 0 && o.All:
+		return fmt.Errorf("token names can't be combined with --all")
+	case len(o.Names) == 0 && !o.All:
+		return fmt.Errorf("token names or --all are required")
+	case o.IncludeCurrent && !o.All:
+		return fmt.Errorf("--include-current requires --all")
+	}
+	return nil
+}
+
+func (o *RevokeOptions) Run() error {
+	ctx := context.TODO()
+	names := o.Names
+	if o.All {
+		tokens, err := listTokens(ctx, o.Client)
+		if err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			if token.Name == o.Current && !o.IncludeCurrent {
+				continue
+			}
+			names = append(names, token.Name)
+		}
+	}
+
+	failed := false
+	for _, name := range names {
+		if err := o.Client.Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+			if kerrors.IsNotFound(err) {
+				err = fmt.Errorf("no access token named %q, list them with 'oc ex tokens list'", name)
+			}
+			fmt.Fprintf(o.ErrOut, "error: unable to revoke %s: %v\n", name, err)
+			failed = true
+			continue
+		}
+		fmt.Fprintf(o.Out, "useroauthaccesstoken/%s revoked\n", name)
+		if name == o.Current {
+			fmt.Fprintln(o.ErrOut, "The token of the current context was revoked, log in again to keep using it.")
+		}
+	}
+	if failed {
+		return kcmdutil.ErrExit
+	}
+	return nil
+}
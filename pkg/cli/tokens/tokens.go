@@ -0,0 +1,63 @@
+package tokens
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+)
+
+const sha256Prefix = "sha256~"
+
+var tokensLong = templates.LongDesc(`
+	Manage the OAuth access tokens of the current user.
+
+	Every login, through oc or the web console, creates an access token that stays valid
+	until it expires. List them to spot the sessions you don't know about anymore, and
+	revoke them, e.g. after losing a laptop.
+`)
+
+// NewCmdTokens implements the OpenShift experimental tokens command
+func NewCmdTokens(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage your OAuth access tokens",
+		Long:  tokensLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(NewCmdList(f, streams))
+	cmd.AddCommand(NewCmdRevoke(f, streams))
+	return cmd
+}
+
+// currentTokenName returns the name of the access token object of the bearer
+// token of the current context, empty when it doesn't use one. The names of
+// sha256 tokens are their hash, the names of older tokens the token itself.
+func currentTokenName(f kcmdutil.Factory) (string, error) {
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return "", err
+	}
+	token := clientConfig.BearerToken
+	if !strings.HasPrefix(token, sha256Prefix) {
+		return token, nil
+	}
+	h := sha256.Sum256([]byte(strings.TrimPrefix(token, sha256Prefix)))
+	return sha256Prefix + base64.RawURLEncoding.EncodeToString(h[0:]), nil
+}
+
+// expiry returns when token expires, zero when it doesn't.
+func expiry(token *oauthv1.UserOAuthAccessToken) time.Time {
+	if token.ExpiresIn <= 0 {
+		return time.Time{}
+	}
+	return token.CreationTimestamp.Add(time.Duration(token.ExpiresIn) * time.Second)
+}
@@ -0,0 +1,153 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	oauthv1 "github.com/openshift/api/oauth/v1"
+	oauthfake "github.com/openshift/client-go/oauth/clientset/versioned/fake"
+)
+
+var now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func newToken(name, client string, age time.Duration, expiresIn int64, scopes ...string) *oauthv1.UserOAuthAccessToken {
+	return &oauthv1.UserOAuthAccessToken{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+		ClientName: client,
+		ExpiresIn:  expiresIn,
+		Scopes:     scopes,
+	}
+}
+
+func newClient() *oauthfake.Clientset {
+	return oauthfake.NewSimpleClientset(
+		newToken("sha256~laptop", "openshift-challenging-client", 48*time.Hour, 24*3600, "user:full"),
+		newToken("sha256~current", "openshift-challenging-client", time.Hour, 24*3600, "user:full"),
+		newToken("sha256~console", "console", 2*time.Hour, 0),
+	)
+}
+
+func TestList(t *testing.T) {
+	out := &bytes.Buffer{}
+	o := NewListOptions(genericiooptions.IOStreams{Out: out, ErrOut: &bytes.Buffer{}})
+	o.Current = "sha256~current"
+	o.Client = newClient().OauthV1().UserOAuthAccessTokens()
+	o.Now = now
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `CURRENT  NAME            CLIENT                        AGE   EXPIRES  SCOPES
+         sha256~laptop   openshift-challenging-client  2d    expired  user:full
+         sha256~console  console                       120m  never    <none>
+*        sha256~current  openshift-challenging-client  60m   in 23h   user:full
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	o.PrintFlags.NoHeaders = true
+	o.PrintFlags.ShowKind = true
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected = `   useroauthaccesstoken/sha256~laptop   openshift-challenging-client  2d    expired  user:full
+   useroauthaccesstoken/sha256~console  console                       120m  never    <none>
+*  useroauthaccesstoken/sha256~current  openshift-challenging-client  60m   in 23h   user:full
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	o.PrintFlags.OutputFormat = "json"
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	tokens := []oauthv1.UserOAuthAccessToken{}
+	if err := json.Unmarshal(out.Bytes(), &tokens); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, out.String())
+	}
+	if len(tokens) != 3 || tokens[0].Name != "sha256~laptop" {
+		t.Errorf("expected the tokens oldest first, got %#v", tokens)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   RevokeOptions
+		remaining []string
+		err       bool
+	}{
+		{
+			name:      "by name",
+			options:   RevokeOptions{Names: []string{"sha256~laptop"}},
+			remaining: []string{"sha256~console", "sha256~current"},
+		},
+		{
+			name:      "all but current",
+			options:   RevokeOptions{All: true},
+			remaining: []string{"sha256~current"},
+		},
+		{
+			name:      "all",
+			options:   RevokeOptions{All: true, IncludeCurrent: true},
+			remaining: []string{},
+		},
+		{
+			name:      "unknown",
+			options:   RevokeOptions{Names: []string{"sha256~unknown", "sha256~console"}},
+			remaining: []string{"sha256~current", "sha256~laptop"},
+			err:       true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newClient().OauthV1().UserOAuthAccessTokens()
+			errOut := &bytes.Buffer{}
+			o := tc.options
+			o.Current, o.Client = "sha256~current", client
+			o.IOStreams = genericiooptions.IOStreams{Out: &bytes.Buffer{}, ErrOut: errOut}
+			if err := o.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if err := o.Run(); (err != nil) != tc.err {
+				t.Fatalf("unexpected error %v: %s", err, errOut.String())
+			}
+			if tc.err && !strings.Contains(errOut.String(), `no access token named "sha256~unknown"`) {
+				t.Errorf("expected the unknown token to be reported, got %q", errOut.String())
+			}
+			list, err := client.List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			remaining := []string{}
+			for _, token := range list.Items {
+				remaining = append(remaining, token.Name)
+			}
+			if strings.Join(remaining, ",") != strings.Join(tc.remaining, ",") {
+				t.Errorf("expected %v to remain, got %v", tc.remaining, remaining)
+			}
+		})
+	}
+}
+
+func TestValidateRevoke(t *testing.T) {
+	for _, o := range []RevokeOptions{
+		{},
+		{Names: []string{"sha256~laptop"}, All: true},
+		{Names: []string{"sha256~laptop"}, IncludeCurrent: true},
+	} {
+		if err := o.Validate(); err == nil {
+			t.Errorf("expected %#v to be rejected", o)
+		}
+	}
+}
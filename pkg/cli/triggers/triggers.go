@@ -0,0 +1,391 @@
+package triggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/oc/pkg/helpers/describe"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// PausedTriggersAnnotation holds the state of the image change triggers of a
+// build config or deployment config before they were paused, so that resume
+// restores it exactly.
+const PausedTriggersAnnotation = "oc.openshift.io/paused-triggers"
+
+var (
+	triggersLong = templates.LongDesc(`
+		Pause and resume the image change triggers of build configs and deployment configs in bulk.
+
+		Pausing the triggers keeps new images from starting builds and rollouts, e.g. during a
+		maintenance window. The state of the triggers before the pause is recorded in the
+		oc.openshift.io/paused-triggers annotation, so that resuming restores it exactly: triggers
+		that were already paused or manual stay so.
+	`)
+
+	pauseExample = templates.Examples(`
+		# Pause the image change triggers of the build configs and deployment configs of the current namespace
+		oc ex triggers pause
+
+		# Pause the image change triggers of everything labeled tier=frontend in all namespaces
+		oc ex triggers pause -A -l tier=frontend
+
+		# Pause the image change triggers of everything downstream of the 'base:latest' image stream tag
+		oc ex triggers pause --downstream-of=base:latest
+	`)
+
+	resumeExample = templates.Examples(`
+		# Resume the image change triggers paused in the current namespace
+		oc ex triggers resume
+
+		# Resume the image change triggers paused downstream of the 'base:latest' image stream tag
+		oc ex triggers resume --downstream-of=base:latest
+	`)
+)
+
+// PausedTrigger is the state of an image change trigger before it was paused.
+type PausedTrigger struct {
+	// From is the image stream tag the trigger follows, empty for the input
+	// image of the strategy of a build config.
+	From string `json:"from"`
+	// Active is whether the trigger fired: not paused for a build config,
+	// automatic for a deployment config.
+	Active bool `json:"active"`
+}
+
+// TriggersOptions contains all the options needed to pause or resume image change triggers
+type TriggersOptions struct {
+	Pause         bool
+	Namespace     string
+	AllNamespaces bool
+	Selector      string
+	// DownstreamOf is the image stream tag, as name:tag, whose build chain
+	// the paused or resumed objects are taken from.
+	DownstreamOf string
+
+	BuildClient buildv1client.BuildV1Interface
+	AppsClient  appsv1client.AppsV1Interface
+
+	genericiooptions.IOStreams
+}
+
+func NewTriggersOptions(pause bool, streams genericiooptions.IOStreams) *TriggersOptions {
+	return &TriggersOptions{
+		Pause:     pause,
+		IOStreams: streams,
+	}
+}
+
+// NewCmdTriggers implements the OpenShift experimental triggers command
+func NewCmdTriggers(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "triggers",
+		Short: "Pause and resume image change triggers in bulk",
+		Long:  triggersLong,
+		Run:   kcmdutil.DefaultSubCommandRun(streams.ErrOut),
+	}
+	cmd.AddCommand(newCmd(f, streams, true, "pause", "Pause the image change triggers of build configs and deployment configs", pauseExample))
+	cmd.AddCommand(newCmd(f, streams, false, "resume", "Resume the image change triggers paused by 'pause'", resumeExample))
+	return cmd
+}
+
+func newCmd(f kcmdutil.Factory, streams genericiooptions.IOStreams, pause bool, use, short, example string) *cobra.Command {
+	o := NewTriggersOptions(pause, streams)
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   short,
+		Long:    triggersLong,
+		Example: example,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().BoolVarP(&o.AllNamespaces, "all-namespaces", "A", o.AllNamespaces, "If true, select the build configs and deployment configs of all namespaces.")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", o.Selector, "Selector (label query) the build configs and deployment configs must match.")
+	cmd.Flags().StringVar(&o.DownstreamOf, "downstream-of", o.DownstreamOf, "If set, only select the build configs in the build chain of this image stream tag, and the deployment configs triggered by its image stream tags.")
+
+	return cmd
+}
+
+func (o *TriggersOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	if o.AllNamespaces {
+		o.Namespace = metav1.NamespaceAll
+	}
+
+	clientConfig, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	if o.BuildClient, err = buildv1client.NewForConfig(clientConfig); err != nil {
+		return err
+	}
+	o.AppsClient, err = appsv1client.NewForConfig(clientConfig)
+	return err
+}
+
+func (o *TriggersOptions) Validate() error {
+	if len(o.DownstreamOf) > 0 {
+		if _, _, ok := imageutil.SplitImageStreamTag(o.DownstreamOf); !ok {
+			return fmt.Errorf("--downstream-of must be an image stream tag as name:tag")
+		}
+		if o.AllNamespaces {
+			return fmt.Errorf("--downstream-of can't be combined with --all-namespaces")
+		}
+	}
+	return nil
+}
+
+func (o *TriggersOptions) Run() error {
+	ctx := context.TODO()
+	listOptions := metav1.ListOptions{LabelSelector: o.Selector}
+	buildConfigs, err := o.BuildClient.BuildConfigs(o.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+	dcs, err := o.AppsClient.DeploymentConfigs(o.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return err
+	}
+
+	var downstreamBCs, downstreamTags sets.String
+	if len(o.DownstreamOf) > 0 {
+		if downstreamBCs, downstreamTags, err = o.downstream(); err != nil {
+			return err
+		}
+	}
+
+	changed := 0
+	for i := range buildConfigs.Items {
+		bc := &buildConfigs.Items[i]
+		if downstreamBCs != nil && !downstreamBCs.Has(bc.Namespace+"/"+bc.Name) {
+			continue
+		}
+		ok, err := o.updateBuildConfig(ctx, bc)
+		if err != nil {
+			return err
+		}
+		if ok {
+			changed++
+			o.report("buildconfig", bc.Namespace, bc.Name)
+		}
+	}
+	for i := range dcs.Items {
+		dc := &dcs.Items[i]
+		if downstreamTags != nil && !triggeredBy(dc, downstreamTags) {
+			continue
+		}
+		ok, err := o.updateDeploymentConfig(ctx, dc)
+		if err != nil {
+			return err
+		}
+		if ok {
+			changed++
+			o.report("deploymentconfig", dc.Namespace, dc.Name)
+		}
+	}
+
+	if changed == 0 {
+		if o.Pause {
+			fmt.Fprintln(o.Out, "No image change triggers to pause.")
+		} else {
+			fmt.Fprintln(o.Out, "No paused image change triggers to resume.")
+		}
+	}
+	return nil
+}
+
+func (o *TriggersOptions) report(kind, namespace, name string) {
+	action := "resumed"
+	if o.Pause {
+		action = "paused"
+	}
+	if o.AllNamespaces {
+		fmt.Fprintf(o.Out, "%s/%s in %s: image change triggers %s\n", kind, name, namespace, action)
+		return
+	}
+	fmt.Fprintf(o.Out, "%s/%s: image change triggers %s\n", kind, name, action)
+}
+
+// downstream returns the namespace/name of the build configs and the
+// namespace/name:tag of the image stream tags of the build chain of
+// DownstreamOf, including it.
+func (o *TriggersOptions) downstream() (sets.String, sets.String, error) {
+	stream, tag, _ := imageutil.SplitImageStreamTag(o.DownstreamOf)
+	ist := imagegraph.MakeImageStreamTagObjectMeta(o.Namespace, stream, tag)
+	buildConfigs, tags := sets.NewString(), sets.NewString(o.Namespace+"/"+o.DownstreamOf)
+
+	describer := describe.NewChainDescriber(describe.NewBuildConfigLister(o.BuildClient), sets.NewString(o.Namespace), "json")
+	desc, err := describer.Describe(ist, false, false)
+	if _, isNotFoundErr := err.(describe.NotFoundErr); isNotFoundErr {
+		return buildConfigs, tags, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	chain := &describe.ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), chain); err != nil {
+		return nil, nil, fmt.Errorf("unable to read the build chain of %q: %v", o.DownstreamOf, err)
+	}
+	for _, node := range chain.Nodes {
+		switch node.Kind {
+		case buildgraph.BuildConfigNodeKind:
+			buildConfigs.Insert(node.Namespace + "/" + node.Name)
+		case imagegraph.ImageStreamTagNodeKind:
+			tags.Insert(node.Namespace + "/" + node.Name)
+		}
+	}
+	return buildConfigs, tags, nil
+}
+
+// triggeredBy returns whether dc has an image change trigger following one of
+// the namespace/name:tag tags.
+func triggeredBy(dc *appsv1.DeploymentConfig, tags sets.String) bool {
+	for _, trigger := range dc.Spec.Triggers {
+		if params := trigger.ImageChangeParams; params != nil && tags.Has(deploymentTriggerFrom(dc, params)) {
+			return true
+		}
+	}
+	return false
+}
+
+func deploymentTriggerFrom(dc *appsv1.DeploymentConfig, params *appsv1.DeploymentTriggerImageChangeParams) string {
+	namespace := params.From.Namespace
+	if len(namespace) == 0 {
+		namespace = dc.Namespace
+	}
+	stream, tag, _ := imageutil.SplitImageStreamTag(params.From.Name)
+	return namespace + "/" + imageutil.JoinImageStreamTag(stream, tag)
+}
+
+func buildTriggerFrom(bc *buildv1.BuildConfig, trigger *buildv1.ImageChangeTrigger) string {
+	if trigger.From == nil {
+		return ""
+	}
+	namespace := trigger.From.Namespace
+	if len(namespace) == 0 {
+		namespace = bc.Namespace
+	}
+	return namespace + "/" + trigger.From.Name
+}
+
+// updateBuildConfig pauses or resumes the image change triggers of bc. It
+// returns false if there is nothing to do.
+func (o *TriggersOptions) updateBuildConfig(ctx context.Context, bc *buildv1.BuildConfig) (bool, error) {
+	triggers := []*buildv1.ImageChangeTrigger{}
+	for _, trigger := range bc.Spec.Triggers {
+		if trigger.ImageChange != nil {
+			triggers = append(triggers, trigger.ImageChange)
+		}
+	}
+	active := func(i int) *bool {
+		paused := !triggers[i].Paused
+		return &paused
+	}
+	apply := func(i int, isActive bool) { triggers[i].Paused = !isActive }
+	from := func(i int) string { return buildTriggerFrom(bc, triggers[i]) }
+
+	ok, err := o.update(&bc.ObjectMeta, len(triggers), from, active, apply)
+	if !ok || err != nil {
+		return ok, err
+	}
+	if _, err := o.BuildClient.BuildConfigs(bc.Namespace).Update(ctx, bc, metav1.UpdateOptions{}); err != nil {
+		return false, fmt.Errorf("unable to update build config %q in %q: %v", bc.Name, bc.Namespace, err)
+	}
+	return true, nil
+}
+
+// updateDeploymentConfig pauses or resumes the image change triggers of dc.
+// It returns false if there is nothing to do.
+func (o *TriggersOptions) updateDeploymentConfig(ctx context.Context, dc *appsv1.DeploymentConfig) (bool, error) {
+	triggers := []*appsv1.DeploymentTriggerImageChangeParams{}
+	for _, trigger := range dc.Spec.Triggers {
+		if trigger.ImageChangeParams != nil {
+			triggers = append(triggers, trigger.ImageChangeParams)
+		}
+	}
+	active := func(i int) *bool { return &triggers[i].Automatic }
+	apply := func(i int, isActive bool) { triggers[i].Automatic = isActive }
+	from := func(i int) string { return deploymentTriggerFrom(dc, triggers[i]) }
+
+	ok, err := o.update(&dc.ObjectMeta, len(triggers), from, active, apply)
+	if !ok || err != nil {
+		return ok, err
+	}
+	if _, err := o.AppsClient.DeploymentConfigs(dc.Namespace).Update(ctx, dc, metav1.UpdateOptions{}); err != nil {
+		return false, fmt.Errorf("unable to update deployment config %q in %q: %v", dc.Name, dc.Namespace, err)
+	}
+	return true, nil
+}
+
+// update pauses, recording their state in the annotations of meta, or
+// resumes the n image change triggers of an object. It returns false if the
+// object has no triggers to pause, is already paused, or isn't paused when
+// resuming.
+func (o *TriggersOptions) update(meta *metav1.ObjectMeta, n int, from func(int) string, active func(int) *bool, apply func(int, bool)) (bool, error) {
+	value, paused := meta.Annotations[PausedTriggersAnnotation]
+	if o.Pause {
+		if paused || n == 0 {
+			return false, nil
+		}
+		state := []PausedTrigger{}
+		for i := 0; i < n; i++ {
+			state = append(state, PausedTrigger{From: from(i), Active: *active(i)})
+			apply(i, false)
+		}
+		data, err := json.Marshal(state)
+		if err != nil {
+			return false, err
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = map[string]string{}
+		}
+		meta.Annotations[PausedTriggersAnnotation] = string(data)
+		return true, nil
+	}
+
+	if !paused {
+		return false, nil
+	}
+	state := []PausedTrigger{}
+	if err := json.Unmarshal([]byte(value), &state); err != nil {
+		return false, fmt.Errorf("invalid %s annotation on %q in %q: %v", PausedTriggersAnnotation, meta.Name, meta.Namespace, err)
+	}
+	for i := 0; i < n; i++ {
+		// triggers added while paused are left as they are
+		for _, previous := range state {
+			if previous.From == from(i) {
+				apply(i, previous.Active)
+				break
+			}
+		}
+	}
+	delete(meta.Annotations, PausedTriggersAnnotation)
+	return true, nil
+}
@@ -0,0 +1,162 @@
+package triggers
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	appsfake "github.com/openshift/client-go/apps/clientset/versioned/fake"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+)
+
+func newBuildConfig(name, from, to string, paused bool) *buildv1.BuildConfig {
+	return &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{
+					DockerStrategy: &buildv1.DockerBuildStrategy{From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from}},
+				},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: to}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{
+				{Type: buildv1.ConfigChangeBuildTriggerType},
+				{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{Paused: paused}},
+			},
+		},
+	}
+}
+
+func newDeploymentConfig(name string, automatic bool, from ...string) *appsv1.DeploymentConfig {
+	dc := &appsv1.DeploymentConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name}}
+	for _, tag := range from {
+		dc.Spec.Triggers = append(dc.Spec.Triggers, appsv1.DeploymentTriggerPolicy{
+			Type: appsv1.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{
+				Automatic: automatic,
+				From:      corev1.ObjectReference{Kind: "ImageStreamTag", Name: tag},
+			},
+		})
+	}
+	return dc
+}
+
+func run(t *testing.T, pause bool, downstreamOf string, buildClient *buildfake.Clientset, appsClient *appsfake.Clientset) string {
+	out := &bytes.Buffer{}
+	o := NewTriggersOptions(pause, genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "test"
+	o.DownstreamOf = downstreamOf
+	o.BuildClient = buildClient.BuildV1()
+	o.AppsClient = appsClient.AppsV1()
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func buildTriggersPaused(t *testing.T, client *buildfake.Clientset, name string) bool {
+	bc, err := client.BuildV1().BuildConfigs("test").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bc.Spec.Triggers[1].ImageChange.Paused
+}
+
+func deploymentTriggersAutomatic(t *testing.T, client *appsfake.Clientset, name string) []bool {
+	dc, err := client.AppsV1().DeploymentConfigs("test").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	automatic := []bool{}
+	for _, trigger := range dc.Spec.Triggers {
+		automatic = append(automatic, trigger.ImageChangeParams.Automatic)
+	}
+	return automatic
+}
+
+func TestPauseResume(t *testing.T) {
+	buildClient := buildfake.NewSimpleClientset(
+		newBuildConfig("active", "base:latest", "app:latest", false),
+		newBuildConfig("paused", "base:latest", "other:latest", true),
+	)
+	appsClient := appsfake.NewSimpleClientset(
+		newDeploymentConfig("mixed", true, "app:latest"),
+		newDeploymentConfig("manual", false, "other:latest"),
+		newDeploymentConfig("untriggered", false),
+	)
+
+	out := run(t, true, "", buildClient, appsClient)
+	expected := "buildconfig/active: image change triggers paused\n" +
+		"buildconfig/paused: image change triggers paused\n" +
+		"deploymentconfig/manual: image change triggers paused\n" +
+		"deploymentconfig/mixed: image change triggers paused\n"
+	if out != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out, expected)
+	}
+	if !buildTriggersPaused(t, buildClient, "active") || !buildTriggersPaused(t, buildClient, "paused") {
+		t.Errorf("expected the triggers of every build config to be paused")
+	}
+	if automatic := deploymentTriggersAutomatic(t, appsClient, "mixed"); !reflect.DeepEqual(automatic, []bool{false}) {
+		t.Errorf("expected the triggers of the deployment config to be manual, got %v", automatic)
+	}
+
+	if out := run(t, true, "", buildClient, appsClient); out != "No image change triggers to pause.\n" {
+		t.Errorf("expected pausing twice to do nothing, got:\n%s", out)
+	}
+
+	run(t, false, "", buildClient, appsClient)
+	if buildTriggersPaused(t, buildClient, "active") || !buildTriggersPaused(t, buildClient, "paused") {
+		t.Errorf("expected the triggers of the build configs to be restored")
+	}
+	if automatic := deploymentTriggersAutomatic(t, appsClient, "mixed"); !reflect.DeepEqual(automatic, []bool{true}) {
+		t.Errorf("expected the triggers of mixed to be restored, got %v", automatic)
+	}
+	if automatic := deploymentTriggersAutomatic(t, appsClient, "manual"); !reflect.DeepEqual(automatic, []bool{false}) {
+		t.Errorf("expected the triggers of manual to be restored, got %v", automatic)
+	}
+	bc, err := buildClient.BuildV1().BuildConfigs("test").Get(context.TODO(), "active", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := bc.Annotations[PausedTriggersAnnotation]; ok {
+		t.Errorf("expected the %s annotation to be removed", PausedTriggersAnnotation)
+	}
+
+	if out := run(t, false, "", buildClient, appsClient); out != "No paused image change triggers to resume.\n" {
+		t.Errorf("expected resuming twice to do nothing, got:\n%s", out)
+	}
+}
+
+func TestPauseDownstreamOf(t *testing.T) {
+	buildClient := buildfake.NewSimpleClientset(
+		newBuildConfig("app", "base:latest", "app:latest", false),
+		newBuildConfig("unrelated", "other:latest", "unrelated:latest", false),
+	)
+	appsClient := appsfake.NewSimpleClientset(
+		newDeploymentConfig("app", true, "app"),
+		newDeploymentConfig("unrelated", true, "unrelated:latest"),
+	)
+
+	out := run(t, true, "base:latest", buildClient, appsClient)
+	expected := "buildconfig/app: image change triggers paused\n" +
+		"deploymentconfig/app: image change triggers paused\n"
+	if out != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", out, expected)
+	}
+	if buildTriggersPaused(t, buildClient, "unrelated") {
+		t.Errorf("expected the triggers of build configs outside of the chain to be left alone")
+	}
+	if automatic := deploymentTriggersAutomatic(t, appsClient, "unrelated"); !reflect.DeepEqual(automatic, []bool{true}) {
+		t.Errorf("expected the triggers of deployment configs outside of the chain to be left alone, got %v", automatic)
+	}
+}
@@ -0,0 +1,399 @@
+package verifyregistry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/registry/api/errcode"
+	registryapiv2 "github.com/distribution/distribution/v3/registry/api/v2"
+	"github.com/spf13/cobra"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	kcmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/templates"
+
+	imageclient "github.com/openshift/client-go/image/clientset/versioned"
+	"github.com/openshift/library-go/pkg/image/registryclient"
+	"github.com/openshift/oc/pkg/cli/registry/info"
+)
+
+var (
+	verifyRegistryLong = templates.LongDesc(`
+		Check that the integrated registry is reachable and that you can push to it.
+
+		The checks follow the path of an image push, to explain the most common reasons
+		for failed pushes:
+
+		* service: the registry service has ready endpoints.
+		* route: the public hostname of the registry serves a certificate this client trusts.
+		* pull: the registry accepts the token of the current session to pull from a
+		  scratch repository of the current project.
+		* push: the token may push to the scratch repository. The check starts an upload
+		  and cancels it right away, nothing is stored in the registry.
+
+		Failed checks are followed by a hint on how to fix them. The command fails if any
+		of the checks fails.
+	`)
+
+	verifyRegistryExample = templates.Examples(`
+		# Check that you can push images to the integrated registry from the current project
+		oc ex verify-registry
+
+		# Check the registry through a hostname of your own, skipping TLS verification
+		oc ex verify-registry --registry=registry.example.com --insecure
+	`)
+)
+
+const (
+	resultOK      = "ok"
+	resultFailed  = "failed"
+	resultSkipped = "skipped"
+)
+
+// VerifyRegistryOptions contains all the options needed to check the integrated registry
+type VerifyRegistryOptions struct {
+	Registry   string
+	Repository string
+	Insecure   bool
+	Timeout    time.Duration
+
+	Namespace   string
+	Token       string
+	KubeClient  kubernetes.Interface
+	ImageClient imageclient.Interface
+
+	// RootCAs are the authorities trusted to sign the certificate of the
+	// registry, the ones of the system when nil.
+	RootCAs *x509.CertPool
+
+	genericiooptions.IOStreams
+}
+
+func NewVerifyRegistryOptions(streams genericiooptions.IOStreams) *VerifyRegistryOptions {
+	return &VerifyRegistryOptions{
+		Repository: "verify-registry",
+		Timeout:    10 * time.Second,
+		IOStreams:  streams,
+	}
+}
+
+// NewCmdVerifyRegistry implements the OpenShift experimental verify-registry command
+func NewCmdVerifyRegistry(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra.Command {
+	o := NewVerifyRegistryOptions(streams)
+	cmd := &cobra.Command{
+		Use:     "verify-registry",
+		Short:   "Check that you can reach and push to the integrated registry",
+		Long:    verifyRegistryLong,
+		Example: verifyRegistryExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			kcmdutil.CheckErr(o.Complete(f, cmd, args))
+			kcmdutil.CheckErr(o.Validate())
+			kcmdutil.CheckErr(o.Run())
+		},
+	}
+
+	cmd.Flags().StringVar(&o.Registry, "registry", o.Registry, "Hostname of the registry to check. Defaults to the public hostname of the integrated registry, or its internal hostname.")
+	cmd.Flags().StringVar(&o.Repository, "repository", o.Repository, "Name of the scratch repository of the current project the pull and push checks use.")
+	cmd.Flags().BoolVar(&o.Insecure, "insecure", o.Insecure, "If true, skip the verification of the registry certificate, or fall back to HTTP.")
+	cmd.Flags().DurationVar(&o.Timeout, "timeout", o.Timeout, "Time to wait for every check to complete.")
+
+	return cmd
+}
+
+func (o *VerifyRegistryOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return kcmdutil.UsageErrorf(cmd, "no arguments are allowed")
+	}
+
+	var err error
+	o.Namespace, _, err = f.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return err
+	}
+	config, err := f.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	o.Token = config.BearerToken
+	o.KubeClient, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	o.ImageClient, err = imageclient.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (o *VerifyRegistryOptions) Validate() error {
+	if len(o.Repository) == 0 || strings.Contains(o.Repository, "/") {
+		return fmt.Errorf("--repository must be the name of a repository of the current project")
+	}
+	if o.Timeout <= 0 {
+		return fmt.Errorf("--timeout must be a positive duration")
+	}
+	return nil
+}
+
+// checkResult is the outcome of a check, with a hint at what to do about it
+// when it failed.
+type checkResult struct {
+	name    string
+	result  string
+	details string
+	hint    string
+}
+
+func (o *VerifyRegistryOptions) Run() error {
+	ctx := context.TODO()
+
+	registryInfo, err := info.FindRegistryInfo(o.ImageClient, o.Namespace, "openshift")
+	if err != nil && len(o.Registry) == 0 {
+		return fmt.Errorf("%v, use --registry to check a registry hostname", err)
+	}
+	if registryInfo == nil {
+		registryInfo = &info.RegistryInfo{}
+	}
+	host := o.Registry
+	if len(host) == 0 {
+		host, _ = registryInfo.HostPort()
+	}
+
+	results := []checkResult{
+		o.checkService(ctx, registryInfo.Internal),
+		o.checkRoute(registryInfo.Public),
+	}
+	results = append(results, o.checkAccess(ctx, host)...)
+
+	w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tRESULT\tDETAILS")
+	failed := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.name, r.result, r.details)
+		if r.result == resultFailed {
+			failed++
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(o.Out)
+	for _, r := range results {
+		if r.result == resultFailed && len(r.hint) > 0 {
+			fmt.Fprintf(o.Out, "%s: %s\n", r.name, r.hint)
+		}
+	}
+	return fmt.Errorf("%d of %d registry checks failed", failed, len(results))
+}
+
+// checkService checks that the service behind the internal hostname of the
+// registry has ready endpoints.
+func (o *VerifyRegistryOptions) checkService(ctx context.Context, internal string) checkResult {
+	r := checkResult{name: "service"}
+	name, namespace, ok := serviceForHost(internal)
+	if !ok {
+		r.result, r.details = resultSkipped, "the registry has no internal service hostname"
+		return r
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+	endpoints, err := o.KubeClient.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case kerrors.IsForbidden(err):
+		r.result, r.details = resultSkipped, fmt.Sprintf("you may not read the endpoints of service %s/%s", namespace, name)
+		return r
+	case kerrors.IsNotFound(err):
+		r.result, r.details = resultFailed, fmt.Sprintf("service %s/%s doesn't exist", namespace, name)
+		r.hint = "The registry isn't deployed, check its operator with 'oc get clusteroperator image-registry'."
+		return r
+	case err != nil:
+		r.result, r.details = resultFailed, err.Error()
+		return r
+	}
+
+	ready := 0
+	for _, subset := range endpoints.Subsets {
+		ready += len(subset.Addresses)
+	}
+	if ready == 0 {
+		r.result, r.details = resultFailed, fmt.Sprintf("service %s/%s has no ready endpoints", namespace, name)
+		r.hint = fmt.Sprintf("The registry pods aren't running, check them with 'oc get pods -n %s'.", namespace)
+		return r
+	}
+	r.result, r.details = resultOK, fmt.Sprintf("%d ready endpoints behind service %s/%s", ready, namespace, name)
+	return r
+}
+
+// serviceForHost returns the name and namespace of the service a hostname
+// like name.namespace.svc:5000 resolves to.
+func serviceForHost(host string) (string, string, bool) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[2] != "svc" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// checkRoute checks that the public hostname of the registry serves a
+// certificate the client trusts.
+func (o *VerifyRegistryOptions) checkRoute(public string) checkResult {
+	r := checkResult{name: "route"}
+	switch {
+	case len(public) == 0:
+		r.result, r.details = resultSkipped, "the registry has no public hostname"
+		return r
+	case o.Insecure:
+		r.result, r.details = resultSkipped, "the certificate isn't verified with --insecure"
+		return r
+	}
+
+	address := public
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "443")
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: o.Timeout}, "tcp", address, &tls.Config{RootCAs: o.RootCAs})
+	if err != nil {
+		r.result, r.details, r.hint = resultFailed, err.Error(), routeHint(err, public)
+		return r
+	}
+	defer conn.Close()
+
+	certificate := conn.ConnectionState().PeerCertificates[0]
+	r.result, r.details = resultOK, fmt.Sprintf("%s serves a trusted certificate valid until %s", public, certificate.NotAfter.UTC().Format(time.RFC3339))
+	return r
+}
+
+func routeHint(err error, public string) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "The certificate of the route isn't signed by an authority this machine trusts. Add the ingress CA of the cluster to the trust store, or pass --insecure."
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("The certificate of the route doesn't cover %s, check the hostname of the default route of the registry.", public)
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return "The certificate of the route expired, check the ingress certificates of the cluster."
+	default:
+		return fmt.Sprintf("Check that %s resolves and that the ingress routers of the cluster are reachable from this machine.", public)
+	}
+}
+
+// checkAccess checks that the registry at host accepts the token of the
+// session to pull from and push to the scratch repository.
+func (o *VerifyRegistryOptions) checkAccess(ctx context.Context, host string) []checkResult {
+	pull, push := checkResult{name: "pull"}, checkResult{name: "push"}
+	switch {
+	case len(host) == 0:
+		pull.result, pull.details = resultSkipped, "the registry has no hostname, use --registry"
+		push.result, push.details = pull.result, pull.details
+		return []checkResult{pull, push}
+	case len(o.Token) == 0:
+		pull.result, pull.details = resultFailed, "no token is in use for this session"
+		pull.hint = "The registry only accepts tokens, log in with a user or a token with 'oc login'."
+		push.result, push.details = resultSkipped, "the pull check failed"
+		return []checkResult{pull, push}
+	}
+
+	transport := http.DefaultTransport
+	if o.RootCAs != nil {
+		transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: o.RootCAs}}
+	}
+	insecureTransport, err := rest.TransportFor(&rest.Config{TLSClientConfig: rest.TLSClientConfig{Insecure: true}, UserAgent: rest.DefaultKubernetesUserAgent()})
+	if err != nil {
+		pull.result, pull.details = resultFailed, err.Error()
+		push.result, push.details = resultSkipped, "the pull check failed"
+		return []checkResult{pull, push}
+	}
+	registryURL := &url.URL{Host: host}
+	credentials := registryclient.NewBasicCredentials()
+	credentials.Add(registryURL, "user", o.Token)
+	registryContext := registryclient.NewContext(transport, insecureTransport).WithCredentials(credentials)
+	repository := o.Namespace + "/" + o.Repository
+
+	ctx, cancel := context.WithTimeout(ctx, 2*o.Timeout)
+	defer cancel()
+
+	repo, err := registryContext.Repository(ctx, registryURL, repository, o.Insecure)
+	if err == nil {
+		_, err = repo.Tags(ctx).All(ctx)
+	}
+	if err != nil && !hasErrorCode(err, registryapiv2.ErrorCodeNameUnknown) {
+		pull.result, pull.details, pull.hint = resultFailed, err.Error(), o.accessHint(err, host, "system:image-puller")
+		push.result, push.details = resultSkipped, "the pull check failed"
+		return []checkResult{pull, push}
+	}
+	pull.result, pull.details = resultOK, fmt.Sprintf("pulls from %s/%s are allowed", host, repository)
+
+	repo, err = registryContext.Copy().WithActions("pull", "push").Repository(ctx, registryURL, repository, o.Insecure)
+	if err == nil {
+		var upload distribution.BlobWriter
+		upload, err = repo.Blobs(ctx).Create(ctx)
+		if err == nil {
+			err = upload.Cancel(ctx)
+		}
+	}
+	if err != nil {
+		push.result, push.details, push.hint = resultFailed, err.Error(), o.accessHint(err, host, "system:image-builder")
+		return []checkResult{pull, push}
+	}
+	push.result, push.details = resultOK, fmt.Sprintf("pushes to %s/%s are allowed", host, repository)
+	return []checkResult{pull, push}
+}
+
+// accessHint explains why the registry at host turned down a request that
+// role in the current project allows.
+func (o *VerifyRegistryOptions) accessHint(err error, host, role string) string {
+	switch {
+	case hasErrorCode(err, errcode.ErrorCodeUnauthorized):
+		return fmt.Sprintf("The registry rejected your token. Log in again with 'oc login' if it expired, or ask a project admin for the %s role: oc policy add-role-to-user %s <user> -n %s", role, role, o.Namespace)
+	case hasErrorCode(err, errcode.ErrorCodeDenied):
+		return fmt.Sprintf("Ask a project admin for the %s role: oc policy add-role-to-user %s <user> -n %s. The project may also be out of image quota.", role, role, o.Namespace)
+	case errors.As(err, new(x509.UnknownAuthorityError)):
+		return "The certificate of the registry isn't signed by an authority this machine trusts. Add the ingress CA of the cluster to the trust store, or pass --insecure."
+	default:
+		return fmt.Sprintf("Check that %s is reachable from this machine. The internal hostname of the registry is only reachable from inside the cluster.", host)
+	}
+}
+
+// hasErrorCode returns whether err is a registry error with code.
+func hasErrorCode(err error, code errcode.ErrorCode) bool {
+	switch t := err.(type) {
+	case errcode.Errors:
+		for _, err := range t {
+			if hasErrorCode(err, code) {
+				return true
+			}
+		}
+		return false
+	case errcode.Error:
+		return t.Code == code
+	case errcode.ErrorCode:
+		return t == code
+	default:
+		return false
+	}
+}
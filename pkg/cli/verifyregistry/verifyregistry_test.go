@@ -0,0 +1,145 @@
+package verifyregistry
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	fakekubeclient "k8s.io/client-go/kubernetes/fake"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeimageclient "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+// newTestRegistry returns a registry that allows pulls from any repository
+// and denies pushes unless allowPush.
+func newTestRegistry(allowPush bool) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/test/verify-registry/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[{"code":"NAME_UNKNOWN","message":"repository name not known to registry"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test/verify-registry/blobs/uploads/":
+			if !allowPush {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors":[{"code":"DENIED","message":"requested access to the resource is denied"}]}`))
+				return
+			}
+			w.Header().Set("Location", "/v2/test/verify-registry/blobs/uploads/1")
+			w.Header().Set("Docker-Upload-UUID", "1")
+			w.Header().Set("Range", "0-0")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodDelete && r.URL.Path == "/v2/test/verify-registry/blobs/uploads/1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newTestOptions(server *httptest.Server, endpoints ...corev1.EndpointAddress) (*VerifyRegistryOptions, *strings.Builder) {
+	host := server.Listener.Addr().String()
+	out := &strings.Builder{}
+	o := NewVerifyRegistryOptions(genericiooptions.IOStreams{Out: out, ErrOut: out})
+	o.Namespace = "test"
+	o.Token = "sha256~token"
+	o.KubeClient = fakekubeclient.NewSimpleClientset(&corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "image-registry", Namespace: "openshift-image-registry"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: endpoints}},
+	})
+	o.ImageClient = fakeimageclient.NewSimpleClientset(&imagev1.ImageStream{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Status: imagev1.ImageStreamStatus{
+			DockerImageRepository:       "image-registry.openshift-image-registry.svc:5000/test/app",
+			PublicDockerImageRepository: host + "/test/app",
+		},
+	})
+	o.RootCAs = x509.NewCertPool()
+	o.RootCAs.AddCert(server.Certificate())
+	return o, out
+}
+
+func TestRun(t *testing.T) {
+	server := newTestRegistry(true)
+	defer server.Close()
+	o, out := newTestOptions(server, corev1.EndpointAddress{IP: "10.0.0.1"})
+
+	if err := o.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Run(); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out)
+	}
+	for _, expected := range []string{
+		"service  ok      1 ready endpoints behind service openshift-image-registry/image-registry",
+		"route    ok      " + server.Listener.Addr().String() + " serves a trusted certificate",
+		"pull     ok",
+		"push     ok",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out)
+		}
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	server := newTestRegistry(false)
+	defer server.Close()
+	o, out := newTestOptions(server)
+
+	err := o.Run()
+	if err == nil || err.Error() != "2 of 4 registry checks failed" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"service  failed  service openshift-image-registry/image-registry has no ready endpoints",
+		"pull     ok",
+		"push     failed",
+		"service: The registry pods aren't running, check them with 'oc get pods -n openshift-image-registry'.",
+		"push: Ask a project admin for the system:image-builder role: oc policy add-role-to-user system:image-builder <user> -n test.",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out)
+		}
+	}
+}
+
+func TestCheckRouteUntrusted(t *testing.T) {
+	server := newTestRegistry(true)
+	defer server.Close()
+	o, _ := newTestOptions(server)
+	o.RootCAs = x509.NewCertPool()
+
+	r := o.checkRoute(server.Listener.Addr().String())
+	if r.result != resultFailed || !strings.Contains(r.hint, "pass --insecure") {
+		t.Errorf("expected the untrusted certificate to fail the route check, got %#v", r)
+	}
+}
+
+func TestServiceForHost(t *testing.T) {
+	tests := []struct {
+		host, name, namespace string
+		ok                    bool
+	}{
+		{host: "image-registry.openshift-image-registry.svc:5000", name: "image-registry", namespace: "openshift-image-registry", ok: true},
+		{host: "docker-registry.default.svc.cluster.local", name: "docker-registry", namespace: "default", ok: true},
+		{host: "registry.example.com", ok: false},
+		{host: "", ok: false},
+	}
+	for _, test := range tests {
+		name, namespace, ok := serviceForHost(test.host)
+		if name != test.name || namespace != test.namespace || ok != test.ok {
+			t.Errorf("%q: expected %s/%s %t, got %s/%s %t", test.host, test.namespace, test.name, test.ok, namespace, name, ok)
+		}
+	}
+}
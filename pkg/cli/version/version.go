@@ -10,6 +10,7 @@ import (
 
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
 	apimachineryversion "k8s.io/apimachinery/pkg/version"
 	"k8s.io/cli-runtime/pkg/genericiooptions"
 	"k8s.io/client-go/discovery"
@@ -34,7 +35,11 @@ type Version struct {
 var (
 	versionLong = templates.LongDesc(`
 		Print the OpenShift client, kube-apiserver, and openshift-apiserver versions for the current context.
-		Pass --client to print only the OpenShift client version.
+		Pass --client to print only the OpenShift client version, or --short to print only the version
+		numbers.
+
+		A warning is printed when the minor versions of the client and of the OpenShift server are more
+		than one apart, which is not supported.
 	`)
 	versionExample = templates.Examples(`
 		# Print the OpenShift client, kube-apiserver, and openshift-apiserver version information for the current context
@@ -45,11 +50,16 @@ var (
 
 		# Print the OpenShift client version information for the current context
 		oc version --client
+
+		# Print only the OpenShift client, server and Kubernetes version numbers
+		oc version --short
 	`)
 )
 
 type VersionOptions struct {
 	kversion.Options
+	Short bool
+
 	oClient         configv1client.ClusterVersionsGetter
 	discoveryClient discovery.CachedDiscoveryInterface
 
@@ -79,9 +89,19 @@ func NewCmdVersion(f cmdutil.Factory, ioStreams genericiooptions.IOStreams) *cob
 	}
 	cmd.Flags().BoolVar(&o.ClientOnly, "client", o.ClientOnly, "Client version only (no server required).")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Output, "One of 'yaml' or 'json'.")
+	cmd.Flags().BoolVar(&o.Short, "short", o.Short, "If true, print only the client, server and Kubernetes version numbers.")
 	return cmd
 }
 
+// Validate checks that --short isn't combined with --output along with the
+// upstream validation.
+func (o *VersionOptions) Validate() error {
+	if o.Short && len(o.Output) > 0 {
+		return fmt.Errorf("--short can't be combined with --output")
+	}
+	return o.Options.Validate()
+}
+
 // Complete is copied from upstream version command with added clusteroperator client
 // to report OpenShift server version
 func (o *VersionOptions) Complete(f cmdutil.Factory, cmd *cobra.Command) error {
@@ -162,7 +182,7 @@ func (o *VersionOptions) Run() error {
 				fmt.Fprintf(o.Out, "Client Version: %s\n", clientVersion.GitVersion)
 			}
 		}
-		if len(versionInfo.KustomizeVersion) != 0 {
+		if len(versionInfo.KustomizeVersion) != 0 && !o.Short {
 			fmt.Fprintf(o.Out, "Kustomize Version: %s\n", versionInfo.KustomizeVersion)
 		}
 		if len(versionInfo.OpenShiftVersion) != 0 {
@@ -189,5 +209,34 @@ func (o *VersionOptions) Run() error {
 		return fmt.Errorf("VersionOptions were not validated: --output=%q should have been rejected", o.Output)
 	}
 
+	reportedClientVersion := clientVersion.GitVersion
+	if len(reportedVersion) != 0 {
+		reportedClientVersion = reportedVersion
+	}
+	if warning := versionSkewWarning(reportedClientVersion, versionInfo.OpenShiftVersion); len(warning) > 0 {
+		fmt.Fprintf(o.ErrOut, "WARNING: %s\n", warning)
+	}
+
 	return serverErr
 }
+
+// versionSkewWarning returns a warning when the client and server versions
+// have different majors or minors more than one apart. Versions that can't
+// be parsed, like the server version of non-admin users or the client
+// version of development builds, aren't compared.
+func versionSkewWarning(client, server string) string {
+	clientVersion, err := utilversion.ParseGeneric(client)
+	if err != nil || clientVersion.Major() == 0 {
+		return ""
+	}
+	serverVersion, err := utilversion.ParseGeneric(server)
+	if err != nil {
+		return ""
+	}
+	skew := int(clientVersion.Minor()) - int(serverVersion.Minor())
+	if clientVersion.Major() == serverVersion.Major() && skew >= -1 && skew <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("version difference between client (%d.%d) and server (%d.%d) exceeds the supported minor version skew of +/-1",
+		clientVersion.Major(), clientVersion.Minor(), serverVersion.Major(), serverVersion.Minor())
+}
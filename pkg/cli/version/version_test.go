@@ -0,0 +1,24 @@
+package version
+
+import "testing"
+
+func TestVersionSkewWarning(t *testing.T) {
+	tests := []struct {
+		client, server string
+		warn           bool
+	}{
+		{client: "4.16.0", server: "4.16.2"},
+		{client: "4.15.3", server: "4.16.2"},
+		{client: "v4.17.0-202406131906.p0.g7c0889f.assembly.stream.el9-7c0889f", server: "4.16.2"},
+		{client: "4.14.0", server: "4.16.2", warn: true},
+		{client: "4.18.1", server: "4.16.2", warn: true},
+		{client: "5.0.0", server: "4.16.2", warn: true},
+		{client: "v0.0.0-unknown", server: "4.16.2"},
+		{client: "4.14.0", server: ""},
+	}
+	for _, tc := range tests {
+		if warning := versionSkewWarning(tc.client, tc.server); (len(warning) > 0) != tc.warn {
+			t.Errorf("client %s, server %s: expected a warning %t, got %q", tc.client, tc.server, tc.warn, warning)
+		}
+	}
+}
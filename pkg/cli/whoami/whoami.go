@@ -20,6 +20,7 @@ import (
 
 	userv1 "github.com/openshift/api/user/v1"
 	userv1typedclient "github.com/openshift/client-go/user/clientset/versioned/typed/user/v1"
+	"github.com/openshift/oc/pkg/helpers/kubeconfig"
 )
 
 const (
@@ -70,7 +71,7 @@ func NewCmdWhoAmI(f kcmdutil.Factory, streams genericiooptions.IOStreams) *cobra
 		Long:    whoamiLong,
 		Example: whoamiExample,
 		Run: func(cmd *cobra.Command, args []string) {
-			kcmdutil.CheckErr(o.Complete(f))
+			kcmdutil.CheckErr(o.Complete(f, cmd))
 			kcmdutil.CheckErr(o.Validate())
 			kcmdutil.CheckErr(o.Run())
 		},
@@ -107,7 +108,7 @@ func (o WhoAmIOptions) WhoAmI() (*userv1.User, error) {
 	return me, err
 }
 
-func (o *WhoAmIOptions) Complete(f kcmdutil.Factory) error {
+func (o *WhoAmIOptions) Complete(f kcmdutil.Factory, cmd *cobra.Command) error {
 	var err error
 
 	o.ClientConfig, err = f.ToRESTConfig()
@@ -122,7 +123,15 @@ func (o *WhoAmIOptions) Complete(f kcmdutil.Factory) error {
 	o.KubeClient = kubeClient
 
 	o.RawConfig, err = f.ToRawKubeConfigLoader().RawConfig()
-	return err
+	if err != nil {
+		return err
+	}
+	current, err := kubeconfig.CurrentContext(f)
+	if err != nil {
+		return err
+	}
+	o.RawConfig.CurrentContext = current.Name
+	return nil
 }
 
 func (o *WhoAmIOptions) Validate() error {
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+// Cache stores on disk the lists of objects fetched from a server, so that
+// commands run within a short window reuse them instead of listing the same
+// objects again. Lists are stored per namespace and per resource, under a
+// directory specific to the server and to the credentials used to reach it.
+// A nil Cache stores nothing.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// New returns a Cache storing the lists fetched with config under dir and
+// reusing them for ttl.
+func New(dir string, ttl time.Duration, config *rest.Config) *Cache {
+	return &Cache{
+		dir: filepath.Join(dir, scope(config)),
+		ttl: ttl,
+		now: time.Now,
+	}
+}
+
+// DefaultDir returns the directory holding the cached lists, honoring
+// XDG_CACHE_HOME and defaulting to ~/.cache/oc/lists.
+func DefaultDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "oc", "lists")
+	}
+	return filepath.Join(homedir.HomeDir(), ".cache", "oc", "lists")
+}
+
+// scope identifies the server config reaches and the user it authenticates
+// as, so that users never see the lists fetched by one another.
+func scope(config *rest.Config) string {
+	h := sha256.New()
+	for _, s := range []string{config.Host, config.Username, config.BearerToken, config.BearerTokenFile, config.CertFile, string(config.CertData)} {
+		fmt.Fprintf(h, "%s\x00", s)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
+
+// list reads the list of resource in namespace into obj when a fresh copy is
+// cached. Otherwise it calls fetch, which fills obj, and caches obj. Failing to
+// read or write the cache only costs a fetch.
+func (c *Cache) list(namespace, resource string, obj interface{}, fetch func() error) error {
+	if c == nil {
+		return fetch()
+	}
+	if len(namespace) == 0 {
+		// not a valid namespace name, so it can't collide with one
+		namespace = "_all"
+	}
+	path := filepath.Join(c.dir, namespace, resource+".json")
+
+	if info, err := os.Stat(path); err == nil && c.now().Sub(info.ModTime()) < c.ttl {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			if err = json.Unmarshal(data, obj); err == nil {
+				return nil
+			}
+		}
+		klog.V(4).Infof("Ignoring the cached list %s: %v", path, err)
+	}
+
+	if err := fetch(); err != nil {
+		return err
+	}
+	if err := write(path, obj); err != nil {
+		klog.V(4).Infof("Unable to cache the list %s: %v", path, err)
+	}
+	return nil
+}
+
+// write stores obj in path through a temporary file, so that commands running
+// concurrently never read a partial list.
+func write(path string, obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Options holds the flag shared by the commands using the cache.
+type Options struct {
+	TTL time.Duration
+	Dir string
+}
+
+func NewOptions() *Options {
+	return &Options{Dir: DefaultDir()}
+}
+
+// AddFlags adds the --cache-ttl flag to flags.
+func (o *Options) AddFlags(flags *pflag.FlagSet) {
+	flags.DurationVar(&o.TTL, "cache-ttl", o.TTL, "If positive, reuse the build config and image stream lists fetched by oc commands within this duration instead of listing them again.")
+}
+
+// ToCache returns the Cache configured by the flags, or nil when caching is disabled.
+func (o *Options) ToCache(config *rest.Config) *Cache {
+	if o == nil || o.TTL <= 0 {
+		return nil
+	}
+	return New(o.Dir, o.TTL, config)
+}
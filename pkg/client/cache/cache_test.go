@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+	imagefake "github.com/openshift/client-go/image/clientset/versioned/fake"
+)
+
+func expectRequests(t *testing.T, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("expected %d requests to the server, got %d", want, got)
+	}
+}
+
+func TestBuildConfigs(t *testing.T) {
+	now := time.Now()
+	c := New(t.TempDir(), time.Minute, &rest.Config{Host: "https://example.com"})
+	c.now = func() time.Time { return now }
+
+	fakeClient := buildfake.NewSimpleClientset(&buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "bc"}})
+	client := BuildV1(c, fakeClient.BuildV1())
+
+	list := func() []buildv1.BuildConfig {
+		t.Helper()
+		l, err := client.BuildConfigs("ns").List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l.Items
+	}
+
+	if items := list(); len(items) != 1 || items[0].Name != "bc" {
+		t.Fatalf("unexpected build configs: %#v", items)
+	}
+	expectRequests(t, len(fakeClient.Actions()), 1)
+
+	// served from the cache
+	if items := list(); len(items) != 1 || items[0].Name != "bc" {
+		t.Fatalf("unexpected cached build configs: %#v", items)
+	}
+	expectRequests(t, len(fakeClient.Actions()), 1)
+
+	// lists with options are never cached
+	if _, err := client.BuildConfigs("ns").List(context.TODO(), metav1.ListOptions{LabelSelector: "app=web"}); err != nil {
+		t.Fatal(err)
+	}
+	expectRequests(t, len(fakeClient.Actions()), 2)
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	list()
+	expectRequests(t, len(fakeClient.Actions()), 3)
+}
+
+func TestImageStreams(t *testing.T) {
+	dir := t.TempDir()
+	fakeClient := imagefake.NewSimpleClientset(&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "is"}})
+
+	for _, config := range []*rest.Config{
+		{Host: "https://example.com", BearerToken: "a"},
+		{Host: "https://example.com", BearerToken: "a"},
+		// other users don't share the lists
+		{Host: "https://example.com", BearerToken: "b"},
+	} {
+		l, err := ImageV1(New(dir, time.Minute, config), fakeClient.ImageV1()).ImageStreams("ns").List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(l.Items) != 1 || l.Items[0].Name != "is" {
+			t.Fatalf("unexpected image streams: %#v", l.Items)
+		}
+	}
+	expectRequests(t, len(fakeClient.Actions()), 2)
+}
+
+func TestOptionsToCache(t *testing.T) {
+	if c := (&Options{}).ToCache(&rest.Config{}); c != nil {
+		t.Errorf("expected no cache without a TTL, got %#v", c)
+	}
+	fakeClient := buildfake.NewSimpleClientset().BuildV1()
+	if client := BuildV1(nil, fakeClient); client != fakeClient {
+		t.Errorf("expected the client to be returned unchanged without a cache")
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+)
+
+// BuildV1 returns a client listing build configs through c. Lists with
+// options, and every other request, go to client.
+func BuildV1(c *Cache, client buildv1client.BuildV1Interface) buildv1client.BuildV1Interface {
+	if c == nil {
+		return client
+	}
+	return &buildV1{BuildV1Interface: client, cache: c}
+}
+
+type buildV1 struct {
+	buildv1client.BuildV1Interface
+	cache *Cache
+}
+
+func (b *buildV1) BuildConfigs(namespace string) buildv1client.BuildConfigInterface {
+	return &buildConfigs{BuildConfigInterface: b.BuildV1Interface.BuildConfigs(namespace), cache: b.cache, namespace: namespace}
+}
+
+type buildConfigs struct {
+	buildv1client.BuildConfigInterface
+	cache     *Cache
+	namespace string
+}
+
+func (b *buildConfigs) List(ctx context.Context, opts metav1.ListOptions) (*buildv1.BuildConfigList, error) {
+	if opts != (metav1.ListOptions{}) {
+		return b.BuildConfigInterface.List(ctx, opts)
+	}
+	list := &buildv1.BuildConfigList{}
+	err := b.cache.list(b.namespace, "buildconfigs", list, func() error {
+		fetched, err := b.BuildConfigInterface.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		*list = *fetched
+		return nil
+	})
+	return list, err
+}
+
+// ImageV1 returns a client listing image streams through c. Lists with
+// options, and every other request, go to client.
+func ImageV1(c *Cache, client imagev1client.ImageV1Interface) imagev1client.ImageV1Interface {
+	if c == nil {
+		return client
+	}
+	return &imageV1{ImageV1Interface: client, cache: c}
+}
+
+type imageV1 struct {
+	imagev1client.ImageV1Interface
+	cache *Cache
+}
+
+func (i *imageV1) ImageStreams(namespace string) imagev1client.ImageStreamInterface {
+	return &imageStreams{ImageStreamInterface: i.ImageV1Interface.ImageStreams(namespace), cache: i.cache, namespace: namespace}
+}
+
+type imageStreams struct {
+	imagev1client.ImageStreamInterface
+	cache     *Cache
+	namespace string
+}
+
+func (i *imageStreams) List(ctx context.Context, opts metav1.ListOptions) (*imagev1.ImageStreamList, error) {
+	if opts != (metav1.ListOptions{}) {
+		return i.ImageStreamInterface.List(ctx, opts)
+	}
+	list := &imagev1.ImageStreamList{}
+	err := i.cache.list(i.namespace, "imagestreams", list, func() error {
+		fetched, err := i.ImageStreamInterface.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		*list = *fetched
+		return nil
+	})
+	return list, err
+}
@@ -0,0 +1,137 @@
+package paging
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	projectv1 "github.com/openshift/api/project/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	projectv1client "github.com/openshift/client-go/project/clientset/versioned/typed/project/v1"
+)
+
+// BuildV1 returns a client listing build configs and builds through client
+// by chunks of chunkSize objects. A chunkSize of 0 disables chunking.
+func BuildV1(client buildv1client.BuildV1Interface, chunkSize int64) buildv1client.BuildV1Interface {
+	if chunkSize <= 0 {
+		return client
+	}
+	return &buildV1{BuildV1Interface: client, chunkSize: chunkSize}
+}
+
+type buildV1 struct {
+	buildv1client.BuildV1Interface
+	chunkSize int64
+}
+
+func (b *buildV1) BuildConfigs(namespace string) buildv1client.BuildConfigInterface {
+	return &buildConfigs{BuildConfigInterface: b.BuildV1Interface.BuildConfigs(namespace), chunkSize: b.chunkSize}
+}
+
+func (b *buildV1) Builds(namespace string) buildv1client.BuildInterface {
+	return &builds{BuildInterface: b.BuildV1Interface.Builds(namespace), chunkSize: b.chunkSize}
+}
+
+type buildConfigs struct {
+	buildv1client.BuildConfigInterface
+	chunkSize int64
+}
+
+func (b *buildConfigs) List(ctx context.Context, opts metav1.ListOptions) (*buildv1.BuildConfigList, error) {
+	if !chunked(opts, b.chunkSize) {
+		return b.BuildConfigInterface.List(ctx, opts)
+	}
+	list := &buildv1.BuildConfigList{}
+	err := listByChunks(ctx, opts, b.chunkSize, list, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return b.BuildConfigInterface.List(ctx, opts)
+	})
+	return list, err
+}
+
+type builds struct {
+	buildv1client.BuildInterface
+	chunkSize int64
+}
+
+func (b *builds) List(ctx context.Context, opts metav1.ListOptions) (*buildv1.BuildList, error) {
+	if !chunked(opts, b.chunkSize) {
+		return b.BuildInterface.List(ctx, opts)
+	}
+	list := &buildv1.BuildList{}
+	err := listByChunks(ctx, opts, b.chunkSize, list, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return b.BuildInterface.List(ctx, opts)
+	})
+	return list, err
+}
+
+// ImageV1 returns a client listing image streams through client by chunks of
+// chunkSize objects. A chunkSize of 0 disables chunking.
+func ImageV1(client imagev1client.ImageV1Interface, chunkSize int64) imagev1client.ImageV1Interface {
+	if chunkSize <= 0 {
+		return client
+	}
+	return &imageV1{ImageV1Interface: client, chunkSize: chunkSize}
+}
+
+type imageV1 struct {
+	imagev1client.ImageV1Interface
+	chunkSize int64
+}
+
+func (i *imageV1) ImageStreams(namespace string) imagev1client.ImageStreamInterface {
+	return &imageStreams{ImageStreamInterface: i.ImageV1Interface.ImageStreams(namespace), chunkSize: i.chunkSize}
+}
+
+type imageStreams struct {
+	imagev1client.ImageStreamInterface
+	chunkSize int64
+}
+
+func (i *imageStreams) List(ctx context.Context, opts metav1.ListOptions) (*imagev1.ImageStreamList, error) {
+	if !chunked(opts, i.chunkSize) {
+		return i.ImageStreamInterface.List(ctx, opts)
+	}
+	list := &imagev1.ImageStreamList{}
+	err := listByChunks(ctx, opts, i.chunkSize, list, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return i.ImageStreamInterface.List(ctx, opts)
+	})
+	return list, err
+}
+
+// ProjectV1 returns a client listing projects through client by chunks of
+// chunkSize objects. A chunkSize of 0 disables chunking.
+func ProjectV1(client projectv1client.ProjectV1Interface, chunkSize int64) projectv1client.ProjectV1Interface {
+	if chunkSize <= 0 {
+		return client
+	}
+	return &projectV1{ProjectV1Interface: client, chunkSize: chunkSize}
+}
+
+type projectV1 struct {
+	projectv1client.ProjectV1Interface
+	chunkSize int64
+}
+
+func (p *projectV1) Projects() projectv1client.ProjectInterface {
+	return &projects{ProjectInterface: p.ProjectV1Interface.Projects(), chunkSize: p.chunkSize}
+}
+
+type projects struct {
+	projectv1client.ProjectInterface
+	chunkSize int64
+}
+
+func (p *projects) List(ctx context.Context, opts metav1.ListOptions) (*projectv1.ProjectList, error) {
+	if !chunked(opts, p.chunkSize) {
+		return p.ProjectInterface.List(ctx, opts)
+	}
+	list := &projectv1.ProjectList{}
+	err := listByChunks(ctx, opts, p.chunkSize, list, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return p.ProjectInterface.List(ctx, opts)
+	})
+	return list, err
+}
@@ -0,0 +1,52 @@
+// Package paging lists objects by chunks, passing the continue token of every
+// chunk to the next request, so that large lists don't come back as single
+// responses that time out. Servers that don't support chunking return the
+// whole list at once, which is used as is. Lists whose continue token expires
+// before they are done are listed again in full.
+package paging
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/pager"
+)
+
+// DefaultChunkSize is the number of objects requested per chunk by default.
+const DefaultChunkSize = 500
+
+// chunked returns whether a list with opts is split in chunks of chunkSize
+// objects. Lists asking for a limit or a continue token themselves are not.
+func chunked(opts metav1.ListOptions, chunkSize int64) bool {
+	return chunkSize > 0 && opts.Limit == 0 && len(opts.Continue) == 0
+}
+
+// listByChunks sets the items and resource version of into, a typed list, to
+// the ones listed with opts by chunks of chunkSize objects through page.
+func listByChunks(ctx context.Context, opts metav1.ListOptions, chunkSize int64, into runtime.Object, page pager.ListPageFunc) error {
+	p := pager.New(page)
+	p.PageSize = chunkSize
+	list, _, err := p.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	if err := meta.SetList(into, items); err != nil {
+		return err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return err
+	}
+	intoMeta, err := meta.ListAccessor(into)
+	if err != nil {
+		return err
+	}
+	intoMeta.SetResourceVersion(listMeta.GetResourceVersion())
+	return nil
+}
@@ -0,0 +1,100 @@
+package paging
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildfake "github.com/openshift/client-go/build/clientset/versioned/fake"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+)
+
+// fakeBuildConfigs serves the build configs named 0 to total-1 by chunks of
+// the requested limit, recording the options of every request.
+type fakeBuildConfigs struct {
+	buildv1client.BuildConfigInterface
+	total    int
+	requests []metav1.ListOptions
+	// expired, when set, fails the requests with a continue token as if it
+	// had expired
+	expired bool
+}
+
+func (f *fakeBuildConfigs) List(ctx context.Context, opts metav1.ListOptions) (*buildv1.BuildConfigList, error) {
+	f.requests = append(f.requests, opts)
+	start := 0
+	if len(opts.Continue) > 0 && f.expired {
+		return nil, kerrors.NewResourceExpired("continue token expired")
+	}
+	if len(opts.Continue) > 0 {
+		var err error
+		if start, err = strconv.Atoi(opts.Continue); err != nil {
+			return nil, err
+		}
+	}
+	end := f.total
+	if opts.Limit > 0 && start+int(opts.Limit) < f.total {
+		end = start + int(opts.Limit)
+	}
+	list := &buildv1.BuildConfigList{}
+	for i := start; i < end; i++ {
+		list.Items = append(list.Items, buildv1.BuildConfig{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: fmt.Sprint(i)}})
+	}
+	if end < f.total {
+		list.Continue = strconv.Itoa(end)
+	}
+	return list, nil
+}
+
+type fakeBuildV1 struct {
+	buildv1client.BuildV1Interface
+	buildConfigs *fakeBuildConfigs
+}
+
+func (f *fakeBuildV1) BuildConfigs(namespace string) buildv1client.BuildConfigInterface {
+	return f.buildConfigs
+}
+
+func TestBuildConfigsByChunks(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunkSize int64
+		opts      metav1.ListOptions
+		expired   bool
+		requests  int
+		items     int
+	}{
+		{name: "chunks", chunkSize: 2, requests: 3, items: 5},
+		{name: "single chunk", chunkSize: 500, requests: 1, items: 5},
+		{name: "disabled", chunkSize: 0, requests: 1, items: 5},
+		{name: "limit requested by the caller", chunkSize: 2, opts: metav1.ListOptions{Limit: 3}, requests: 1, items: 3},
+		{name: "full list when the continue token expires", chunkSize: 2, expired: true, requests: 3, items: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buildConfigs := &fakeBuildConfigs{total: 5, expired: tt.expired}
+			client := &fakeBuildV1{BuildV1Interface: buildfake.NewSimpleClientset().BuildV1(), buildConfigs: buildConfigs}
+
+			list, err := BuildV1(client, tt.chunkSize).BuildConfigs("ns").List(context.TODO(), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buildConfigs.requests) != tt.requests {
+				t.Errorf("expected %d requests, got %d: %#v", tt.requests, len(buildConfigs.requests), buildConfigs.requests)
+			}
+			if len(list.Items) != tt.items {
+				t.Fatalf("expected %d build configs, got %d", tt.items, len(list.Items))
+			}
+			for i, bc := range list.Items {
+				if bc.Name != fmt.Sprint(i) {
+					t.Errorf("expected build config %d to be %q, got %q", i, fmt.Sprint(i), bc.Name)
+				}
+			}
+		})
+	}
+}
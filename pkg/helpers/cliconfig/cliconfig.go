@@ -0,0 +1,144 @@
+package cliconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/client-go/util/homedir"
+)
+
+// EnvPrefix prefixes the environment variables overriding flag defaults.
+const EnvPrefix = "OC_DEFAULT_"
+
+// Config holds the user preferences of oc. An example config:
+//
+//	flags:
+//	  v: 2
+//	commands:
+//	  adm build-chain:
+//	    output: dot
+//	    all: true
+type Config struct {
+	// Flags holds default values of the flags of every command.
+	Flags map[string]interface{} `json:"flags,omitempty"`
+	// Commands holds default values of the flags of a single command, keyed
+	// by the path of the command without the root command name.
+	Commands map[string]map[string]interface{} `json:"commands,omitempty"`
+}
+
+// Dir returns the directory holding the oc user preferences, honoring
+// XDG_CONFIG_HOME and defaulting to ~/.config/oc.
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "oc")
+	}
+	return filepath.Join(homedir.HomeDir(), ".config", "oc")
+}
+
+// DefaultPath returns the location of the config file.
+func DefaultPath() string {
+	return filepath.Join(Dir(), "config.yaml")
+}
+
+// Load reads the config stored in path. A missing file holds an empty config.
+func Load(path string) (*Config, error) {
+	config := &Config{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// ApplyDefaults sets the flags of cmd that were not given on the command line
+// to their configured default. Defaults are looked up, by order of precedence,
+// in the environment variable specific to the command, e.g.
+// OC_DEFAULT_ADM_BUILD_CHAIN_OUTPUT, the environment variable for all the
+// commands, e.g. OC_DEFAULT_V, then the config for the command and finally the
+// config for all the commands.
+func (c *Config) ApplyDefaults(cmd *cobra.Command, getenv func(string) string) error {
+	path := strings.TrimPrefix(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()), " ")
+
+	var errs []string
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || flag.Name == "help" {
+			return
+		}
+		values, source, ok := c.lookup(path, flag.Name, getenv)
+		if !ok {
+			return
+		}
+		if _, isSlice := flag.Value.(pflag.SliceValue); len(values) != 1 && !isSlice {
+			errs = append(errs, fmt.Sprintf("invalid default %q for --%s from %s: a list is only valid for flags taking several values", values, flag.Name, source))
+			return
+		}
+		// the values of slice flags after the first one are appended
+		for _, value := range values {
+			if err := cmd.Flags().Set(flag.Name, value); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid default %q for --%s from %s: %v", value, flag.Name, source, err))
+				return
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// lookup returns the values of the default of flag, a single one unless it
+// is configured as a list, and where it was found.
+func (c *Config) lookup(path, flag string, getenv func(string) string) ([]string, string, bool) {
+	for _, name := range []string{envName(path, flag), envName("", flag)} {
+		if value := getenv(name); len(value) > 0 {
+			return []string{value}, name, true
+		}
+	}
+	if value, ok := c.Commands[path][flag]; ok && len(path) > 0 {
+		return formatValues(value), fmt.Sprintf("the config of %q", path), true
+	}
+	if value, ok := c.Flags[flag]; ok {
+		return formatValues(value), "the config", true
+	}
+	return nil, "", false
+}
+
+// formatValues returns value, as decoded from the config, in the format of
+// the command line: numbers are decoded as float64 and have to be printed
+// without exponent, and lists are made of several values.
+func formatValues(value interface{}) []string {
+	switch t := value.(type) {
+	case []interface{}:
+		values := []string{}
+		for _, item := range t {
+			values = append(values, formatValues(item)...)
+		}
+		return values
+	case float64:
+		return []string{strconv.FormatFloat(t, 'f', -1, 64)}
+	case nil:
+		return []string{""}
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+func envName(path, flag string) string {
+	name := flag
+	if len(path) > 0 {
+		name = path + "_" + flag
+	}
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+}
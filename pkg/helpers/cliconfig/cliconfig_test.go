@@ -0,0 +1,130 @@
+package cliconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func testCommand(t *testing.T, args ...string) (*cobra.Command, *string, *bool, *int) {
+	var output string
+	var all bool
+	var v int
+	root := &cobra.Command{Use: "oc"}
+	root.PersistentFlags().IntVar(&v, "v", 0, "")
+	adm := &cobra.Command{Use: "adm"}
+	cmd := &cobra.Command{Use: "build-chain", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().StringVarP(&output, "output", "o", "", "")
+	cmd.Flags().BoolVar(&all, "all", false, "")
+	adm.AddCommand(cmd)
+	root.AddCommand(adm)
+
+	found, flags, err := root.Find(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := found.ParseFlags(flags); err != nil {
+		t.Fatal(err)
+	}
+	return found, &output, &all, &v
+}
+
+func TestApplyDefaults(t *testing.T) {
+	config := &Config{
+		Flags:    map[string]interface{}{"v": 2, "output": "yaml"},
+		Commands: map[string]map[string]interface{}{"adm build-chain": {"output": "dot", "all": true}},
+	}
+
+	cmd, output, all, v := testCommand(t, "adm", "build-chain")
+	if err := config.ApplyDefaults(cmd, func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	if *output != "dot" || !*all || *v != 2 {
+		t.Errorf("unexpected flags: output=%s all=%t v=%d", *output, *all, *v)
+	}
+
+	cmd, output, all, v = testCommand(t, "adm", "build-chain", "--all=false", "-o", "json")
+	env := map[string]string{"OC_DEFAULT_V": "4", "OC_DEFAULT_ADM_BUILD_CHAIN_OUTPUT": "dot"}
+	if err := config.ApplyDefaults(cmd, func(name string) string { return env[name] }); err != nil {
+		t.Fatal(err)
+	}
+	if *output != "json" || *all || *v != 4 {
+		t.Errorf("unexpected flags: output=%s all=%t v=%d", *output, *all, *v)
+	}
+
+	cmd, _, _, _ = testCommand(t, "adm", "build-chain")
+	if err := config.ApplyDefaults(cmd, func(name string) string { return map[string]string{"OC_DEFAULT_ALL": "maybe"}[name] }); err == nil {
+		t.Errorf("expected an invalid default to be reported")
+	}
+}
+
+func TestApplyDefaultsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "flags:\n  chunk-size: 1000000\n  ratio: 0.5\n  namespaces: [team-a, team-b]\n  labels: [app=web, tier=front]\n  output: [dot, json]\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newCommand := func() (*cobra.Command, *int64, *float64, *[]string, *[]string) {
+		var chunkSize int64
+		var ratio float64
+		var namespaces, labels []string
+		cmd := &cobra.Command{Use: "oc"}
+		cmd.Flags().Int64Var(&chunkSize, "chunk-size", 500, "")
+		cmd.Flags().Float64Var(&ratio, "ratio", 1, "")
+		cmd.Flags().StringSliceVar(&namespaces, "namespaces", []string{"default"}, "")
+		cmd.Flags().StringArrayVar(&labels, "labels", nil, "")
+		return cmd, &chunkSize, &ratio, &namespaces, &labels
+	}
+
+	cmd, chunkSize, ratio, namespaces, labels := newCommand()
+	cmd.Flags().String("unused", "", "")
+	if err := config.ApplyDefaults(cmd, func(string) string { return "" }); err != nil {
+		t.Fatal(err)
+	}
+	if *chunkSize != 1000000 || *ratio != 0.5 {
+		t.Errorf("unexpected numbers: chunk-size=%d ratio=%v", *chunkSize, *ratio)
+	}
+	if !reflect.DeepEqual(*namespaces, []string{"team-a", "team-b"}) || !reflect.DeepEqual(*labels, []string{"app=web", "tier=front"}) {
+		t.Errorf("unexpected lists: namespaces=%v labels=%v", *namespaces, *labels)
+	}
+
+	cmd, _, _, _, _ = newCommand()
+	cmd.Flags().String("output", "", "")
+	if err := config.ApplyDefaults(cmd, func(string) string { return "" }); err == nil || !strings.Contains(err.Error(), "--output") {
+		t.Errorf("expected a list to be rejected for --output, got %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if config, err := Load(path); err != nil || len(config.Flags) != 0 {
+		t.Fatalf("expected an empty config from a missing file, got %v, %v", config, err)
+	}
+
+	if err := os.WriteFile(path, []byte("flags:\n  v: 2\ncommands:\n  adm build-chain:\n    output: dot\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Commands["adm build-chain"]["output"] != "dot" {
+		t.Errorf("unexpected config: %#v", config)
+	}
+
+	if err := os.WriteFile(path, []byte("flag:\n  v: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Errorf("expected unknown fields to be rejected")
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Deprecation describes a flag or a command on its way out: what replaces it
+// and when it goes away. Both are printed in the warning shown when the
+// deprecated flag or command is used, and in the help of its replacement.
+type Deprecation struct {
+	// Replacement, when set, is the flag, without its dashes, or the command
+	// replacing the deprecated one.
+	Replacement string
+	// RemovalRelease, when set, is the release the deprecated flag or
+	// command will be removed in.
+	RemovalRelease string
+}
+
+// message returns the explanation following the warning pflag and cobra
+// print when a deprecated flag or command is used, replacement being how
+// the replacement is spelled on the command line.
+func (d Deprecation) message(replacement string) string {
+	removal := "it will be removed in a future release"
+	if len(d.RemovalRelease) > 0 {
+		removal = "it will be removed in " + d.RemovalRelease
+	}
+	if len(d.Replacement) == 0 {
+		return removal
+	}
+	return fmt.Sprintf("use %s instead, %s", replacement, removal)
+}
+
+// removal returns when the deprecated flag or command goes away, for the help
+// of its replacement.
+func (d Deprecation) removal() string {
+	if len(d.RemovalRelease) > 0 {
+		return "will be removed in " + d.RemovalRelease
+	}
+	return "will be removed in a future release"
+}
+
+// DeprecateFlag marks the flag name of flags as deprecated. It is hidden from
+// the help and a warning is printed when it is used. Use DeprecatedFlagAlias
+// instead when the flag is renamed rather than removed.
+func DeprecateFlag(flags *pflag.FlagSet, name string, d Deprecation) error {
+	replacement := d.Replacement
+	if len(replacement) > 0 {
+		replacement = "--" + replacement
+	}
+	return flags.MarkDeprecated(name, d.message(replacement))
+}
+
+// DeprecatedFlagAlias adds name to flags as a deprecated alias of the flag
+// d.Replacement, for flags being renamed. Both names set the same value, and
+// using the alias also marks the replacement as changed, so that callers only
+// need to check the replacement with flags.Changed. The alias is hidden from
+// the help, where the replacement mentions it, and a warning is printed when
+// it is used.
+func DeprecatedFlagAlias(flags *pflag.FlagSet, name string, d Deprecation) error {
+	target := flags.Lookup(d.Replacement)
+	if target == nil {
+		return fmt.Errorf("flag %q does not exist", d.Replacement)
+	}
+	flags.AddFlag(&pflag.Flag{
+		Name:     name,
+		Usage:    target.Usage,
+		Value:    &aliasValue{Value: target.Value, target: target},
+		DefValue: target.DefValue,
+	})
+	if err := flags.MarkDeprecated(name, d.message("--"+d.Replacement)); err != nil {
+		return err
+	}
+	target.Usage = strings.TrimSpace(fmt.Sprintf("%s --%s is a deprecated alias that %s.", target.Usage, name, d.removal()))
+	return nil
+}
+
+// aliasValue is the value of a deprecated flag alias, setting the value of the
+// flag it is an alias of and marking that flag as changed.
+type aliasValue struct {
+	pflag.Value
+	target *pflag.Flag
+}
+
+func (v *aliasValue) Set(value string) error {
+	if err := v.Value.Set(value); err != nil {
+		return err
+	}
+	v.target.Changed = true
+	return nil
+}
+
+// DeprecateCommand marks cmd as deprecated. Cobra hides it from the help of
+// its parent and prints a warning when it is run.
+func DeprecateCommand(cmd *cobra.Command, d Deprecation) {
+	cmd.Deprecated = d.message(fmt.Sprintf("%q", d.Replacement))
+}
+
+// DeprecatedCommandAlias adds alias to the aliases of cmd, for commands being
+// renamed, and prints a warning when cmd is run through it. The help of cmd
+// mentions the alias and when it goes away.
+func DeprecatedCommandAlias(cmd *cobra.Command, alias string, d Deprecation) {
+	cmd.Aliases = append(cmd.Aliases, alias)
+	if len(cmd.Long) > 0 {
+		cmd.Long = strings.TrimRight(cmd.Long, "\n") + "\n\n"
+	}
+	cmd.Long += fmt.Sprintf("%q is a deprecated alias of this command that %s.", alias, d.removal())
+
+	d.Replacement = cmd.Name()
+	warn := func(c *cobra.Command) {
+		if c.CalledAs() == alias {
+			fmt.Fprintf(c.ErrOrStderr(), "Command %q is deprecated, %s\n", alias, d.message(fmt.Sprintf("%q", d.Replacement)))
+		}
+	}
+	if preRunE := cmd.PreRunE; preRunE != nil {
+		cmd.PreRunE = func(c *cobra.Command, args []string) error {
+			warn(c)
+			return preRunE(c, args)
+		}
+		return
+	}
+	preRun := cmd.PreRun
+	cmd.PreRun = func(c *cobra.Command, args []string) {
+		warn(c)
+		if preRun != nil {
+			preRun(c, args)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDeprecatedFlagAlias(t *testing.T) {
+	var images []string
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{Use: "new-build", Run: func(*cobra.Command, []string) {}}
+	cmd.SetOut(out)
+	cmd.SetErr(out)
+	cmd.Flags().StringSliceVar(&images, "image", nil, "Name of a container image to use as a builder.")
+	if err := DeprecatedFlagAlias(cmd.Flags(), "docker-image", Deprecation{Replacement: "image", RemovalRelease: "4.18"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd.SetArgs([]string{"--docker-image=python"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !cmd.Flags().Changed("image") {
+		t.Errorf("expected the alias to mark the replacement as changed")
+	}
+
+	images = nil
+	cmd.SetArgs([]string{"--image=ruby", "--docker-image=python"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(images, ",") != "ruby,python" {
+		t.Errorf("expected both flags to set the images, got %v", images)
+	}
+	if expected := "Flag --docker-image has been deprecated, use --image instead, it will be removed in 4.18"; !strings.Contains(out.String(), expected) {
+		t.Errorf("expected %q in output:\n%s", expected, out)
+	}
+
+	usage := cmd.Flags().FlagUsages()
+	if strings.Contains(usage, "--docker-image strings") {
+		t.Errorf("expected the alias to be hidden:\n%s", usage)
+	}
+	if expected := "Name of a container image to use as a builder. --docker-image is a deprecated alias that will be removed in 4.18."; !strings.Contains(usage, expected) {
+		t.Errorf("expected %q in usage:\n%s", expected, usage)
+	}
+
+	if err := DeprecatedFlagAlias(cmd.Flags(), "old", Deprecation{Replacement: "missing"}); err == nil {
+		t.Errorf("expected an alias of a missing flag to be rejected")
+	}
+}
+
+func TestDeprecateFlag(t *testing.T) {
+	out := &bytes.Buffer{}
+	cmd := &cobra.Command{Use: "observe", Run: func(*cobra.Command, []string) {}}
+	cmd.SetErr(out)
+	cmd.Flags().Bool("strict-templates", false, "")
+	cmd.Flags().SetOutput(out)
+	if err := DeprecateFlag(cmd.Flags(), "strict-templates", Deprecation{}); err != nil {
+		t.Fatal(err)
+	}
+	cmd.SetArgs([]string{"--strict-templates"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Flag --strict-templates has been deprecated, it will be removed in a future release"; !strings.Contains(out.String(), expected) {
+		t.Errorf("expected %q in output:\n%s", expected, out)
+	}
+}
+
+func TestDeprecatedCommandAlias(t *testing.T) {
+	ran := false
+	out := &bytes.Buffer{}
+	root := &cobra.Command{Use: "oc"}
+	cmd := &cobra.Command{
+		Use:  "import-image",
+		Long: "Import the latest image information from a tag in a container image registry.\n",
+		Run:  func(*cobra.Command, []string) { ran = true },
+	}
+	root.AddCommand(cmd)
+	root.SetOut(out)
+	root.SetErr(out)
+	DeprecatedCommandAlias(cmd, "import-repository", Deprecation{RemovalRelease: "4.18"})
+
+	root.SetArgs([]string{"import-image"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !ran || out.Len() > 0 {
+		t.Errorf("expected the command to run without warning, got %q", out)
+	}
+
+	root.SetArgs([]string{"import-repository"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Command \"import-repository\" is deprecated, use \"import-image\" instead, it will be removed in 4.18\n"; out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+	if expected := "\n\n\"import-repository\" is a deprecated alias of this command that will be removed in 4.18."; !strings.HasSuffix(cmd.Long, expected) {
+		t.Errorf("expected the help to mention the alias, got %q", cmd.Long)
+	}
+}
+
+func TestDeprecateCommand(t *testing.T) {
+	cmd := &cobra.Command{Use: "get-token"}
+	DeprecateCommand(cmd, Deprecation{Replacement: "oc create token"})
+	if expected := "use \"oc create token\" instead, it will be removed in a future release"; cmd.Deprecated != expected {
+		t.Errorf("expected %q, got %q", expected, cmd.Deprecated)
+	}
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"sigs.k8s.io/yaml"
+)
+
+// PrintDiff writes a unified diff of the YAML of the before and after JSON
+// serializations of the object called name to out, or nothing if they are
+// the same. The managed fields and the resource version, which change on
+// every update, are left out. An empty before or after stands for an object
+// that didn't exist.
+func PrintDiff(out io.Writer, name string, before, after []byte) error {
+	a, err := diffYAML(before)
+	if err != nil {
+		return fmt.Errorf("unable to compare %s: %v", name, err)
+	}
+	b, err := diffYAML(after)
+	if err != nil {
+		return fmt.Errorf("unable to compare %s: %v", name, err)
+	}
+	return difflib.WriteUnifiedDiff(out, difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: "a/" + name,
+		ToFile:   "b/" + name,
+		Context:  3,
+	})
+}
+
+func diffYAML(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return "", err
+	}
+	if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "managedFields")
+		delete(metadata, "resourceVersion")
+	}
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if last := len(lines) - 1; len(lines[last]) == 0 {
+		lines = lines[:last]
+	}
+	return lines
+}
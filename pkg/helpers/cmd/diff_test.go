@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrintDiff(t *testing.T) {
+	before := []byte(`{"kind":"ConfigMap","metadata":{"name":"test","resourceVersion":"1","managedFields":[{"manager":"oc"}]},"data":{"a":"1","b":"2"}}`)
+	after := []byte(`{"kind":"ConfigMap","metadata":{"name":"test","resourceVersion":"2","managedFields":[{"manager":"kubectl"}]},"data":{"a":"1","b":"3"}}`)
+
+	out := &bytes.Buffer{}
+	if err := PrintDiff(out, "configmaps/test", before, after); err != nil {
+		t.Fatal(err)
+	}
+	expected := `--- a/configmaps/test
++++ b/configmaps/test
+@@ -1,6 +1,6 @@
+ data:
+   a: "1"
+-  b: "2"
++  b: "3"
+ kind: ConfigMap
+ metadata:
+   name: test
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	if err := PrintDiff(out, "configmaps/test", before, before); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no diff for an unchanged object, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := PrintDiff(out, "configmaps/test", nil, after); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("--- a/configmaps/test\n+++ b/configmaps/test\n@@ -0,0 +1,6 @@\n+data:\n")) {
+		t.Errorf("expected a created object to be all additions, got:\n%s", out.String())
+	}
+}
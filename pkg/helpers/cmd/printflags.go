@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// ReportPrintFlags are the printing flags of the commands that list objects
+// in tables of their own rather than through the server-side printers:
+// -o/--output, --no-headers and --show-kind, so that they all behave the
+// same way.
+type ReportPrintFlags struct {
+	// OutputFormat is empty for a table, or json or yaml.
+	OutputFormat string
+	// NoHeaders leaves the headers out of tables.
+	NoHeaders bool
+	// ShowKind prefixes the names of the objects in tables with their kind.
+	ShowKind bool
+}
+
+func NewReportPrintFlags() *ReportPrintFlags {
+	return &ReportPrintFlags{}
+}
+
+// AddFlags adds the printing flags to cmd.
+func (f *ReportPrintFlags) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.OutputFormat, "output", "o", f.OutputFormat, "Output format, a table when empty. One of: (json, yaml)")
+	cmd.Flags().BoolVar(&f.NoHeaders, "no-headers", f.NoHeaders, "If true, when using the default output, don't print headers.")
+	cmd.Flags().BoolVar(&f.ShowKind, "show-kind", f.ShowKind, "If true, when using the default output, prefix the names of the objects with their kind.")
+}
+
+// Validate returns an error when the output format isn't supported.
+func (f *ReportPrintFlags) Validate() error {
+	switch f.OutputFormat {
+	case "", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("--output must be either empty, 'json' or 'yaml', got %q", f.OutputFormat)
+}
+
+// Structured returns whether the report is printed as json or yaml instead
+// of a table.
+func (f *ReportPrintFlags) Structured() bool {
+	return len(f.OutputFormat) > 0
+}
+
+// PrintStructured writes v, the whole report, to out in the output format.
+func (f *ReportPrintFlags) PrintStructured(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+	if f.OutputFormat == "yaml" {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+// NewTable returns a writer aligning the tab separated columns written to
+// out, with the headers already written unless --no-headers is set. The
+// writer must be flushed once the rows are written.
+func (f *ReportPrintFlags) NewTable(out io.Writer, headers ...string) *tabwriter.Writer {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	if !f.NoHeaders {
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+	return w
+}
+
+// Name returns the name of an object of kind, a lowercase resource such as
+// buildconfig, as shown in tables: prefixed with its kind with --show-kind.
+func (f *ReportPrintFlags) Name(kind, name string) string {
+	if f.ShowKind {
+		return kind + "/" + name
+	}
+	return name
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestReportPrintFlags(t *testing.T) {
+	report := []struct {
+		Name string `json:"name"`
+	}{{Name: "ruby"}}
+
+	for _, tc := range []struct {
+		flags    ReportPrintFlags
+		expected string
+	}{
+		{
+			flags:    ReportPrintFlags{},
+			expected: "NAME  IMAGE\nruby  sha256:abc\n",
+		},
+		{
+			flags:    ReportPrintFlags{NoHeaders: true, ShowKind: true},
+			expected: "imagestream/ruby  sha256:abc\n",
+		},
+		{
+			flags:    ReportPrintFlags{OutputFormat: "json"},
+			expected: "[\n    {\n        \"name\": \"ruby\"\n    }\n]\n",
+		},
+		{
+			flags:    ReportPrintFlags{OutputFormat: "yaml"},
+			expected: "- name: ruby\n",
+		},
+	} {
+		if err := tc.flags.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		out := &bytes.Buffer{}
+		if tc.flags.Structured() {
+			if err := tc.flags.PrintStructured(out, report); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			w := tc.flags.NewTable(out, "NAME", "IMAGE")
+			fmt.Fprintf(w, "%s\t%s\n", tc.flags.Name("imagestream", report[0].Name), "sha256:abc")
+			w.Flush()
+		}
+		if out.String() != tc.expected {
+			t.Errorf("%+v: expected:\n%s\ngot:\n%s", tc.flags, tc.expected, out.String())
+		}
+	}
+
+	if err := (&ReportPrintFlags{OutputFormat: "wide"}).Validate(); err == nil {
+		t.Errorf("expected the wide output to be rejected")
+	}
+}
@@ -0,0 +1,317 @@
+package debughttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBodySize is the size of the bodies dumped at LevelBody, larger bodies
+// are truncated so that uploads and large lists aren't held in memory.
+const maxBodySize = 64 * 1024
+
+// Level controls how much of every request and response is dumped.
+type Level string
+
+const (
+	// LevelNone disables dumping.
+	LevelNone Level = ""
+	// LevelHeaders dumps method, URL, status, latency and headers.
+	LevelHeaders Level = "headers"
+	// LevelBody additionally dumps request and response bodies.
+	LevelBody Level = "body"
+)
+
+// ValidLevels lists the levels accepted by ParseLevel.
+var ValidLevels = []string{string(LevelHeaders), string(LevelBody)}
+
+// ParseLevel converts a flag value into a Level.
+func ParseLevel(value string) (Level, error) {
+	switch Level(value) {
+	case LevelNone, LevelHeaders, LevelBody:
+		return Level(value), nil
+	}
+	return LevelNone, fmt.Errorf("invalid debug level %q, must be one of: %s", value, strings.Join(ValidLevels, ", "))
+}
+
+const redacted = "<redacted>"
+
+var (
+	// sensitiveHeaders are never printed, whatever the level.
+	sensitiveHeaders = map[string]bool{
+		"Authorization":       true,
+		"Proxy-Authorization": true,
+		"Cookie":              true,
+		"Set-Cookie":          true,
+		"X-Csrf-Token":        true,
+	}
+
+	// sensitiveFields matches JSON string fields carrying credentials.
+	sensitiveFields = regexp.MustCompile(`("(?:token|accessToken|access_token|refreshToken|refresh_token|password|client_secret|clientSecret|id_token|client-key-data|client-certificate-data)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	// secretsPath matches the paths of the secrets resource, whose bodies
+	// are never dumped.
+	secretsPath = regexp.MustCompile(`/secrets(?:/|$)`)
+
+	// sensitiveQuery matches query parameters carrying credentials.
+	sensitiveQuery = regexp.MustCompile(`((?:access_token|code|password)=)[^&]*`)
+)
+
+// NewRoundTripper returns a round tripper dumping every request going
+// through rt, and the response it received, to out.
+func NewRoundTripper(rt http.RoundTripper, level Level, out io.Writer) http.RoundTripper {
+	return &roundTripper{delegate: rt, level: level, out: out}
+}
+
+type roundTripper struct {
+	delegate http.RoundTripper
+	level    Level
+	out      io.Writer
+
+	// lock serializes writes so concurrent requests don't interleave
+	lock sync.Mutex
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "> %s %s\n", req.Method, sanitizeURL(req.URL.String()))
+	if rt.level == LevelHeaders || rt.level == LevelBody {
+		writeHeaders(buf, ">", req.Header)
+	}
+	if rt.level == LevelBody && req.Body != nil && req.Body != http.NoBody {
+		if reason := skipRequestBody(req); len(reason) > 0 {
+			fmt.Fprintf(buf, "> [body not dumped: %s]\n", reason)
+		} else {
+			body, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			writeBody(buf, ">", body)
+		}
+	}
+
+	start := time.Now()
+	resp, err := rt.delegate.RoundTrip(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		fmt.Fprintf(buf, "< error after %s: %v\n", latency.Round(time.Millisecond), err)
+		rt.write(buf)
+		return resp, err
+	}
+
+	fmt.Fprintf(buf, "< %s in %s\n", resp.Status, latency.Round(time.Millisecond))
+	if rt.level == LevelHeaders || rt.level == LevelBody {
+		writeHeaders(buf, "<", resp.Header)
+	}
+	if rt.level == LevelBody && resp.Body != nil && resp.Body != http.NoBody {
+		if reason := skipResponseBody(req, resp); len(reason) > 0 {
+			fmt.Fprintf(buf, "< [body not dumped: %s]\n", reason)
+		} else {
+			// the body is dumped as it is consumed, when it is closed, so
+			// that the caller still reads it as it arrives
+			resp.Body = &teeBody{
+				ReadCloser: resp.Body,
+				header:     fmt.Sprintf("< body of %s %s\n", req.Method, sanitizeURL(req.URL.String())),
+				rt:         rt,
+			}
+		}
+	}
+	rt.write(buf)
+
+	return resp, nil
+}
+
+// skipRequestBody returns why the body of req isn't dumped, if it isn't.
+func skipRequestBody(req *http.Request) string {
+	switch {
+	case secretsPath.MatchString(req.URL.Path):
+		return "secrets"
+	case req.ContentLength < 0 || req.ContentLength > maxBodySize:
+		return fmt.Sprintf("%d bytes", req.ContentLength)
+	case !isText(req.Header.Get("Content-Type")):
+		return req.Header.Get("Content-Type")
+	}
+	return ""
+}
+
+// skipResponseBody returns why the body of resp isn't dumped, if it isn't:
+// the bodies of watches, of followed logs and of upgraded connections are
+// streams that are never done being read.
+func skipResponseBody(req *http.Request, resp *http.Response) string {
+	query := req.URL.Query()
+	switch {
+	case resp.StatusCode == http.StatusSwitchingProtocols:
+		return "upgraded connection"
+	case query.Get("watch") == "true" || query.Get("watch") == "1" || query.Get("follow") == "true":
+		return "stream"
+	case secretsPath.MatchString(req.URL.Path):
+		return "secrets"
+	case !isText(resp.Header.Get("Content-Type")):
+		return resp.Header.Get("Content-Type")
+	}
+	return ""
+}
+
+// isText returns whether contentType is JSON or YAML, as opposed to the
+// protobuf, binary and streamed contents.
+func isText(contentType string) bool {
+	if len(contentType) == 0 {
+		return true
+	}
+	if strings.Contains(contentType, "stream=") {
+		return false
+	}
+	return strings.Contains(contentType, "json") || strings.Contains(contentType, "yaml")
+}
+
+// teeBody keeps the first maxBodySize bytes read from a response body, and
+// dumps them when the body is closed.
+type teeBody struct {
+	io.ReadCloser
+	header string
+	rt     *roundTripper
+
+	body      bytes.Buffer
+	truncated bool
+	once      sync.Once
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if room := maxBodySize - b.body.Len(); room < n {
+		b.body.Write(p[:room])
+		b.truncated = true
+	} else {
+		b.body.Write(p[:n])
+	}
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	b.once.Do(func() {
+		buf := &bytes.Buffer{}
+		buf.WriteString(b.header)
+		writeBody(buf, "<", b.body.Bytes())
+		if b.truncated {
+			fmt.Fprintf(buf, "< [truncated to %d bytes]\n", maxBodySize)
+		}
+		b.rt.write(buf)
+	})
+	return b.ReadCloser.Close()
+}
+
+func (rt *roundTripper) write(buf *bytes.Buffer) {
+	rt.lock.Lock()
+	defer rt.lock.Unlock()
+	rt.out.Write(buf.Bytes())
+}
+
+func writeHeaders(w io.Writer, prefix string, headers http.Header) {
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range headers[key] {
+			if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+				value = redacted
+			}
+			fmt.Fprintf(w, "%s %s: %s\n", prefix, key, value)
+		}
+	}
+}
+
+func writeBody(w io.Writer, prefix string, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(sanitizeBody(string(body)), "\n"), "\n") {
+		fmt.Fprintf(w, "%s %s\n", prefix, line)
+	}
+}
+
+func sanitizeURL(url string) string {
+	return sensitiveQuery.ReplaceAllString(url, "${1}"+redacted)
+}
+
+func sanitizeBody(body string) string {
+	body = redactSecrets(body)
+	return sensitiveFields.ReplaceAllString(body, `${1}"`+redacted+`"`)
+}
+
+// redactSecrets returns body with the data of the Secrets it holds, at any
+// depth as in lists and templates, redacted.
+func redactSecrets(body string) string {
+	if !strings.Contains(body, `"Secret`) {
+		return body
+	}
+	var obj interface{}
+	if err := json.Unmarshal([]byte(body), &obj); err != nil {
+		// a truncated body can't be decoded, keep none of it
+		return redacted
+	}
+	if !redactSecretData(obj) {
+		return body
+	}
+	data := &bytes.Buffer{}
+	encoder := json.NewEncoder(data)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(obj); err != nil {
+		return redacted
+	}
+	return data.String()
+}
+
+// redactSecretData redacts the values of the data and stringData of the
+// Secrets in obj, and returns whether there were any.
+func redactSecretData(obj interface{}) bool {
+	found := false
+	switch t := obj.(type) {
+	case map[string]interface{}:
+		switch t["kind"] {
+		case "Secret":
+			found = true
+			redactFields(t)
+		case "SecretList":
+			// the items of lists returned by the server have no kind
+			found = true
+			if items, ok := t["items"].([]interface{}); ok {
+				for _, item := range items {
+					if item, ok := item.(map[string]interface{}); ok {
+						redactFields(item)
+					}
+				}
+			}
+		}
+		for _, value := range t {
+			found = redactSecretData(value) || found
+		}
+	case []interface{}:
+		for _, value := range t {
+			found = redactSecretData(value) || found
+		}
+	}
+	return found
+}
+
+// redactFields redacts the values of the data and stringData of secret.
+func redactFields(secret map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		if data, ok := secret[field].(map[string]interface{}); ok {
+			for key := range data {
+				data[key] = redacted
+			}
+		}
+	}
+}
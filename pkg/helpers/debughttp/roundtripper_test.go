@@ -0,0 +1,240 @@
+package debughttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type fakeRoundTripper struct {
+	resp *http.Response
+}
+
+func (rt *fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.resp, nil
+}
+
+func TestRoundTripper(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      Level
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:       "default",
+			level:      LevelNone,
+			expected:   []string{"> POST https://example.com/oauth/token?code=<redacted>&state=1", "< 201 Created in"},
+			unexpected: []string{"Authorization", "secret", "abc123"},
+		},
+		{
+			name:       "headers",
+			level:      LevelHeaders,
+			expected:   []string{"> Authorization: <redacted>", "> Accept: application/json", "< Content-Type: application/json"},
+			unexpected: []string{"secret", "abc123"},
+		},
+		{
+			name:       "body",
+			level:      LevelBody,
+			expected:   []string{`> {"password": "<redacted>", "user": "dev"}`, `< {"access_token":"<redacted>","expires_in":3600}`},
+			unexpected: []string{"secret", "abc123"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{
+				Status:     "201 Created",
+				StatusCode: http.StatusCreated,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"access_token":"abc123","expires_in":3600}`)),
+			}
+			req, err := http.NewRequest("POST", "https://example.com/oauth/token?code=abc123&state=1", strings.NewReader(`{"password": "secret", "user": "dev"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("Accept", "application/json")
+
+			out := &bytes.Buffer{}
+			got, err := NewRoundTripper(&fakeRoundTripper{resp: resp}, test.level, out).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(got.Body)
+			got.Body.Close()
+			if string(body) != `{"access_token":"abc123","expires_in":3600}` {
+				t.Errorf("response body was not preserved: %s", body)
+			}
+			for _, s := range test.expected {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("expected %q in output:\n%s", s, out.String())
+				}
+			}
+			for _, s := range test.unexpected {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("unexpected %q in output:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRoundTripperSkipsBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		status      int
+		contentType string
+		expected    string
+	}{
+		{
+			name:     "watch",
+			url:      "https://example.com/apis/build.openshift.io/v1/namespaces/test/buildconfigs?watch=true",
+			status:   http.StatusOK,
+			expected: "< [body not dumped: stream]",
+		},
+		{
+			name:     "followed logs",
+			url:      "https://example.com/apis/build.openshift.io/v1/namespaces/test/builds/app-1/log?follow=true",
+			status:   http.StatusOK,
+			expected: "< [body not dumped: stream]",
+		},
+		{
+			name:     "upgrade",
+			url:      "https://example.com/api/v1/namespaces/test/pods/app/exec?command=sh",
+			status:   http.StatusSwitchingProtocols,
+			expected: "< [body not dumped: upgraded connection]",
+		},
+		{
+			name:        "binary",
+			url:         "https://example.com/api/v1/namespaces/test/pods",
+			status:      http.StatusOK,
+			contentType: "application/vnd.kubernetes.protobuf",
+			expected:    "< [body not dumped: application/vnd.kubernetes.protobuf]",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// reading the body would block until the stream ends
+			reader, writer := io.Pipe()
+			defer writer.Close()
+			resp := &http.Response{
+				StatusCode: test.status,
+				Header:     http.Header{"Content-Type": []string{test.contentType}},
+				Body:       reader,
+			}
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := &bytes.Buffer{}
+			got, err := NewRoundTripper(&fakeRoundTripper{resp: resp}, LevelBody, out).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Body != reader {
+				t.Errorf("expected the body to be left alone")
+			}
+			if !strings.Contains(out.String(), test.expected) {
+				t.Errorf("expected %q in output:\n%s", test.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestRoundTripperTruncatesBodies(t *testing.T) {
+	body := strings.Repeat("a", 2*maxBodySize)
+	req, err := http.NewRequest("POST", "https://example.com/apis/build.openshift.io/v1/namespaces/test/buildconfigs/app/instantiatebinary", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+	out := &bytes.Buffer{}
+	got, err := NewRoundTripper(&fakeRoundTripper{resp: resp}, LevelBody, out).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, _ := io.ReadAll(got.Body)
+	got.Body.Close()
+	if string(read) != body {
+		t.Errorf("response body was not preserved")
+	}
+	for _, expected := range []string{fmt.Sprintf("> [body not dumped: %d bytes]", len(body)), fmt.Sprintf("< [truncated to %d bytes]", maxBodySize)} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in output", expected)
+		}
+	}
+	if len(out.String()) > 2*maxBodySize {
+		t.Errorf("expected the dump to be truncated, got %d bytes", len(out.String()))
+	}
+}
+
+func TestRoundTripperRedactsSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		expected string
+	}{
+		{
+			name:     "secrets resource",
+			url:      "https://example.com/api/v1/namespaces/test/secrets/builder-token",
+			body:     `{"kind":"Secret","data":{"token":"c2VjcmV0"}}`,
+			expected: "< [body not dumped: secrets]",
+		},
+		{
+			name:     "secret in a list",
+			url:      "https://example.com/apis/template.openshift.io/v1/namespaces/test/processedtemplates",
+			body:     `{"kind":"List","items":[{"kind":"Secret","data":{"key":"c2VjcmV0"},"stringData":{"password":"secret"}}]}`,
+			expected: `< {"items":[{"data":{"key":"<redacted>"},"kind":"Secret","stringData":{"password":"<redacted>"}}],"kind":"List"}`,
+		},
+		{
+			name:     "secret list",
+			url:      "https://example.com/api/v1/secrets",
+			body:     `{"kind":"SecretList","items":[{"data":{"key":"c2VjcmV0"}}]}`,
+			expected: "< [body not dumped: secrets]",
+		},
+		{
+			name:     "kubeconfig",
+			url:      "https://example.com/api/v1/namespaces/test/configmaps/kubeconfig",
+			body:     `{"users":[{"user":{"client-key-data":"c2VjcmV0"}}]}`,
+			expected: `< {"users":[{"user":{"client-key-data":"<redacted>"}}]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(test.body))}
+			req, err := http.NewRequest("GET", test.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := &bytes.Buffer{}
+			got, err := NewRoundTripper(&fakeRoundTripper{resp: resp}, LevelBody, out).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			io.ReadAll(got.Body)
+			got.Body.Close()
+			if !strings.Contains(out.String(), test.expected) {
+				t.Errorf("expected %q in output:\n%s", test.expected, out.String())
+			}
+			if strings.Contains(out.String(), "c2VjcmV0") || strings.Contains(out.String(), `"secret"`) {
+				t.Errorf("expected the secret data to be redacted:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	body := `{"kind":"SecretList","items":[{"metadata":{"name":"a"},"data":{"key":"c2VjcmV0"}}]}`
+	if got := redactSecrets(body); strings.Contains(got, "c2VjcmV0") || !strings.Contains(got, `"name":"a"`) {
+		t.Errorf("expected the data of the items to be redacted, got %s", got)
+	}
+	if got := redactSecrets(`{"kind":"Secret","data":{"key":`); got != redacted {
+		t.Errorf("expected a truncated secret to be redacted, got %s", got)
+	}
+}
@@ -0,0 +1,119 @@
+package describe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gonum/graph"
+
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/library-go/pkg/image/reference"
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// anonymizer replaces namespaces, names and group values of a build chain
+// with hashes of them. The hashes are stable across runs so that outputs
+// generated at different times can still be compared. Tags are kept since
+// they rarely carry sensitive information and help understanding the chain.
+// The zero value leaves everything untouched.
+type anonymizer struct {
+	enabled bool
+}
+
+func (a anonymizer) hash(prefix, value string) string {
+	if !a.enabled {
+		return value
+	}
+	sum := sha256.Sum256([]byte(value))
+	return prefix + "-" + hex.EncodeToString(sum[:])[:10]
+}
+
+func (a anonymizer) namespace(namespace string) string {
+	return a.hash("ns", namespace)
+}
+
+func (a anonymizer) buildConfigName(name string) string {
+	return a.hash("bc", name)
+}
+
+func (a anonymizer) deploymentConfigName(name string) string {
+	return a.hash("dc", name)
+}
+
+// imageStreamTagName anonymizes the image stream part of a name:tag.
+func (a anonymizer) imageStreamTagName(name string) string {
+	if !a.enabled {
+		return name
+	}
+	stream, tag, _ := imageutil.SplitImageStreamTag(name)
+	return imageutil.JoinImageStreamTag(a.hash("is", stream), tag)
+}
+
+// imageStreamName anonymizes the name of an image stream.
+func (a anonymizer) imageStreamName(name string) string {
+	return a.hash("is", name)
+}
+
+// dockerImage anonymizes the repository of an image of an external registry,
+// keeping its tag or digest.
+func (a anonymizer) dockerImage(ref reference.DockerImageReference) string {
+	if !a.enabled {
+		return ref.String()
+	}
+	repository := ref
+	repository.Tag, repository.ID = "", ""
+	name := a.hash("image", repository.String())
+	switch {
+	case len(ref.ID) > 0:
+		return name + "@" + ref.ID
+	case len(ref.Tag) > 0:
+		return name + ":" + ref.Tag
+	}
+	return name
+}
+
+func (a anonymizer) group(group string) string {
+	if group == ungrouped {
+		return group
+	}
+	return a.hash("group", group)
+}
+
+// nodeID returns the unique name of the node, which is also used as its DOT label.
+func (a anonymizer) nodeID(node graph.Node) string {
+	switch t := node.(type) {
+	case *imagegraph.ImageStreamTagNode:
+		return fmt.Sprintf("%s|%s/%s", imagegraph.ImageStreamTagNodeKind, a.namespace(t.Namespace), a.imageStreamTagName(t.Name))
+	case *imagegraph.ImageStreamNode:
+		return fmt.Sprintf("%s|%s/%s", imagegraph.ImageStreamNodeKind, a.namespace(t.Namespace), a.imageStreamName(t.Name))
+	case *buildgraph.BuildConfigNode:
+		return fmt.Sprintf("%s|%s/%s", buildgraph.BuildConfigNodeKind, a.namespace(t.BuildConfig.Namespace), a.buildConfigName(t.BuildConfig.Name))
+	case *appsgraph.DeploymentConfigNode:
+		return fmt.Sprintf("%s|%s/%s", appsgraph.DeploymentConfigNodeKind, a.namespace(t.DeploymentConfig.Namespace), a.deploymentConfigName(t.DeploymentConfig.Name))
+	case *imagegraph.DockerImageRepositoryNode:
+		return fmt.Sprintf("%s|%s", imagegraph.DockerRepositoryNodeKind, a.dockerImage(t.Ref))
+	}
+	if n, ok := node.(interface{ UniqueName() osgraph.UniqueName }); ok {
+		return n.UniqueName().String()
+	}
+	return ""
+}
+
+// ResourceName implements osgraph.Namer for the nodes of a build chain.
+func (a anonymizer) ResourceName(obj interface{}) string {
+	switch t := obj.(type) {
+	case *imagegraph.ImageStreamTagNode:
+		return "istag/" + a.imageStreamTagName(t.Name)
+	case *imagegraph.ImageStreamNode:
+		return "is/" + a.imageStreamName(t.Name)
+	case *buildgraph.BuildConfigNode:
+		return "bc/" + a.buildConfigName(t.BuildConfig.Name)
+	case *appsgraph.DeploymentConfigNode:
+		return "dc/" + a.deploymentConfigName(t.DeploymentConfig.Name)
+	}
+	return namespacedFormatter{hideNamespace: true}.ResourceName(obj)
+}
@@ -0,0 +1,240 @@
+package describe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// noLane marks a column of the ascii output without any pending edge.
+const noLane = -1
+
+// asciiOutput draws g with ASCII characters, one node per line after the
+// nodes it depends on, like 'git log --graph'. Every column carries an edge
+// to a node below: lines ending with "." fork a column for the other
+// dependents of the node above, and lines ending with "'" merge the columns
+// reaching the same node. Edges back to a node above, which close a cycle,
+// aren't drawn. Labels are shortened so that lines fit in MaxWidth columns.
+func (d *ChainDescriber) asciiOutput(g osgraph.Graph, namer osgraph.Namer, anon anonymizer, cut map[int]bool, reverse bool) string {
+	if reverse {
+		g = g.EdgeSubgraph(osgraph.ReverseExistingDirectEdge)
+	}
+
+	labels := map[int]string{}
+	for _, node := range g.Nodes() {
+		labels[node.ID()] = d.asciiLabel(node, namer, anon)
+		if cut[node.ID()] {
+			labels[node.ID()] += truncatedSuffix
+		}
+	}
+	byLabel := func(nodes []graph.Node) {
+		sort.Slice(nodes, func(i, j int) bool { return labels[nodes[i].ID()] < labels[nodes[j].ID()] })
+	}
+
+	lines := []string{}
+	lanes := []int{}
+	drawn := map[int]bool{}
+	for _, node := range topologicalOrder(g, byLabel) {
+		drawn[node.ID()] = true
+		cols := []int{}
+		for i, id := range lanes {
+			if id == node.ID() {
+				cols = append(cols, i)
+			}
+		}
+		if len(cols) == 0 {
+			cols = append(cols, freeLane(&lanes))
+			lanes[cols[0]] = node.ID()
+		}
+		col := cols[0]
+		if len(cols) > 1 {
+			lines = append(lines, joinRow(lanes, col, cols[1:], '\''))
+			for _, c := range cols[1:] {
+				lanes[c] = noLane
+			}
+			lanes = trimLanes(lanes)
+		}
+
+		row := []byte(laneRow(lanes))
+		row[2*col] = '*'
+		lines = append(lines, d.asciiLine(string(row), labels[node.ID()]))
+
+		children := []graph.Node{}
+		for _, child := range g.From(node) {
+			if !drawn[child.ID()] {
+				children = append(children, child)
+			}
+		}
+		byLabel(children)
+		if len(children) == 0 {
+			lanes[col] = noLane
+		} else {
+			lanes[col] = children[0].ID()
+			forks := []int{}
+			for _, child := range children[1:] {
+				c := freeLane(&lanes)
+				lanes[c] = child.ID()
+				forks = append(forks, c)
+			}
+			if len(forks) > 0 {
+				lines = append(lines, joinRow(lanes, col, forks, '.'))
+			}
+		}
+		lanes = trimLanes(lanes)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// asciiLabel returns the label of node in the ascii output.
+func (d *ChainDescriber) asciiLabel(node graph.Node, namer osgraph.Namer, anon anonymizer) string {
+	singleNamespace := len(d.namespaces) == 1 && !d.namespaces.Has(metav1.NamespaceAll)
+	switch t := node.(type) {
+	case *imagegraph.ImageStreamTagNode:
+		return outputHelper(namer.ResourceName(t), anon.namespace(t.Namespace), singleNamespace) + d.environmentsSuffix(t, anon) + d.vulnerabilitiesSuffix(t) + d.imageDetailsSuffix(t)
+	case *imagegraph.ImageStreamNode:
+		return outputHelper(namer.ResourceName(t), anon.namespace(t.Namespace), singleNamespace)
+	case *buildgraph.BuildConfigNode:
+		label := outputHelper(namer.ResourceName(t), anon.namespace(t.BuildConfig.Namespace), singleNamespace)
+		if isCustomBuild(t) {
+			label += customSuffix
+		}
+		if d.activity != nil {
+			label += fmt.Sprintf(" (%d builds)", d.activity[t.UniqueName()])
+		}
+		return label
+	case *appsgraph.DeploymentConfigNode:
+		return outputHelper(namer.ResourceName(t), anon.namespace(t.DeploymentConfig.Namespace), singleNamespace)
+	case *imagegraph.DockerImageRepositoryNode:
+		return externalLabel(t, anon)
+	}
+	panic("this graph contains node kinds other than imageStreamTags, imageStreams, buildConfigs, deploymentConfigs and external images")
+}
+
+// asciiLine returns the row of lanes followed by label, shortened so that the
+// line fits in MaxWidth columns when it is positive.
+func (d *ChainDescriber) asciiLine(row, label string) string {
+	if d.MaxWidth > 0 {
+		available := d.MaxWidth - len(row) - 1
+		if available < 1 {
+			available = 1
+		}
+		label = shorten(label, available)
+	}
+	return row + " " + label
+}
+
+// topologicalOrder returns the nodes of g, every node after the nodes with an
+// edge to it. Ties are broken by sortFn. When only cycles are left, the first
+// of their nodes according to sortFn comes next, as if the edges to it were
+// removed.
+func topologicalOrder(g osgraph.Graph, sortFn func([]graph.Node)) []graph.Node {
+	nodes := g.Nodes()
+	sortFn(nodes)
+	inDegree := map[int]int{}
+	ready := []graph.Node{}
+	for _, node := range nodes {
+		inDegree[node.ID()] = len(g.To(node))
+		if inDegree[node.ID()] == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	order := []graph.Node{}
+	done := map[int]bool{}
+	for len(order) < len(nodes) {
+		if len(ready) == 0 {
+			for _, node := range nodes {
+				if !done[node.ID()] {
+					ready = append(ready, node)
+					break
+				}
+			}
+		}
+		node := ready[0]
+		ready = ready[1:]
+		order = append(order, node)
+		done[node.ID()] = true
+
+		children := g.From(node)
+		sortFn(children)
+		for _, child := range children {
+			if inDegree[child.ID()]--; inDegree[child.ID()] == 0 && !done[child.ID()] {
+				ready = append(ready, child)
+			}
+		}
+		sortFn(ready)
+	}
+	return order
+}
+
+// freeLane returns the first column of lanes without any pending edge,
+// adding one if needed.
+func freeLane(lanes *[]int) int {
+	for i, id := range *lanes {
+		if id == noLane {
+			return i
+		}
+	}
+	*lanes = append(*lanes, noLane)
+	return len(*lanes) - 1
+}
+
+// trimLanes drops the trailing columns of lanes without any pending edge.
+func trimLanes(lanes []int) []int {
+	for len(lanes) > 0 && lanes[len(lanes)-1] == noLane {
+		lanes = lanes[:len(lanes)-1]
+	}
+	return lanes
+}
+
+// laneRow returns a row with a '|' in every column with a pending edge.
+func laneRow(lanes []int) string {
+	if len(lanes) == 0 {
+		return ""
+	}
+	row := []byte(strings.Repeat(" ", 2*len(lanes)-1))
+	for i, id := range lanes {
+		if id != noLane {
+			row[2*i] = '|'
+		}
+	}
+	return string(row)
+}
+
+// joinRow returns a row linking the column col to the columns others with a
+// horizontal line ending with mark in each of them. The columns it crosses
+// are drawn with '+'.
+func joinRow(lanes []int, col int, others []int, mark byte) string {
+	row := []byte(laneRow(lanes))
+	row[2*col] = '|'
+	lo, hi := col, col
+	for _, c := range others {
+		if c < lo {
+			lo = c
+		}
+		if c > hi {
+			hi = c
+		}
+	}
+	for i := 2 * lo; i <= 2*hi; i++ {
+		switch {
+		case i == 2*col:
+		case row[i] == '|':
+			row[i] = '+'
+		default:
+			row[i] = '-'
+		}
+	}
+	for _, c := range others {
+		row[2*c] = mark
+	}
+	return strings.TrimRight(string(row), " ")
+}
@@ -0,0 +1,420 @@
+package describe
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	appsv1client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	imagev1client "github.com/openshift/client-go/image/clientset/versioned/typed/image/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	"github.com/openshift/library-go/pkg/image/reference"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// BuildConfigLister lists the objects a build chain is made of: the build
+// configurations of a namespace and, to weight the chain by activity, their
+// builds. A namespace that doesn't exist holds no objects.
+type BuildConfigLister interface {
+	ListBuildConfigs(ctx context.Context, namespace string) ([]buildv1.BuildConfig, error)
+	ListBuilds(ctx context.Context, namespace string) ([]buildv1.Build, error)
+}
+
+// NewBuildConfigLister returns a BuildConfigLister backed by the build API.
+func NewBuildConfigLister(c buildv1client.BuildV1Interface) BuildConfigLister {
+	return &buildConfigLister{c: c, selector: labels.Everything()}
+}
+
+// NewFilteredBuildConfigLister returns a BuildConfigLister backed by the build
+// API that only lists the build configurations matching selector. The
+// selector is sent to the server, so that the build configurations left out
+// are never transferred.
+func NewFilteredBuildConfigLister(c buildv1client.BuildV1Interface, selector labels.Selector) BuildConfigLister {
+	return &buildConfigLister{c: c, selector: selector}
+}
+
+type buildConfigLister struct {
+	c        buildv1client.BuildV1Interface
+	selector labels.Selector
+}
+
+func (l *buildConfigLister) ListBuildConfigs(ctx context.Context, namespace string) ([]buildv1.BuildConfig, error) {
+	opts := metav1.ListOptions{}
+	if !l.selector.Empty() {
+		opts.LabelSelector = l.selector.String()
+	}
+	list, err := l.c.BuildConfigs(namespace).List(ctx, opts)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (l *buildConfigLister) ListBuilds(ctx context.Context, namespace string) ([]buildv1.Build, error) {
+	list, err := l.c.Builds(namespace).List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// DeploymentConfigLister lists the deployment configs of a namespace, to
+// find the environments the images of a build chain run in. A namespace that
+// doesn't exist holds no deployment configs.
+type DeploymentConfigLister interface {
+	ListDeploymentConfigs(ctx context.Context, namespace string) ([]appsv1.DeploymentConfig, error)
+}
+
+// NewDeploymentConfigLister returns a DeploymentConfigLister backed by the apps API.
+func NewDeploymentConfigLister(c appsv1client.AppsV1Interface) DeploymentConfigLister {
+	return &deploymentConfigLister{c: c}
+}
+
+type deploymentConfigLister struct {
+	c appsv1client.AppsV1Interface
+}
+
+func (l *deploymentConfigLister) ListDeploymentConfigs(ctx context.Context, namespace string) ([]appsv1.DeploymentConfig, error) {
+	list, err := l.c.DeploymentConfigs(namespace).List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// ImageStreamLister lists the image streams of a namespace, to resolve the
+// outputs pushed by reference to the integrated registry back to their image
+// streams. A namespace that doesn't exist holds no image streams.
+type ImageStreamLister interface {
+	ListImageStreams(ctx context.Context, namespace string) ([]imagev1.ImageStream, error)
+}
+
+// NewImageStreamLister returns an ImageStreamLister backed by the image API.
+func NewImageStreamLister(c imagev1client.ImageV1Interface) ImageStreamLister {
+	return &imageStreamLister{c: c}
+}
+
+type imageStreamLister struct {
+	c imagev1client.ImageV1Interface
+}
+
+func (l *imageStreamLister) ListImageStreams(ctx context.Context, namespace string) ([]imagev1.ImageStream, error) {
+	list, err := l.c.ImageStreams(namespace).List(ctx, metav1.ListOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// chainLoader loads the build configurations of a namespace, and their
+// builds when withBuilds is set, into the graph of a build chain. The image
+// stream tags referenced without a tag default to latest, with a warning,
+// unless strict is set. When at is set, the objects created after it are
+// left out. When deploymentConfigs is set, the deployment configs of the
+// namespace are loaded to find the environments of the image stream tags.
+// The image streams of the namespace are only loaded, by loadImageStreams,
+// when build configurations push by reference to its repositories.
+type chainLoader struct {
+	namespace         string
+	lister            BuildConfigLister
+	deploymentConfigs DeploymentConfigLister
+	withBuilds        bool
+	strict            bool
+	at                *time.Time
+
+	buildConfigs []buildv1.BuildConfig
+	builds       []buildv1.Build
+	dcs          []appsv1.DeploymentConfig
+	streams      []imagev1.ImageStream
+	// streamsLoaded is whether streams were listed.
+	streamsLoaded bool
+	warnings      []ChainWarning
+	// err is the error Load failed with.
+	err error
+}
+
+// RetryTransient calls fn until it succeeds or fails with an error that isn't
+// transient, backing off between calls, and returns its last error.
+func RetryTransient(fn func() error) error {
+	return retry.OnError(transientBackoff, isTransient, fn)
+}
+
+// transientBackoff is how the requests failing with transient errors are
+// retried.
+var transientBackoff = retry.DefaultBackoff
+
+// isTransient returns whether err may go away when the request is retried.
+func isTransient(err error) bool {
+	return errors.IsServerTimeout(err) || errors.IsTimeout(err) || errors.IsTooManyRequests(err) ||
+		errors.IsInternalError(err) || errors.IsServiceUnavailable(err) ||
+		utilnet.IsConnectionReset(err) || utilnet.IsProbableEOF(err)
+}
+
+func (l *chainLoader) Load() error {
+	ctx := context.TODO()
+	err := RetryTransient(func() (err error) {
+		l.buildConfigs, err = l.lister.ListBuildConfigs(ctx, l.namespace)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if l.at != nil {
+		existing := []buildv1.BuildConfig{}
+		for _, bc := range l.buildConfigs {
+			if !bc.CreationTimestamp.Time.After(*l.at) {
+				existing = append(existing, bc)
+			}
+		}
+		l.buildConfigs = existing
+	}
+	for i := range l.buildConfigs {
+		l.dropMalformedTriggers(&l.buildConfigs[i])
+		if err := l.defaultTags(&l.buildConfigs[i]); err != nil {
+			return err
+		}
+	}
+	if l.deploymentConfigs != nil {
+		err := RetryTransient(func() (err error) {
+			l.dcs, err = l.deploymentConfigs.ListDeploymentConfigs(ctx, l.namespace)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if !l.withBuilds {
+		return nil
+	}
+	err = RetryTransient(func() (err error) {
+		l.builds, err = l.lister.ListBuilds(ctx, l.namespace)
+		return err
+	})
+	if err != nil || l.at == nil {
+		return err
+	}
+	existing := []buildv1.Build{}
+	for _, build := range l.builds {
+		if !build.CreationTimestamp.Time.After(*l.at) {
+			existing = append(existing, build)
+		}
+	}
+	l.builds = existing
+	return nil
+}
+
+// defaultTags sets the tag of the image stream tags bc refers to without one
+// to latest, which would otherwise be left out of the chain.
+func (l *chainLoader) defaultTags(bc *buildv1.BuildConfig) error {
+	refs := []*corev1.ObjectReference{bc.Spec.Output.To, buildutil.GetInputReference(bc.Spec.Strategy)}
+	for _, trigger := range bc.Spec.Triggers {
+		if trigger.ImageChange != nil {
+			refs = append(refs, trigger.ImageChange.From)
+		}
+	}
+	for _, ref := range refs {
+		if ref == nil || ref.Kind != "ImageStreamTag" {
+			continue
+		}
+		if _, _, ok := imageutil.SplitImageStreamTag(ref.Name); ok {
+			continue
+		}
+		if l.strict {
+			return fmt.Errorf("build config %q in %q refers to image stream tag %q without a tag", bc.Name, bc.Namespace, ref.Name)
+		}
+		l.warnings = append(l.warnings, buildConfigWarning(WarningMissingTag, bc.Namespace, bc.Name, "build config %q in %q refers to image stream tag %q without a tag, assuming %q", bc.Name, bc.Namespace, ref.Name, imageutil.JoinImageStreamTag(ref.Name, "")))
+		ref.Name = imageutil.JoinImageStreamTag(ref.Name, "")
+	}
+	return nil
+}
+
+// dropMalformedTriggers removes the image change triggers of bc that can't
+// follow an image stream tag, with a warning: those without parameters, and
+// those on another kind of image, explicitly or through the image the
+// strategy of bc builds from.
+func (l *chainLoader) dropMalformedTriggers(bc *buildv1.BuildConfig) {
+	kept := []buildv1.BuildTriggerPolicy{}
+	for _, trigger := range bc.Spec.Triggers {
+		if trigger.Type != buildv1.ImageChangeBuildTriggerType {
+			kept = append(kept, trigger)
+			continue
+		}
+		if reason := malformedTriggerReason(bc, trigger); len(reason) > 0 {
+			l.warnings = append(l.warnings, buildConfigWarning(WarningMalformedTrigger, bc.Namespace, bc.Name, "build config %q in %q %s, it is ignored", bc.Name, bc.Namespace, reason))
+			continue
+		}
+		kept = append(kept, trigger)
+	}
+	bc.Spec.Triggers = kept
+}
+
+// malformedTriggerReason returns why the image change trigger of bc can't
+// follow an image stream tag, or an empty string when it can.
+func malformedTriggerReason(bc *buildv1.BuildConfig, trigger buildv1.BuildTriggerPolicy) string {
+	if trigger.ImageChange == nil {
+		return "has an image change trigger without parameters"
+	}
+	from := trigger.ImageChange.From
+	if from == nil {
+		from = buildutil.GetInputReference(bc.Spec.Strategy)
+	}
+	switch {
+	case from == nil:
+		return "has an image change trigger without an image and doesn't build from one"
+	case from.Kind != "ImageStreamTag" && from.Kind != "ImageStream":
+		return fmt.Sprintf("has an image change trigger on %s %q, which isn't an image stream tag", from.Kind, from.Name)
+	}
+	return ""
+}
+
+// missingOutputStreams returns warnings about the build configurations of
+// loaders pushing to an image stream tag whose image stream doesn't exist,
+// among the namespaces whose image streams were loaded.
+func missingOutputStreams(loaders []*chainLoader) []ChainWarning {
+	loaded := map[string]bool{}
+	streams := map[string]bool{}
+	for _, loader := range loaders {
+		if !loader.streamsLoaded {
+			continue
+		}
+		loaded[loader.namespace] = true
+		for _, stream := range loader.streams {
+			streams[stream.Namespace+"/"+stream.Name] = true
+		}
+	}
+	warnings := []ChainWarning{}
+	for _, loader := range loaders {
+		for _, bc := range loader.buildConfigs {
+			to := bc.Spec.Output.To
+			if to == nil || to.Kind != "ImageStreamTag" {
+				continue
+			}
+			namespace := to.Namespace
+			if len(namespace) == 0 {
+				namespace = bc.Namespace
+			}
+			stream, _, _ := imageutil.SplitImageStreamTag(to.Name)
+			if loaded[namespace] && !streams[namespace+"/"+stream] {
+				warnings = append(warnings, buildConfigWarning(WarningMissingOutputStream, bc.Namespace, bc.Name, "build config %q in %q pushes to image stream tag %q in %q, whose image stream doesn't exist", bc.Name, bc.Namespace, to.Name, namespace))
+			}
+		}
+	}
+	return warnings
+}
+
+// loadImageStreams lists the image streams of the namespaces of loaders the
+// build configurations of loaders push to by reference, in a registry, so
+// that resolveRegistryOutputs can resolve them. The image streams of other
+// namespaces aren't needed, and users allowed to read build configurations
+// may not be allowed to list image streams: the outputs of the namespaces
+// whose image streams can't be listed are left unresolved.
+func loadImageStreams(lister ImageStreamLister, loaders []*chainLoader) {
+	pushedTo := map[string]bool{}
+	for _, loader := range loaders {
+		for _, bc := range loader.buildConfigs {
+			to := bc.Spec.Output.To
+			if to == nil || to.Kind != "DockerImage" {
+				continue
+			}
+			if ref, err := reference.Parse(to.Name); err == nil && len(ref.Registry) > 0 && len(ref.Namespace) > 0 {
+				pushedTo[ref.Namespace] = true
+			}
+		}
+	}
+	ctx := context.TODO()
+	for _, loader := range loaders {
+		if !pushedTo[loader.namespace] {
+			continue
+		}
+		err := RetryTransient(func() (err error) {
+			loader.streams, err = lister.ListImageStreams(ctx, loader.namespace)
+			return err
+		})
+		switch {
+		case err == nil:
+			loader.streamsLoaded = true
+		case errors.IsForbidden(err) || errors.IsNotFound(err):
+			klog.V(4).Infof("Leaving the outputs pushed to the registry in %q unresolved: %v", loader.namespace, err)
+		default:
+			klog.Warningf("Leaving the outputs pushed to the registry in %q unresolved: %v", loader.namespace, err)
+		}
+	}
+}
+
+// resolveRegistryOutputs turns the outputs of the build configurations of
+// loaders pushed by reference to the repository of a loaded image stream,
+// in the integrated registry, into references to its image stream tag so
+// that they connect to the chain.
+func resolveRegistryOutputs(loaders []*chainLoader) {
+	streams := map[string]*imagev1.ImageStream{}
+	for _, loader := range loaders {
+		for i := range loader.streams {
+			stream := &loader.streams[i]
+			for _, repository := range []string{stream.Status.DockerImageRepository, stream.Status.PublicDockerImageRepository} {
+				if ref, err := reference.Parse(repository); err == nil && len(repository) > 0 {
+					streams[ref.AsRepository().Exact()] = stream
+				}
+			}
+		}
+	}
+	if len(streams) == 0 {
+		return
+	}
+	for _, loader := range loaders {
+		for i := range loader.buildConfigs {
+			to := loader.buildConfigs[i].Spec.Output.To
+			if to == nil || to.Kind != "DockerImage" {
+				continue
+			}
+			ref, err := reference.Parse(to.Name)
+			if err != nil || (len(ref.ID) > 0 && len(ref.Tag) == 0) {
+				continue
+			}
+			stream, ok := streams[ref.AsRepository().Exact()]
+			if !ok {
+				continue
+			}
+			klog.V(4).Infof("Resolved the output %q of build configuration %q in %q to image stream %q in %q", to.Name, loader.buildConfigs[i].Name, loader.buildConfigs[i].Namespace, stream.Name, stream.Namespace)
+			to.Kind = "ImageStreamTag"
+			to.Namespace = stream.Namespace
+			to.Name = imageutil.JoinImageStreamTag(stream.Name, ref.Tag)
+		}
+	}
+}
+
+func (l *chainLoader) AddToGraph(g osgraph.Graph) error {
+	for i := range l.buildConfigs {
+		buildgraph.EnsureBuildConfigNode(g, &l.buildConfigs[i])
+	}
+	for i := range l.builds {
+		buildgraph.EnsureBuildNode(g, &l.builds[i])
+	}
+	return nil
+}
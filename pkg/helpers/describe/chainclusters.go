@@ -0,0 +1,146 @@
+package describe
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// ClusterChain is the json output of a build chain described in a cluster.
+type ClusterChain struct {
+	// Cluster is the name of the kubeconfig context of the cluster.
+	Cluster string
+	Output  *ChainOutput
+}
+
+// TagAlias is an image stream tag of a cluster pointing to the image of a
+// node merged across clusters.
+type TagAlias struct {
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// MergeClusterChains returns the json output of the build chains of several
+// clusters merged into a single graph. The image stream tags pointing to the
+// same image, as the same image promoted from a cluster to another, are a
+// single node identified by the digest of the image, listing every image
+// stream tag pointing to it as an alias. The images of external registries
+// are shared by the clusters, and the other nodes are told apart by their
+// cluster, appended to their ID.
+func MergeClusterChains(chains []ClusterChain) (string, error) {
+	out := &ChainOutput{
+		Nodes: []ChainNode{},
+		Edges: []ChainEdge{},
+	}
+	nodes := map[string]*ChainNode{}
+	order := []string{}
+	edges := sets.NewString()
+	roots := []string{}
+	for _, chain := range chains {
+		ids := map[string]string{}
+		id := func(id string) string {
+			if mapped, ok := ids[id]; ok {
+				return mapped
+			}
+			// edges to the external images left out of the output
+			return clusterNodeID(chain.Cluster, ChainNode{ID: id, Kind: strings.SplitN(id, "|", 2)[0]})
+		}
+		for _, node := range chain.Output.Nodes {
+			node := node
+			nodeID := clusterNodeID(chain.Cluster, node)
+			ids[node.ID] = nodeID
+			if merged, ok := nodes[nodeID]; ok {
+				mergeClusterNode(merged, chain.Cluster, node)
+				continue
+			}
+			switch {
+			case hasDigest(node):
+				node.Aliases = []TagAlias{{Cluster: chain.Cluster, Namespace: node.Namespace, Name: node.Name}}
+			case node.Kind != imagegraph.DockerRepositoryNodeKind:
+				node.Cluster = chain.Cluster
+			}
+			node.ID = nodeID
+			nodes[nodeID] = &node
+			order = append(order, nodeID)
+		}
+		for _, edge := range chain.Output.Edges {
+			edge.From, edge.To = id(edge.From), id(edge.To)
+			if edges.Has(edge.From + "|" + edge.To) {
+				continue
+			}
+			edges.Insert(edge.From + "|" + edge.To)
+			out.Edges = append(out.Edges, edge)
+		}
+		for _, root := range append([]string{chain.Output.Root}, chain.Output.Roots...) {
+			if len(root) > 0 {
+				roots = append(roots, id(root))
+			}
+		}
+		for _, warning := range chain.Output.Warnings {
+			warning.Cluster = chain.Cluster
+			out.Warnings = append(out.Warnings, warning)
+		}
+	}
+	for _, id := range order {
+		out.Nodes = append(out.Nodes, *nodes[id])
+	}
+
+	roots = sets.NewString(roots...).List()
+	if len(roots) == 1 {
+		out.Root = roots[0]
+	} else {
+		out.Roots = roots
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].ID < out.Nodes[j].ID })
+	sort.Slice(out.Edges, func(i, j int) bool {
+		if out.Edges[i].From != out.Edges[j].From {
+			return out.Edges[i].From < out.Edges[j].From
+		}
+		return out.Edges[i].To < out.Edges[j].To
+	})
+	return out.marshal()
+}
+
+// clusterNodeID returns the ID of node of cluster in the merged graph: the
+// digest of the image of an image stream tag when it is known, the pull spec
+// of an external image, and otherwise its ID in cluster.
+func clusterNodeID(cluster string, node ChainNode) string {
+	switch {
+	case hasDigest(node):
+		return imagegraph.ImageStreamTagNodeKind + "|" + node.Image.Digest
+	case node.Kind == imagegraph.DockerRepositoryNodeKind:
+		return node.ID
+	}
+	return node.ID + "@" + cluster
+}
+
+// hasDigest returns whether node is an image stream tag whose image is known.
+func hasDigest(node ChainNode) bool {
+	return node.Kind == imagegraph.ImageStreamTagNodeKind && node.Image != nil && len(node.Image.Digest) > 0
+}
+
+// mergeClusterNode merges node of cluster into merged, an image stream tag
+// pointing to the same image in another cluster or the same external image.
+func mergeClusterNode(merged *ChainNode, cluster string, node ChainNode) {
+	if merged.Kind == imagegraph.ImageStreamTagNodeKind {
+		merged.Aliases = append(merged.Aliases, TagAlias{Cluster: cluster, Namespace: node.Namespace, Name: node.Name})
+	}
+	if len(node.Environments) > 0 {
+		merged.Environments = sets.NewString(merged.Environments...).Insert(node.Environments...).List()
+	}
+	merged.Truncated = merged.Truncated || node.Truncated
+	if len(node.Metadata) > 0 {
+		metadata := map[string]string{}
+		for key, value := range node.Metadata {
+			metadata[key] = value
+		}
+		for key, value := range merged.Metadata {
+			metadata[key] = value
+		}
+		merged.Metadata = metadata
+	}
+}
@@ -0,0 +1,203 @@
+package describe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gonum/graph"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildedges "github.com/openshift/oc/pkg/helpers/graph/buildgraph"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+const (
+	// CriticalPathByDuration weights the critical path by the estimated
+	// duration of the builds along it.
+	CriticalPathByDuration = "duration"
+	// CriticalPathByHops weights the critical path by the number of builds
+	// along it.
+	CriticalPathByHops = "hops"
+)
+
+// CriticalPath is the longest dependency path of a build chain, the builds
+// that must run one after the other before a change of its root reaches the
+// end of the chain.
+type CriticalPath struct {
+	// By is what the path is weighted by, duration or hops.
+	By     string `json:"by"`
+	Builds int    `json:"builds"`
+	// Duration is the estimated duration of all the builds of the path.
+	Duration *metav1.Duration   `json:"duration,omitempty"`
+	Steps    []CriticalPathStep `json:"steps"`
+}
+
+// CriticalPathStep is a node of the critical path. Build configs carry the
+// mean duration of their completed builds and the cumulative duration of the
+// path up to them, unless they never completed a build.
+type CriticalPathStep struct {
+	ID       string           `json:"id"`
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	Total    *metav1.Duration `json:"total,omitempty"`
+	// Unknown is set on build configs without any completed build, whose
+	// duration is counted as zero.
+	Unknown bool `json:"unknown,omitempty"`
+
+	node graph.Node
+}
+
+// estimateBuildDurations returns the mean duration of the completed builds of
+// every build configuration of the graph that completed any.
+func estimateBuildDurations(g osgraph.Graph) map[osgraph.UniqueName]time.Duration {
+	durations := map[osgraph.UniqueName]time.Duration{}
+	for _, node := range g.NodesByKind(buildgraph.BuildConfigNodeKind) {
+		bcNode := node.(*buildgraph.BuildConfigNode)
+		var total time.Duration
+		count := 0
+		for _, successor := range g.SuccessorNodesByEdgeKind(bcNode, buildedges.BuildEdgeKind) {
+			build, ok := successor.(*buildgraph.BuildNode)
+			if !ok || build.Build.Status.Phase != buildv1.BuildPhaseComplete {
+				continue
+			}
+			duration := build.Build.Status.Duration
+			if duration == 0 && build.Build.Status.StartTimestamp != nil && build.Build.Status.CompletionTimestamp != nil {
+				duration = build.Build.Status.CompletionTimestamp.Sub(build.Build.Status.StartTimestamp.Time)
+			}
+			total += duration
+			count++
+		}
+		if count > 0 {
+			durations[bcNode.UniqueName()] = total / time.Duration(count)
+		}
+	}
+	return durations
+}
+
+// criticalPath returns the longest path of g, weighted by CriticalPath. Ties
+// are broken by the number of nodes of the paths, then by the IDs of their
+// nodes. The edges closing cycles are ignored.
+func (d *ChainDescriber) criticalPath(g osgraph.Graph, anon anonymizer) *CriticalPath {
+	weight := func(node graph.Node) time.Duration {
+		bcNode, ok := node.(*buildgraph.BuildConfigNode)
+		switch {
+		case !ok:
+			return 0
+		case d.CriticalPath == CriticalPathByHops:
+			return 1
+		}
+		return d.durations[bcNode.UniqueName()]
+	}
+
+	order := topologicalOrder(g, func(nodes []graph.Node) {
+		sort.Slice(nodes, func(i, j int) bool { return anon.nodeID(nodes[i]) < anon.nodeID(nodes[j]) })
+	})
+	position := map[int]int{}
+	for i, node := range order {
+		position[node.ID()] = i
+	}
+	dist, hops, prev := map[int]time.Duration{}, map[int]int{}, map[int]graph.Node{}
+	for _, node := range order {
+		dist[node.ID()], hops[node.ID()] = weight(node), 1
+	}
+	var end graph.Node
+	for _, node := range order {
+		if end == nil || dist[node.ID()] > dist[end.ID()] || (dist[node.ID()] == dist[end.ID()] && hops[node.ID()] > hops[end.ID()]) {
+			end = node
+		}
+		for _, child := range g.From(node) {
+			if position[child.ID()] <= position[node.ID()] {
+				continue
+			}
+			candidate, candidateHops := dist[node.ID()]+weight(child), hops[node.ID()]+1
+			if candidate > dist[child.ID()] || (candidate == dist[child.ID()] && candidateHops > hops[child.ID()]) {
+				dist[child.ID()], hops[child.ID()], prev[child.ID()] = candidate, candidateHops, node
+			}
+		}
+	}
+
+	path := &CriticalPath{By: d.CriticalPath, Steps: []CriticalPathStep{}}
+	for node := end; node != nil; node = prev[node.ID()] {
+		path.Steps = append([]CriticalPathStep{{ID: anon.nodeID(node), node: node}}, path.Steps...)
+	}
+	var total time.Duration
+	for i := range path.Steps {
+		step := &path.Steps[i]
+		bcNode, ok := step.node.(*buildgraph.BuildConfigNode)
+		if !ok {
+			continue
+		}
+		path.Builds++
+		if d.CriticalPath != CriticalPathByDuration {
+			continue
+		}
+		duration, known := d.durations[bcNode.UniqueName()]
+		total += duration
+		step.Duration, step.Total, step.Unknown = &metav1.Duration{Duration: duration}, &metav1.Duration{Duration: total}, !known
+	}
+	if d.CriticalPath == CriticalPathByDuration {
+		path.Duration = &metav1.Duration{Duration: total}
+	}
+	return path
+}
+
+// describeCriticalPath returns the critical path of the chain of name in the
+// requested format.
+func (d *ChainDescriber) describeCriticalPath(path *CriticalPath, name string, namer osgraph.Namer, anon anonymizer) (string, error) {
+	switch d.outputFormat {
+	case "json":
+		data, err := json.MarshalIndent(path, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case "":
+		return d.criticalPathHumanReadable(path, name, namer, anon), nil
+	}
+	return "", fmt.Errorf("unknown specified format %q", d.outputFormat)
+}
+
+func (d *ChainDescriber) criticalPathHumanReadable(path *CriticalPath, name string, namer osgraph.Namer, anon anonymizer) string {
+	out := &bytes.Buffer{}
+	if path.Duration == nil {
+		fmt.Fprintf(out, "Critical path of %s: %d builds\n", name, path.Builds)
+		for _, step := range path.Steps {
+			fmt.Fprintf(out, "  %s\n", d.asciiLabel(step.node, namer, anon))
+		}
+		return out.String()
+	}
+
+	fmt.Fprintf(out, "Critical path of %s: %d builds, estimated %s\n", name, path.Builds, path.Duration.Duration)
+	rows := &bytes.Buffer{}
+	w := tabwriter.NewWriter(rows, 0, 2, 2, ' ', 0)
+	unknown := 0
+	for _, step := range path.Steps {
+		switch {
+		case step.Duration == nil:
+			fmt.Fprintf(w, "  %s\t\t\n", d.asciiLabel(step.node, namer, anon))
+		case step.Unknown:
+			unknown++
+			fmt.Fprintf(w, "  %s\tunknown\t%s\n", d.asciiLabel(step.node, namer, anon), step.Total.Duration)
+		default:
+			fmt.Fprintf(w, "  %s\t+%s\t%s\n", d.asciiLabel(step.node, namer, anon), step.Duration.Duration, step.Total.Duration)
+		}
+	}
+	w.Flush()
+	// image stream tags leave the duration columns empty
+	for _, line := range strings.SplitAfter(rows.String(), "\n") {
+		if len(line) > 0 {
+			fmt.Fprintln(out, strings.TrimRight(line, " \n"))
+		}
+	}
+	if unknown > 0 {
+		fmt.Fprintf(out, "%d build configs without completed builds are counted as 0s\n", unknown)
+	}
+	return out.String()
+}
@@ -0,0 +1,53 @@
+package describe
+
+import (
+	"sort"
+
+	"github.com/gonum/graph"
+
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// CrossNamespaceEdge is a dependency of a build chain between nodes of
+// different namespaces, e.g. a build config building from an image of another
+// team or pushing to it.
+type CrossNamespaceEdge struct {
+	// From and To are the IDs of the nodes, as in the json output.
+	From string
+	To   string
+	// FromNamespace and ToNamespace are the namespaces of the nodes. They are
+	// never anonymized so that they can be checked against policies.
+	FromNamespace string
+	ToNamespace   string
+}
+
+// crossNamespaceEdges returns the edges of g between nodes of different
+// namespaces, sorted so that the result is stable across runs.
+func crossNamespaceEdges(g osgraph.Graph, a anonymizer) []CrossNamespaceEdge {
+	edges := []CrossNamespaceEdge{}
+	for _, e := range g.Edges() {
+		if !crossesNamespaces(e) {
+			continue
+		}
+		edges = append(edges, CrossNamespaceEdge{
+			From:          a.nodeID(e.From()),
+			To:            a.nodeID(e.To()),
+			FromNamespace: nodeNamespace(e.From()),
+			ToNamespace:   nodeNamespace(e.To()),
+		})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// crossesNamespaces returns whether e is between nodes of different
+// namespaces. Images of external registries belong to none.
+func crossesNamespaces(e graph.Edge) bool {
+	from, to := nodeNamespace(e.From()), nodeNamespace(e.To())
+	return len(from) > 0 && len(to) > 0 && from != to
+}
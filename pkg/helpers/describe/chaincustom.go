@@ -0,0 +1,42 @@
+package describe
+
+import (
+	"github.com/gonum/graph"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildhelpers "github.com/openshift/oc/pkg/helpers/build"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// customSuffix marks the build configs using the Custom strategy in the
+// human-readable and ascii outputs.
+const customSuffix = " (custom)"
+
+// isCustomBuild returns whether node is a build config using the Custom
+// strategy. The input image of such a build config is the builder image
+// running its builds rather than a base image of its output.
+func isCustomBuild(node graph.Node) bool {
+	bc, ok := node.(*buildgraph.BuildConfigNode)
+	return ok && strategyType(bc.BuildConfig.Spec.Strategy) == string(buildv1.CustomBuildStrategyType)
+}
+
+// hasCustomBuilds returns whether g has build configs using the Custom
+// strategy.
+func hasCustomBuilds(g osgraph.Graph) bool {
+	for _, node := range g.Nodes() {
+		if isCustomBuild(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// strategyType returns the type of strategy, found from the strategy set
+// when the type is left empty.
+func strategyType(strategy buildv1.BuildStrategy) string {
+	if len(strategy.Type) > 0 {
+		return string(strategy.Type)
+	}
+	return buildhelpers.StrategyType(strategy)
+}
@@ -0,0 +1,57 @@
+package describe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+	"github.com/gonum/graph/topo"
+
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// cycleEdges returns the edges of g that are part of a cycle, keyed by
+// edgeKey. An edge is part of a cycle when both its ends belong to the same
+// strongly connected component.
+func cycleEdges(g osgraph.Graph) map[[2]int]bool {
+	component := map[int]int{}
+	for i, scc := range topo.TarjanSCC(g) {
+		if len(scc) < 2 {
+			continue
+		}
+		for _, node := range scc {
+			component[node.ID()] = i + 1
+		}
+	}
+	cycles := map[[2]int]bool{}
+	if len(component) == 0 {
+		return cycles
+	}
+	for _, e := range g.Edges() {
+		if c := component[e.From().ID()]; c != 0 && c == component[e.To().ID()] {
+			cycles[edgeKey(e)] = true
+		}
+	}
+	return cycles
+}
+
+// edgeKey identifies e by the IDs of its ends.
+func edgeKey(e graph.Edge) [2]int {
+	return [2]int{e.From().ID(), e.To().ID()}
+}
+
+// cycleMessages describes the cycles of g, sorted so that the output is
+// stable across runs.
+func cycleMessages(g osgraph.Graph, namer osgraph.Namer) []string {
+	messages := []string{}
+	for _, cycle := range topo.CyclesIn(g) {
+		names := []string{}
+		for _, node := range cycle {
+			names = append(names, namer.ResourceName(node))
+		}
+		messages = append(messages, fmt.Sprintf("Cycle detected in build configurations: %s", strings.Join(names, " -> ")))
+	}
+	sort.Strings(messages)
+	return messages
+}
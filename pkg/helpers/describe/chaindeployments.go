@@ -0,0 +1,46 @@
+package describe
+
+import (
+	appsv1 "github.com/openshift/api/apps/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	appsedges "github.com/openshift/oc/pkg/helpers/graph/appsgraph"
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// triggeringImageStreamTags returns the image stream tags the image change
+// triggers of dc follow, defaulting their namespace to the one of dc.
+func triggeringImageStreamTags(dc *appsv1.DeploymentConfig) []*imagev1.ImageStreamTag {
+	ists := []*imagev1.ImageStreamTag{}
+	for _, trigger := range dc.Spec.Triggers {
+		params := trigger.ImageChangeParams
+		if trigger.Type != appsv1.DeploymentTriggerOnImageChange || params == nil || params.From.Kind != "ImageStreamTag" {
+			continue
+		}
+		namespace := params.From.Namespace
+		if len(namespace) == 0 {
+			namespace = dc.Namespace
+		}
+		stream, tag, _ := imageutil.SplitImageStreamTag(params.From.Name)
+		ists = append(ists, imagegraph.MakeImageStreamTagObjectMeta(namespace, stream, tag))
+	}
+	return ists
+}
+
+// addDeploymentConfigs adds the deployment configs of dcs with image change
+// triggers to g, with an edge from every image stream tag they follow so that
+// they end the chains going through these image stream tags.
+func addDeploymentConfigs(g osgraph.Graph, dcs []appsv1.DeploymentConfig) {
+	for i := range dcs {
+		ists := triggeringImageStreamTags(&dcs[i])
+		if len(ists) == 0 {
+			continue
+		}
+		dcNode := appsgraph.EnsureDeploymentConfigNode(g, &dcs[i])
+		for _, ist := range ists {
+			g.AddEdge(imagegraph.FindOrCreateSyntheticImageStreamTagNode(g, ist), dcNode, appsedges.TriggersDeploymentEdgeKind)
+		}
+	}
+}
@@ -2,20 +2,27 @@ package describe
 
 import (
 	"fmt"
+	"net/url"
+	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gonum/graph"
 	"github.com/gonum/graph/encoding/dot"
-	"github.com/gonum/graph/path"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 
+	buildv1 "github.com/openshift/api/build/v1"
 	imagev1 "github.com/openshift/api/image/v1"
-	buildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
 	dotutil "github.com/openshift/oc/pkg/helpers/dot"
+	appsedges "github.com/openshift/oc/pkg/helpers/graph/appsgraph"
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
 	buildedges "github.com/openshift/oc/pkg/helpers/graph/buildgraph"
 	buildanalysis "github.com/openshift/oc/pkg/helpers/graph/buildgraph/analysis"
 	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
@@ -38,15 +45,153 @@ func (e NotFoundErr) Error() string {
 // ChainDescriber generates extended information about a chain of
 // dependencies of an image stream
 type ChainDescriber struct {
-	c            buildv1client.BuildConfigsGetter
+	lister       BuildConfigLister
 	namespaces   sets.String
 	outputFormat string
 	namer        osgraph.Namer
+
+	// ActivitySince, when set, makes the describer count the builds created
+	// by every build configuration since that time and weight the edges of
+	// the graph accordingly.
+	ActivitySince *time.Time
+	// SplitByTag annotates every dependency with the image stream tag it
+	// goes through in the human-readable and dot outputs.
+	SplitByTag bool
+	// GroupByLabel, when set, aggregates the build configurations of the
+	// chain by the value of this label and describes the dependencies
+	// between those groups instead of the chain itself.
+	GroupByLabel string
+	// Anonymize replaces namespaces, names and group values with stable
+	// hashes in every output format.
+	Anonymize bool
+	// LinkBase, when set, is the URL of the web console the nodes of the dot
+	// output link to.
+	LinkBase string
+	// LabelMaxLength, when positive, shortens the labels of the nodes of the
+	// dot output, or every line of them when wrapped, to that many characters.
+	LabelMaxLength int
+	// WrapLabels splits the labels of the nodes of the dot output over
+	// several lines after their kind and namespace.
+	WrapLabels bool
+	// MaxWidth, when positive, shortens the labels of the nodes of the ascii
+	// output so that its lines fit in that many columns.
+	MaxWidth int
+	// MaxNodes, when positive, truncates the chains made of more nodes than
+	// that to the nodes closest to their roots.
+	MaxNodes int
+	// FlagCrossNamespace marks the dependencies between nodes of different
+	// namespaces in the outputs, which otherwise only report them in the
+	// CrossNamespace of the descriptions.
+	FlagCrossNamespace bool
+	// MaxDepth, when positive, leaves out the nodes more than that many
+	// dependencies away from the roots, marking the nodes whose dependencies
+	// were left out.
+	MaxDepth int
+	// IncludeManual includes the build configurations whose input image
+	// doesn't trigger them, marking those dependencies as manual since the
+	// configurations must be rebuilt by hand when the image changes.
+	IncludeManual bool
+	// Workers is the number of chains, or partitions of a merged chain,
+	// computed concurrently. It defaults to GOMAXPROCS.
+	Workers int
+	// Concurrency, when positive, is the number of namespaces loaded at
+	// once. They are all loaded at once otherwise, which may overwhelm the
+	// API server when there are hundreds of them.
+	Concurrency int
+	// Strict fails loading the build configurations that refer to image
+	// stream tags without a tag, instead of assuming latest with a warning.
+	Strict bool
+	// FailFast fails loading the graph when a namespace can't be loaded,
+	// instead of leaving it out of the chains with a warning. Transient
+	// errors are retried either way.
+	FailFast bool
+	// At, when set, leaves out the build configurations, and the builds
+	// counted by ActivitySince, created after that time. The configurations
+	// are described as they are now, their past specs aren't recorded.
+	At *time.Time
+	// EnvironmentLabel, when set along with DeploymentConfigs, annotates the
+	// image stream tags with the values of this label on the running
+	// deployment configs they trigger.
+	EnvironmentLabel  string
+	DeploymentConfigs DeploymentConfigLister
+	// IncludeDeployments, along with DeploymentConfigs, adds the deployment
+	// configs whose image change triggers follow the image stream tags of the
+	// chain, so that the chain shows everything redeployed when they change.
+	IncludeDeployments bool
+	// CriticalPath, when set to CriticalPathByDuration or CriticalPathByHops,
+	// describes the longest dependency path of the chain, weighted by the
+	// mean duration of the completed builds of its build configurations or
+	// by their number, instead of the chain itself.
+	CriticalPath string
+	// Simulate describes the builds a change of the roots would trigger,
+	// in the order they would run, instead of the chain itself.
+	Simulate bool
+	// ColorByStatus colors the build configurations of the dot output, and
+	// the dependencies through them, by the phase of their latest build:
+	// green when it completed, red when it failed and yellow while it runs.
+	ColorByStatus bool
+	// OnlyFailing leaves out the branches of the chain without a build
+	// configuration whose latest build failed, keeping the nodes leading
+	// from the roots to those configurations and the nodes depending on them.
+	OnlyFailing bool
+	// OwnedNamespaces, when set, leaves out the nodes of the chain outside of
+	// those namespaces, unless they lead from the roots to nodes inside.
+	OwnedNamespaces sets.String
+	// ImageStreams, when set, resolves the outputs pushed by reference to
+	// the repository of an image stream of the chain namespaces, in the
+	// integrated registry, back to its image stream tag. Image streams are
+	// only listed in the namespaces pushed to by reference.
+	ImageStreams ImageStreamLister
+	// EdgeProviders add more kinds of relationships to the graph, after the
+	// inputs, triggers and outputs of the build configurations.
+	EdgeProviders []osgraph.EdgeProvider
+	// Provenance, when set, is recorded in the json output along with the
+	// hash of its content.
+	Provenance *ChainProvenance
+	// Enricher, when set, attaches metadata to the nodes of the json and
+	// ndjson outputs.
+	Enricher NodeEnricher
+	// IncludeExternal adds the images of external registries the build
+	// configurations of the chains are built from, as terminal nodes, so
+	// that the chains show their true upstream base images.
+	IncludeExternal bool
+	// Scanner, when set, annotates the image stream tags of the chains with
+	// the known vulnerabilities of their images, which are reported in the
+	// Vulnerable of the descriptions.
+	Scanner VulnerabilityScanner
+	// Inspector, when set, annotates the image stream tags of the chains with
+	// the digest, creation time and size of their current image, to spot the
+	// images that weren't rebuilt since their base images changed.
+	Inspector ImageInspector
+	// HideTags describes the chains at the repository level, merging the
+	// image stream tags of every image stream, and the dependencies going
+	// through them, into a node of the image stream.
+	HideTags bool
+
+	activity     map[osgraph.UniqueName]int
+	durations    map[osgraph.UniqueName]time.Duration
+	phases       map[osgraph.UniqueName]buildv1.BuildPhase
+	environments map[osgraph.UniqueName]sets.String
+	loaded       *osgraph.Graph
+	truncated    int
+	crossings    []CrossNamespaceEdge
+	vulnerable   []VulnerableImage
+	warnings     []ChainWarning
+
+	// scanned caches the vulnerabilities found by Scanner, shared by the
+	// chains described concurrently.
+	scanned     map[osgraph.UniqueName]*VulnerabilityCounts
+	scannedLock sync.Mutex
+	// inspected caches the images found by Inspector, shared by the chains
+	// described concurrently.
+	inspected     map[osgraph.UniqueName]*ImageDetails
+	inspectedLock sync.Mutex
 }
 
-// NewChainDescriber returns a new ChainDescriber
-func NewChainDescriber(c buildv1client.BuildConfigsGetter, namespaces sets.String, out string) *ChainDescriber {
-	return &ChainDescriber{c: c, namespaces: namespaces, outputFormat: out, namer: namespacedFormatter{hideNamespace: true}}
+// NewChainDescriber returns a new ChainDescriber reading the build
+// configurations of the chain from lister
+func NewChainDescriber(lister BuildConfigLister, namespaces sets.String, out string) *ChainDescriber {
+	return &ChainDescriber{lister: lister, namespaces: namespaces, outputFormat: out, namer: namespacedFormatter{hideNamespace: true}}
 }
 
 // MakeGraph will create the graph of all build configurations and the image streams
@@ -54,148 +199,722 @@ func NewChainDescriber(c buildv1client.BuildConfigsGetter, namespaces sets.Strin
 func (d *ChainDescriber) MakeGraph() (osgraph.Graph, error) {
 	g := osgraph.New()
 
-	loaders := []GraphLoader{}
-	for namespace := range d.namespaces {
+	loaders := []*chainLoader{}
+	for _, namespace := range d.namespaces.List() {
 		klog.V(4).Infof("Loading build configurations from %q", namespace)
-		loaders = append(loaders, &bcLoader{namespace: namespace, lister: d.c})
+		loader := &chainLoader{namespace: namespace, lister: d.lister, withBuilds: d.loadsBuilds(), strict: d.Strict, at: d.At}
+		if len(d.EnvironmentLabel) > 0 || d.IncludeDeployments {
+			loader.deploymentConfigs = d.DeploymentConfigs
+		}
+		loaders = append(loaders, loader)
 	}
 	loadingFuncs := []func() error{}
 	for _, loader := range loaders {
-		loadingFuncs = append(loadingFuncs, loader.Load)
+		loader := loader
+		loadingFuncs = append(loadingFuncs, func() error {
+			loader.err = loader.Load()
+			return loader.err
+		})
 	}
 
-	if errs := parallel.Run(loadingFuncs...); len(errs) > 0 {
-		return g, utilerrors.NewAggregate(errs)
+	d.warnings = nil
+	if errs := parallel.RunLimited(d.Concurrency, loadingFuncs...); len(errs) > 0 {
+		if d.FailFast || len(errs) == len(loaders) {
+			return g, utilerrors.NewAggregate(errs)
+		}
+		loaded := []*chainLoader{}
+		for _, loader := range loaders {
+			if loader.err != nil {
+				code := WarningNamespaceUnavailable
+				if kerrors.IsForbidden(loader.err) {
+					code = WarningPermissionDeniedNamespace
+				}
+				d.warnings = append(d.warnings, ChainWarning{Code: code, Namespace: loader.namespace, Message: fmt.Sprintf("unable to load namespace %q, it is left out of the chain: %v", loader.namespace, loader.err)})
+				continue
+			}
+			loaded = append(loaded, loader)
+		}
+		loaders = loaded
 	}
 
+	if d.ImageStreams != nil {
+		loadImageStreams(d.ImageStreams, loaders)
+	}
+	resolveRegistryOutputs(loaders)
+	d.warnings = append(d.warnings, missingOutputStreams(loaders)...)
+	d.environments = nil
 	for _, loader := range loaders {
 		loader.AddToGraph(g)
+		d.warnings = append(d.warnings, loader.warnings...)
+		if len(d.EnvironmentLabel) > 0 && loader.deploymentConfigs != nil {
+			d.environments = addEnvironments(d.environments, loader.dcs, d.EnvironmentLabel)
+		}
+	}
+	if d.IncludeDeployments {
+		for _, loader := range loaders {
+			addDeploymentConfigs(g, loader.dcs)
+		}
 	}
 
-	buildedges.AddAllInputOutputEdges(g)
+	osgraph.AddEdges(g, d.edgeProviders()...)
+	if d.ActivitySince != nil {
+		d.activity = countBuildActivity(g, *d.ActivitySince)
+	}
+	if d.CriticalPath == CriticalPathByDuration {
+		d.durations = estimateBuildDurations(g)
+	}
+	d.phases = nil
+	if d.ColorByStatus || d.OnlyFailing {
+		d.phases = latestBuildPhases(g)
+	}
 
 	return g, nil
 }
 
+// edgeProviders returns the providers of the edges of the graph according to
+// the options of the describer.
+func (d *ChainDescriber) edgeProviders() []osgraph.EdgeProvider {
+	providers := []osgraph.EdgeProvider{buildedges.InputOutputProvider{}}
+	if d.loadsBuilds() {
+		providers = append(providers, buildedges.BuildProvider{})
+	}
+	return append(providers, d.EdgeProviders...)
+}
+
+// loadsBuilds returns whether the options of the describer need the builds of
+// the build configurations.
+func (d *ChainDescriber) loadsBuilds() bool {
+	return d.ActivitySince != nil || d.CriticalPath == CriticalPathByDuration || d.ColorByStatus || d.OnlyFailing
+}
+
+// countBuildActivity returns the number of builds created since the provided
+// time for every build configuration found in the graph.
+func countBuildActivity(g osgraph.Graph, since time.Time) map[osgraph.UniqueName]int {
+	activity := map[osgraph.UniqueName]int{}
+	for _, node := range g.NodesByKind(buildgraph.BuildConfigNodeKind) {
+		bcNode := node.(*buildgraph.BuildConfigNode)
+		count := 0
+		for _, successor := range g.SuccessorNodesByEdgeKind(bcNode, buildedges.BuildEdgeKind) {
+			if build, ok := successor.(*buildgraph.BuildNode); ok && !build.Build.CreationTimestamp.Time.Before(since) {
+				count++
+			}
+		}
+		activity[bcNode.UniqueName()] = count
+	}
+	return activity
+}
+
+// latestBuildPhases returns the phase of the latest build of every build
+// configuration of the graph that ran any.
+func latestBuildPhases(g osgraph.Graph) map[osgraph.UniqueName]buildv1.BuildPhase {
+	phases := map[osgraph.UniqueName]buildv1.BuildPhase{}
+	for _, node := range g.NodesByKind(buildgraph.BuildConfigNodeKind) {
+		bcNode := node.(*buildgraph.BuildConfigNode)
+		var latest *buildv1.Build
+		for _, successor := range g.SuccessorNodesByEdgeKind(bcNode, buildedges.BuildEdgeKind) {
+			build, ok := successor.(*buildgraph.BuildNode)
+			if !ok {
+				continue
+			}
+			if latest == nil || latest.CreationTimestamp.Before(&build.Build.CreationTimestamp) ||
+				(latest.CreationTimestamp.Equal(&build.Build.CreationTimestamp) && latest.Name < build.Build.Name) {
+				latest = build.Build
+			}
+		}
+		if latest != nil {
+			phases[bcNode.UniqueName()] = latest.Status.Phase
+		}
+	}
+	return phases
+}
+
+// failedPhase returns whether a build in phase failed.
+func failedPhase(phase buildv1.BuildPhase) bool {
+	return phase == buildv1.BuildPhaseFailed || phase == buildv1.BuildPhaseError
+}
+
+// phaseColor returns the DOT color of a build configuration whose latest
+// build is in phase, or an empty string for the phases left uncolored.
+func phaseColor(phase buildv1.BuildPhase) string {
+	switch phase {
+	case buildv1.BuildPhaseComplete:
+		return "green"
+	case buildv1.BuildPhaseFailed, buildv1.BuildPhaseError:
+		return "red"
+	case buildv1.BuildPhaseNew, buildv1.BuildPhasePending, buildv1.BuildPhaseRunning:
+		return "yellow"
+	}
+	return ""
+}
+
+// statusColor returns the DOT color of node by the phase of its latest build,
+// or an empty string when it isn't a build configuration or isn't colored.
+func (d *ChainDescriber) statusColor(node graph.Node) string {
+	bcNode, ok := node.(*buildgraph.BuildConfigNode)
+	if !ok || d.phases == nil {
+		return ""
+	}
+	phase, ok := d.phases[bcNode.UniqueName()]
+	if !ok {
+		return ""
+	}
+	return phaseColor(phase)
+}
+
 // Describe returns the output of the graph starting from the provided
 // image stream tag (name:tag) in namespace. Namespace is needed here
 // because image stream tags with the same name can be found across
 // different namespaces.
 func (d *ChainDescriber) Describe(ist *imagev1.ImageStreamTag, includeInputImages, reverse bool) (string, error) {
-	g, err := d.MakeGraph()
+	return d.DescribeMerged([]*imagev1.ImageStreamTag{ist}, includeInputImages, reverse)
+}
+
+// DescribeMerged returns the output of the union of the graphs starting from
+// each of the provided image stream tags. Image stream tags that can't be
+// found in the graph don't have any dependency and are skipped, NotFoundErr
+// is only returned when none of them can be found. The graph is loaded once
+// and reused by subsequent calls.
+func (d *ChainDescriber) DescribeMerged(ists []*imagev1.ImageStreamTag, includeInputImages, reverse bool) (string, error) {
+	g, err := d.load()
 	if err != nil {
 		return "", err
 	}
+	desc := d.describe(g, ists, includeInputImages, reverse)
+	d.truncated = desc.Truncated
+	d.crossings = desc.CrossNamespace
+	d.vulnerable = desc.Vulnerable
+	return desc.Output, desc.Err
+}
+
+// ChainDescription is the description of the build chain of an image stream tag.
+type ChainDescription struct {
+	Output string
+	// Truncated is the number of nodes left out of the chain because of MaxNodes.
+	Truncated int
+	// CrossNamespace are the dependencies of the chain between nodes of
+	// different namespaces.
+	CrossNamespace []CrossNamespaceEdge
+	// Vulnerable are the image stream tags of the chain with known
+	// vulnerabilities, most critical first, when the describer has a Scanner.
+	Vulnerable []VulnerableImage
+	Err        error
+}
+
+// DescribeEach describes the build chain of every image stream tag on its own,
+// as Describe does, computing up to Workers chains concurrently. The
+// descriptions are returned in the order of ists.
+func (d *ChainDescriber) DescribeEach(ists []*imagev1.ImageStreamTag, includeInputImages, reverse bool) ([]ChainDescription, error) {
+	g, err := d.load()
+	if err != nil {
+		return nil, err
+	}
+	descs := make([]ChainDescription, len(ists))
+	d.forEach(len(ists), func(i int) {
+		descs[i] = d.describe(g, ists[i:i+1], includeInputImages, reverse)
+	})
+	return descs, nil
+}
+
+// forEach calls fn with every index up to n, from up to Workers goroutines.
+func (d *ChainDescriber) forEach(n int, fn func(i int)) {
+	workers := d.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
 
-	// Retrieve the imageStreamTag node of interest
-	istNode := g.Find(imagegraph.ImageStreamTagNodeName(ist))
-	if istNode == nil {
-		return "", NotFoundErr(fmt.Sprintf("%q", ist.Name))
+// load returns the graph of the build configurations, making it on first use.
+func (d *ChainDescriber) load() (osgraph.Graph, error) {
+	if d.loaded == nil {
+		g, err := d.MakeGraph()
+		if err != nil {
+			return g, err
+		}
+		d.loaded = &g
 	}
+	return *d.loaded, nil
+}
 
-	markers := buildanalysis.FindCircularBuilds(g, d.namer)
-	if len(markers) > 0 {
+// describe returns the description of the union of the chains of ists in g.
+// It only reads the describer, but for the cache of its scans, so that chains
+// can be described concurrently.
+func (d *ChainDescriber) describe(g osgraph.Graph, ists []*imagev1.ImageStreamTag, includeInputImages, reverse bool) ChainDescription {
+	anon := anonymizer{enabled: d.Anonymize}
+	namer := d.namer
+	if d.Anonymize {
+		namer = anon
+	}
+
+	// Retrieve the imageStreamTag nodes of interest
+	roots := []graph.Node{}
+	names := []string{}
+	missing := []string{}
+	for _, ist := range ists {
+		istNode := g.Find(imagegraph.ImageStreamTagNodeName(ist))
+		if istNode == nil {
+			missing = append(missing, fmt.Sprintf("%q", ist.Name))
+			continue
+		}
+		roots = append(roots, istNode)
+		names = append(names, anon.imageStreamTagName(ist.Name))
+	}
+	if len(roots) == 0 {
+		return ChainDescription{Err: NotFoundErr(strings.Join(missing, ", "))}
+	}
+	if len(missing) > 0 {
+		klog.V(2).Infof("Skipping image stream tags without dependencies: %s", strings.Join(missing, ", "))
+	}
+	name := strings.Join(names, ", ")
+
+	// the other formats render cycles along with the rest of the chain
+	markers := buildanalysis.FindCircularBuilds(g, namer)
+	if len(markers) > 0 && (len(d.outputFormat) == 0 || len(d.GroupByLabel) > 0) {
 		for _, marker := range markers {
-			if strings.Contains(marker.Message, ist.Name) {
-				return marker.Message, nil
+			for _, n := range names {
+				if strings.Contains(marker.Message, n) {
+					return ChainDescription{Output: marker.Message}
+				}
 			}
 		}
 	}
 
 	buildInputEdgeKinds := []string{buildedges.BuildTriggerImageEdgeKind}
-	if includeInputImages {
+	if includeInputImages || d.IncludeManual {
 		buildInputEdgeKinds = append(buildInputEdgeKinds, buildedges.BuildInputImageEdgeKind)
 	}
 
-	// Partition down to the subgraph containing the imagestreamtag of interest
-	var partitioned osgraph.Graph
-	if reverse {
-		partitioned = partitionReverse(g, istNode, buildInputEdgeKinds)
-	} else {
-		partitioned = partition(g, istNode, buildInputEdgeKinds)
+	// Partition down to the subgraph containing the imagestreamtags of interest
+	partitioned := d.partitionAll(g, roots, buildInputEdgeKinds, reverse)
+	if d.OwnedNamespaces != nil {
+		partitioned = ownedSubgraph(partitioned, roots, d.OwnedNamespaces, reverse)
+	}
+	if d.OnlyFailing {
+		partitioned = d.failingSubgraph(partitioned, roots, reverse)
+	}
+	var cut map[int]bool
+	if d.MaxDepth > 0 {
+		partitioned, cut = limitDepth(partitioned, roots, d.MaxDepth, reverse)
+	}
+	truncated := 0
+	if total := len(partitioned.Nodes()); d.MaxNodes > 0 && total > d.MaxNodes {
+		partitioned = truncate(partitioned, roots, d.MaxNodes)
+		truncated = total - d.MaxNodes
+		klog.V(2).Infof("Truncated the build chain of %s to %d of its %d nodes", name, d.MaxNodes, total)
+	}
+	if d.IncludeExternal {
+		partitioned = addExternalImages(g, partitioned, cut, reverse)
+	}
+	if d.HideTags {
+		partitioned, roots = hideTags(partitioned, roots)
+	}
+	vulnerable, err := d.scanVulnerabilities(partitioned, anon)
+	if err != nil {
+		return ChainDescription{Err: err}
+	}
+	if err := d.inspectImages(partitioned); err != nil {
+		return ChainDescription{Err: err}
+	}
+	output, err := d.output(partitioned, roots, name, anon, namer, cut, reverse)
+	return ChainDescription{Output: output, Truncated: truncated, CrossNamespace: crossNamespaceEdges(partitioned, anon), Vulnerable: vulnerable, Err: err}
+}
+
+// output returns the partitioned graph in the requested format, marking the
+// nodes of cut whose dependencies were left out.
+func (d *ChainDescriber) output(partitioned osgraph.Graph, roots []graph.Node, name string, anon anonymizer, namer osgraph.Namer, cut map[int]bool, reverse bool) (string, error) {
+	if len(d.GroupByLabel) > 0 {
+		return d.describeGroups(chainGroups(partitioned, d.GroupByLabel, anon), name, anon)
+	}
+	if len(d.CriticalPath) > 0 {
+		return d.describeCriticalPath(d.criticalPath(partitioned, anon), name, namer, anon)
+	}
+	if d.Simulate {
+		return d.describeSimulation(d.simulate(partitioned, roots, anon), name, namer, anon)
 	}
 
 	switch strings.ToLower(d.outputFormat) {
 	case "dot":
-		data, err := dot.Marshal(partitioned, dotutil.Quote(ist.Name), "", "  ", false)
+		var dotGraph graph.Graph = partitioned
+		cycles := cycleEdges(partitioned)
+		if d.activity != nil || d.SplitByTag || d.IncludeManual || d.relabelsDotNodes() || len(d.LinkBase) > 0 || d.environments != nil || d.Scanner != nil || d.Inspector != nil || hasCustomBuilds(partitioned) || len(cycles) > 0 || len(cut) > 0 || d.FlagCrossNamespace || d.phases != nil {
+			dotGraph = &attributedGraph{
+				Graph:          partitioned,
+				nodeAttributes: d.dotNodeAttributes(anon, cut, hasCustomBuilds(partitioned)),
+				edgeAttributes: d.dotEdgeAttributes(partitioned, cycles),
+			}
+		}
+		data, err := dot.Marshal(dotGraph, dotutil.Quote(name), "", "  ", false)
 		if err != nil {
 			return "", err
 		}
 		return string(data), nil
+	case "json":
+		out := chainOutput(partitioned, roots, anon, func(node graph.Node) []string { return d.environmentsOf(node, anon) }, cut, d.FlagCrossNamespace, d.IncludeExternal)
+		d.addVulnerabilities(out, partitioned, anon)
+		d.addImageDetails(out, partitioned, anon)
+		if warnings := d.chainWarnings(partitioned, namer, anon); len(warnings) > 0 {
+			out.Warnings = warnings
+		}
+		if err := d.enrich(out); err != nil {
+			return "", err
+		}
+		provenance, err := d.provenance(out, anon)
+		if err != nil {
+			return "", err
+		}
+		out.Provenance = provenance
+		return out.marshal()
+	case "ndjson":
+		out := chainOutput(partitioned, roots, anon, func(node graph.Node) []string { return d.environmentsOf(node, anon) }, cut, d.FlagCrossNamespace, d.IncludeExternal)
+		d.addVulnerabilities(out, partitioned, anon)
+		d.addImageDetails(out, partitioned, anon)
+		if err := d.enrich(out); err != nil {
+			return "", err
+		}
+		return out.ndjson()
+	case "graphml":
+		return chainOutput(partitioned, roots, anon, func(node graph.Node) []string { return d.environmentsOf(node, anon) }, cut, d.FlagCrossNamespace, d.IncludeExternal).graphML(name)
+	case "ascii":
+		return strings.Join(append([]string{d.asciiOutput(partitioned, namer, anon, cut, reverse)}, cycleMessages(partitioned, namer)...), "\n"), nil
+	case "mermaid":
+		return d.mermaidOutput(partitioned, namer, anon, cut), nil
+	case "list":
+		return listOutput(partitioned, anon), nil
+	case "summary":
+		return d.summaryOutput(partitioned, roots, name, namer, anon, reverse), nil
+	case "spdx":
+		created := time.Now()
+		if d.At != nil {
+			created = *d.At
+		}
+		return spdxOutput(partitioned, roots, name, anon, created, d.IncludeExternal)
+	case "template":
+		return templateOutput(partitioned, name)
 	case "":
-		return d.humanReadableOutput(partitioned, d.namer, istNode, reverse), nil
+		trees := []string{}
+		for _, root := range roots {
+			trees = append(trees, d.humanReadableOutput(partitioned, namer, root, cut, reverse))
+		}
+		if messages := cycleMessages(partitioned, namer); len(messages) > 0 {
+			trees = append(trees, strings.Join(messages, "\n"))
+		}
+		return strings.Join(trees, "\n\n"), nil
 	}
 
 	return "", fmt.Errorf("unknown specified format %q", d.outputFormat)
 }
 
+// describeGroups returns the output of the dependencies between groups of
+// build configurations in the requested format.
+func (d *ChainDescriber) describeGroups(groups *ChainGroups, name string, anon anonymizer) (string, error) {
+	switch strings.ToLower(d.outputFormat) {
+	case "dot":
+		return groups.dotGraph(name)
+	case "json":
+		provenance, err := d.provenance(groups, anon)
+		if err != nil {
+			return "", err
+		}
+		groups.Provenance = provenance
+		return groups.marshal()
+	case "":
+		return groups.humanReadable(), nil
+	}
+	return "", fmt.Errorf("unknown specified format %q", d.outputFormat)
+}
+
+// partitionAll returns the union of the partitions of the graph starting from
+// each of the given roots, computed concurrently.
+func (d *ChainDescriber) partitionAll(g osgraph.Graph, roots []graph.Node, buildInputEdgeKinds []string, reverse bool) osgraph.Graph {
+	partitionFn := partition
+	if reverse {
+		partitionFn = partitionReverse
+	}
+	if len(roots) == 1 {
+		return partitionFn(g, roots[0], buildInputEdgeKinds)
+	}
+
+	partitions := make([]osgraph.Graph, len(roots))
+	d.forEach(len(roots), func(i int) {
+		partitions[i] = partitionFn(g, roots[i], buildInputEdgeKinds)
+	})
+	desired := []graph.Node{}
+	seen := map[int]bool{}
+	for _, partition := range partitions {
+		for _, node := range partition.Nodes() {
+			if !seen[node.ID()] {
+				seen[node.ID()] = true
+				desired = append(desired, node)
+			}
+		}
+	}
+	nodeFn := osgraph.NodesOfKind(chainNodeKinds...)
+	edgeFn := osgraph.EdgesOfKind(append([]string{buildedges.BuildOutputEdgeKind, appsedges.TriggersDeploymentEdgeKind}, buildInputEdgeKinds...)...)
+	return g.Subgraph(nodeFn, edgeFn).SubgraphWithNodes(desired, osgraph.ExistingDirectEdge)
+}
+
+// Truncated returns the number of nodes left out of the last described chain
+// because of MaxNodes.
+func (d *ChainDescriber) Truncated() int {
+	return d.truncated
+}
+
+// CrossNamespace returns the dependencies between nodes of different
+// namespaces of the last described chain.
+func (d *ChainDescriber) CrossNamespace() []CrossNamespaceEdge {
+	return d.crossings
+}
+
+// Vulnerable returns the image stream tags with known vulnerabilities of the
+// last described chain.
+func (d *ChainDescriber) Vulnerable() []VulnerableImage {
+	return d.vulnerable
+}
+
+// Warnings returns the problems found in the build configurations of the
+// chains described so far.
+func (d *ChainDescriber) Warnings() []ChainWarning {
+	return d.warnings
+}
+
+// truncate returns the subgraph made of the max nodes of g closest to the
+// given roots, regardless of the direction of the edges.
+func truncate(g osgraph.Graph, roots []graph.Node, max int) osgraph.Graph {
+	kept := []graph.Node{}
+	seen := map[int]bool{}
+	queue := []graph.Node{}
+	for _, root := range roots {
+		if !seen[root.ID()] {
+			seen[root.ID()] = true
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 && len(kept) < max {
+		node := queue[0]
+		queue = queue[1:]
+		kept = append(kept, node)
+
+		neighbors := append(append([]graph.Node{}, g.From(node)...), g.To(node)...)
+		sort.Slice(neighbors, func(i, j int) bool { return neighbors[i].ID() < neighbors[j].ID() })
+		for _, neighbor := range neighbors {
+			if !seen[neighbor.ID()] {
+				seen[neighbor.ID()] = true
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	return g.SubgraphWithNodes(kept, osgraph.ExistingDirectEdge)
+}
+
+// truncatedSuffix marks the nodes whose dependencies were left out because
+// of MaxDepth in the human readable and ascii outputs.
+const truncatedSuffix = " (truncated)"
+
+// limitDepth returns the subgraph of g made of the nodes at most max edges
+// away from the roots, following the edges backwards when reverse is set, and
+// the nodes of that subgraph whose dependencies were left out.
+func limitDepth(g osgraph.Graph, roots []graph.Node, max int, reverse bool) (osgraph.Graph, map[int]bool) {
+	next := g.From
+	if reverse {
+		next = g.To
+	}
+	depth := map[int]int{}
+	queue := []graph.Node{}
+	for _, root := range roots {
+		if _, ok := depth[root.ID()]; !ok {
+			depth[root.ID()] = 0
+			queue = append(queue, root)
+		}
+	}
+	kept := []graph.Node{}
+	cut := map[int]bool{}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		kept = append(kept, node)
+		for _, n := range next(node) {
+			if _, ok := depth[n.ID()]; ok {
+				continue
+			}
+			if depth[node.ID()] == max {
+				cut[node.ID()] = true
+				continue
+			}
+			depth[n.ID()] = depth[node.ID()] + 1
+			queue = append(queue, n)
+		}
+	}
+	return g.SubgraphWithNodes(kept, osgraph.ExistingDirectEdge), cut
+}
+
+// ownedSubgraph returns the subgraph of g made of the roots, the nodes in
+// the owned namespaces and the nodes leading to them from the roots, so that
+// the owned nodes stay connected to the chain.
+func ownedSubgraph(g osgraph.Graph, roots []graph.Node, owned sets.String, reverse bool) osgraph.Graph {
+	away := g.From
+	if reverse {
+		away = g.To
+	}
+	leads := map[int]bool{}
+	visiting := map[int]bool{}
+	var leadsToOwned func(node graph.Node) bool
+	leadsToOwned = func(node graph.Node) bool {
+		if kept, ok := leads[node.ID()]; ok {
+			return kept
+		}
+		if visiting[node.ID()] {
+			return false
+		}
+		visiting[node.ID()] = true
+		kept := owned.Has(nodeNamespace(node))
+		for _, next := range away(node) {
+			// visit every node to record whether it leads to an owned one
+			if leadsToOwned(next) {
+				kept = true
+			}
+		}
+		leads[node.ID()] = kept
+		return kept
+	}
+
+	kept := []graph.Node{}
+	isRoot := map[int]bool{}
+	for _, root := range roots {
+		isRoot[root.ID()] = true
+	}
+	for _, node := range g.Nodes() {
+		if leadsToOwned(node) || isRoot[node.ID()] {
+			kept = append(kept, node)
+		}
+	}
+	return g.SubgraphWithNodes(kept, osgraph.ExistingDirectEdge)
+}
+
+// failingSubgraph returns the subgraph of g made of the roots, the build
+// configurations whose latest build failed, the nodes leading to them from
+// the roots and the nodes depending on them, following the edges backwards
+// when reverse is set.
+func (d *ChainDescriber) failingSubgraph(g osgraph.Graph, roots []graph.Node, reverse bool) osgraph.Graph {
+	away, toward := g.From, g.To
+	if reverse {
+		away, toward = g.To, g.From
+	}
+	kept := append([]graph.Node{}, roots...)
+	seen := map[int]bool{}
+	for _, root := range roots {
+		seen[root.ID()] = true
+	}
+	for _, node := range g.Nodes() {
+		bcNode, ok := node.(*buildgraph.BuildConfigNode)
+		if !ok || !failedPhase(d.phases[bcNode.UniqueName()]) {
+			continue
+		}
+		for _, next := range [][]graph.Node{reachable(node, toward), reachable(node, away)} {
+			for _, n := range next {
+				if !seen[n.ID()] {
+					seen[n.ID()] = true
+					kept = append(kept, n)
+				}
+			}
+		}
+	}
+	return g.SubgraphWithNodes(kept, osgraph.ExistingDirectEdge)
+}
+
+// nodeNamespace returns the namespace of a node of a chain.
+func nodeNamespace(node graph.Node) string {
+	switch t := node.(type) {
+	case *imagegraph.ImageStreamTagNode:
+		return t.Namespace
+	case *imagegraph.ImageStreamNode:
+		return t.Namespace
+	case *buildgraph.BuildConfigNode:
+		return t.BuildConfig.Namespace
+	case *appsgraph.DeploymentConfigNode:
+		return t.DeploymentConfig.Namespace
+	}
+	return ""
+}
+
+// chainNodeKinds are the kinds of the nodes a chain is made of. Deployment
+// configs are only in the graph with IncludeDeployments, images of external
+// registries are added to the chains with IncludeExternal.
+var chainNodeKinds = []string{buildgraph.BuildConfigNodeKind, imagegraph.ImageStreamTagNodeKind, appsgraph.DeploymentConfigNodeKind}
+
 // partition the graph down to a subgraph starting from the given root
 func partition(g osgraph.Graph, root graph.Node, buildInputEdgeKinds []string) osgraph.Graph {
-	// Filter out all but BuildConfig and ImageStreamTag nodes
-	nodeFn := osgraph.NodesOfKind(buildgraph.BuildConfigNodeKind, imagegraph.ImageStreamTagNodeKind)
-	// Filter out all but BuildInputImage and BuildOutput edges
+	// Filter out all but BuildConfig, ImageStreamTag and DeploymentConfig nodes
+	nodeFn := osgraph.NodesOfKind(chainNodeKinds...)
+	// Filter out all but BuildInputImage, BuildOutput and TriggersDeployment edges
 	edgeKinds := []string{}
 	edgeKinds = append(edgeKinds, buildInputEdgeKinds...)
-	edgeKinds = append(edgeKinds, buildedges.BuildOutputEdgeKind)
+	edgeKinds = append(edgeKinds, buildedges.BuildOutputEdgeKind, appsedges.TriggersDeploymentEdgeKind)
 	edgeFn := osgraph.EdgesOfKind(edgeKinds...)
 	sub := g.Subgraph(nodeFn, edgeFn)
 
-	// Filter out inbound edges to the IST of interest
-	edgeFn = osgraph.RemoveInboundEdges([]graph.Node{root})
-	sub = sub.Subgraph(nodeFn, edgeFn)
-
-	// Check all paths leading from the root node, collect any
-	// node found in them, and create the desired subgraph
-	desired := []graph.Node{root}
-	paths := path.DijkstraAllPaths(sub)
-	for _, node := range sub.Nodes() {
-		if node == root {
-			continue
-		}
-		path, _, _ := paths.Between(root, node)
-		if len(path) != 0 {
-			desired = append(desired, node)
-		}
-	}
-	return sub.SubgraphWithNodes(desired, osgraph.ExistingDirectEdge)
+	// Collect the nodes reachable from the root node and create the
+	// desired subgraph, keeping the edges back to the root that close cycles
+	return sub.SubgraphWithNodes(reachable(root, sub.From), osgraph.ExistingDirectEdge)
 }
 
 // partitionReverse the graph down to a subgraph starting from the given root
 func partitionReverse(g osgraph.Graph, root graph.Node, buildInputEdgeKinds []string) osgraph.Graph {
-	// Filter out all but BuildConfig and ImageStreamTag nodes
-	nodeFn := osgraph.NodesOfKind(buildgraph.BuildConfigNodeKind, imagegraph.ImageStreamTagNodeKind)
-	// Filter out all but BuildInputImage and BuildOutput edges
+	// Filter out all but BuildConfig, ImageStreamTag and DeploymentConfig nodes
+	nodeFn := osgraph.NodesOfKind(chainNodeKinds...)
+	// Filter out all but BuildInputImage, BuildOutput and TriggersDeployment edges
 	edgeKinds := []string{}
 	edgeKinds = append(edgeKinds, buildInputEdgeKinds...)
-	edgeKinds = append(edgeKinds, buildedges.BuildOutputEdgeKind)
+	edgeKinds = append(edgeKinds, buildedges.BuildOutputEdgeKind, appsedges.TriggersDeploymentEdgeKind)
 	edgeFn := osgraph.EdgesOfKind(edgeKinds...)
 	sub := g.Subgraph(nodeFn, edgeFn)
 
-	// Filter out inbound edges to the IST of interest
-	edgeFn = osgraph.RemoveOutboundEdges([]graph.Node{root})
-	sub = sub.Subgraph(nodeFn, edgeFn)
+	// Collect the nodes the root node is reachable from and create the
+	// desired subgraph, keeping the edges from the root that close cycles
+	return sub.SubgraphWithNodes(reachable(root, sub.To), osgraph.ExistingDirectEdge)
+}
 
-	// Check all paths leading from the root node, collect any
-	// node found in them, and create the desired subgraph
-	desired := []graph.Node{root}
-	paths := path.DijkstraAllPaths(sub)
-	for _, node := range sub.Nodes() {
-		if node == root {
-			continue
-		}
-		path, _, _ := paths.Between(node, root)
-		if len(path) != 0 {
-			desired = append(desired, node)
+// reachable returns root and the nodes reachable from it following next.
+// Edges lead from image stream tags to the build configurations they
+// trigger, and from there to their outputs, so a breadth-first search visits
+// every edge of the chain once instead of computing the paths between every
+// pair of nodes.
+func reachable(root graph.Node, next func(graph.Node) []graph.Node) []graph.Node {
+	nodes := []graph.Node{root}
+	seen := map[int]bool{root.ID(): true}
+	for i := 0; i < len(nodes); i++ {
+		for _, n := range next(nodes[i]) {
+			if !seen[n.ID()] {
+				seen[n.ID()] = true
+				nodes = append(nodes, n)
+			}
 		}
 	}
-	return sub.SubgraphWithNodes(desired, osgraph.ExistingDirectEdge)
+	return nodes
 }
 
 // humanReadableOutput traverses the provided graph using DFS and outputs it
 // in a human-readable format. It starts from the provided root, assuming it
 // is an imageStreamTag node and continues to the rest of the graph handling
 // only imageStreamTag and buildConfig nodes.
-func (d *ChainDescriber) humanReadableOutput(g osgraph.Graph, f osgraph.Namer, root graph.Node, reverse bool) string {
+func (d *ChainDescriber) humanReadableOutput(g osgraph.Graph, f osgraph.Namer, root graph.Node, cut map[int]bool, reverse bool) string {
 	if reverse {
 		g = g.EdgeSubgraph(osgraph.ReverseExistingDirectEdge)
 	}
@@ -207,11 +926,14 @@ func (d *ChainDescriber) humanReadableOutput(g osgraph.Graph, f osgraph.Namer, r
 	depth := map[graph.Node]int{
 		root: 0,
 	}
+	parent := map[graph.Node]graph.Node{}
+	anon := anonymizer{enabled: d.Anonymize}
 	out := ""
 
 	dfs := &DepthFirst{
 		Visit: func(u, v graph.Node) {
 			depth[v] = depth[u] + 1
+			parent[v] = u
 		},
 	}
 
@@ -220,11 +942,38 @@ func (d *ChainDescriber) humanReadableOutput(g osgraph.Graph, f osgraph.Namer, r
 
 		switch t := node.(type) {
 		case *imagegraph.ImageStreamTagNode:
-			info = outputHelper(f.ResourceName(t), t.Namespace, singleNamespace)
+			info = outputHelper(f.ResourceName(t), anon.namespace(t.Namespace), singleNamespace) + d.environmentsSuffix(t, anon) + d.vulnerabilitiesSuffix(t) + d.imageDetailsSuffix(t)
+		case *imagegraph.ImageStreamNode:
+			info = outputHelper(f.ResourceName(t), anon.namespace(t.Namespace), singleNamespace)
 		case *buildgraph.BuildConfigNode:
-			info = outputHelper(f.ResourceName(t), t.BuildConfig.Namespace, singleNamespace)
+			info = outputHelper(f.ResourceName(t), anon.namespace(t.BuildConfig.Namespace), singleNamespace)
+			if isCustomBuild(t) {
+				info += customSuffix
+			}
+			if d.activity != nil {
+				info += fmt.Sprintf(" (%d builds)", d.activity[t.UniqueName()])
+			}
+			if ist, ok := parent[node].(*imagegraph.ImageStreamTagNode); ok && d.SplitByTag {
+				info += fmt.Sprintf(" [tag: %s]", ist.ImageTag())
+			}
+			if d.IncludeExternal && !reverse {
+				info += externalSuffix(g, t, anon)
+			}
+		case *appsgraph.DeploymentConfigNode:
+			info = outputHelper(f.ResourceName(t), anon.namespace(t.DeploymentConfig.Namespace), singleNamespace)
+		case *imagegraph.DockerImageRepositoryNode:
+			info = externalLabel(t, anon)
 		default:
-			panic("this graph contains node kinds other than imageStreamTags and buildConfigs")
+			panic("this graph contains node kinds other than imageStreamTags, imageStreams, buildConfigs, deploymentConfigs and external images")
+		}
+		if p, ok := parent[node]; ok && d.manual(g, g.Edge(p, node)) {
+			info += " (manual)"
+		}
+		if cut[node.ID()] {
+			info += truncatedSuffix
+		}
+		if p, ok := parent[node]; ok && d.FlagCrossNamespace && crossesNamespaces(g.Edge(p, node)) {
+			info += " (cross-namespace)"
 		}
 
 		if depth[node] != 0 {
@@ -241,6 +990,274 @@ func (d *ChainDescriber) humanReadableOutput(g osgraph.Graph, f osgraph.Namer, r
 	return out
 }
 
+// dotNodeAttributes returns the extra DOT attributes of the nodes according to
+// the options of the describer, whether they were cut off and whether the
+// chain has customBuilds, or nil if there are none.
+func (d *ChainDescriber) dotNodeAttributes(anon anonymizer, cut map[int]bool, customBuilds bool) func(graph.Node) []dot.Attribute {
+	if !d.relabelsDotNodes() && len(d.LinkBase) == 0 && d.environments == nil && d.Scanner == nil && d.Inspector == nil && !customBuilds && len(cut) == 0 && d.phases == nil {
+		return nil
+	}
+	return func(node graph.Node) []dot.Attribute {
+		var attrs []dot.Attribute
+		if d.relabelsDotNodes() {
+			full := anon.nodeID(node)
+			label := formatLabel(full, d.LabelMaxLength, d.WrapLabels)
+			attrs = append(attrs, dot.Attribute{Key: "label", Value: fmt.Sprintf("%q", label)})
+			if label != full {
+				attrs = append(attrs, dot.Attribute{Key: "tooltip", Value: fmt.Sprintf("%q", full)})
+			}
+		}
+		if link := consoleLink(d.LinkBase, node); len(link) > 0 {
+			attrs = append(attrs, dot.Attribute{Key: "URL", Value: fmt.Sprintf("%q", link)})
+		}
+		xlabel := d.environmentsOf(node, anon)
+		if counts := d.vulnerabilitiesOf(node); counts != nil {
+			xlabel = append(xlabel, "vulnerabilities: "+counts.String())
+		}
+		if details := d.imageDetailsOf(node); details != nil {
+			xlabel = append(xlabel, "image: "+details.String())
+		}
+		if isCustomBuild(node) {
+			xlabel = append(xlabel, "custom")
+		}
+		if len(xlabel) > 0 {
+			attrs = append(attrs, dot.Attribute{Key: "xlabel", Value: fmt.Sprintf("%q", strings.Join(xlabel, ", "))})
+		}
+		if cut[node.ID()] {
+			attrs = append(attrs, dot.Attribute{Key: "style", Value: "dashed"})
+		}
+		if color := d.statusColor(node); len(color) > 0 {
+			attrs = append(attrs, dot.Attribute{Key: "color", Value: color})
+		}
+		return attrs
+	}
+}
+
+func (d *ChainDescriber) relabelsDotNodes() bool {
+	return d.Anonymize || d.LabelMaxLength > 0 || d.WrapLabels
+}
+
+// formatLabel wraps label after its kind and namespace separators when wrap
+// is set, and shortens it, or every line of it, to maxLength characters by
+// eliding its middle so that both the kind and the tag remain visible.
+func formatLabel(label string, maxLength int, wrap bool) string {
+	lines := []string{label}
+	if wrap {
+		lines = strings.SplitAfter(label, "|")
+		if last := len(lines) - 1; strings.Contains(lines[last], "/") {
+			lines = append(lines[:last], strings.SplitAfterN(lines[last], "/", 2)...)
+		}
+	}
+	for i, line := range lines {
+		lines[i] = shorten(line, maxLength)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func shorten(s string, maxLength int) string {
+	const ellipsis = "..."
+	runes := []rune(s)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return s
+	}
+	if maxLength <= len(ellipsis) {
+		return string(runes[:maxLength])
+	}
+	head := (maxLength - len(ellipsis) + 1) / 2
+	tail := maxLength - len(ellipsis) - head
+	return string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+}
+
+// consoleLink returns the URL of the web console page of the image stream or
+// build config of node, or an empty string if base is empty.
+func consoleLink(base string, node graph.Node) string {
+	if len(base) == 0 {
+		return ""
+	}
+	var namespace, resource, name string
+	switch t := node.(type) {
+	case *imagegraph.ImageStreamTagNode:
+		stream, _, _ := imageutil.SplitImageStreamTag(t.Name)
+		namespace, resource, name = t.Namespace, "imagestreams", stream
+	case *imagegraph.ImageStreamNode:
+		namespace, resource, name = t.Namespace, "imagestreams", t.Name
+	case *buildgraph.BuildConfigNode:
+		namespace, resource, name = t.BuildConfig.Namespace, "buildconfigs", t.BuildConfig.Name
+	case *appsgraph.DeploymentConfigNode:
+		namespace, resource, name = t.DeploymentConfig.Namespace, "deploymentconfigs", t.DeploymentConfig.Name
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s/k8s/ns/%s/%s/%s", strings.TrimSuffix(base, "/"), url.PathEscape(namespace), resource, url.PathEscape(name))
+}
+
+// dotEdgeAttributes returns the extra DOT attributes of the edges of g
+// according to the options of the describer. Edges that are part of a cycle,
+// keyed as in cycles, are drawn in red.
+func (d *ChainDescriber) dotEdgeAttributes(g osgraph.Graph, cycles map[[2]int]bool) func(graph.Edge) []dot.Attribute {
+	var activity func(graph.Edge) []dot.Attribute
+	if d.activity != nil {
+		activity = d.activityEdgeAttributes(g)
+	}
+	return func(e graph.Edge) []dot.Attribute {
+		var attrs []dot.Attribute
+		if activity != nil {
+			attrs = append(attrs, activity(e)...)
+		}
+		if tag := edgeTag(e); d.SplitByTag && len(tag) > 0 {
+			kinds := strings.Join(g.EdgeKinds(e).List(), ",")
+			attrs = append(attrs, dot.Attribute{Key: "label", Value: fmt.Sprintf("%q", kinds+" ("+tag+")")})
+		}
+		if d.manual(g, e) {
+			attrs = mergeAttributes(attrs, []dot.Attribute{{Key: "style", Value: "dotted"}, {Key: "color", Value: "orange"}})
+			if !d.SplitByTag {
+				attrs = append(attrs, dot.Attribute{Key: "label", Value: `"manual"`})
+			}
+		}
+		if d.FlagCrossNamespace && crossesNamespaces(e) {
+			attrs = mergeAttributes(attrs, []dot.Attribute{{Key: "color", Value: "blue"}, {Key: "penwidth", Value: "2"}})
+		}
+		if color := d.edgeStatusColor(e); len(color) > 0 {
+			attrs = mergeAttributes(attrs, []dot.Attribute{{Key: "color", Value: color}})
+		}
+		if cycles[edgeKey(e)] {
+			attrs = mergeAttributes(attrs, []dot.Attribute{{Key: "color", Value: "red"}})
+		}
+		return attrs
+	}
+}
+
+// edgeStatusColor returns the DOT color of the build configuration e leads to
+// or from, by the phase of its latest build.
+func (d *ChainDescriber) edgeStatusColor(e graph.Edge) string {
+	if color := d.statusColor(e.From()); len(color) > 0 {
+		return color
+	}
+	return d.statusColor(e.To())
+}
+
+// manual returns whether e is a dependency on an input image that doesn't
+// trigger the build configuration, when IncludeManual is set.
+func (d *ChainDescriber) manual(g osgraph.Graph, e graph.Edge) bool {
+	if !d.IncludeManual || e == nil {
+		return false
+	}
+	kinds := g.EdgeKinds(e)
+	return kinds.Has(buildedges.BuildInputImageEdgeKind) && !kinds.Has(buildedges.BuildTriggerImageEdgeKind)
+}
+
+// activityEdgeAttributes returns a function that weights every edge touching a
+// build configuration by the number of builds that configuration produced.
+// Edges of configurations without any recent build are rendered as dormant.
+func (d *ChainDescriber) activityEdgeAttributes(g osgraph.Graph) func(graph.Edge) []dot.Attribute {
+	max := 0
+	for _, node := range g.NodesByKind(buildgraph.BuildConfigNodeKind) {
+		if count := d.activity[node.(*buildgraph.BuildConfigNode).UniqueName()]; count > max {
+			max = count
+		}
+	}
+
+	return func(e graph.Edge) []dot.Attribute {
+		bcNode, ok := e.From().(*buildgraph.BuildConfigNode)
+		if !ok {
+			if bcNode, ok = e.To().(*buildgraph.BuildConfigNode); !ok {
+				return nil
+			}
+		}
+		count := d.activity[bcNode.UniqueName()]
+		if count == 0 {
+			return []dot.Attribute{
+				{Key: "weight", Value: "1"},
+				{Key: "style", Value: "dashed"},
+				{Key: "color", Value: "gray"},
+			}
+		}
+		return []dot.Attribute{
+			{Key: "weight", Value: fmt.Sprintf("%d", count+1)},
+			{Key: "penwidth", Value: fmt.Sprintf("%.1f", 1+4*float64(count)/float64(max))},
+		}
+	}
+}
+
+// attributedGraph wraps a graph so that additional DOT attributes can be
+// attached to its nodes and edges when marshaling it.
+type attributedGraph struct {
+	osgraph.Graph
+
+	nodeAttributes func(graph.Node) []dot.Attribute
+	edgeAttributes func(graph.Edge) []dot.Attribute
+}
+
+func (g *attributedGraph) Nodes() []graph.Node {
+	nodes := g.Graph.Nodes()
+	if g.nodeAttributes == nil {
+		return nodes
+	}
+	attributed := make([]graph.Node, 0, len(nodes))
+	for _, n := range nodes {
+		attributed = append(attributed, attributedNode{Node: n, extra: g.nodeAttributes(n)})
+	}
+	return attributed
+}
+
+func (g *attributedGraph) Edge(u, v graph.Node) graph.Edge {
+	if n, ok := u.(attributedNode); ok {
+		u = n.Node
+	}
+	if n, ok := v.(attributedNode); ok {
+		v = n.Node
+	}
+	e := g.Graph.Edge(u, v)
+	if e == nil || g.edgeAttributes == nil {
+		return e
+	}
+	return attributedEdge{Edge: e, extra: g.edgeAttributes(e)}
+}
+
+type attributedNode struct {
+	graph.Node
+	extra []dot.Attribute
+}
+
+func (n attributedNode) DOTAttributes() []dot.Attribute {
+	return mergeAttributes(dotAttributes(n.Node), n.extra)
+}
+
+type attributedEdge struct {
+	graph.Edge
+	extra []dot.Attribute
+}
+
+func (e attributedEdge) DOTAttributes() []dot.Attribute {
+	return mergeAttributes(dotAttributes(e.Edge), e.extra)
+}
+
+func dotAttributes(obj interface{}) []dot.Attribute {
+	if a, ok := obj.(dot.Attributer); ok {
+		return a.DOTAttributes()
+	}
+	return nil
+}
+
+// mergeAttributes appends extra to attrs, extra attributes replacing the
+// attributes of attrs with the same key.
+func mergeAttributes(attrs, extra []dot.Attribute) []dot.Attribute {
+	merged := []dot.Attribute{}
+	for _, attr := range attrs {
+		overridden := false
+		for _, e := range extra {
+			if e.Key == attr.Key {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, attr)
+		}
+	}
+	return append(merged, extra...)
+}
+
 // outputHelper returns resource/name in a single namespace, <namespace resource/name>
 // in multiple namespaces
 func outputHelper(info, namespace string, singleNamespace bool) string {
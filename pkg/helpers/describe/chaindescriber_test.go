@@ -1,19 +1,38 @@
 package describe
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gonum/graph"
 	"github.com/gonum/graph/simple"
 
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clienttesting "k8s.io/client-go/testing"
 
+	appsv1 "github.com/openshift/api/apps/v1"
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	fakeappsclient "github.com/openshift/client-go/apps/clientset/versioned/fake"
 	fakebuildclient "github.com/openshift/client-go/build/clientset/versioned/fake"
 	buildclientscheme "github.com/openshift/client-go/build/clientset/versioned/scheme"
 	fakebuildv1client "github.com/openshift/client-go/build/clientset/versioned/typed/build/v1/fake"
+	fakeimageclient "github.com/openshift/client-go/image/clientset/versioned/fake"
 	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
 )
 
@@ -212,7 +231,7 @@ func TestChainDescriber(t *testing.T) {
 
 			fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
 
-			desc, err := NewChainDescriber(fakeClient, test.namespaces, test.output).Describe(ist, test.includeInputImg, test.reverse)
+			desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), test.namespaces, test.output).Describe(ist, test.includeInputImg, test.reverse)
 			t.Logf("%s: output:\n%s\n\n", test.testName, desc)
 			if err != test.expectedErr {
 				t.Fatalf("%s: error mismatch: expected %v, got %v", test.testName, test.expectedErr, err)
@@ -308,3 +327,2223 @@ func TestDepthFirst(t *testing.T) {
 		return false
 	})
 }
+
+func TestChainDescriberActivity(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	newBuild := func(name, bc string, created time.Time) *buildv1.Build {
+		return &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "test",
+				Labels:            map[string]string{buildv1.BuildConfigLabel: bc},
+				CreationTimestamp: metav1.NewTime(created),
+			},
+		}
+	}
+	objs = append(objs,
+		newBuild("ruby-hello-world-1", "ruby-hello-world", now.Add(-48*time.Hour)),
+		newBuild("ruby-hello-world-2", "ruby-hello-world", now.Add(-time.Hour)),
+		newBuild("ruby-hello-world-3", "ruby-hello-world", now.Add(-time.Minute)),
+		newBuild("ruby-sample-build-1", "ruby-sample-build", now.Add(-48*time.Hour)),
+	)
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+	since := now.Add(-24 * time.Hour)
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.ActivitySince = &since
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"\tbc/ruby-hello-world (2 builds)", "\tbc/ruby-sample-build (0 builds)"} {
+		if !strings.Contains(desc, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, desc)
+		}
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.ActivitySince = &since
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"weight=3", "penwidth=5.0", "style=dashed"} {
+		if !strings.Contains(desc, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, desc)
+		}
+	}
+}
+
+func TestChainDescriberJSON(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, desc)
+	}
+	if out.Root != "ImageStreamTag|test/ruby-25-centos7:latest" {
+		t.Errorf("unexpected root: %s", out.Root)
+	}
+	if len(out.Nodes) != 5 || len(out.Edges) != 4 {
+		t.Fatalf("expected 5 nodes and 4 edges, got:\n%s", desc)
+	}
+	expected := ChainEdge{
+		From:  "ImageStreamTag|test/ruby-25-centos7:latest",
+		To:    "BuildConfig|test/ruby-hello-world",
+		Kinds: []string{"BuildInputImage", "BuildTriggerImage"},
+		Tag:   "latest",
+
+		BuildConfig: &EdgeBuildConfig{
+			Strategy:   "Docker",
+			GitURI:     "https://github.com/openshift/ruby-hello-world",
+			OutputKind: "ImageStreamTag",
+			Output:     "test/ruby-hello-world:latest",
+		},
+	}
+	found := false
+	for _, e := range out.Edges {
+		if reflect.DeepEqual(e, expected) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected edge %#v in:\n%s", expected, desc)
+	}
+}
+
+func TestChainDescriberSplitByTag(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.SplitByTag = true
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, "\tbc/ruby-hello-world [tag: latest]") {
+		t.Errorf("expected the tag on the build config line:\n%s", desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.SplitByTag = true
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, `[label="BuildInputImage,BuildTriggerImage (latest)"]`) {
+		t.Errorf("expected tagged edge labels:\n%s", desc)
+	}
+}
+
+func TestChainDescriberGroupByLabel(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/multiple-trigger-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	teams := map[string]string{"parent1": "base", "parent2": "base", "child1": "apps", "child2": "apps", "child3": "apps"}
+	for _, obj := range objs {
+		if bc, ok := obj.(*buildv1.BuildConfig); ok && len(teams[bc.Name]) > 0 {
+			bc.Labels = map[string]string{"team": teams[bc.Name]}
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.GroupByLabel = "team"
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "<none>\n\tapps (2 build configs)\napps\nbase\n\tapps (3 build configs)"; desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.GroupByLabel = "team"
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainGroups{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, desc)
+	}
+	expected := &ChainGroups{
+		Label:        "team",
+		Groups:       []string{"<none>", "apps", "base"},
+		Dependencies: []GroupDependency{{From: "<none>", To: "apps", Count: 2}, {From: "base", To: "apps", Count: 3}},
+	}
+	if !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %#v, got %#v", expected, out)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.GroupByLabel = "team"
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`[label="base"]`, `[label=3]`} {
+		if !strings.Contains(desc, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, desc)
+		}
+	}
+}
+
+func TestChainDescriberAnonymize(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/multiple-namespaces-bcs.yaml", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("master", "ruby-25-centos7", "latest")
+	a := anonymizer{enabled: true}
+
+	for _, output := range []string{"", "dot", "json"} {
+		describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test", "master", "default"), output)
+		describer.Anonymize = true
+		desc, err := describer.Describe(ist, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, leaked := range []string{"ruby", "master", "test/", "another"} {
+			if strings.Contains(desc, leaked) {
+				t.Errorf("%q output leaks %q:\n%s", output, leaked, desc)
+			}
+		}
+		for _, expected := range []string{a.imageStreamTagName("ruby-25-centos7:latest"), a.namespace("another"), ":latest"} {
+			if !strings.Contains(desc, expected) {
+				t.Errorf("%q output: expected %q in:\n%s", output, expected, desc)
+			}
+		}
+		again, err := describer.Describe(ist, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if output != "dot" && again != desc {
+			t.Errorf("%q output is not stable across runs:\n%s\n%s", output, desc, again)
+		}
+	}
+}
+
+func TestChainDescriberMerged(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/multiple-trigger-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ists := []*imagev1.ImageStreamTag{
+		imagegraph.MakeImageStreamTagObjectMeta("test", "parent1img", "latest"),
+		imagegraph.MakeImageStreamTagObjectMeta("test", "parent3img", "latest"),
+		imagegraph.MakeImageStreamTagObjectMeta("test", "missing", "latest"),
+	}
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json").DescribeMerged(ists, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, desc)
+	}
+	expectedRoots := []string{"ImageStreamTag|test/parent1img:latest", "ImageStreamTag|test/parent3img:latest"}
+	if len(out.Root) != 0 || !reflect.DeepEqual(out.Roots, expectedRoots) {
+		t.Errorf("unexpected roots: %q, %v", out.Root, out.Roots)
+	}
+	ids := []string{}
+	for _, node := range out.Nodes {
+		ids = append(ids, node.ID)
+	}
+	for _, expected := range []string{"BuildConfig|test/child2", "BuildConfig|test/child3", "ImageStreamTag|test/child3img:latest"} {
+		if !strings.Contains(strings.Join(ids, ","), expected) {
+			t.Errorf("expected node %s in merged output:\n%s", expected, desc)
+		}
+	}
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	desc, err = describer.DescribeMerged(ists, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trees := strings.Split(desc, "\n\n"); len(trees) != 2 || !strings.HasPrefix(trees[1], "istag/parent3img:latest") {
+		t.Errorf("expected a tree per root:\n%s", desc)
+	}
+
+	if _, err := describer.DescribeMerged(ists[2:], false, false); err != NotFoundErr(`"missing:latest"`) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestChainDescriberLinkBase(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.LinkBase = "https://console.example.com/"
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`URL="https://console.example.com/k8s/ns/test/buildconfigs/ruby-hello-world"`,
+		`URL="https://console.example.com/k8s/ns/test/imagestreams/ruby-25-centos7"`,
+	} {
+		if !strings.Contains(desc, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, desc)
+		}
+	}
+}
+
+func TestFormatLabel(t *testing.T) {
+	tests := []struct {
+		maxLength int
+		wrap      bool
+		expected  string
+	}{
+		{expected: "ImageStreamTag|test/ruby-25-centos7:latest"},
+		{maxLength: 20, expected: "ImageStre...7:latest"},
+		{wrap: true, expected: "ImageStreamTag|\ntest/\nruby-25-centos7:latest"},
+		{maxLength: 10, wrap: true, expected: "Imag...ag|\ntest/\nruby...est"},
+	}
+	for _, test := range tests {
+		if got := formatLabel("ImageStreamTag|test/ruby-25-centos7:latest", test.maxLength, test.wrap); got != test.expected {
+			t.Errorf("%d, %t: expected %q, got %q", test.maxLength, test.wrap, test.expected, got)
+		}
+	}
+}
+
+func TestChainDescriberMaxNodes(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.MaxNodes = 3
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, desc)
+	}
+	if len(out.Nodes) != 3 || describer.Truncated() != 2 {
+		t.Fatalf("expected 3 nodes and 2 left out, got %d left out:\n%s", describer.Truncated(), desc)
+	}
+	for _, n := range out.Nodes {
+		if strings.HasPrefix(n.ID, "ImageStreamTag|") && n.ID != out.Root {
+			t.Errorf("expected only the root and the build configs it triggers to be kept, got %s", n.ID)
+		}
+	}
+
+	describer.MaxNodes = 5
+	if _, err := describer.Describe(ist, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if describer.Truncated() != 0 {
+		t.Errorf("expected a chain within the limit not to be truncated, got %d left out", describer.Truncated())
+	}
+}
+
+func TestChainDescriberMaxDepth(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.MaxDepth = 1
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, desc)
+	}
+	if len(out.Nodes) != 3 || len(out.Edges) != 2 {
+		t.Fatalf("expected the root and the build configs it triggers, got:\n%s", desc)
+	}
+	for _, n := range out.Nodes {
+		if n.Truncated != (n.Kind == "BuildConfig") {
+			t.Errorf("expected only the build configs to be truncated, got %#v", n)
+		}
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.MaxDepth = 1
+	if desc, err = describer.Describe(ist, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(desc, "style=dashed") != 2 {
+		t.Errorf("expected the truncated nodes to be dashed, got:\n%s", desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.MaxDepth = 2
+	if desc, err = describer.Describe(ist, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(desc, "\n") != 4 || strings.Contains(desc, truncatedSuffix) {
+		t.Errorf("expected a chain within the depth not to be truncated, got:\n%s", desc)
+	}
+}
+
+func TestChainDescriberDescribeEach(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ists := []*imagev1.ImageStreamTag{
+		imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest"),
+		imagegraph.MakeImageStreamTagObjectMeta("test", "missing", "latest"),
+		imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-hello-world", "latest"),
+		imagegraph.MakeImageStreamTagObjectMeta("test", "origin-ruby-sample", "latest"),
+	}
+
+	for _, output := range []string{"", "dot", "json"} {
+		describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), output)
+		describer.Workers = 2
+		descs, err := describer.DescribeEach(ists, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(descs) != len(ists) {
+			t.Fatalf("expected %d descriptions, got %d", len(ists), len(descs))
+		}
+		for i, ist := range ists {
+			expected, expectedErr := describer.Describe(ist, false, false)
+			if descs[i].Output != expected || !reflect.DeepEqual(descs[i].Err, expectedErr) {
+				t.Errorf("%s: expected the description of %s to be %q (%v), got %q (%v)", output, ist.Name, expected, expectedErr, descs[i].Output, descs[i].Err)
+			}
+		}
+		if _, ok := descs[1].Err.(NotFoundErr); !ok {
+			t.Errorf("%s: expected a missing image stream tag to be reported, got %v", output, descs[1].Err)
+		}
+	}
+}
+
+func TestChainDescriberIncludeManual(t *testing.T) {
+	newBuildConfig := func(name string, triggers ...buildv1.BuildTriggerPolicy) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: triggers,
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("triggered", buildv1.BuildTriggerPolicy{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}),
+		newBuildConfig("webhook", buildv1.BuildTriggerPolicy{Type: buildv1.GitHubWebHookBuildTriggerType}),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(desc, "bc/webhook") {
+		t.Errorf("expected configs that aren't triggered to be left out by default:\n%s", desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.IncludeManual = true
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"\tbc/triggered\n", "\tbc/webhook (manual)\n"} {
+		if !strings.Contains(desc+"\n", expected) {
+			t.Errorf("expected %q in output:\n%s", expected, desc)
+		}
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.IncludeManual = true
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(desc, `label="manual"`) != 1 || strings.Count(desc, "style=dotted") != 1 {
+		t.Errorf("expected a single manual edge in output:\n%s", desc)
+	}
+}
+
+func TestChainDescriberTaglessReferences(t *testing.T) {
+	bc := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base"},
+				}},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+		},
+	}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(bc.DeepCopy()).Fake)}
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, "bc/app") {
+		t.Errorf("expected the tagless reference to default to latest:\n%s", desc)
+	}
+	if warnings := describer.Warnings(); len(warnings) != 1 || warnings[0].Code != WarningMissingTag || !strings.Contains(warnings[0].Message, `"base:latest"`) {
+		t.Errorf("expected a warning about the tagless reference, got %v", warnings)
+	}
+
+	fakeClient = &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(bc.DeepCopy()).Fake)}
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.Strict = true
+	if _, err := describer.Describe(ist, false, false); err == nil || !strings.Contains(err.Error(), "without a tag") {
+		t.Errorf("expected strict mode to fail, got %v", err)
+	}
+}
+
+func TestChainDescriberASCII(t *testing.T) {
+	newBuildConfig := func(name, from string, extraFrom ...string) runtime.Object {
+		bc := &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+		for _, extra := range extraFrom {
+			bc.Spec.Triggers = append(bc.Spec.Triggers, buildv1.BuildTriggerPolicy{
+				Type:        buildv1.ImageChangeBuildTriggerType,
+				ImageChange: &buildv1.ImageChangeTrigger{From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: extra}},
+			})
+		}
+		return bc
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("frontend", "base:latest"),
+		newBuildConfig("backend", "base:latest"),
+		newBuildConfig("bundle", "backend:latest", "frontend:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "ascii")
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"* istag/base:latest",
+		"|-.",
+		"* | bc/backend",
+		"| * bc/frontend",
+		"* | istag/backend:latest",
+		"| * istag/frontend:latest",
+		"|-'",
+		"* bc/bundle",
+		"* istag/bundle:latest",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "ascii")
+	describer.MaxWidth = 12
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(desc, "\n") {
+		if len(line) > 12 {
+			t.Errorf("expected lines of at most 12 columns, got %q", line)
+		}
+	}
+}
+
+func TestChainDescriberMermaid(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "mermaid")
+	describer.MaxDepth = 1
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"flowchart TD",
+		`  n0["bc/ruby-hello-world"]`,
+		`  n1["bc/ruby-sample-build"]`,
+		`  n2("istag/ruby-25-centos7:latest")`,
+		"  n2 --> n0",
+		"  n2 --> n1",
+		"  classDef truncated stroke-dasharray:5 5",
+		"  class n0,n1 truncated",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+}
+
+func TestChainDescriberList(t *testing.T) {
+	newBuildConfig := func(name, from string, extraFrom ...string) runtime.Object {
+		bc := &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+		for _, extra := range extraFrom {
+			bc.Spec.Triggers = append(bc.Spec.Triggers, buildv1.BuildTriggerPolicy{
+				Type:        buildv1.ImageChangeBuildTriggerType,
+				ImageChange: &buildv1.ImageChangeTrigger{From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: extra}},
+			})
+		}
+		return bc
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("bundle", "frontend:latest", "backend:latest"),
+		newBuildConfig("frontend", "base:latest"),
+		newBuildConfig("backend", "base:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "list").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"test/base:latest",
+		"test/backend:latest",
+		"test/frontend:latest",
+		"test/bundle:latest",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+}
+
+func TestChainDescriberSummary(t *testing.T) {
+	newBuildConfig := func(name, from string) runtime.Object {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("frontend", "base:latest"),
+		newBuildConfig("backend", "base:latest"),
+		newBuildConfig("worker", "backend:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "summary").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"Summary of the build chain of base:latest:",
+		"  Nodes:          7 (4 image stream tags, 3 build configs)",
+		"  Edges:          6",
+		"  Maximum depth:  4",
+		"",
+		"Fan-out:",
+		"  istag/base:latest      2",
+		"  istag/backend:latest   1",
+		"  istag/frontend:latest  0",
+		"  istag/worker:latest    0",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+}
+
+func TestChainDescriberNDJSON(t *testing.T) {
+	newBuildConfig := func(name, from string) runtime.Object {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "ndjson").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(desc, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a line per node, got:\n%s", desc)
+	}
+	records := map[string]ChainRecord{}
+	for _, line := range lines {
+		record := ChainRecord{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		records[record.Node.ID] = record
+	}
+	root := records["ImageStreamTag|test/base:latest"]
+	if !root.Root || len(root.Edges) != 1 || root.Edges[0].To != "BuildConfig|test/app" {
+		t.Errorf("expected the root to lead to bc/app, got %#v", root)
+	}
+	app := records["BuildConfig|test/app"]
+	if app.Root || len(app.Edges) != 1 || app.Edges[0].To != "ImageStreamTag|test/app:latest" {
+		t.Errorf("expected bc/app to lead to istag/app:latest, got %#v", app)
+	}
+	if output := records["ImageStreamTag|test/app:latest"]; output.Edges == nil || len(output.Edges) > 0 {
+		t.Errorf("expected istag/app:latest without dependencies, got %#v", output)
+	}
+}
+
+func TestChainDescriberGraphML(t *testing.T) {
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(&buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+				}},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:1.0"}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+		},
+	}).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "graphml").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := graphMLDocument{}
+	if err := xml.Unmarshal([]byte(desc), &doc); err != nil {
+		t.Fatalf("invalid GraphML: %v\n%s", err, desc)
+	}
+	if doc.XMLName.Space != graphMLNamespace || doc.Graph.EdgeDefault != "directed" {
+		t.Errorf("expected a directed GraphML graph, got:\n%s", desc)
+	}
+	attributes := func(data graphMLAttributes) map[string]string {
+		values := map[string]string{}
+		for _, d := range data {
+			values[d.Key] = d.Value
+		}
+		return values
+	}
+	nodes := map[string]map[string]string{}
+	for _, node := range doc.Graph.Nodes {
+		nodes[node.ID] = attributes(node.Data)
+	}
+	expectedNodes := map[string]map[string]string{
+		"ImageStreamTag|test/base:latest": {"kind": "ImageStreamTag", "namespace": "test", "name": "base:latest", "tag": "latest", "root": "true"},
+		"BuildConfig|test/app":            {"kind": "BuildConfig", "namespace": "test", "name": "app"},
+		"ImageStreamTag|test/app:1.0":     {"kind": "ImageStreamTag", "namespace": "test", "name": "app:1.0", "tag": "1.0"},
+	}
+	if !reflect.DeepEqual(nodes, expectedNodes) {
+		t.Errorf("expected nodes %v, got %v", expectedNodes, nodes)
+	}
+	if len(doc.Graph.Edges) != 2 {
+		t.Fatalf("expected 2 edges, got:\n%s", desc)
+	}
+	for _, edge := range doc.Graph.Edges {
+		if bc := attributes(edge.Data)["buildConfig"]; bc != "app" {
+			t.Errorf("expected the edge from %s to %s to carry bc/app, got %q", edge.Source, edge.Target, bc)
+		}
+	}
+}
+
+func TestChainDescriberSPDX(t *testing.T) {
+	newBuildConfig := func(name, from string) runtime.Object {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest"),
+		newBuildConfig("bundle", "app:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "spdx")
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	describer.At = &at
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &spdxDocument{}
+	if err := json.Unmarshal([]byte(desc), doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2024-01-01T00:00:00Z" || !strings.HasPrefix(doc.DocumentNamespace, spdxNamespaceBase) {
+		t.Errorf("unexpected document header: %#v", doc)
+	}
+	packages := []string{}
+	for _, pkg := range doc.Packages {
+		packages = append(packages, pkg.SPDXID+" "+pkg.Name+" "+pkg.PrimaryPackagePurpose)
+	}
+	expectedPackages := []string{
+		"SPDXRef-BuildConfig-test-app test/app OTHER",
+		"SPDXRef-BuildConfig-test-bundle test/bundle OTHER",
+		"SPDXRef-ImageStreamTag-test-app-latest test/app:latest CONTAINER",
+		"SPDXRef-ImageStreamTag-test-base-latest test/base:latest CONTAINER",
+		"SPDXRef-ImageStreamTag-test-bundle-latest test/bundle:latest CONTAINER",
+	}
+	if !reflect.DeepEqual(packages, expectedPackages) {
+		t.Errorf("expected packages:\n%s\ngot:\n%s", strings.Join(expectedPackages, "\n"), strings.Join(packages, "\n"))
+	}
+	relationships := []string{}
+	for _, r := range doc.Relationships {
+		relationships = append(relationships, r.SPDXElementID+" "+r.RelationshipType+" "+r.RelatedSPDXElement)
+	}
+	expectedRelationships := []string{
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-ImageStreamTag-test-base-latest",
+		"SPDXRef-BuildConfig-test-app BUILD_TOOL_OF SPDXRef-ImageStreamTag-test-app-latest",
+		"SPDXRef-BuildConfig-test-bundle BUILD_TOOL_OF SPDXRef-ImageStreamTag-test-bundle-latest",
+		"SPDXRef-ImageStreamTag-test-app-latest GENERATED_FROM SPDXRef-ImageStreamTag-test-base-latest",
+		"SPDXRef-ImageStreamTag-test-bundle-latest GENERATED_FROM SPDXRef-ImageStreamTag-test-app-latest",
+	}
+	if !reflect.DeepEqual(relationships, expectedRelationships) {
+		t.Errorf("expected relationships:\n%s\ngot:\n%s", strings.Join(expectedRelationships, "\n"), strings.Join(relationships, "\n"))
+	}
+
+	again, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != desc {
+		t.Errorf("expected describing the same chain twice to yield the same document")
+	}
+}
+
+func TestChainDescriberEnvironments(t *testing.T) {
+	bc := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+				}},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+		},
+	}
+	newDeploymentConfig := func(name, environment, from string, available int32) runtime.Object {
+		return &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: map[string]string{"environment": environment}},
+			Spec: appsv1.DeploymentConfigSpec{Triggers: appsv1.DeploymentTriggerPolicies{{
+				Type:              appsv1.DeploymentTriggerOnImageChange,
+				ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: from}},
+			}}},
+			Status: appsv1.DeploymentConfigStatus{AvailableReplicas: available},
+		}
+	}
+	appsClient := fakeappsclient.NewSimpleClientset(
+		newDeploymentConfig("app-prod", "prod", "app:latest", 2),
+		newDeploymentConfig("app-dev", "dev", "app", 1),
+		// scaled down, the image doesn't run there
+		newDeploymentConfig("app-stage", "stage", "app:latest", 0),
+	)
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(bc).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.EnvironmentLabel = "environment"
+	describer.DeploymentConfigs = NewDeploymentConfigLister(appsClient.AppsV1())
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, "istag/app:latest [environments: dev, prod]") {
+		t.Errorf("expected the environments of app:latest in:\n%s", desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.EnvironmentLabel = "environment"
+	describer.DeploymentConfigs = NewDeploymentConfigLister(appsClient.AppsV1())
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range out.Nodes {
+		expected := []string(nil)
+		if node.ID == "ImageStreamTag|test/app:latest" {
+			expected = []string{"dev", "prod"}
+		}
+		if !reflect.DeepEqual(node.Environments, expected) {
+			t.Errorf("expected the environments of %s to be %v, got %v", node.ID, expected, node.Environments)
+		}
+	}
+}
+
+func TestChainDescriberIncludeDeployments(t *testing.T) {
+	bc := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+					From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+				}},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+		},
+	}
+	newDeploymentConfig := func(namespace, name, from string) runtime.Object {
+		return &appsv1.DeploymentConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.DeploymentConfigSpec{Triggers: appsv1.DeploymentTriggerPolicies{{
+				Type:              appsv1.DeploymentTriggerOnImageChange,
+				ImageChangeParams: &appsv1.DeploymentTriggerImageChangeParams{From: corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "test", Name: from}},
+			}}},
+		}
+	}
+	appsClient := fakeappsclient.NewSimpleClientset(
+		newDeploymentConfig("test", "app", "app:latest"),
+		newDeploymentConfig("test", "tools", "base:latest"),
+		newDeploymentConfig("test", "other", "other:latest"),
+	)
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(bc).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	for format, expected := range map[string]string{
+		"": strings.Join([]string{
+			"istag/base:latest",
+			"\tbc/app",
+			"\t\tistag/app:latest",
+			"\t\t\tdc/app",
+			"\tdc/tools",
+		}, "\n"),
+		"mermaid": strings.Join([]string{
+			"flowchart TD",
+			`  n0["bc/app"]`,
+			`  n1{{"dc/app"}}`,
+			`  n2{{"dc/tools"}}`,
+			`  n3("istag/app:latest")`,
+			`  n4("istag/base:latest")`,
+			"  n0 --> n3",
+			"  n3 --> n1",
+			"  n4 --> n0",
+			"  n4 --> n2",
+		}, "\n"),
+	} {
+		describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), format)
+		describer.IncludeDeployments = true
+		describer.DeploymentConfigs = NewDeploymentConfigLister(appsClient.AppsV1())
+		desc, err := describer.Describe(ist, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc != expected {
+			t.Errorf("expected the %q output:\n%s\ngot:\n%s", format, expected, desc)
+		}
+	}
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.IncludeDeployments = true
+	describer.DeploymentConfigs = NewDeploymentConfigLister(appsClient.AppsV1())
+	desc, err := describer.Describe(imagegraph.MakeImageStreamTagObjectMeta("test", "other", "latest"), false, false)
+	if err != nil {
+		t.Fatalf("expected an image stream tag only deployed to have a chain, got %v", err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	expectedEdges := []ChainEdge{{From: "ImageStreamTag|test/other:latest", To: "DeploymentConfig|test/other", Kinds: []string{"TriggersDeployment"}, Tag: "latest"}}
+	if !reflect.DeepEqual(out.Edges, expectedEdges) {
+		t.Errorf("expected edges %#v, got %#v", expectedEdges, out.Edges)
+	}
+}
+
+func TestChainDescriberPartialFailures(t *testing.T) {
+	newBuildConfig := func(namespace, name, from string) runtime.Object {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: "base", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	resource := schema.GroupResource{Group: "build.openshift.io", Resource: "buildconfigs"}
+	newLister := func() (BuildConfigLister, *int) {
+		client := fakebuildclient.NewSimpleClientset(newBuildConfig("team-a", "app", "base:latest"), newBuildConfig("team-b", "web", "base:latest"))
+		calls := 0
+		client.PrependReactor("list", "buildconfigs", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			switch action.GetNamespace() {
+			case "team-a":
+				// fails once, then succeeds
+				calls++
+				if calls == 1 {
+					return true, nil, kerrors.NewServiceUnavailable("try again")
+				}
+			case "team-b":
+				return true, nil, kerrors.NewForbidden(resource, "", fmt.Errorf("denied"))
+			}
+			return false, nil, nil
+		})
+		return NewBuildConfigLister(&fakebuildv1client.FakeBuildV1{Fake: &client.Fake}), &calls
+	}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("base", "base", "latest")
+
+	lister, calls := newLister()
+	describer := NewChainDescriber(lister, sets.NewString("team-a", "team-b"), "")
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *calls != 2 {
+		t.Errorf("expected the transient error to be retried once, got %d calls", *calls)
+	}
+	if !strings.Contains(desc, "bc/app") || strings.Contains(desc, "bc/web") {
+		t.Errorf("expected the chain of the namespaces that could be loaded, got:\n%s", desc)
+	}
+	if warnings := describer.Warnings(); len(warnings) != 1 || warnings[0].Code != WarningPermissionDeniedNamespace || !strings.Contains(warnings[0].Message, `unable to load namespace "team-b"`) {
+		t.Errorf("expected a warning about team-b, got %v", warnings)
+	}
+
+	lister, _ = newLister()
+	describer = NewChainDescriber(lister, sets.NewString("team-a", "team-b"), "")
+	describer.FailFast = true
+	if _, err := describer.Describe(ist, false, false); err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("expected the failure to load team-b with FailFast, got %v", err)
+	}
+}
+
+func TestChainDescriberRegistryOutputs(t *testing.T) {
+	newBuildConfig := func(name, from, output string) runtime.Object {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "DockerImage", Name: output}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest", "image-registry.openshift-image-registry.svc:5000/test/app:v1"),
+		newBuildConfig("public", "base:latest", "registry.apps.example.com/test/public"),
+		newBuildConfig("external", "base:latest", "quay.io/test/external:latest"),
+		newBuildConfig("bundle", "app:v1", "quay.io/test/bundle:latest"),
+	).Fake)}
+	imageClient := fakeimageclient.NewSimpleClientset(
+		&imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+			Status:     imagev1.ImageStreamStatus{DockerImageRepository: "image-registry.openshift-image-registry.svc:5000/test/app"},
+		},
+		&imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Name: "public", Namespace: "test"},
+			Status: imagev1.ImageStreamStatus{
+				DockerImageRepository:       "image-registry.openshift-image-registry.svc:5000/test/public",
+				PublicDockerImageRepository: "registry.apps.example.com/test/public",
+			},
+		},
+	)
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.ImageStreams = NewImageStreamLister(imageClient.ImageV1())
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	nodes := []string{}
+	for _, node := range out.Nodes {
+		nodes = append(nodes, node.ID)
+	}
+	expected := []string{
+		"BuildConfig|test/app",
+		"BuildConfig|test/bundle",
+		"BuildConfig|test/external",
+		"BuildConfig|test/public",
+		"ImageStreamTag|test/app:v1",
+		"ImageStreamTag|test/base:latest",
+		"ImageStreamTag|test/public:latest",
+	}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("expected nodes:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(nodes, "\n"))
+	}
+
+	// users who can't list image streams still get the chain, without the
+	// outputs pushed by reference
+	forbiddenClient := fakeimageclient.NewSimpleClientset()
+	forbiddenClient.PrependReactor("list", "imagestreams", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, kerrors.NewForbidden(schema.GroupResource{Group: "image.openshift.io", Resource: "imagestreams"}, "", fmt.Errorf("not allowed"))
+	})
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.ImageStreams = NewImageStreamLister(forbiddenClient.ImageV1())
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Warnings) > 0 {
+		t.Errorf("expected no warnings, got %#v", out.Warnings)
+	}
+	nodes = []string{}
+	for _, node := range out.Nodes {
+		nodes = append(nodes, node.ID)
+	}
+	expected = []string{
+		"BuildConfig|test/app",
+		"BuildConfig|test/external",
+		"BuildConfig|test/public",
+		"ImageStreamTag|test/base:latest",
+	}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("expected nodes:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(nodes, "\n"))
+	}
+
+	// the image streams of the namespaces nothing pushes to by reference
+	// aren't listed
+	imageClient.ClearActions()
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test", "other"), "json")
+	describer.ImageStreams = NewImageStreamLister(imageClient.ImageV1())
+	if _, err := describer.Describe(ist, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if len(imageClient.Actions()) == 0 {
+		t.Errorf("expected the image streams of test to be listed")
+	}
+	for _, action := range imageClient.Actions() {
+		if action.GetNamespace() != "test" {
+			t.Errorf("expected only the image streams of test to be listed, got %#v", action)
+		}
+	}
+}
+
+func TestChainDescriberCycles(t *testing.T) {
+	objs, err := readObjectsFromPath("../graph/genericgraph/test/circular.yaml", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("example", "ruby-25-centos7", "latest")
+	message := "Cycle detected in build configurations: "
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("example"), "json").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatalf("invalid json output: %v\n%s", err, desc)
+	}
+	if len(out.Nodes) != 6 || len(out.Edges) != 6 {
+		t.Fatalf("expected 6 nodes and 6 edges, got:\n%s", desc)
+	}
+	for _, e := range out.Edges {
+		if !e.Cycle {
+			t.Errorf("expected edge from %s to %s to be part of a cycle", e.From, e.To)
+		}
+	}
+	if len(out.Warnings) != 1 || out.Warnings[0].Code != WarningCycleDetected || !strings.HasPrefix(out.Warnings[0].Message, message) {
+		t.Errorf("expected a cycle warning, got %#v", out.Warnings)
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("example"), "dot").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(desc, "color=red") != 6 {
+		t.Errorf("expected every edge to be red, got:\n%s", desc)
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("example"), "ascii").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(desc, message) != 1 || strings.Count(desc, "\n* ") != 5 {
+		t.Errorf("expected the chain followed by the cycle, got:\n%s", desc)
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("example"), "spdx").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(desc, `"comment": "cycle"`) != 6 {
+		t.Errorf("expected every relationship between packages to be part of the cycle, got:\n%s", desc)
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("example"), "mermaid").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(desc, "\n  linkStyle 0,1,2,3,4,5 stroke:red") {
+		t.Errorf("expected every link to be red, got:\n%s", desc)
+	}
+}
+
+func TestChainDescriberTriggerCycles(t *testing.T) {
+	objs, err := readObjectsFromPath("../graph/genericgraph/test/circular.yaml", "example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without input images the cycle only goes through triggers
+	for _, obj := range objs {
+		if bc, ok := obj.(*buildv1.BuildConfig); ok {
+			from := bc.Spec.Strategy.DockerStrategy.From
+			bc.Spec.Strategy.DockerStrategy.From = nil
+			for _, trigger := range bc.Spec.Triggers {
+				if trigger.ImageChange != nil {
+					trigger.ImageChange.From = from
+				}
+			}
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("example", "ruby-25-centos7", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("example"), "").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trees := strings.Split(desc, "\n\n")
+	if len(trees) != 2 || !strings.HasPrefix(trees[0], "istag/ruby-25-centos7:latest") || !strings.HasPrefix(trees[1], "Cycle detected in build configurations: ") {
+		t.Errorf("expected the chain followed by the cycle, got:\n%s", desc)
+	}
+}
+
+func TestChainDescriberSelector(t *testing.T) {
+	newBuildConfig := func(name string, labels map[string]string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: labels},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	client := fakebuildclient.NewSimpleClientset(newBuildConfig("web", map[string]string{"team": "web"}), newBuildConfig("db", nil))
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(client.Fake)}
+	selector, err := labels.Parse("team=web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	desc, err := NewChainDescriber(NewFilteredBuildConfigLister(fakeClient, selector), sets.NewString("test"), "").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, "bc/web") || strings.Contains(desc, "bc/db") {
+		t.Errorf("expected only the build config matching the selector, got:\n%s", desc)
+	}
+	for _, action := range client.Actions() {
+		if action.Matches("list", "buildconfigs") {
+			if got := action.(clienttesting.ListAction).GetListRestrictions().Labels.String(); got != "team=web" {
+				t.Errorf("expected the selector to be sent to the server, got %q", got)
+			}
+		}
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, "bc/web") || !strings.Contains(desc, "bc/db") {
+		t.Errorf("expected all the build configs without a selector, got:\n%s", desc)
+	}
+}
+
+func TestChainDescriberCriticalPath(t *testing.T) {
+	newBuildConfig := func(name, from string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	newBuild := func(name, bc string, phase buildv1.BuildPhase, duration time.Duration) *buildv1.Build {
+		return &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: map[string]string{buildv1.BuildConfigLabel: bc}},
+			Status:     buildv1.BuildStatus{Phase: phase, Duration: duration},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest"),
+		newBuildConfig("web", "app:latest"),
+		newBuildConfig("tools", "base:latest"),
+		newBuild("app-1", "app", buildv1.BuildPhaseComplete, 5*time.Minute),
+		newBuild("app-2", "app", buildv1.BuildPhaseComplete, 3*time.Minute),
+		newBuild("app-3", "app", buildv1.BuildPhaseFailed, time.Hour),
+		newBuild("web-1", "web", buildv1.BuildPhaseComplete, 2*time.Minute),
+		newBuild("tools-1", "tools", buildv1.BuildPhaseRunning, 0),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.CriticalPath = CriticalPathByDuration
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Critical path of base:latest: 2 builds, estimated 6m0s
+  istag/base:latest
+  bc/app             +4m0s  4m0s
+  istag/app:latest
+  bc/web             +2m0s  6m0s
+  istag/web:latest
+`
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.CriticalPath = CriticalPathByHops
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := &CriticalPath{}
+	if err := json.Unmarshal([]byte(desc), path); err != nil {
+		t.Fatal(err)
+	}
+	ids := []string{}
+	for _, step := range path.Steps {
+		ids = append(ids, step.ID)
+	}
+	if path.Builds != 2 || path.Duration != nil || len(ids) != 5 || !strings.Contains(ids[3], "web") {
+		t.Errorf("expected the path through web weighted by hops, got %s", desc)
+	}
+}
+
+func TestChainDescriberSimulate(t *testing.T) {
+	newBuildConfig := func(name, from string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest"),
+		newBuildConfig("tools", "base:latest"),
+		newBuildConfig("web", "app:latest"),
+		newBuildConfig("docs", "web:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.Simulate = true
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `A change of base:latest triggers 4 builds in 3 stages
+Stage 1, 2 builds in parallel:
+  bc/app    triggered by istag/base:latest
+  bc/tools  triggered by istag/base:latest
+Stage 2:
+  bc/web  triggered by istag/app:latest
+Stage 3:
+  bc/docs  triggered by istag/web:latest
+`
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	describer = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	describer.Simulate = true
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	simulation := &Simulation{}
+	if err := json.Unmarshal([]byte(desc), simulation); err != nil {
+		t.Fatal(err)
+	}
+	if simulation.Builds != 4 || len(simulation.Stages) != 3 || len(simulation.Stages[0].Builds) != 2 {
+		t.Fatalf("expected 4 builds in 3 stages, got %s", desc)
+	}
+	if build := simulation.Stages[1].Builds[0]; !strings.Contains(build.ID, "web") || len(build.TriggeredBy) != 1 || !strings.Contains(build.TriggeredBy[0], "app:latest") {
+		t.Errorf("expected web to be triggered by app:latest, got %#v", build)
+	}
+}
+
+func TestChainDescriberColorByStatus(t *testing.T) {
+	newBuildConfig := func(name, from string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	now := time.Now()
+	newBuild := func(name, bc string, phase buildv1.BuildPhase, created time.Time) *buildv1.Build {
+		return &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: map[string]string{buildv1.BuildConfigLabel: bc}, CreationTimestamp: metav1.NewTime(created)},
+			Status:     buildv1.BuildStatus{Phase: phase},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest"),
+		newBuildConfig("web", "app:latest"),
+		newBuildConfig("tools", "base:latest"),
+		newBuildConfig("docs", "base:latest"),
+		newBuild("app-1", "app", buildv1.BuildPhaseFailed, now.Add(-time.Hour)),
+		newBuild("app-2", "app", buildv1.BuildPhaseComplete, now),
+		newBuild("web-1", "web", buildv1.BuildPhaseComplete, now.Add(-time.Hour)),
+		newBuild("web-2", "web", buildv1.BuildPhaseFailed, now),
+		newBuild("tools-1", "tools", buildv1.BuildPhaseRunning, now),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot")
+	describer.ColorByStatus = true
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colors := map[string]string{}
+	for _, match := range regexp.MustCompile(`label="BuildConfig\|test/(\w+)"\s+color=(\w+)`).FindAllStringSubmatch(desc, -1) {
+		colors[match[1]] = match[2]
+	}
+	expected := map[string]string{"app": "green", "web": "red", "tools": "yellow"}
+	if !reflect.DeepEqual(colors, expected) {
+		t.Errorf("expected the build configs colored %v, got %v in:\n%s", expected, colors, desc)
+	}
+	if edges := regexp.MustCompile(`label="[\w,]+"\s+color=green`).FindAllString(desc, -1); len(edges) != 2 {
+		t.Errorf("expected the input and output of bc/app colored green in:\n%s", desc)
+	}
+}
+
+func TestChainDescriberOnlyFailing(t *testing.T) {
+	newBuildConfig := func(name, from string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	now := time.Now()
+	newBuild := func(name, bc string, phase buildv1.BuildPhase, created time.Time) *buildv1.Build {
+		return &buildv1.Build{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Labels: map[string]string{buildv1.BuildConfigLabel: bc}, CreationTimestamp: metav1.NewTime(created)},
+			Status:     buildv1.BuildStatus{Phase: phase},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest"),
+		newBuildConfig("web", "app:latest"),
+		newBuildConfig("worker", "web:latest"),
+		newBuildConfig("tools", "base:latest"),
+		newBuildConfig("docs", "base:latest"),
+		newBuild("app-1", "app", buildv1.BuildPhaseComplete, now),
+		newBuild("web-1", "web", buildv1.BuildPhaseComplete, now.Add(-time.Hour)),
+		newBuild("web-2", "web", buildv1.BuildPhaseFailed, now),
+		newBuild("tools-1", "tools", buildv1.BuildPhaseFailed, now.Add(-time.Hour)),
+		newBuild("tools-2", "tools", buildv1.BuildPhaseRunning, now),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	describer.OnlyFailing = true
+	desc, err := describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the branch of web, whose latest build failed, is left
+	expected := strings.Join([]string{
+		"istag/base:latest",
+		"\tbc/app",
+		"\t\tistag/app:latest",
+		"\t\t\tbc/web",
+		"\t\t\t\tistag/web:latest",
+		"\t\t\t\t\tbc/worker",
+		"\t\t\t\t\t\tistag/worker:latest",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+}
+
+func TestChainDescriberProvenance(t *testing.T) {
+	objs, err := readObjectsFromPath("../../../pkg/cli/admin/buildchain/test/single-namespace-bcs.yaml", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(filterByScheme(buildclientscheme.Scheme, objs...)...).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "ruby-25-centos7", "latest")
+	provenance := &ChainProvenance{
+		ClientVersion: "v4.16.0",
+		ServerVersion: "v1.29.0",
+		User:          "alice",
+		Namespaces:    []string{"test"},
+		Flags:         []string{"--output=json", "--selector=team=web"},
+	}
+
+	describe := func(anonymize bool) *ChainOutput {
+		describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+		describer.Provenance = provenance
+		describer.Anonymize = anonymize
+		desc, err := describer.Describe(ist, false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := &ChainOutput{}
+		if err := json.Unmarshal([]byte(desc), out); err != nil {
+			t.Fatalf("invalid json output: %v\n%s", err, desc)
+		}
+		return out
+	}
+
+	out := describe(false)
+	if out.Provenance == nil {
+		t.Fatalf("expected the provenance to be recorded")
+	}
+	recorded := *out.Provenance
+	out.Provenance = nil
+	content, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := fmt.Sprintf("sha256:%x", sha256.Sum256(content)); recorded.ContentHash != expected {
+		t.Errorf("expected the content hash %s, got %s", expected, recorded.ContentHash)
+	}
+	recorded.ContentHash = ""
+	if !reflect.DeepEqual(&recorded, provenance) {
+		t.Errorf("expected the provenance %#v, got %#v", provenance, recorded)
+	}
+	if len(provenance.ContentHash) > 0 {
+		t.Errorf("expected the provenance of the describer to be left unchanged")
+	}
+
+	anonymized := describe(true).Provenance
+	if anonymized.User != "" || anonymized.Namespaces[0] == "test" || !reflect.DeepEqual(anonymized.Flags, []string{"--output", "--selector"}) {
+		t.Errorf("expected the user, namespaces and flag values to be left out of anonymized output, got %#v", anonymized)
+	}
+}
+
+// fakeScanner reports the vulnerabilities of image stream tags by
+// namespace/name, and counts the scans.
+type fakeScanner struct {
+	lock   sync.Mutex
+	counts map[string]*VulnerabilityCounts
+	scans  int
+}
+
+func (s *fakeScanner) ScanImageStreamTag(ctx context.Context, namespace, name string) (*VulnerabilityCounts, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.scans++
+	return s.counts[namespace+"/"+name], nil
+}
+
+func TestChainDescriberVulnerabilities(t *testing.T) {
+	newBuildConfig := func(name, from string) runtime.Object {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("runtime", "base:latest"),
+		newBuildConfig("app", "runtime:latest"),
+		newBuildConfig("tool", "base:latest"),
+	).Fake)}
+	scanner := &fakeScanner{counts: map[string]*VulnerabilityCounts{
+		"test/base:latest":    {High: 2, Low: 5},
+		"test/runtime:latest": {Critical: 1},
+		"test/tool:latest":    {},
+	}}
+	d := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	d.Scanner = scanner
+
+	desc, err := d.Describe(imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest"), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), &out); err != nil {
+		t.Fatal(err)
+	}
+	vulnerabilities := map[string]*VulnerabilityCounts{}
+	for _, node := range out.Nodes {
+		vulnerabilities[node.ID] = node.Vulnerabilities
+	}
+	expectedVulnerabilities := map[string]*VulnerabilityCounts{
+		"ImageStreamTag|test/base:latest":    {High: 2, Low: 5},
+		"ImageStreamTag|test/runtime:latest": {Critical: 1},
+		"ImageStreamTag|test/app:latest":     nil,
+		"ImageStreamTag|test/tool:latest":    {},
+		"BuildConfig|test/runtime":           nil,
+		"BuildConfig|test/app":               nil,
+		"BuildConfig|test/tool":              nil,
+	}
+	if !reflect.DeepEqual(vulnerabilities, expectedVulnerabilities) {
+		t.Errorf("expected vulnerabilities %v, got %v", expectedVulnerabilities, vulnerabilities)
+	}
+
+	// the critical vulnerability comes first, though base has more dependents
+	expectedVulnerable := []VulnerableImage{
+		{ID: "ImageStreamTag|test/runtime:latest", Vulnerabilities: VulnerabilityCounts{Critical: 1}, Dependents: 1},
+		{ID: "ImageStreamTag|test/base:latest", Vulnerabilities: VulnerabilityCounts{High: 2, Low: 5}, Dependents: 3},
+	}
+	if !reflect.DeepEqual(d.Vulnerable(), expectedVulnerable) {
+		t.Errorf("expected vulnerable images %v, got %v", expectedVulnerable, d.Vulnerable())
+	}
+
+	// scans are cached across chains of the describer
+	if _, err := d.Describe(imagegraph.MakeImageStreamTagObjectMeta("test", "runtime", "latest"), false, false); err != nil {
+		t.Fatal(err)
+	}
+	if scanner.scans != 4 {
+		t.Errorf("expected 4 scans, got %d", scanner.scans)
+	}
+}
+
+func TestVulnerabilityCounts(t *testing.T) {
+	counts := VulnerabilityCounts{High: 2, Low: 5}
+	for severity, expected := range map[string]int{"critical": 0, "high": 2, "medium": 2, "low": 7} {
+		if actual := counts.AtLeast(severity); actual != expected {
+			t.Errorf("expected %d vulnerabilities of severity %s or worse, got %d", expected, severity, actual)
+		}
+	}
+	if counts.String() != "2 high, 5 low" {
+		t.Errorf("unexpected string %q", counts.String())
+	}
+	if (VulnerabilityCounts{}).String() != "none" {
+		t.Errorf("unexpected string %q", VulnerabilityCounts{}.String())
+	}
+}
+
+func TestChainDescriberIncludeExternal(t *testing.T) {
+	newBuildConfig := func(name, fromKind, from string, triggers ...string) runtime.Object {
+		bc := &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: fromKind, Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+			},
+		}
+		for _, trigger := range triggers {
+			bc.Spec.Triggers = append(bc.Spec.Triggers, buildv1.BuildTriggerPolicy{
+				Type:        buildv1.ImageChangeBuildTriggerType,
+				ImageChange: &buildv1.ImageChangeTrigger{From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: trigger}},
+			})
+		}
+		return bc
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("base", "DockerImage", "registry.example.com/ubi:8"),
+		newBuildConfig("app", "ImageStreamTag", "base:latest", "base:latest"),
+		// tool is triggered by base but built from golang
+		newBuildConfig("tool", "DockerImage", "golang:1.20", "base:latest"),
+	).Fake)}
+
+	d := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	d.IncludeExternal = true
+	desc, err := d.Describe(imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest"), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"istag/base:latest",
+		"\tbc/app",
+		"\t\tistag/app:latest",
+		"\tbc/tool [from: docker.io/library/golang:1.20]",
+		"\t\tistag/tool:latest",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	d = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	d.IncludeExternal = true
+	desc, err = d.Describe(imagegraph.MakeImageStreamTagObjectMeta("test", "app", "latest"), false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), &out); err != nil {
+		t.Fatal(err)
+	}
+	expectedNodes := []ChainNode{
+		{ID: "BuildConfig|test/app", Kind: "BuildConfig", Namespace: "test", Name: "app"},
+		{ID: "BuildConfig|test/base", Kind: "BuildConfig", Namespace: "test", Name: "base"},
+		{ID: "DockerImageReference|registry.example.com/ubi:8", Kind: "DockerImageReference", Name: "registry.example.com/ubi:8"},
+		{ID: "ImageStreamTag|test/app:latest", Kind: "ImageStreamTag", Namespace: "test", Name: "app:latest"},
+		{ID: "ImageStreamTag|test/base:latest", Kind: "ImageStreamTag", Namespace: "test", Name: "base:latest"},
+	}
+	if !reflect.DeepEqual(out.Nodes, expectedNodes) {
+		t.Errorf("expected nodes %#v, got %#v", expectedNodes, out.Nodes)
+	}
+	external := []ChainEdge{}
+	for _, edge := range out.Edges {
+		if strings.HasPrefix(edge.From, "DockerImageReference|") {
+			external = append(external, edge)
+		}
+	}
+	if len(external) != 1 || external[0].To != "BuildConfig|test/base" || external[0].CrossNamespace {
+		t.Errorf("expected a single edge from the external image to bc/base, got %#v", external)
+	}
+
+	// the anonymized output hides the repository of external images
+	d.Anonymize = true
+	if desc, err = d.Describe(imagegraph.MakeImageStreamTagObjectMeta("test", "app", "latest"), false, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(desc, "registry.example.com") || !regexp.MustCompile(`"name": "image-[0-9a-f]{10}:8"`).MatchString(desc) {
+		t.Errorf("expected the external image to be anonymized:\n%s", desc)
+	}
+}
+
+func TestChainDescriberHideTags(t *testing.T) {
+	newBuildConfig := func(name, from, to string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: to}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+			},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("app", "base:latest", "app:latest"),
+		newBuildConfig("app-debug", "base:latest", "app:debug"),
+		newBuildConfig("web", "app:latest", "web:latest"),
+		newBuildConfig("web-debug", "app:debug", "web:latest"),
+	).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+
+	d := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "")
+	d.HideTags = true
+	desc, err := d.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"is/base",
+		"\tbc/app",
+		"\t\tis/app",
+		"\t\t\tbc/web",
+		"\t\t\t\tis/web",
+		"\t\t\tbc/web-debug",
+		"\t\t\t\tis/web",
+		"\tbc/app-debug",
+		"\t\tis/app",
+		"\t\t\tbc/web",
+		"\t\t\t\tis/web",
+		"\t\t\tbc/web-debug",
+		"\t\t\t\tis/web",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	d = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json")
+	d.HideTags = true
+	if desc, err = d.Describe(ist, false, false); err != nil {
+		t.Fatal(err)
+	}
+	out := ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Root != "ImageStream|test/base" {
+		t.Errorf("expected the root to be merged into its image stream, got %q", out.Root)
+	}
+	nodes := []string{}
+	for _, node := range out.Nodes {
+		nodes = append(nodes, node.ID)
+	}
+	expectedNodes := []string{
+		"BuildConfig|test/app",
+		"BuildConfig|test/app-debug",
+		"BuildConfig|test/web",
+		"BuildConfig|test/web-debug",
+		"ImageStream|test/app",
+		"ImageStream|test/base",
+		"ImageStream|test/web",
+	}
+	if !reflect.DeepEqual(nodes, expectedNodes) {
+		t.Errorf("expected nodes %v, got %v", expectedNodes, nodes)
+	}
+	// the outputs of app and app-debug go to the same image stream
+	into := 0
+	for _, edge := range out.Edges {
+		if edge.To == "ImageStream|test/app" {
+			into++
+		}
+	}
+	if into != 2 || len(out.Edges) != 8 {
+		t.Errorf("expected the edges to be merged through the image streams, got %#v", out.Edges)
+	}
+}
+
+func TestChainDescriberTemplate(t *testing.T) {
+	newBuildConfig := func(namespace, name, fromNamespace, from string) *buildv1.BuildConfig {
+		return &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: "0123", ResourceVersion: "42"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Namespace: fromNamespace, Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+				Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{LastTriggeredImageID: "sha256:0123"}}},
+			},
+			Status: buildv1.BuildConfigStatus{LastVersion: 3},
+		}
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		newBuildConfig("team-a", "app", "base-images", "base:latest"),
+		newBuildConfig("team-b", "web", "team-a", "app:latest"),
+	).Fake)}
+
+	d := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("base-images", "team-a", "team-b"), "template")
+	desc, err := d.Describe(imagegraph.MakeImageStreamTagObjectMeta("base-images", "base", "latest"), false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Objects    []json.RawMessage `json:"objects"`
+		Parameters []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"parameters"`
+	}{}
+	if err := json.Unmarshal([]byte(desc), &template); err != nil {
+		t.Fatal(err)
+	}
+	if template.Kind != "Template" || template.Metadata.Name != "base-latest-build-chain" {
+		t.Errorf("unexpected template %s/%s", template.Kind, template.Metadata.Name)
+	}
+	parameters := []string{}
+	for _, parameter := range template.Parameters {
+		parameters = append(parameters, parameter.Name+"="+parameter.Value)
+	}
+	// the image stream of base-images, which doesn't take part in the
+	// pipeline, isn't parameterized
+	if expected := []string{"NAMESPACE_TEAM_A=team-a", "NAMESPACE_TEAM_B=team-b"}; !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("expected parameters %v, got %v", expected, parameters)
+	}
+
+	objects := []string{}
+	bcs := map[string]*buildv1.BuildConfig{}
+	for _, raw := range template.Objects {
+		obj := struct {
+			Kind     string            `json:"kind"`
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}{}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			t.Fatal(err)
+		}
+		objects = append(objects, fmt.Sprintf("%s %s/%s", obj.Kind, obj.Metadata.Namespace, obj.Metadata.Name))
+		if obj.Kind == "BuildConfig" {
+			bc := &buildv1.BuildConfig{}
+			if err := json.Unmarshal(raw, bc); err != nil {
+				t.Fatal(err)
+			}
+			bcs[bc.Name] = bc
+		}
+	}
+	expectedObjects := []string{
+		"ImageStream ${NAMESPACE_TEAM_A}/app",
+		"ImageStream ${NAMESPACE_TEAM_B}/web",
+		"BuildConfig ${NAMESPACE_TEAM_A}/app",
+		"BuildConfig ${NAMESPACE_TEAM_B}/web",
+	}
+	if !reflect.DeepEqual(objects, expectedObjects) {
+		t.Errorf("expected objects %v, got %v", expectedObjects, objects)
+	}
+	if from := bcs["app"].Spec.Strategy.DockerStrategy.From; from.Namespace != "base-images" {
+		t.Errorf("expected app to keep being built from base-images, got %#v", from)
+	}
+	if from := bcs["web"].Spec.Strategy.DockerStrategy.From; from.Namespace != "${NAMESPACE_TEAM_A}" {
+		t.Errorf("expected web to be built from the namespace parameter of app, got %#v", from)
+	}
+	web := bcs["web"]
+	if len(web.UID) > 0 || len(web.ResourceVersion) > 0 || web.Status.LastVersion != 0 || web.Spec.Triggers[0].ImageChange.LastTriggeredImageID != "" {
+		t.Errorf("expected the server set fields to be left out, got %#v", web)
+	}
+}
+
+func TestChainDescriberCustomStrategy(t *testing.T) {
+	custom := &buildv1.BuildConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+		Spec: buildv1.BuildConfigSpec{
+			CommonSpec: buildv1.CommonSpec{
+				// the strategy type is left for the server to default
+				Strategy: buildv1.BuildStrategy{CustomStrategy: &buildv1.CustomBuildStrategy{
+					From: corev1.ObjectReference{Kind: "ImageStreamTag", Name: "builder:latest"},
+				}},
+				Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+			Triggers: []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}},
+		},
+	}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(custom).Fake)}
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "builder", "latest")
+
+	desc, err := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"istag/builder:latest",
+		"\tbc/app (custom)",
+		"\t\tistag/app:latest",
+	}, "\n")
+	if desc != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, desc)
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "json").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range out.Nodes {
+		if node.Custom != (node.ID == "BuildConfig|test/app") {
+			t.Errorf("expected only bc/app to be custom, got %#v", node)
+		}
+	}
+	for _, edge := range out.Edges {
+		if edge.BuildConfig == nil || edge.BuildConfig.Strategy != "Custom" {
+			t.Errorf("expected the edges of bc/app to have the Custom strategy, got %#v", edge.BuildConfig)
+		}
+	}
+
+	desc, err = NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), "dot").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(desc, `xlabel="custom"`) {
+		t.Errorf("expected bc/app to be labeled custom:\n%s", desc)
+	}
+}
+
+func TestChainDescriberWarnings(t *testing.T) {
+	newBuildConfig := func(name, from string, triggers ...buildv1.ImageChangeTrigger) *buildv1.BuildConfig {
+		bc := &buildv1.BuildConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: buildv1.BuildConfigSpec{
+				CommonSpec: buildv1.CommonSpec{
+					Strategy: buildv1.BuildStrategy{DockerStrategy: &buildv1.DockerBuildStrategy{
+						From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: from},
+					}},
+					Output: buildv1.BuildOutput{To: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: name + ":latest"}},
+				},
+			},
+		}
+		for i := range triggers {
+			bc.Spec.Triggers = append(bc.Spec.Triggers, buildv1.BuildTriggerPolicy{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &triggers[i]})
+		}
+		return bc
+	}
+	// app pushes by reference to the registry, for the image streams of the
+	// namespace to be listed
+	app := newBuildConfig("app", "base:latest", buildv1.ImageChangeTrigger{})
+	app.Spec.Output.To = &corev1.ObjectReference{Kind: "DockerImage", Name: "image-registry.openshift-image-registry.svc:5000/test/app:latest"}
+	fakeClient := &fakebuildv1client.FakeBuildV1{Fake: &(fakebuildclient.NewSimpleClientset(
+		app,
+		// worker pushes to an image stream that doesn't exist and has a
+		// trigger on a docker image
+		newBuildConfig("worker", "base:latest", buildv1.ImageChangeTrigger{}, buildv1.ImageChangeTrigger{
+			From: &corev1.ObjectReference{Kind: "DockerImage", Name: "quay.io/test/tools:latest"},
+		}),
+		// unrelated pushes to an image stream that doesn't exist but isn't
+		// part of the chain
+		newBuildConfig("unrelated", "other:latest", buildv1.ImageChangeTrigger{}),
+	).Fake)}
+	imageClient := fakeimageclient.NewSimpleClientset(
+		&imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Name: "base", Namespace: "test"}},
+		&imagev1.ImageStream{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "test"},
+			Status:     imagev1.ImageStreamStatus{DockerImageRepository: "image-registry.openshift-image-registry.svc:5000/test/app"},
+		},
+	)
+	ist := imagegraph.MakeImageStreamTagObjectMeta("test", "base", "latest")
+	newDescriber := func(output string) *ChainDescriber {
+		describer := NewChainDescriber(NewBuildConfigLister(fakeClient), sets.NewString("test"), output)
+		describer.ImageStreams = NewImageStreamLister(imageClient.ImageV1())
+		return describer
+	}
+
+	desc, err := newDescriber("json").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	codes := []WarningCode{}
+	for _, warning := range out.Warnings {
+		if warning.Namespace != "test" || warning.BuildConfig != "worker" || len(warning.Message) == 0 {
+			t.Errorf("expected a warning about bc/worker, got %#v", warning)
+		}
+		codes = append(codes, warning.Code)
+	}
+	if expected := []WarningCode{WarningMalformedTrigger, WarningMissingOutputStream}; !reflect.DeepEqual(codes, expected) {
+		t.Errorf("expected the codes %v, got %v", expected, codes)
+	}
+
+	describer := newDescriber("json")
+	describer.Anonymize = true
+	desc, err = describer.Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out = &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	for _, warning := range out.Warnings {
+		if warning.Namespace == "test" || warning.BuildConfig == "worker" || len(warning.Message) > 0 {
+			t.Errorf("expected an anonymized warning, got %#v", warning)
+		}
+	}
+
+	desc, err = newDescriber("summary").Describe(ist, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Join([]string{
+		"Warnings:",
+		"  MALFORMED_TRIGGER      1",
+		"  MISSING_OUTPUT_STREAM  1",
+	}, "\n")
+	if !strings.HasSuffix(desc, "\n\n"+expected) {
+		t.Errorf("expected the summary to end with:\n%s\ngot:\n%s", expected, desc)
+	}
+}
+
+func TestMergeClusterChains(t *testing.T) {
+	base := &ImageDetails{Digest: "sha256:0123456789abcdef0123"}
+	newChain := func(app string, appImage *ImageDetails) *ChainOutput {
+		return &ChainOutput{
+			Root: "ImageStreamTag|test/base:latest",
+			Nodes: []ChainNode{
+				{ID: "ImageStreamTag|test/base:latest", Kind: "ImageStreamTag", Namespace: "test", Name: "base:latest", Image: base},
+				{ID: "BuildConfig|test/app", Kind: "BuildConfig", Namespace: "test", Name: "app"},
+				{ID: "ImageStreamTag|test/" + app, Kind: "ImageStreamTag", Namespace: "test", Name: app, Image: appImage},
+			},
+			Edges: []ChainEdge{
+				{From: "ImageStreamTag|test/base:latest", To: "BuildConfig|test/app", Kinds: []string{"BuildInputImage"}},
+				{From: "BuildConfig|test/app", To: "ImageStreamTag|test/" + app, Kinds: []string{"BuildOutput"}},
+			},
+			Warnings: []ChainWarning{{Code: WarningMissingOutputStream, Namespace: "test", BuildConfig: "app"}},
+		}
+	}
+	// base was promoted from staging to production under another tag, app
+	// wasn't pushed in production yet
+	staging := newChain("app:latest", &ImageDetails{Digest: "sha256:fedcba9876543210fedc"})
+	production := newChain("app:latest", nil)
+	production.Root = "ImageStreamTag|test/base:prod"
+	production.Nodes[0].ID, production.Nodes[0].Name = "ImageStreamTag|test/base:prod", "base:prod"
+	production.Edges[0].From = "ImageStreamTag|test/base:prod"
+
+	desc, err := MergeClusterChains([]ClusterChain{{Cluster: "staging", Output: staging}, {Cluster: "production", Output: production}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &ChainOutput{}
+	if err := json.Unmarshal([]byte(desc), out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Root != "ImageStreamTag|sha256:0123456789abcdef0123" || len(out.Roots) != 0 {
+		t.Errorf("expected the root to be the image shared by the clusters, got %q and %v", out.Root, out.Roots)
+	}
+	nodes := map[string]ChainNode{}
+	for _, node := range out.Nodes {
+		nodes[node.ID] = node
+	}
+	expectedNodes := []string{
+		"BuildConfig|test/app@production",
+		"BuildConfig|test/app@staging",
+		"ImageStreamTag|sha256:0123456789abcdef0123",
+		"ImageStreamTag|sha256:fedcba9876543210fedc",
+		"ImageStreamTag|test/app:latest@production",
+	}
+	if ids := sets.StringKeySet(nodes).List(); !reflect.DeepEqual(ids, expectedNodes) {
+		t.Errorf("expected the nodes %v, got %v", expectedNodes, ids)
+	}
+	expectedAliases := []TagAlias{{Cluster: "staging", Namespace: "test", Name: "base:latest"}, {Cluster: "production", Namespace: "test", Name: "base:prod"}}
+	if aliases := nodes["ImageStreamTag|sha256:0123456789abcdef0123"].Aliases; !reflect.DeepEqual(aliases, expectedAliases) {
+		t.Errorf("expected the aliases %#v, got %#v", expectedAliases, aliases)
+	}
+	if cluster := nodes["BuildConfig|test/app@production"].Cluster; cluster != "production" {
+		t.Errorf("expected the build config to be of production, got %q", cluster)
+	}
+	expectedEdges := []string{
+		"BuildConfig|test/app@production -> ImageStreamTag|test/app:latest@production",
+		"BuildConfig|test/app@staging -> ImageStreamTag|sha256:fedcba9876543210fedc",
+		"ImageStreamTag|sha256:0123456789abcdef0123 -> BuildConfig|test/app@production",
+		"ImageStreamTag|sha256:0123456789abcdef0123 -> BuildConfig|test/app@staging",
+	}
+	edges := []string{}
+	for _, edge := range out.Edges {
+		edges = append(edges, edge.From+" -> "+edge.To)
+	}
+	if !reflect.DeepEqual(edges, expectedEdges) {
+		t.Errorf("expected the edges %v, got %v", expectedEdges, edges)
+	}
+	if len(out.Warnings) != 2 || out.Warnings[0].Cluster != "staging" || out.Warnings[1].Cluster != "production" {
+		t.Errorf("expected a warning of each cluster, got %#v", out.Warnings)
+	}
+}
@@ -0,0 +1,29 @@
+package describe
+
+import "fmt"
+
+// NodeEnricher attaches custom metadata to the nodes of a build chain, such
+// as inventory IDs or vulnerability counts, so that sites can merge what they
+// know about their images into the json outputs without changing the
+// command. It may be called from several goroutines at once.
+type NodeEnricher interface {
+	// Enrich returns the metadata of node, nil when it has none.
+	Enrich(node ChainNode) (map[string]string, error)
+}
+
+// enrich sets the metadata of the nodes of out from the Enricher of d.
+func (d *ChainDescriber) enrich(out *ChainOutput) error {
+	if d.Enricher == nil {
+		return nil
+	}
+	for i := range out.Nodes {
+		metadata, err := d.Enricher.Enrich(out.Nodes[i])
+		if err != nil {
+			return fmt.Errorf("unable to enrich %s: %v", out.Nodes[i].ID, err)
+		}
+		if len(metadata) > 0 {
+			out.Nodes[i].Metadata = metadata
+		}
+	}
+	return nil
+}
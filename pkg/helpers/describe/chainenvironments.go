@@ -0,0 +1,64 @@
+package describe
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsv1 "github.com/openshift/api/apps/v1"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// addEnvironments adds to environments the values of label on the deployment
+// configs of dcs having available replicas, by the image stream tag node their
+// image change triggers follow. It returns the updated environments.
+func addEnvironments(environments map[osgraph.UniqueName]sets.String, dcs []appsv1.DeploymentConfig, label string) map[osgraph.UniqueName]sets.String {
+	if environments == nil {
+		environments = map[osgraph.UniqueName]sets.String{}
+	}
+	for _, dc := range dcs {
+		environment, ok := dc.Labels[label]
+		if !ok || len(environment) == 0 || dc.Status.AvailableReplicas == 0 {
+			continue
+		}
+		for _, ist := range triggeringImageStreamTags(&dc) {
+			name := imagegraph.ImageStreamTagNodeName(ist)
+			if environments[name] == nil {
+				environments[name] = sets.NewString()
+			}
+			environments[name].Insert(environment)
+		}
+	}
+	return environments
+}
+
+// environmentsOf returns the sorted environments node runs in, anonymized
+// like group values.
+func (d *ChainDescriber) environmentsOf(node graph.Node, anon anonymizer) []string {
+	ist, ok := node.(*imagegraph.ImageStreamTagNode)
+	if !ok {
+		return nil
+	}
+	environments := []string{}
+	for _, environment := range d.environments[ist.UniqueName()].List() {
+		environments = append(environments, anon.group(environment))
+	}
+	if len(environments) == 0 {
+		return nil
+	}
+	sort.Strings(environments)
+	return environments
+}
+
+// environmentsSuffix returns the environments node runs in as a suffix of its
+// label in the human-readable and ascii outputs.
+func (d *ChainDescriber) environmentsSuffix(node graph.Node, anon anonymizer) string {
+	environments := d.environmentsOf(node, anon)
+	if len(environments) == 0 {
+		return ""
+	}
+	return " [environments: " + strings.Join(environments, ", ") + "]"
+}
@@ -0,0 +1,79 @@
+package describe
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	buildedges "github.com/openshift/oc/pkg/helpers/graph/buildgraph"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// externalEdgeKinds are the kinds of the edges from the images of external
+// registries to the build configurations built from them. Image change
+// triggers don't follow those images, so their edges are kept whether or not
+// they trigger.
+var externalEdgeKinds = []string{buildedges.BuildInputImageEdgeKind, buildedges.BuildTriggerImageEdgeKind}
+
+// addExternalImages returns chain, a subgraph of g, along with the images of
+// external registries its build configurations are built from, as terminal
+// nodes since nothing in the cluster builds them. The build configurations of
+// cut don't get theirs when reverse is set, since their dependencies were
+// left out.
+func addExternalImages(g, chain osgraph.Graph, cut map[int]bool, reverse bool) osgraph.Graph {
+	nodes := chain.Nodes()
+	external := map[int]bool{}
+	for _, node := range chain.Nodes() {
+		if _, ok := node.(*buildgraph.BuildConfigNode); !ok || (reverse && cut[node.ID()]) {
+			continue
+		}
+		for _, from := range g.To(node) {
+			if _, ok := from.(*imagegraph.DockerImageRepositoryNode); !ok || external[from.ID()] {
+				continue
+			}
+			if g.EdgeKinds(g.Edge(from, node)).HasAny(externalEdgeKinds...) {
+				external[from.ID()] = true
+				nodes = append(nodes, from)
+			}
+		}
+	}
+	if len(external) == 0 {
+		return chain
+	}
+	return g.SubgraphWithNodes(nodes, func(out osgraph.Interface, from, to graph.Node, edgeKinds sets.String) bool {
+		if !osgraph.ExistingDirectEdge(out, from, to, edgeKinds) {
+			return false
+		}
+		if external[from.ID()] {
+			return edgeKinds.HasAny(externalEdgeKinds...)
+		}
+		return chain.Edge(from, to) != nil
+	})
+}
+
+// externalLabel returns the label of an image of an external registry in the
+// human-readable and ascii outputs.
+func externalLabel(node *imagegraph.DockerImageRepositoryNode, anon anonymizer) string {
+	return "image/" + anon.dockerImage(node.Ref)
+}
+
+// externalSuffix returns the images of external registries bc is built from
+// in g as a suffix of its label in the human-readable output, which only
+// walks the chain down from its roots.
+func externalSuffix(g osgraph.Graph, bc graph.Node, anon anonymizer) string {
+	images := []string{}
+	for _, from := range g.To(bc) {
+		if ref, ok := from.(*imagegraph.DockerImageRepositoryNode); ok {
+			images = append(images, anon.dockerImage(ref.Ref))
+		}
+	}
+	if len(images) == 0 {
+		return ""
+	}
+	sort.Strings(images)
+	return " [from: " + strings.Join(images, ", ") + "]"
+}
@@ -0,0 +1,140 @@
+package describe
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+// graphMLDocument is a GraphML document, as imported by yEd, Gephi and most
+// graph analysis tools.
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// graphMLKey declares an attribute of the nodes or of the edges.
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string            `xml:"id,attr"`
+	Data graphMLAttributes `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string            `xml:"id,attr"`
+	Source string            `xml:"source,attr"`
+	Target string            `xml:"target,attr"`
+	Data   graphMLAttributes `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// graphMLKeys are the attributes of the nodes and edges of the GraphML
+// output. Attributes without a value are left out of the nodes and edges.
+var graphMLKeys = []graphMLKey{
+	{ID: "kind", For: "node", Name: "kind", Type: "string"},
+	{ID: "namespace", For: "node", Name: "namespace", Type: "string"},
+	{ID: "name", For: "node", Name: "name", Type: "string"},
+	{ID: "tag", For: "node", Name: "tag", Type: "string"},
+	{ID: "environments", For: "node", Name: "environments", Type: "string"},
+	{ID: "truncated", For: "node", Name: "truncated", Type: "boolean"},
+	{ID: "root", For: "node", Name: "root", Type: "boolean"},
+	{ID: "custom", For: "node", Name: "custom", Type: "boolean"},
+	{ID: "kinds", For: "edge", Name: "kinds", Type: "string"},
+	{ID: "buildConfig", For: "edge", Name: "buildConfig", Type: "string"},
+	{ID: "edgeTag", For: "edge", Name: "tag", Type: "string"},
+	{ID: "cycle", For: "edge", Name: "cycle", Type: "boolean"},
+	{ID: "crossNamespace", For: "edge", Name: "crossNamespace", Type: "boolean"},
+}
+
+// graphML returns o as a GraphML document. Nodes are identified by their ID
+// in the json output and edges carry the name of the build config they come
+// from or lead to.
+func (o *ChainOutput) graphML(name string) (string, error) {
+	doc := &graphMLDocument{
+		XMLNS: graphMLNamespace,
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: name, EdgeDefault: "directed", Nodes: []graphMLNode{}, Edges: []graphMLEdge{}},
+	}
+	roots := map[string]bool{o.Root: len(o.Root) > 0}
+	for _, root := range o.Roots {
+		roots[root] = true
+	}
+	buildConfigs := map[string]string{}
+	for _, node := range o.Nodes {
+		n := graphMLNode{ID: node.ID}
+		n.Data.add("kind", node.Kind)
+		n.Data.add("namespace", node.Namespace)
+		n.Data.add("name", node.Name)
+		switch node.Kind {
+		case imagegraph.ImageStreamTagNodeKind:
+			if i := strings.LastIndex(node.Name, ":"); i >= 0 {
+				n.Data.add("tag", node.Name[i+1:])
+			}
+		case buildgraph.BuildConfigNodeKind:
+			buildConfigs[node.ID] = node.Name
+		}
+		n.Data.add("environments", strings.Join(node.Environments, ","))
+		n.Data.addBool("truncated", node.Truncated)
+		n.Data.addBool("root", roots[node.ID])
+		n.Data.addBool("custom", node.Custom)
+		doc.Graph.Nodes = append(doc.Graph.Nodes, n)
+	}
+	for i, edge := range o.Edges {
+		e := graphMLEdge{ID: "e" + strconv.Itoa(i), Source: edge.From, Target: edge.To}
+		e.Data.add("kinds", strings.Join(edge.Kinds, ","))
+		if bc, ok := buildConfigs[edge.From]; ok {
+			e.Data.add("buildConfig", bc)
+		} else {
+			e.Data.add("buildConfig", buildConfigs[edge.To])
+		}
+		e.Data.add("edgeTag", edge.Tag)
+		e.Data.addBool("cycle", edge.Cycle)
+		e.Data.addBool("crossNamespace", edge.CrossNamespace)
+		doc.Graph.Edges = append(doc.Graph.Edges, e)
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data), nil
+}
+
+// graphMLAttributes are the attributes of a node or an edge.
+type graphMLAttributes []graphMLData
+
+func (a *graphMLAttributes) add(key, value string) {
+	if len(value) > 0 {
+		*a = append(*a, graphMLData{Key: key, Value: value})
+	}
+}
+
+func (a *graphMLAttributes) addBool(key string, value bool) {
+	if value {
+		*a = append(*a, graphMLData{Key: key, Value: "true"})
+	}
+}
@@ -0,0 +1,164 @@
+package describe
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph/encoding/dot"
+	"github.com/gonum/graph/simple"
+
+	dotutil "github.com/openshift/oc/pkg/helpers/dot"
+	buildedges "github.com/openshift/oc/pkg/helpers/graph/buildgraph"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// ungrouped is the group of the build configurations missing the grouping label.
+const ungrouped = "<none>"
+
+// ChainGroups is a build chain aggregated by the value of a label of its
+// build configurations, typically the team owning them.
+type ChainGroups struct {
+	Label        string            `json:"label"`
+	Groups       []string          `json:"groups"`
+	Dependencies []GroupDependency `json:"dependencies"`
+	// Provenance records how the groups were computed, when requested.
+	Provenance *ChainProvenance `json:"provenance,omitempty"`
+}
+
+// GroupDependency counts the build configurations of group To depending on
+// images built by build configurations of group From.
+type GroupDependency struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Count int    `json:"count"`
+}
+
+// chainGroups aggregates the build configurations of g by the value of label.
+// A dependency exists between two groups when a build configuration of the
+// first one outputs an image stream tag used as input by a build configuration
+// of the second one. Dependencies within a group are not reported.
+func chainGroups(g osgraph.Graph, label string, a anonymizer) *ChainGroups {
+	groupOf := func(node *buildgraph.BuildConfigNode) string {
+		if value := node.BuildConfig.Labels[label]; len(value) > 0 {
+			return a.group(value)
+		}
+		return ungrouped
+	}
+
+	groups := map[string]bool{}
+	for _, node := range g.NodesByKind(buildgraph.BuildConfigNodeKind) {
+		groups[groupOf(node.(*buildgraph.BuildConfigNode))] = true
+	}
+
+	producers := map[*imagegraph.ImageStreamTagNode][]*buildgraph.BuildConfigNode{}
+	consumers := map[*imagegraph.ImageStreamTagNode][]*buildgraph.BuildConfigNode{}
+	for _, e := range g.Edges() {
+		switch from := e.From().(type) {
+		case *buildgraph.BuildConfigNode:
+			if ist, ok := e.To().(*imagegraph.ImageStreamTagNode); ok && g.EdgeKinds(e).Has(buildedges.BuildOutputEdgeKind) {
+				producers[ist] = append(producers[ist], from)
+			}
+		case *imagegraph.ImageStreamTagNode:
+			if bc, ok := e.To().(*buildgraph.BuildConfigNode); ok {
+				consumers[from] = append(consumers[from], bc)
+			}
+		}
+	}
+
+	type pair struct{ from, to string }
+	counted := map[pair]map[*buildgraph.BuildConfigNode]bool{}
+	for ist, bcs := range consumers {
+		for _, producer := range producers[ist] {
+			for _, consumer := range bcs {
+				p := pair{from: groupOf(producer), to: groupOf(consumer)}
+				if p.from == p.to {
+					continue
+				}
+				if counted[p] == nil {
+					counted[p] = map[*buildgraph.BuildConfigNode]bool{}
+				}
+				counted[p][consumer] = true
+			}
+		}
+	}
+
+	out := &ChainGroups{Label: label, Groups: []string{}, Dependencies: []GroupDependency{}}
+	for group := range groups {
+		out.Groups = append(out.Groups, group)
+	}
+	sort.Strings(out.Groups)
+	for p, bcs := range counted {
+		out.Dependencies = append(out.Dependencies, GroupDependency{From: p.from, To: p.to, Count: len(bcs)})
+	}
+	sort.Slice(out.Dependencies, func(i, j int) bool {
+		if out.Dependencies[i].From != out.Dependencies[j].From {
+			return out.Dependencies[i].From < out.Dependencies[j].From
+		}
+		return out.Dependencies[i].To < out.Dependencies[j].To
+	})
+	return out
+}
+
+// humanReadable lists every group followed by the groups depending on it.
+func (c *ChainGroups) humanReadable() string {
+	lines := []string{}
+	for _, group := range c.Groups {
+		lines = append(lines, group)
+		for _, dep := range c.Dependencies {
+			if dep.From == group {
+				lines = append(lines, fmt.Sprintf("\t%s (%d build configs)", dep.To, dep.Count))
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *ChainGroups) marshal() (string, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// dotGraph renders the groups as a DOT graph named name, edges being labeled with
+// the number of dependent build configurations.
+func (c *ChainGroups) dotGraph(name string) (string, error) {
+	g := simple.NewDirectedGraph(0, 0)
+	nodes := map[string]groupNode{}
+	for _, group := range c.Groups {
+		n := groupNode{Node: simple.Node(g.NewNodeID()), name: group}
+		g.AddNode(n)
+		nodes[group] = n
+	}
+	for _, dep := range c.Dependencies {
+		g.SetEdge(groupEdge{Edge: simple.Edge{F: nodes[dep.From], T: nodes[dep.To]}, count: dep.Count})
+	}
+	data, err := dot.Marshal(g, dotutil.Quote(name), "", "  ", false)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+type groupNode struct {
+	simple.Node
+	name string
+}
+
+func (n groupNode) DOTAttributes() []dot.Attribute {
+	return []dot.Attribute{{Key: "label", Value: fmt.Sprintf("%q", n.name)}}
+}
+
+type groupEdge struct {
+	simple.Edge
+	count int
+}
+
+func (e groupEdge) DOTAttributes() []dot.Attribute {
+	return []dot.Attribute{{Key: "label", Value: fmt.Sprintf("%d", e.count)}}
+}
@@ -0,0 +1,135 @@
+package describe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/gonum/graph"
+
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+	"github.com/openshift/oc/pkg/helpers/parallel"
+)
+
+// ImageDetails describe the image an image stream tag currently points to.
+type ImageDetails struct {
+	// Digest is the digest of the image, e.g. sha256:<hex>.
+	Digest string `json:"digest"`
+	// Created is when the tag was last updated to point to the image.
+	Created time.Time `json:"created"`
+	// Size is the size of the image in bytes, zero when unknown.
+	Size int64 `json:"size,omitempty"`
+}
+
+// String returns the short digest, the creation time and the size of the
+// image.
+func (i ImageDetails) String() string {
+	parts := []string{shortDigest(i.Digest)}
+	if !i.Created.IsZero() {
+		parts = append(parts, "created "+i.Created.UTC().Format(time.RFC3339))
+	}
+	if i.Size > 0 {
+		parts = append(parts, units.HumanSize(float64(i.Size)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortDigest returns digest with its hex part shortened to 12 characters.
+func shortDigest(digest string) string {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || len(hex) <= 12 {
+		return digest
+	}
+	return algorithm + ":" + hex[:12]
+}
+
+// ImageInspector tells which image image stream tags point to. It may be
+// called from several goroutines at once.
+type ImageInspector interface {
+	// InspectImageStreamTag returns the image of the image stream tag name in
+	// namespace, nil when the tag doesn't point to an image yet.
+	InspectImageStreamTag(ctx context.Context, namespace, name string) (*ImageDetails, error)
+}
+
+// inspectImages looks up the images of the image stream tags of g that
+// weren't inspected yet with the Inspector of d. Images are cached so that
+// they can be shared by the chains of a describer.
+func (d *ChainDescriber) inspectImages(g osgraph.Graph) error {
+	if d.Inspector == nil {
+		return nil
+	}
+	ists := []*imagegraph.ImageStreamTagNode{}
+	d.inspectedLock.Lock()
+	if d.inspected == nil {
+		d.inspected = map[osgraph.UniqueName]*ImageDetails{}
+	}
+	for _, node := range g.Nodes() {
+		if ist, ok := node.(*imagegraph.ImageStreamTagNode); ok {
+			if _, ok := d.inspected[ist.UniqueName()]; !ok {
+				ists = append(ists, ist)
+			}
+		}
+	}
+	d.inspectedLock.Unlock()
+
+	inspections := []func() error{}
+	for _, ist := range ists {
+		ist := ist
+		inspections = append(inspections, func() error {
+			details, err := d.Inspector.InspectImageStreamTag(context.TODO(), ist.Namespace, ist.Name)
+			if err != nil {
+				return fmt.Errorf("unable to inspect the image of %s/%s: %v", ist.Namespace, ist.Name, err)
+			}
+			d.inspectedLock.Lock()
+			defer d.inspectedLock.Unlock()
+			d.inspected[ist.UniqueName()] = details
+			return nil
+		})
+	}
+	if errs := parallel.RunLimited(d.Concurrency, inspections...); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// addImageDetails sets the images of the nodes of out, the machine readable
+// form of g.
+func (d *ChainDescriber) addImageDetails(out *ChainOutput, g osgraph.Graph, anon anonymizer) {
+	if d.Inspector == nil {
+		return
+	}
+	byID := map[string]*ImageDetails{}
+	for _, node := range g.Nodes() {
+		if details := d.imageDetailsOf(node); details != nil {
+			byID[anon.nodeID(node)] = details
+		}
+	}
+	for i := range out.Nodes {
+		out.Nodes[i].Image = byID[out.Nodes[i].ID]
+	}
+}
+
+// imageDetailsOf returns the image of node, nil when it isn't an image stream
+// tag or doesn't point to an image.
+func (d *ChainDescriber) imageDetailsOf(node graph.Node) *ImageDetails {
+	ist, ok := node.(*imagegraph.ImageStreamTagNode)
+	if !ok || d.Inspector == nil {
+		return nil
+	}
+	d.inspectedLock.Lock()
+	defer d.inspectedLock.Unlock()
+	return d.inspected[ist.UniqueName()]
+}
+
+// imageDetailsSuffix returns the image of node as a suffix of its label in
+// the human-readable and ascii outputs.
+func (d *ChainDescriber) imageDetailsSuffix(node graph.Node) string {
+	details := d.imageDetailsOf(node)
+	if details == nil {
+		return ""
+	}
+	return " [image: " + details.String() + "]"
+}
@@ -0,0 +1,32 @@
+package describe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// listOutput returns the image stream tags of g in build order, one
+// namespace/name:tag per line, or its image streams with HideTags: every
+// image stream tag comes after the ones it is built from, so that scripts can rebuild them in turn. Ties are
+// broken by name and cycles are broken as in the ascii output.
+func listOutput(g osgraph.Graph, anon anonymizer) string {
+	order := topologicalOrder(g, func(nodes []graph.Node) {
+		sort.Slice(nodes, func(i, j int) bool { return anon.nodeID(nodes[i]) < anon.nodeID(nodes[j]) })
+	})
+	lines := []string{}
+	for _, node := range order {
+		switch t := node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			lines = append(lines, fmt.Sprintf("%s/%s", anon.namespace(t.Namespace), anon.imageStreamTagName(t.Name)))
+		case *imagegraph.ImageStreamNode:
+			lines = append(lines, fmt.Sprintf("%s/%s", anon.namespace(t.Namespace), anon.imageStreamName(t.Name)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
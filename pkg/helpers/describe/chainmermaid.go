@@ -0,0 +1,87 @@
+package describe
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// mermaidEscaper replaces the characters that end a Mermaid label with their
+// entity codes.
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "#", "#35;")
+
+// mermaidOutput returns g as a Mermaid flowchart, which renders in markdown
+// where DOT doesn't. Image stream tags are rounded boxes and build configs
+// rectangles, deployment configs hexagons, labeled as in the ascii output. Manual dependencies are dotted,
+// the edges of cycles red, the flagged edges between namespaces blue and the
+// nodes of cut dashed.
+func (d *ChainDescriber) mermaidOutput(g osgraph.Graph, namer osgraph.Namer, anon anonymizer, cut map[int]bool) string {
+	nodes := g.Nodes()
+	sort.Slice(nodes, func(i, j int) bool { return anon.nodeID(nodes[i]) < anon.nodeID(nodes[j]) })
+	ids := map[int]string{}
+	lines := []string{"flowchart TD"}
+	truncated := []string{}
+	for i, node := range nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.ID()] = id
+		label := mermaidEscaper.Replace(d.asciiLabel(node, namer, anon))
+		switch node.(type) {
+		case *buildgraph.BuildConfigNode:
+			lines = append(lines, fmt.Sprintf("  %s[\"%s\"]", id, label))
+		case *appsgraph.DeploymentConfigNode:
+			lines = append(lines, fmt.Sprintf("  %s{{\"%s\"}}", id, label))
+		default:
+			lines = append(lines, fmt.Sprintf("  %s(\"%s\")", id, label))
+		}
+		if cut[node.ID()] {
+			truncated = append(truncated, id)
+		}
+	}
+
+	edges := g.Edges()
+	sort.Slice(edges, func(i, j int) bool {
+		fi, fj := ids[edges[i].From().ID()], ids[edges[j].From().ID()]
+		if fi != fj {
+			return fi < fj
+		}
+		return ids[edges[i].To().ID()] < ids[edges[j].To().ID()]
+	})
+	cycles := cycleEdges(g)
+	red, blue := []string{}, []string{}
+	for i, e := range edges {
+		labels := []string{}
+		if tag := edgeTag(e); d.SplitByTag && len(tag) > 0 {
+			labels = append(labels, tag)
+		}
+		arrow := "-->"
+		if d.manual(g, e) {
+			arrow = "-.->"
+			labels = append(labels, "manual")
+		}
+		if len(labels) > 0 {
+			arrow += fmt.Sprintf("|\"%s\"|", mermaidEscaper.Replace(strings.Join(labels, ", ")))
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s", ids[e.From().ID()], arrow, ids[e.To().ID()]))
+		switch {
+		case cycles[edgeKey(e)]:
+			red = append(red, fmt.Sprintf("%d", i))
+		case d.FlagCrossNamespace && crossesNamespaces(e):
+			blue = append(blue, fmt.Sprintf("%d", i))
+		}
+	}
+
+	if len(blue) > 0 {
+		lines = append(lines, fmt.Sprintf("  linkStyle %s stroke:blue", strings.Join(blue, ",")))
+	}
+	if len(red) > 0 {
+		lines = append(lines, fmt.Sprintf("  linkStyle %s stroke:red", strings.Join(red, ",")))
+	}
+	if len(truncated) > 0 {
+		lines = append(lines, "  classDef truncated stroke-dasharray:5 5", fmt.Sprintf("  class %s truncated", strings.Join(truncated, ",")))
+	}
+	return strings.Join(lines, "\n")
+}
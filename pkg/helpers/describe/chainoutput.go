@@ -0,0 +1,241 @@
+package describe
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// ChainOutput is the machine readable representation of a build chain.
+type ChainOutput struct {
+	// Root is the ID of the image stream tag the chain was computed for.
+	Root string `json:"root,omitempty"`
+	// Roots are the IDs of the image stream tags a merged chain was computed for.
+	Roots []string    `json:"roots,omitempty"`
+	Nodes []ChainNode `json:"nodes"`
+	Edges []ChainEdge `json:"edges"`
+	// Provenance records how the chain was computed, when requested.
+	Provenance *ChainProvenance `json:"provenance,omitempty"`
+	// Warnings are the problems found in the chain, identified by stable
+	// codes.
+	Warnings []ChainWarning `json:"warnings,omitempty"`
+}
+
+// ChainNode is an image stream tag, a build config, a deployment config or an
+// image of an external registry taking part in a build chain. External images
+// have no namespace, their name is their pull spec.
+type ChainNode struct {
+	// ID uniquely identifies the node in the chain, edges refer to nodes by ID.
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Environments are the values of the environment label of the running
+	// deployment configs an image stream tag triggers.
+	Environments []string `json:"environments,omitempty"`
+	// Truncated is set when dependencies of the node were left out of the
+	// chain because of MaxDepth.
+	Truncated bool `json:"truncated,omitempty"`
+	// Metadata is what the NodeEnricher of the describer attached to the
+	// node, when set.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Vulnerabilities are the known vulnerabilities of the image of an image
+	// stream tag, when scanned.
+	Vulnerabilities *VulnerabilityCounts `json:"vulnerabilities,omitempty"`
+	// Image is the image an image stream tag points to, when inspected.
+	Image *ImageDetails `json:"image,omitempty"`
+	// Custom is set on build configs using the Custom strategy, whose input
+	// image is the builder image running their builds rather than a base
+	// image of their output.
+	Custom bool `json:"custom,omitempty"`
+	// Cluster is the kubeconfig context of the cluster the node was found
+	// in, when merging the chains of several clusters.
+	Cluster string `json:"cluster,omitempty"`
+	// Aliases are the image stream tags of every cluster pointing to the
+	// image of an image stream tag, when merging the chains of several
+	// clusters.
+	Aliases []TagAlias `json:"aliases,omitempty"`
+}
+
+// ChainEdge is a dependency between two nodes of a build chain.
+type ChainEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kinds []string `json:"kinds"`
+	// Tag is the image stream tag the dependency goes through.
+	Tag string `json:"tag,omitempty"`
+	// BuildConfig describes the build config the dependency leads to or
+	// comes from. It is left out of anonymized output.
+	BuildConfig *EdgeBuildConfig `json:"buildConfig,omitempty"`
+	// Cycle is set when the dependency is part of a cycle between build
+	// configs, which can't all be rebuilt in order.
+	Cycle bool `json:"cycle,omitempty"`
+	// CrossNamespace is set, when requested, on dependencies between nodes of
+	// different namespaces.
+	CrossNamespace bool `json:"crossNamespace,omitempty"`
+}
+
+// ChainRecord is a node of a build chain along with the dependencies leading
+// from it, a line of the ndjson output, so that chains can be streamed and
+// stored line by line.
+type ChainRecord struct {
+	Node ChainNode `json:"node"`
+	// Root is set on the image stream tags the chain was computed for.
+	Root  bool        `json:"root,omitempty"`
+	Edges []ChainEdge `json:"edges"`
+}
+
+// EdgeBuildConfig holds what a tool planning rebuilds needs to know about a
+// build config: how it builds, from which repository and where it pushes to.
+type EdgeBuildConfig struct {
+	Strategy string `json:"strategy"`
+	GitURI   string `json:"gitURI,omitempty"`
+	GitRef   string `json:"gitRef,omitempty"`
+	// OutputKind is ImageStreamTag, ImageStreamImage or DockerImage.
+	OutputKind string `json:"outputKind,omitempty"`
+	// Output is the namespace/name of the image stream tag or image, or the
+	// pull spec of the image, the build pushes to.
+	Output string `json:"output,omitempty"`
+}
+
+// chainOutput converts the partitioned graph into its machine readable form,
+// with the environments the nodes run in and whether their dependencies were
+// cut off, flagging the edges between namespaces when flagCrossNamespace is
+// set. Images of external registries are only listed when includeExternal is
+// set. Nodes and edges are sorted so that the output is stable across runs.
+func chainOutput(g osgraph.Graph, roots []graph.Node, a anonymizer, environments func(graph.Node) []string, cut map[int]bool, flagCrossNamespace, includeExternal bool) *ChainOutput {
+	out := &ChainOutput{
+		Nodes: []ChainNode{},
+		Edges: []ChainEdge{},
+	}
+	if len(roots) == 1 {
+		out.Root = a.nodeID(roots[0])
+	} else {
+		for _, root := range roots {
+			out.Roots = append(out.Roots, a.nodeID(root))
+		}
+	}
+	for _, node := range g.Nodes() {
+		switch t := node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			out.Nodes = append(out.Nodes, ChainNode{ID: a.nodeID(t), Kind: imagegraph.ImageStreamTagNodeKind, Namespace: a.namespace(t.Namespace), Name: a.imageStreamTagName(t.Name), Environments: environments(t), Truncated: cut[t.ID()]})
+		case *imagegraph.ImageStreamNode:
+			out.Nodes = append(out.Nodes, ChainNode{ID: a.nodeID(t), Kind: imagegraph.ImageStreamNodeKind, Namespace: a.namespace(t.Namespace), Name: a.imageStreamName(t.Name), Truncated: cut[t.ID()]})
+		case *buildgraph.BuildConfigNode:
+			out.Nodes = append(out.Nodes, ChainNode{ID: a.nodeID(t), Kind: buildgraph.BuildConfigNodeKind, Namespace: a.namespace(t.BuildConfig.Namespace), Name: a.buildConfigName(t.BuildConfig.Name), Truncated: cut[t.ID()], Custom: isCustomBuild(t)})
+		case *appsgraph.DeploymentConfigNode:
+			out.Nodes = append(out.Nodes, ChainNode{ID: a.nodeID(t), Kind: appsgraph.DeploymentConfigNodeKind, Namespace: a.namespace(t.DeploymentConfig.Namespace), Name: a.deploymentConfigName(t.DeploymentConfig.Name)})
+		case *imagegraph.DockerImageRepositoryNode:
+			if !includeExternal {
+				continue
+			}
+			out.Nodes = append(out.Nodes, ChainNode{ID: a.nodeID(t), Kind: imagegraph.DockerRepositoryNodeKind, Name: a.dockerImage(t.Ref)})
+		}
+	}
+	cycles := cycleEdges(g)
+	for _, e := range g.Edges() {
+		out.Edges = append(out.Edges, ChainEdge{
+			From:  a.nodeID(e.From()),
+			To:    a.nodeID(e.To()),
+			Kinds: g.EdgeKinds(e).List(),
+			Tag:   edgeTag(e),
+
+			BuildConfig:    edgeBuildConfig(e, a),
+			Cycle:          cycles[edgeKey(e)],
+			CrossNamespace: flagCrossNamespace && crossesNamespaces(e),
+		})
+	}
+	sort.Slice(out.Nodes, func(i, j int) bool { return out.Nodes[i].ID < out.Nodes[j].ID })
+	sort.Slice(out.Edges, func(i, j int) bool {
+		if out.Edges[i].From != out.Edges[j].From {
+			return out.Edges[i].From < out.Edges[j].From
+		}
+		return out.Edges[i].To < out.Edges[j].To
+	})
+	return out
+}
+
+func (o *ChainOutput) marshal() (string, error) {
+	data, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ndjson returns a record for every node of o, one json object per line
+// sorted by ID.
+func (o *ChainOutput) ndjson() (string, error) {
+	roots := sets.NewString(o.Roots...)
+	if len(o.Root) > 0 {
+		roots.Insert(o.Root)
+	}
+	edges := map[string][]ChainEdge{}
+	for _, e := range o.Edges {
+		edges[e.From] = append(edges[e.From], e)
+	}
+	lines := []string{}
+	for _, node := range o.Nodes {
+		record := ChainRecord{Node: node, Root: roots.Has(node.ID), Edges: edges[node.ID]}
+		if record.Edges == nil {
+			record.Edges = []ChainEdge{}
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, string(data))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// edgeBuildConfig describes the build config the edge starts from or leads to.
+func edgeBuildConfig(e graph.Edge, a anonymizer) *EdgeBuildConfig {
+	if a.enabled {
+		return nil
+	}
+	bcNode, ok := e.From().(*buildgraph.BuildConfigNode)
+	if !ok {
+		if bcNode, ok = e.To().(*buildgraph.BuildConfigNode); !ok {
+			return nil
+		}
+	}
+	bc := bcNode.BuildConfig
+	out := &EdgeBuildConfig{Strategy: strategyType(bc.Spec.Strategy)}
+	if git := bc.Spec.Source.Git; git != nil {
+		out.GitURI = git.URI
+		out.GitRef = git.Ref
+	}
+	if to := bc.Spec.Output.To; to != nil {
+		out.OutputKind = to.Kind
+		out.Output = to.Name
+		if to.Kind != "DockerImage" {
+			namespace := to.Namespace
+			if len(namespace) == 0 {
+				namespace = bc.Namespace
+			}
+			out.Output = namespace + "/" + to.Name
+		}
+	}
+	return out
+}
+
+// edgeTag returns the tag of the image stream tag the edge starts from or leads to.
+func edgeTag(e graph.Edge) string {
+	if ist, ok := e.From().(*imagegraph.ImageStreamTagNode); ok {
+		return ist.ImageTag()
+	}
+	if ist, ok := e.To().(*imagegraph.ImageStreamTagNode); ok {
+		return ist.ImageTag()
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package describe
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChainProvenance records how a build chain was computed, so that archived
+// json outputs can be audited and computed again.
+type ChainProvenance struct {
+	// ClientVersion is the version of oc that computed the chain.
+	ClientVersion string `json:"clientVersion"`
+	// ServerVersion is the version of the cluster, when it could be read.
+	ServerVersion string `json:"serverVersion,omitempty"`
+	// User is the user that computed the chain, when it could be read. It is
+	// left out of anonymized output.
+	User string `json:"user,omitempty"`
+	// Namespaces are the namespaces the build configs were looked up in.
+	Namespaces []string `json:"namespaces"`
+	// AllNamespaces is set when the namespaces were listed with --all.
+	AllNamespaces bool `json:"allNamespaces,omitempty"`
+	// Flags are the flags set on the command line, as --name=value. Only
+	// their names are kept in anonymized output.
+	Flags []string `json:"flags,omitempty"`
+	// ContentHash is the sha256 of the json output without its provenance,
+	// which stays the same as long as the chain doesn't change.
+	ContentHash string `json:"contentHash"`
+}
+
+// provenance returns Provenance completed with the hash of the json of v, an
+// output without provenance, or nil when Provenance isn't set.
+func (d *ChainDescriber) provenance(v interface{}, anon anonymizer) (*ChainProvenance, error) {
+	if d.Provenance == nil {
+		return nil, nil
+	}
+	content, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	p := *d.Provenance
+	p.ContentHash = fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+	if !anon.enabled {
+		return &p, nil
+	}
+	p.User = ""
+	p.Namespaces = make([]string, 0, len(d.Provenance.Namespaces))
+	for _, namespace := range d.Provenance.Namespaces {
+		p.Namespaces = append(p.Namespaces, anon.namespace(namespace))
+	}
+	p.Flags = nil
+	for _, flag := range d.Provenance.Flags {
+		name, _, _ := strings.Cut(flag, "=")
+		p.Flags = append(p.Flags, name)
+	}
+	return &p, nil
+}
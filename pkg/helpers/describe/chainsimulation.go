@@ -0,0 +1,152 @@
+package describe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gonum/graph"
+
+	buildedges "github.com/openshift/oc/pkg/helpers/graph/buildgraph"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// Simulation is the builds that a change of the roots of a build chain
+// would trigger, grouped in stages: the builds of a stage are triggered
+// together, once the builds of the previous stages pushed their images.
+type Simulation struct {
+	Builds int               `json:"builds"`
+	Stages []SimulationStage `json:"stages"`
+}
+
+// SimulationStage is the builds of a simulation running in parallel.
+type SimulationStage struct {
+	Builds []SimulatedBuild `json:"builds"`
+}
+
+// SimulatedBuild is a build of a build config triggered by a change of the
+// image stream tags it follows.
+type SimulatedBuild struct {
+	ID string `json:"id"`
+	// TriggeredBy are the image stream tags whose change triggers the build.
+	TriggeredBy []string `json:"triggeredBy"`
+
+	node     graph.Node
+	triggers []graph.Node
+}
+
+// simulate returns the builds of g triggered, directly or through the
+// images pushed by other builds, by a change of roots. A build config runs
+// in the stage after the last of the builds it waits for. Only image change
+// triggers are followed and the edges closing cycles are ignored.
+func (d *ChainDescriber) simulate(g osgraph.Graph, roots []graph.Node, anon anonymizer) *Simulation {
+	order := topologicalOrder(g, func(nodes []graph.Node) {
+		sort.Slice(nodes, func(i, j int) bool { return anon.nodeID(nodes[i]) < anon.nodeID(nodes[j]) })
+	})
+	position := map[int]int{}
+	for i, node := range order {
+		position[node.ID()] = i
+	}
+
+	// stage is the stage a build config runs in, or the stage after which an
+	// image stream tag changes, 0 for the roots
+	stage, reached := map[int]int{}, map[int]bool{}
+	triggers := map[int][]graph.Node{}
+	for _, root := range roots {
+		reached[root.ID()] = true
+	}
+	follow := func(from, to graph.Node, next int) bool {
+		if position[to.ID()] <= position[from.ID()] {
+			return false
+		}
+		reached[to.ID()] = true
+		if next > stage[to.ID()] {
+			stage[to.ID()] = next
+		}
+		return true
+	}
+	builds := []graph.Node{}
+	for _, node := range order {
+		if !reached[node.ID()] {
+			continue
+		}
+		switch node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			for _, bc := range g.SuccessorNodesByEdgeKind(node, buildedges.BuildTriggerImageEdgeKind) {
+				if follow(node, bc, stage[node.ID()]+1) {
+					triggers[bc.ID()] = append(triggers[bc.ID()], node)
+				}
+			}
+		case *buildgraph.BuildConfigNode:
+			builds = append(builds, node)
+			for _, ist := range g.SuccessorNodesByEdgeKind(node, buildedges.BuildOutputEdgeKind) {
+				follow(node, ist, stage[node.ID()])
+			}
+		}
+	}
+
+	simulation := &Simulation{Builds: len(builds), Stages: []SimulationStage{}}
+	for _, node := range builds {
+		for len(simulation.Stages) < stage[node.ID()] {
+			simulation.Stages = append(simulation.Stages, SimulationStage{Builds: []SimulatedBuild{}})
+		}
+		build := SimulatedBuild{ID: anon.nodeID(node), TriggeredBy: []string{}, node: node, triggers: triggers[node.ID()]}
+		for _, trigger := range build.triggers {
+			build.TriggeredBy = append(build.TriggeredBy, anon.nodeID(trigger))
+		}
+		s := &simulation.Stages[stage[node.ID()]-1]
+		s.Builds = append(s.Builds, build)
+	}
+	for _, s := range simulation.Stages {
+		sort.SliceStable(s.Builds, func(i, j int) bool { return s.Builds[i].ID < s.Builds[j].ID })
+	}
+	return simulation
+}
+
+// describeSimulation returns the simulated builds of the chain of name in
+// the requested format.
+func (d *ChainDescriber) describeSimulation(simulation *Simulation, name string, namer osgraph.Namer, anon anonymizer) (string, error) {
+	switch d.outputFormat {
+	case "json":
+		data, err := json.MarshalIndent(simulation, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case "":
+		return d.simulationHumanReadable(simulation, name, namer, anon), nil
+	}
+	return "", fmt.Errorf("unknown specified format %q", d.outputFormat)
+}
+
+func (d *ChainDescriber) simulationHumanReadable(simulation *Simulation, name string, namer osgraph.Namer, anon anonymizer) string {
+	out := &bytes.Buffer{}
+	if simulation.Builds == 0 {
+		fmt.Fprintf(out, "A change of %s doesn't trigger any build\n", name)
+		return out.String()
+	}
+	fmt.Fprintf(out, "A change of %s triggers %d builds in %d stages\n", name, simulation.Builds, len(simulation.Stages))
+	for i, s := range simulation.Stages {
+		switch len(s.Builds) {
+		case 1:
+			fmt.Fprintf(out, "Stage %d:\n", i+1)
+		default:
+			fmt.Fprintf(out, "Stage %d, %d builds in parallel:\n", i+1, len(s.Builds))
+		}
+		w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+		for _, build := range s.Builds {
+			labels := []string{}
+			for _, trigger := range build.triggers {
+				labels = append(labels, d.asciiLabel(trigger, namer, anon))
+			}
+			fmt.Fprintf(w, "  %s\ttriggered by %s\n", d.asciiLabel(build.node, namer, anon), strings.Join(labels, ", "))
+		}
+		w.Flush()
+	}
+	return out.String()
+}
@@ -0,0 +1,207 @@
+package describe
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gonum/graph"
+
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+const (
+	spdxVersion     = "SPDX-2.3"
+	spdxDocumentID  = "SPDXRef-DOCUMENT"
+	spdxNoAssertion = "NOASSERTION"
+	// spdxNamespaceBase prefixes the namespace of the documents, which is
+	// made unique by the hash of their content.
+	spdxNamespaceBase = "https://openshift.io/spdx/build-chain/"
+)
+
+// spdxInvalidIDChars matches the characters SPDX doesn't allow in element IDs.
+var spdxInvalidIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdxDocument is a relationship-only SPDX document: it lists the images and
+// build configs of a chain as packages, without any file or license
+// information, and how they depend on each other.
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string `json:"SPDXID"`
+	Name                  string `json:"name"`
+	DownloadLocation      string `json:"downloadLocation"`
+	FilesAnalyzed         bool   `json:"filesAnalyzed"`
+	PrimaryPackagePurpose string `json:"primaryPackagePurpose"`
+	Comment               string `json:"comment,omitempty"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+	Comment            string `json:"comment,omitempty"`
+}
+
+// spdxOutput returns g as an SPDX document in JSON. Images are packages
+// GENERATED_FROM the images their build config builds from, and build configs
+// are packages BUILD_TOOL_OF the images they push to. Deployment configs are
+// packages DEPENDS_ON the images that trigger them. The document DESCRIBES
+// the roots of the chain. Relationships that are part of a cycle between build
+// configs are commented as such. Images of external registries are only
+// packages when includeExternal is set.
+func spdxOutput(g osgraph.Graph, roots []graph.Node, name string, a anonymizer, created time.Time, includeExternal bool) (string, error) {
+	doc := &spdxDocument{
+		SPDXVersion: spdxVersion,
+		DataLicense: "CC0-1.0",
+		SPDXID:      spdxDocumentID,
+		Name:        name,
+		CreationInfo: spdxCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: oc-build-chain"},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	nodes := g.Nodes()
+	sort.Slice(nodes, func(i, j int) bool { return a.nodeID(nodes[i]) < a.nodeID(nodes[j]) })
+	ids := map[int]string{}
+	used := map[string]bool{}
+	for _, node := range nodes {
+		pkg := spdxPackage{DownloadLocation: spdxNoAssertion}
+		switch t := node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			pkg.Name = a.namespace(t.Namespace) + "/" + a.imageStreamTagName(t.Name)
+			pkg.PrimaryPackagePurpose = "CONTAINER"
+		case *imagegraph.ImageStreamNode:
+			pkg.Name = a.namespace(t.Namespace) + "/" + a.imageStreamName(t.Name)
+			pkg.PrimaryPackagePurpose = "CONTAINER"
+		case *buildgraph.BuildConfigNode:
+			pkg.Name = a.namespace(t.BuildConfig.Namespace) + "/" + a.buildConfigName(t.BuildConfig.Name)
+			pkg.PrimaryPackagePurpose = "OTHER"
+			pkg.Comment = "build config"
+		case *appsgraph.DeploymentConfigNode:
+			pkg.Name = a.namespace(t.DeploymentConfig.Namespace) + "/" + a.deploymentConfigName(t.DeploymentConfig.Name)
+			pkg.PrimaryPackagePurpose = "APPLICATION"
+			pkg.Comment = "deployment config"
+		case *imagegraph.DockerImageRepositoryNode:
+			if !includeExternal {
+				continue
+			}
+			pkg.Name = a.dockerImage(t.Ref)
+			pkg.PrimaryPackagePurpose = "CONTAINER"
+			pkg.Comment = "external image"
+		default:
+			continue
+		}
+		pkg.SPDXID = spdxID(a.nodeID(node), used)
+		ids[node.ID()] = pkg.SPDXID
+		doc.Packages = append(doc.Packages, pkg)
+	}
+
+	for _, root := range roots {
+		if id, ok := ids[root.ID()]; ok {
+			doc.Relationships = append(doc.Relationships, spdxRelationship{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: id})
+		}
+	}
+	cycles := cycleEdges(g)
+	comment := func(inCycle bool) string {
+		if inCycle {
+			return "cycle"
+		}
+		return ""
+	}
+	relationships := []spdxRelationship{}
+	for _, node := range nodes {
+		if dc, ok := node.(*appsgraph.DeploymentConfigNode); ok {
+			for _, from := range g.To(dc) {
+				if id, ok := ids[from.ID()]; ok {
+					relationships = append(relationships, spdxRelationship{SPDXElementID: ids[dc.ID()], RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id})
+				}
+			}
+			continue
+		}
+		bc, ok := node.(*buildgraph.BuildConfigNode)
+		if !ok {
+			continue
+		}
+		outputs, inputs := []graph.Node{}, []graph.Node{}
+		for _, to := range g.From(bc) {
+			if _, ok := ids[to.ID()]; ok {
+				outputs = append(outputs, to)
+			}
+		}
+		for _, from := range g.To(bc) {
+			if _, ok := ids[from.ID()]; ok {
+				inputs = append(inputs, from)
+			}
+		}
+		for _, output := range outputs {
+			outputCycle := cycles[[2]int{bc.ID(), output.ID()}]
+			relationships = append(relationships, spdxRelationship{SPDXElementID: ids[bc.ID()], RelationshipType: "BUILD_TOOL_OF", RelatedSPDXElement: ids[output.ID()], Comment: comment(outputCycle)})
+			for _, input := range inputs {
+				inputCycle := outputCycle && cycles[[2]int{input.ID(), bc.ID()}]
+				relationships = append(relationships, spdxRelationship{SPDXElementID: ids[output.ID()], RelationshipType: "GENERATED_FROM", RelatedSPDXElement: ids[input.ID()], Comment: comment(inputCycle)})
+			}
+		}
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		ri, rj := relationships[i], relationships[j]
+		if ri.SPDXElementID != rj.SPDXElementID {
+			return ri.SPDXElementID < rj.SPDXElementID
+		}
+		if ri.RelationshipType != rj.RelationshipType {
+			return ri.RelationshipType < rj.RelationshipType
+		}
+		return ri.RelatedSPDXElement < rj.RelatedSPDXElement
+	})
+	doc.Relationships = append(doc.Relationships, relationships...)
+
+	// the namespace only depends on the content, so that describing the same
+	// chain twice yields the same document
+	content, err := json.Marshal([]interface{}{doc.Name, doc.Packages, doc.Relationships})
+	if err != nil {
+		return "", err
+	}
+	doc.DocumentNamespace = fmt.Sprintf("%s%s-%x", spdxNamespaceBase, spdxInvalidIDChars.ReplaceAllString(name, "-"), sha256.Sum256(content))
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// spdxID returns the SPDX element ID of the node with the given chain ID,
+// made of the characters SPDX allows and distinct from the IDs already used.
+func spdxID(nodeID string, used map[string]bool) string {
+	base := "SPDXRef-" + strings.Trim(spdxInvalidIDChars.ReplaceAllString(nodeID, "-"), "-")
+	id := base
+	for i := 2; used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[id] = true
+	return id
+}
@@ -0,0 +1,42 @@
+package describe
+
+import (
+	"fmt"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// MalformedImageChangeTriggerWarning is the key of the markers of the image
+// change triggers that can't follow an image stream tag.
+const MalformedImageChangeTriggerWarning = "MalformedImageChangeTrigger"
+
+// FindMalformedImageChangeTriggers checks all build configs for image change
+// triggers that can't follow an image stream tag and never start a build,
+// the triggers build-chain leaves out of the chains.
+func FindMalformedImageChangeTriggers(g osgraph.Graph, f osgraph.Namer) []osgraph.Marker {
+	markers := []osgraph.Marker{}
+
+	for _, uncastBcNode := range g.NodesByKind(buildgraph.BuildConfigNodeKind) {
+		bcNode := uncastBcNode.(*buildgraph.BuildConfigNode)
+		for _, trigger := range bcNode.BuildConfig.Spec.Triggers {
+			if trigger.Type != buildv1.ImageChangeBuildTriggerType {
+				continue
+			}
+			reason := malformedTriggerReason(bcNode.BuildConfig, trigger)
+			if len(reason) == 0 {
+				continue
+			}
+			markers = append(markers, osgraph.Marker{
+				Node: bcNode,
+
+				Severity: osgraph.WarningSeverity,
+				Key:      MalformedImageChangeTriggerWarning,
+				Message:  fmt.Sprintf("%s %s, so it never starts a build.", f.ResourceName(bcNode), reason),
+			})
+		}
+	}
+
+	return markers
+}
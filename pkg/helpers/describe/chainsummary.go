@@ -0,0 +1,123 @@
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gonum/graph"
+
+	appsgraph "github.com/openshift/oc/pkg/helpers/graph/appsgraph/nodes"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// summaryOutput returns metrics of the complexity of g: its number of nodes
+// by kind and of edges, its maximum depth from the roots, the fan-out of its
+// image stream tags or image streams, the number of configs built or deployed
+// from each of them, the widest first, and the number of its warnings by code.
+func (d *ChainDescriber) summaryOutput(g osgraph.Graph, roots []graph.Node, name string, namer osgraph.Namer, anon anonymizer, reverse bool) string {
+	counts := map[string]int{}
+	fanOut := []graph.Node{}
+	for _, node := range g.Nodes() {
+		switch node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			counts["image stream tag"]++
+			fanOut = append(fanOut, node)
+		case *imagegraph.ImageStreamNode:
+			counts["image stream"]++
+			fanOut = append(fanOut, node)
+		case *buildgraph.BuildConfigNode:
+			counts["build config"]++
+		case *appsgraph.DeploymentConfigNode:
+			counts["deployment config"]++
+		case *imagegraph.DockerImageRepositoryNode:
+			counts["external image"]++
+		}
+	}
+	kinds := []string{}
+	for _, kind := range []string{"image stream tag", "image stream", "build config", "deployment config", "external image"} {
+		if counts[kind] > 0 {
+			kinds = append(kinds, plural(counts[kind], kind))
+		}
+	}
+	labels := map[int]string{}
+	for _, node := range fanOut {
+		labels[node.ID()] = d.asciiLabel(node, namer, anon)
+	}
+	sort.Slice(fanOut, func(i, j int) bool {
+		if a, b := len(g.From(fanOut[i])), len(g.From(fanOut[j])); a != b {
+			return a > b
+		}
+		return labels[fanOut[i].ID()] < labels[fanOut[j].ID()]
+	})
+
+	out := &bytes.Buffer{}
+	fmt.Fprintf(out, "Summary of the build chain of %s:\n", name)
+	w := tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+	fmt.Fprintf(w, "  Nodes:\t%d (%s)\n", len(g.Nodes()), strings.Join(kinds, ", "))
+	fmt.Fprintf(w, "  Edges:\t%d\n", len(g.Edges()))
+	fmt.Fprintf(w, "  Maximum depth:\t%d\n", maxDepth(g, roots, reverse))
+	w.Flush()
+	if len(fanOut) > 0 {
+		fmt.Fprintf(out, "\nFan-out:\n")
+		w = tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+		for _, node := range fanOut {
+			fmt.Fprintf(w, "  %s\t%d\n", labels[node.ID()], len(g.From(node)))
+		}
+		w.Flush()
+	}
+	if codes, counts := warningCounts(d.chainWarnings(g, namer, anon)); len(codes) > 0 {
+		fmt.Fprintf(out, "\nWarnings:\n")
+		w = tabwriter.NewWriter(out, 0, 2, 2, ' ', 0)
+		for _, code := range codes {
+			fmt.Fprintf(w, "  %s\t%d\n", code, counts[code])
+		}
+		w.Flush()
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// maxDepth returns the number of edges between the roots of g and its
+// farthest node, following the edges backwards when reverse is set, as in
+// the human-readable tree.
+func maxDepth(g osgraph.Graph, roots []graph.Node, reverse bool) int {
+	next := g.From
+	if reverse {
+		next = g.To
+	}
+	depth := map[int]int{}
+	queue := []graph.Node{}
+	for _, root := range roots {
+		if _, ok := depth[root.ID()]; !ok {
+			depth[root.ID()] = 0
+			queue = append(queue, root)
+		}
+	}
+	max := 0
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if depth[node.ID()] > max {
+			max = depth[node.ID()]
+		}
+		for _, n := range next(node) {
+			if _, ok := depth[n.ID()]; !ok {
+				depth[n.ID()] = depth[node.ID()] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return max
+}
+
+// plural returns count followed by noun, pluralized unless count is one.
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
@@ -0,0 +1,55 @@
+package describe
+
+import (
+	"github.com/gonum/graph"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// hideTags returns g with the image stream tags of every image stream merged
+// into a node of their image stream, along with the nodes roots were merged
+// into. The dependencies going through the tags of a stream are merged as
+// well, so that the chain is described at the repository level.
+func hideTags(g osgraph.Graph, roots []graph.Node) (osgraph.Graph, []graph.Node) {
+	out := osgraph.New()
+	for _, node := range g.Nodes() {
+		if _, ok := node.(*imagegraph.ImageStreamTagNode); !ok {
+			out.AddNode(node)
+		}
+	}
+	streams := map[int]graph.Node{}
+	for _, node := range g.Nodes() {
+		ist, ok := node.(*imagegraph.ImageStreamTagNode)
+		if !ok {
+			continue
+		}
+		name, _, _ := imageutil.SplitImageStreamTag(ist.Name)
+		streams[ist.ID()] = imagegraph.FindOrCreateSyntheticImageStreamNode(out, &imagev1.ImageStream{ObjectMeta: metav1.ObjectMeta{Namespace: ist.Namespace, Name: name}})
+	}
+	merged := func(node graph.Node) graph.Node {
+		if stream, ok := streams[node.ID()]; ok {
+			return stream
+		}
+		return node
+	}
+
+	for _, e := range g.Edges() {
+		for _, kind := range g.EdgeKinds(e).List() {
+			out.AddEdge(merged(e.From()), merged(e.To()), kind)
+		}
+	}
+	mergedRoots := []graph.Node{}
+	seen := map[int]bool{}
+	for _, root := range roots {
+		if root = merged(root); !seen[root.ID()] {
+			seen[root.ID()] = true
+			mergedRoots = append(mergedRoots, root)
+		}
+	}
+	return out, mergedRoots
+}
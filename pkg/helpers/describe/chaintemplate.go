@@ -0,0 +1,158 @@
+package describe
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	buildv1 "github.com/openshift/api/build/v1"
+	imagev1 "github.com/openshift/api/image/v1"
+	templatev1 "github.com/openshift/api/template/v1"
+	"github.com/openshift/library-go/pkg/build/buildutil"
+	"github.com/openshift/library-go/pkg/image/imageutil"
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+)
+
+// templateInvalidNameChars matches the characters the names of templates and
+// of their parameters can't be made of.
+var templateInvalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// templateOutput returns g as a template, in JSON, of its build configs and
+// of the image streams of their namespaces it refers to. The namespaces of
+// the build configs are parameters of the template, defaulting to their
+// current value, so that processing it replicates the pipeline into other
+// projects. The image streams are created empty, the images of the image
+// stream tags no build config of the chain pushes to have to be imported
+// again.
+func templateOutput(g osgraph.Graph, name string) (string, error) {
+	bcs := []*buildv1.BuildConfig{}
+	namespaces := sets.NewString()
+	for _, node := range g.Nodes() {
+		if bc, ok := node.(*buildgraph.BuildConfigNode); ok {
+			bcs = append(bcs, bc.BuildConfig)
+			namespaces.Insert(bc.BuildConfig.Namespace)
+		}
+	}
+	sort.Slice(bcs, func(i, j int) bool {
+		if bcs[i].Namespace != bcs[j].Namespace {
+			return bcs[i].Namespace < bcs[j].Namespace
+		}
+		return bcs[i].Name < bcs[j].Name
+	})
+	parameters := map[string]string{}
+	for _, namespace := range namespaces.List() {
+		parameters[namespace] = templateParameter(namespace, namespaces.Len())
+	}
+
+	streams := map[string]sets.String{}
+	for _, node := range g.Nodes() {
+		var namespace, stream string
+		switch t := node.(type) {
+		case *imagegraph.ImageStreamTagNode:
+			namespace = t.Namespace
+			stream, _, _ = imageutil.SplitImageStreamTag(t.Name)
+		case *imagegraph.ImageStreamNode:
+			namespace, stream = t.Namespace, t.Name
+		default:
+			continue
+		}
+		if _, ok := parameters[namespace]; !ok {
+			continue
+		}
+		if streams[namespace] == nil {
+			streams[namespace] = sets.NewString()
+		}
+		streams[namespace].Insert(stream)
+	}
+
+	template := &templatev1.Template{
+		TypeMeta:   metav1.TypeMeta{APIVersion: templatev1.GroupVersion.String(), Kind: "Template"},
+		ObjectMeta: metav1.ObjectMeta{Name: templateName(name)},
+		Message:    fmt.Sprintf("The build chain of %s was created.", name),
+		Objects:    []runtime.RawExtension{},
+		Parameters: []templatev1.Parameter{},
+	}
+	for _, namespace := range namespaces.List() {
+		template.Parameters = append(template.Parameters, templatev1.Parameter{
+			Name:        parameters[namespace],
+			DisplayName: "Namespace",
+			Description: fmt.Sprintf("The namespace the objects of %s are created in.", namespace),
+			Value:       namespace,
+			Required:    true,
+		})
+		for _, stream := range streams[namespace].List() {
+			template.Objects = append(template.Objects, runtime.RawExtension{Object: &imagev1.ImageStream{
+				TypeMeta:   metav1.TypeMeta{APIVersion: imagev1.GroupVersion.String(), Kind: "ImageStream"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "${" + parameters[namespace] + "}", Name: stream},
+			}})
+		}
+	}
+	for _, bc := range bcs {
+		template.Objects = append(template.Objects, runtime.RawExtension{Object: templateBuildConfig(bc, parameters)})
+	}
+
+	data, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// templateBuildConfig returns a copy of bc without its status and server set
+// metadata, whose namespace and references to the image streams of the
+// namespaces of parameters are replaced with these parameters.
+func templateBuildConfig(bc *buildv1.BuildConfig, parameters map[string]string) *buildv1.BuildConfig {
+	out := &buildv1.BuildConfig{
+		TypeMeta: metav1.TypeMeta{APIVersion: buildv1.GroupVersion.String(), Kind: "BuildConfig"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "${" + parameters[bc.Namespace] + "}",
+			Name:        bc.Name,
+			Labels:      bc.Labels,
+			Annotations: bc.Annotations,
+		},
+		Spec: *bc.Spec.DeepCopy(),
+	}
+
+	refs := []*corev1.ObjectReference{out.Spec.Output.To, buildutil.GetInputReference(out.Spec.Strategy)}
+	for i := range out.Spec.Source.Images {
+		refs = append(refs, &out.Spec.Source.Images[i].From)
+	}
+	for _, trigger := range out.Spec.Triggers {
+		if trigger.ImageChange != nil {
+			trigger.ImageChange.LastTriggeredImageID = ""
+			refs = append(refs, trigger.ImageChange.From)
+		}
+	}
+	for _, ref := range refs {
+		if ref == nil || (ref.Kind != "ImageStreamTag" && ref.Kind != "ImageStreamImage" && ref.Kind != "ImageStream") {
+			continue
+		}
+		if parameter, ok := parameters[ref.Namespace]; ok {
+			ref.Namespace = "${" + parameter + "}"
+		}
+	}
+	return out
+}
+
+// templateParameter returns the name of the parameter of the template for
+// namespace, NAMESPACE when the chain is in a single namespace.
+func templateParameter(namespace string, namespaces int) string {
+	if namespaces == 1 {
+		return "NAMESPACE"
+	}
+	return "NAMESPACE_" + strings.ToUpper(strings.Trim(templateInvalidNameChars.ReplaceAllString(namespace, "_"), "_"))
+}
+
+// templateName returns the name of the template of the chain called name.
+func templateName(name string) string {
+	return strings.Trim(templateInvalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-") + "-build-chain"
+}
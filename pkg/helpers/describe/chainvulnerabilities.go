@@ -0,0 +1,204 @@
+package describe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonum/graph"
+
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+	imagegraph "github.com/openshift/oc/pkg/helpers/graph/imagegraph/nodes"
+	"github.com/openshift/oc/pkg/helpers/parallel"
+)
+
+// Severities are the severities of vulnerabilities, from the most severe.
+var Severities = []string{"critical", "high", "medium", "low"}
+
+// VulnerabilityCounts are the numbers of known vulnerabilities of an image
+// by severity.
+type VulnerabilityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+}
+
+// AtLeast returns the number of vulnerabilities of c of severity or worse.
+func (c VulnerabilityCounts) AtLeast(severity string) int {
+	counts := []int{c.Critical, c.High, c.Medium, c.Low}
+	total := 0
+	for i, s := range Severities {
+		total += counts[i]
+		if s == severity {
+			break
+		}
+	}
+	return total
+}
+
+// String returns the non-zero counts of c, from the most severe, or none.
+func (c VulnerabilityCounts) String() string {
+	parts := []string{}
+	for i, count := range []int{c.Critical, c.High, c.Medium, c.Low} {
+		if count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count, Severities[i]))
+		}
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// VulnerabilityScanner reports the known vulnerabilities of the images image
+// stream tags point to, from an image scanner. It may be called from several
+// goroutines at once.
+type VulnerabilityScanner interface {
+	// ScanImageStreamTag returns the vulnerabilities of the image of the
+	// image stream tag name in namespace, nil when it wasn't scanned.
+	ScanImageStreamTag(ctx context.Context, namespace, name string) (*VulnerabilityCounts, error)
+}
+
+// VulnerableImage is an image stream tag of a build chain whose image has
+// known vulnerabilities.
+type VulnerableImage struct {
+	// ID is the ID of the image stream tag, as in the json output.
+	ID              string
+	Vulnerabilities VulnerabilityCounts
+	// Dependents is the number of build configs of the chain built, directly
+	// or not, from the image stream tag, which are rebuilt once it is fixed.
+	Dependents int
+}
+
+// scanVulnerabilities scans the image stream tags of g that weren't scanned
+// yet with the Scanner of d, and returns the ones with vulnerabilities in the
+// order to fix them. Scans are cached so that they can be shared by the
+// chains of a describer.
+func (d *ChainDescriber) scanVulnerabilities(g osgraph.Graph, anon anonymizer) ([]VulnerableImage, error) {
+	if d.Scanner == nil {
+		return nil, nil
+	}
+	ists := []*imagegraph.ImageStreamTagNode{}
+	d.scannedLock.Lock()
+	if d.scanned == nil {
+		d.scanned = map[osgraph.UniqueName]*VulnerabilityCounts{}
+	}
+	for _, node := range g.Nodes() {
+		if ist, ok := node.(*imagegraph.ImageStreamTagNode); ok {
+			if _, ok := d.scanned[ist.UniqueName()]; !ok {
+				ists = append(ists, ist)
+			}
+		}
+	}
+	d.scannedLock.Unlock()
+
+	scans := []func() error{}
+	for _, ist := range ists {
+		ist := ist
+		scans = append(scans, func() error {
+			counts, err := d.Scanner.ScanImageStreamTag(context.TODO(), ist.Namespace, ist.Name)
+			if err != nil {
+				return fmt.Errorf("unable to scan %s/%s: %v", ist.Namespace, ist.Name, err)
+			}
+			d.scannedLock.Lock()
+			defer d.scannedLock.Unlock()
+			d.scanned[ist.UniqueName()] = counts
+			return nil
+		})
+	}
+	if errs := parallel.RunLimited(d.Concurrency, scans...); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	vulnerable := []VulnerableImage{}
+	for _, node := range g.Nodes() {
+		counts := d.vulnerabilitiesOf(node)
+		if counts == nil || counts.AtLeast("low") == 0 {
+			continue
+		}
+		vulnerable = append(vulnerable, VulnerableImage{ID: anon.nodeID(node), Vulnerabilities: *counts, Dependents: dependentBuildConfigs(g, node)})
+	}
+	SortVulnerableImages(vulnerable)
+	return vulnerable, nil
+}
+
+// SortVulnerableImages sorts images in the order to fix them: the most
+// critical first and, among equals, those with the most dependents first.
+func SortVulnerableImages(images []VulnerableImage) {
+	sort.SliceStable(images, func(i, j int) bool {
+		for _, severity := range Severities {
+			if a, b := images[i].Vulnerabilities.AtLeast(severity), images[j].Vulnerabilities.AtLeast(severity); a != b {
+				return a > b
+			}
+		}
+		if images[i].Dependents != images[j].Dependents {
+			return images[i].Dependents > images[j].Dependents
+		}
+		return images[i].ID < images[j].ID
+	})
+}
+
+// addVulnerabilities sets the vulnerabilities of the nodes of out, the
+// machine readable form of g.
+func (d *ChainDescriber) addVulnerabilities(out *ChainOutput, g osgraph.Graph, anon anonymizer) {
+	if d.Scanner == nil {
+		return
+	}
+	byID := map[string]*VulnerabilityCounts{}
+	for _, node := range g.Nodes() {
+		if counts := d.vulnerabilitiesOf(node); counts != nil {
+			byID[anon.nodeID(node)] = counts
+		}
+	}
+	for i := range out.Nodes {
+		out.Nodes[i].Vulnerabilities = byID[out.Nodes[i].ID]
+	}
+}
+
+// vulnerabilitiesOf returns the vulnerabilities of the image of node, nil
+// when it isn't an image stream tag or wasn't scanned.
+func (d *ChainDescriber) vulnerabilitiesOf(node graph.Node) *VulnerabilityCounts {
+	ist, ok := node.(*imagegraph.ImageStreamTagNode)
+	if !ok || d.Scanner == nil {
+		return nil
+	}
+	d.scannedLock.Lock()
+	defer d.scannedLock.Unlock()
+	return d.scanned[ist.UniqueName()]
+}
+
+// vulnerabilitiesSuffix returns the vulnerabilities of the image of node as a
+// suffix of its label in the human-readable and ascii outputs.
+func (d *ChainDescriber) vulnerabilitiesSuffix(node graph.Node) string {
+	counts := d.vulnerabilitiesOf(node)
+	if counts == nil {
+		return ""
+	}
+	return " [vulnerabilities: " + counts.String() + "]"
+}
+
+// dependentBuildConfigs returns the number of build configs reachable from
+// node in g.
+func dependentBuildConfigs(g osgraph.Graph, node graph.Node) int {
+	count := 0
+	seen := map[int]bool{node.ID(): true}
+	queue := []graph.Node{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range g.From(current) {
+			if seen[next.ID()] {
+				continue
+			}
+			seen[next.ID()] = true
+			if _, ok := next.(*buildgraph.BuildConfigNode); ok {
+				count++
+			}
+			queue = append(queue, next)
+		}
+	}
+	return count
+}
@@ -0,0 +1,112 @@
+package describe
+
+import (
+	"fmt"
+	"sort"
+
+	buildgraph "github.com/openshift/oc/pkg/helpers/graph/buildgraph/nodes"
+	osgraph "github.com/openshift/oc/pkg/helpers/graph/genericgraph"
+)
+
+// WarningCode identifies the kind of a problem found while describing build
+// chains. Codes are stable so that automation can route the problems to the
+// teams owning them, unlike the messages which may be reworded.
+type WarningCode string
+
+const (
+	// WarningCycleDetected is reported for the cycles between the build
+	// configs of a chain, which can't all be rebuilt in order.
+	WarningCycleDetected WarningCode = "CYCLE_DETECTED"
+	// WarningMissingOutputStream is reported for the build configs pushing
+	// to an image stream tag whose image stream doesn't exist.
+	WarningMissingOutputStream WarningCode = "MISSING_OUTPUT_STREAM"
+	// WarningMalformedTrigger is reported for the image change triggers that
+	// can't follow an image stream tag, which are left out of the chains.
+	WarningMalformedTrigger WarningCode = "MALFORMED_TRIGGER"
+	// WarningPermissionDeniedNamespace is reported for the namespaces left
+	// out of the chains because their build configs can't be listed.
+	WarningPermissionDeniedNamespace WarningCode = "PERMISSION_DENIED_NAMESPACE"
+	// WarningNamespaceUnavailable is reported for the namespaces left out of
+	// the chains because of any other error.
+	WarningNamespaceUnavailable WarningCode = "NAMESPACE_UNAVAILABLE"
+	// WarningMissingTag is reported for the references to image stream tags
+	// without a tag, which are assumed to be to latest.
+	WarningMissingTag WarningCode = "MISSING_TAG"
+)
+
+// ChainWarning is a problem found while describing build chains.
+type ChainWarning struct {
+	Code WarningCode `json:"code"`
+	// Namespace is the namespace the problem was found in, if any.
+	Namespace string `json:"namespace,omitempty"`
+	// BuildConfig is the name of the build config the problem was found in,
+	// if any.
+	BuildConfig string `json:"buildConfig,omitempty"`
+	// Message describes the problem. It is left out of anonymized output
+	// unless it only refers to anonymized names.
+	Message string `json:"message,omitempty"`
+	// Cluster is the kubeconfig context of the cluster the problem was found
+	// in, when merging the chains of several clusters.
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// buildConfigWarning returns a warning about the build config name of
+// namespace.
+func buildConfigWarning(code WarningCode, namespace, name, format string, args ...interface{}) ChainWarning {
+	return ChainWarning{Code: code, Namespace: namespace, BuildConfig: name, Message: fmt.Sprintf(format, args...)}
+}
+
+// chainWarnings returns the warnings about g: the cycles between its build
+// configs and the warnings found while loading that are about its build
+// configs or about a whole namespace. Warnings are sorted by code, namespace
+// and build config so that the output is stable across runs.
+func (d *ChainDescriber) chainWarnings(g osgraph.Graph, namer osgraph.Namer, anon anonymizer) []ChainWarning {
+	bcs := map[string]bool{}
+	for _, node := range g.Nodes() {
+		if bc, ok := node.(*buildgraph.BuildConfigNode); ok {
+			bcs[bc.BuildConfig.Namespace+"/"+bc.BuildConfig.Name] = true
+		}
+	}
+	warnings := []ChainWarning{}
+	for _, warning := range d.warnings {
+		if len(warning.BuildConfig) > 0 && !bcs[warning.Namespace+"/"+warning.BuildConfig] {
+			continue
+		}
+		if anon.enabled {
+			warning.Namespace = anon.namespace(warning.Namespace)
+			if len(warning.BuildConfig) > 0 {
+				warning.BuildConfig = anon.buildConfigName(warning.BuildConfig)
+			}
+			warning.Message = ""
+		}
+		warnings = append(warnings, warning)
+	}
+	for _, message := range cycleMessages(g, namer) {
+		warnings = append(warnings, ChainWarning{Code: WarningCycleDetected, Message: message})
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		a, b := warnings[i], warnings[j]
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.BuildConfig < b.BuildConfig
+	})
+	return warnings
+}
+
+// warningCounts returns the number of warnings by code, sorted by code.
+func warningCounts(warnings []ChainWarning) ([]WarningCode, map[WarningCode]int) {
+	codes := []WarningCode{}
+	counts := map[WarningCode]int{}
+	for _, warning := range warnings {
+		if counts[warning.Code] == 0 {
+			codes = append(codes, warning.Code)
+		}
+		counts[warning.Code]++
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes, counts
+}
@@ -361,6 +361,9 @@ func formatImageStreamTags(out *tabwriter.Writer, stream *imagev1.ImageStream) {
 		if insecure {
 			fmt.Fprintf(out, "    will use insecure HTTPS or HTTP connections\n")
 		}
+		if hasSpecTag && tagRef.From != nil && !tagRef.Reference {
+			fmt.Fprintf(out, "    import policy: scheduled=%t insecure=%t\n", scheduled, insecure)
+		}
 		switch tagRef.ReferencePolicy.Type {
 		case imagev1.LocalTagReferencePolicy:
 			fmt.Fprintf(out, "    prefer registry pullthrough when referencing this tag\n")
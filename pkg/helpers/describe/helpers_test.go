@@ -141,6 +141,7 @@ func TestFormatImageStreamTags(t *testing.T) {
 		"tagged from foo/bar:latest",
 		"tagged from mysql/latest@sha256:e52c65",
 		"updates automatically from registry mysql",
+		"import policy: scheduled=true insecure=false",
 		"reference to registry mysql:2",
 		"prefer registry pullthrough when referencing this tag",
 		"~ importing latest image ...",
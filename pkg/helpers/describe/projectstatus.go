@@ -621,6 +621,7 @@ func getMarkerScanners(logsCommandName, securityPolicyCommandFormat, setProbeCom
 		appsanalysis.FindDeploymentConfigTriggerErrors,
 		appsanalysis.FindPersistentVolumeClaimWarnings,
 		buildanalysis.FindMissingInputImageStreams,
+		FindMalformedImageChangeTriggers,
 		func(g osgraph.Graph, f osgraph.Namer) []osgraph.Marker {
 			return appsanalysis.FindDeploymentConfigReadinessWarnings(g, f, setProbeCommandName)
 		},
@@ -264,6 +264,19 @@ func TestProjectStatus(t *testing.T) {
 				"-> istag/ruby-hello-world:latest",
 			},
 		},
+		"malformed image change trigger": {
+			File: "broken-trigger.yaml",
+			Extra: []runtime.Object{
+				&projectv1.Project{
+					ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: ""},
+				},
+			},
+			ErrFn: func(err error) bool { return err == nil },
+			Contains: []string{
+				"* bc/ruby-hello-world is pushing to istag/ruby-hello-world:latest, but the image stream for that tag does not exist.",
+				"* bc/ruby-hello-world has an image change trigger on DockerImage \"docker.io/centos/ruby-25-centos7:latest\", which isn't an image stream tag, so it never starts a build.",
+			},
+		},
 		"running build": {
 			File: "new-project-one-build.yaml",
 			Extra: []runtime.Object{
@@ -61,6 +61,16 @@ func AddTriggerDeploymentConfigsEdges(g osgraph.MutableUniqueGraph, node *appsgr
 }
 
 func AddAllTriggerDeploymentConfigsEdges(g osgraph.MutableUniqueGraph) {
+	DeploymentTriggerProvider{}.AddEdges(g)
+}
+
+// DeploymentTriggerProvider links every DeploymentConfig of a graph to the
+// images of its containers, through the image stream tags that trigger it
+// when it has an image change trigger for them.
+type DeploymentTriggerProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (DeploymentTriggerProvider) AddEdges(g osgraph.MutableUniqueGraph) {
 	for _, node := range g.(graph.Graph).Nodes() {
 		if dcNode, ok := node.(*appsgraph.DeploymentConfigNode); ok {
 			AddTriggerDeploymentConfigsEdges(g, dcNode)
@@ -53,11 +53,7 @@ func AddBuildEdges(g osgraph.MutableUniqueGraph, node *buildgraph.BuildConfigNod
 
 // AddAllBuildEdges adds build edges to all BuildConfig nodes in the given graph
 func AddAllBuildEdges(g osgraph.MutableUniqueGraph) {
-	for _, node := range g.(graph.Graph).Nodes() {
-		if bcNode, ok := node.(*buildgraph.BuildConfigNode); ok {
-			AddBuildEdges(g, bcNode)
-		}
-	}
+	BuildProvider{}.AddEdges(g)
 }
 
 func imageRefNode(g osgraph.MutableUniqueGraph, ref *corev1.ObjectReference, bc *buildv1.BuildConfig) graph.Node {
@@ -126,9 +122,61 @@ func AddInputOutputEdges(g osgraph.MutableUniqueGraph, node *buildgraph.BuildCon
 
 // AddAllInputOutputEdges adds input and output edges for all BuildConfigs in the given graph
 func AddAllInputOutputEdges(g osgraph.MutableUniqueGraph) {
+	InputOutputProvider{}.AddEdges(g)
+}
+
+// StrategyFromProvider links every BuildConfig of a graph to the image its
+// strategy builds from and to its source repository.
+type StrategyFromProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (StrategyFromProvider) AddEdges(g osgraph.MutableUniqueGraph) {
+	eachBuildConfig(g, AddInputEdges)
+}
+
+// ImageChangeTriggerProvider links every BuildConfig of a graph to the images
+// whose changes trigger its builds.
+type ImageChangeTriggerProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (ImageChangeTriggerProvider) AddEdges(g osgraph.MutableUniqueGraph) {
+	eachBuildConfig(g, AddTriggerEdges)
+}
+
+// BuildOutputProvider links every BuildConfig of a graph to the image it
+// pushes its builds to.
+type BuildOutputProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (BuildOutputProvider) AddEdges(g osgraph.MutableUniqueGraph) {
+	eachBuildConfig(g, AddOutputEdges)
+}
+
+// InputOutputProvider adds the edges of StrategyFromProvider,
+// ImageChangeTriggerProvider and BuildOutputProvider one BuildConfig at a
+// time, which keeps the synthetic nodes created in the order of the
+// BuildConfigs they are found from.
+type InputOutputProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (InputOutputProvider) AddEdges(g osgraph.MutableUniqueGraph) {
+	eachBuildConfig(g, func(g osgraph.MutableUniqueGraph, node *buildgraph.BuildConfigNode) {
+		AddInputOutputEdges(g, node)
+	})
+}
+
+// BuildProvider links every BuildConfig of a graph to the Builds it owns.
+type BuildProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (BuildProvider) AddEdges(g osgraph.MutableUniqueGraph) {
+	eachBuildConfig(g, AddBuildEdges)
+}
+
+func eachBuildConfig(g osgraph.MutableUniqueGraph, fn func(osgraph.MutableUniqueGraph, *buildgraph.BuildConfigNode)) {
 	for _, node := range g.(graph.Graph).Nodes() {
 		if bcNode, ok := node.(*buildgraph.BuildConfigNode); ok {
-			AddInputOutputEdges(g, bcNode)
+			fn(g, bcNode)
 		}
 	}
 }
@@ -2,10 +2,13 @@ package buildgraph
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/gonum/graph"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -57,6 +60,45 @@ func TestNamespaceEdgeMatching(t *testing.T) {
 	}
 }
 
+func TestEdgeProviders(t *testing.T) {
+	bc := &buildv1.BuildConfig{}
+	bc.Namespace = "ns"
+	bc.Name = "the-bc"
+	bc.Spec.Strategy.DockerStrategy = &buildv1.DockerBuildStrategy{From: &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "base:latest"}}
+	bc.Spec.Output.To = &corev1.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}
+	bc.Spec.Triggers = []buildv1.BuildTriggerPolicy{{Type: buildv1.ImageChangeBuildTriggerType, ImageChange: &buildv1.ImageChangeTrigger{}}}
+
+	tests := []struct {
+		name      string
+		providers []osgraph.EdgeProvider
+		expected  []string
+	}{
+		{name: "strategy from", providers: []osgraph.EdgeProvider{StrategyFromProvider{}}, expected: []string{BuildInputImageEdgeKind}},
+		{name: "image change trigger", providers: []osgraph.EdgeProvider{ImageChangeTriggerProvider{}}, expected: []string{BuildTriggerImageEdgeKind}},
+		{name: "output", providers: []osgraph.EdgeProvider{BuildOutputProvider{}}, expected: []string{BuildOutputEdgeKind}},
+		{
+			name:      "input and output",
+			providers: []osgraph.EdgeProvider{InputOutputProvider{}},
+			expected:  []string{BuildInputImageEdgeKind, BuildOutputEdgeKind, BuildTriggerImageEdgeKind},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			g := osgraph.New()
+			nodes.EnsureBuildConfigNode(g, bc)
+			osgraph.AddEdges(g, tc.providers...)
+			kinds := []string{}
+			for _, edge := range g.Edges() {
+				kinds = append(kinds, g.EdgeKinds(edge).List()...)
+			}
+			sort.Strings(kinds)
+			if !reflect.DeepEqual(kinds, tc.expected) {
+				t.Errorf("expected edges %v, got %v", tc.expected, kinds)
+			}
+		})
+	}
+}
+
 func namespaceFor(node graph.Node) (string, error) {
 	obj := node.(objectifier).Object()
 	switch t := obj.(type) {
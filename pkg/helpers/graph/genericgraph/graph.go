@@ -77,6 +77,30 @@ type MutableUniqueGraph interface {
 	NodeFinder
 }
 
+// EdgeProvider adds the edges of one kind of relationship between the nodes
+// of a graph, creating the synthetic nodes they point to when needed. Graph
+// commands build their graphs from a list of providers so that new kinds of
+// relationships can be added without changing how the graphs are traversed.
+type EdgeProvider interface {
+	AddEdges(g MutableUniqueGraph)
+}
+
+// EdgeProviderFunc adapts a function adding edges to a graph, such as the
+// AddAll functions of the graph packages, to an EdgeProvider.
+type EdgeProviderFunc func(g MutableUniqueGraph)
+
+// AddEdges calls f(g).
+func (f EdgeProviderFunc) AddEdges(g MutableUniqueGraph) {
+	f(g)
+}
+
+// AddEdges adds the edges of every provider to g, in order.
+func AddEdges(g MutableUniqueGraph, providers ...EdgeProvider) {
+	for _, provider := range providers {
+		provider.AddEdges(g)
+	}
+}
+
 type Edge struct {
 	simple.Edge
 	kinds sets.String
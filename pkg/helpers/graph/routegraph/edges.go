@@ -37,6 +37,15 @@ func AddRouteEdges(g osgraph.MutableUniqueGraph, node *routegraph.RouteNode) {
 
 // AddAllRouteEdges adds service edges to all route nodes in the given graph
 func AddAllRouteEdges(g osgraph.MutableUniqueGraph) {
+	RouteProvider{}.AddEdges(g)
+}
+
+// RouteProvider links every route of a graph to the services it sends
+// traffic to.
+type RouteProvider struct{}
+
+// AddEdges implements osgraph.EdgeProvider.
+func (RouteProvider) AddEdges(g osgraph.MutableUniqueGraph) {
 	for _, node := range g.(graph.Graph).Nodes() {
 		if routeNode, ok := node.(*routegraph.RouteNode); ok {
 			AddRouteEdges(g, routeNode)
@@ -0,0 +1,113 @@
+package kubeconfig
+
+import (
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Context is the kubeconfig context the clients of a command are built from,
+// along with the cluster and the user they connect with, once --context,
+// --cluster and --user are applied.
+type Context struct {
+	// Name is the name of the context, empty when there is none.
+	Name    string
+	Cluster string
+	User    string
+}
+
+// contextResolver is implemented by the kubeconfig loaders knowing the
+// overrides their clients are built with.
+type contextResolver interface {
+	CurrentContext() (Context, error)
+}
+
+// CurrentContext returns the context the clients of getter are built from. The
+// raw config returned by the kubeconfig loaders ignores --context, --cluster
+// and --user, they are only applied when getter was wrapped with
+// NewContextClientGetter.
+func CurrentContext(getter genericclioptions.RESTClientGetter) (Context, error) {
+	loader := getter.ToRawKubeConfigLoader()
+	if resolver, ok := loader.(contextResolver); ok {
+		return resolver.CurrentContext()
+	}
+	config, err := loader.RawConfig()
+	if err != nil {
+		return Context{}, err
+	}
+	return resolveContext(config, "", "", ""), nil
+}
+
+// NewContextClientGetter returns a RESTClientGetter building its clients from
+// flags, whose kubeconfig loader resolves the context, cluster and user given
+// with flags for CurrentContext.
+func NewContextClientGetter(flags *genericclioptions.ConfigFlags) genericclioptions.RESTClientGetter {
+	return &contextClientGetter{ConfigFlags: flags}
+}
+
+type contextClientGetter struct {
+	*genericclioptions.ConfigFlags
+}
+
+func (g *contextClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return &contextClientConfig{loader: g.ConfigFlags.ToRawKubeConfigLoader(), flags: g.ConfigFlags}
+}
+
+// contextClientConfig is a kubeconfig loader resolving the context, cluster
+// and user overridden by flags.
+type contextClientConfig struct {
+	loader clientcmd.ClientConfig
+	flags  *genericclioptions.ConfigFlags
+}
+
+func (c *contextClientConfig) RawConfig() (clientcmdapi.Config, error) {
+	return c.loader.RawConfig()
+}
+
+func (c *contextClientConfig) ClientConfig() (*rest.Config, error) {
+	return c.loader.ClientConfig()
+}
+
+func (c *contextClientConfig) Namespace() (string, bool, error) {
+	return c.loader.Namespace()
+}
+
+func (c *contextClientConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return c.loader.ConfigAccess()
+}
+
+func (c *contextClientConfig) CurrentContext() (Context, error) {
+	config, err := c.RawConfig()
+	if err != nil {
+		return Context{}, err
+	}
+	return resolveContext(config, stringValue(c.flags.Context), stringValue(c.flags.ClusterName), stringValue(c.flags.AuthInfoName)), nil
+}
+
+// resolveContext returns the context of config, overridden by context, and
+// its cluster and user, overridden by cluster and user, the way the clients
+// built from config are.
+func resolveContext(config clientcmdapi.Config, context, cluster, user string) Context {
+	resolved := Context{Name: config.CurrentContext}
+	if len(context) > 0 {
+		resolved.Name = context
+	}
+	if current, ok := config.Contexts[resolved.Name]; ok {
+		resolved.Cluster, resolved.User = current.Cluster, current.AuthInfo
+	}
+	if len(cluster) > 0 {
+		resolved.Cluster = cluster
+	}
+	if len(user) > 0 {
+		resolved.User = user
+	}
+	return resolved
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
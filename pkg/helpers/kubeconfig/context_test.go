@@ -0,0 +1,77 @@
+package kubeconfig
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestCurrentContext(t *testing.T) {
+	config := clientcmdapi.NewConfig()
+	config.CurrentContext = "default"
+	config.Clusters["dev"] = &clientcmdapi.Cluster{Server: "https://dev.example.com:6443"}
+	config.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod.example.com:6443"}
+	config.AuthInfos["developer"] = &clientcmdapi.AuthInfo{Token: "developer"}
+	config.AuthInfos["admin"] = &clientcmdapi.AuthInfo{Token: "admin"}
+	config.Contexts["default"] = &clientcmdapi.Context{Cluster: "dev", AuthInfo: "developer"}
+	config.Contexts["other"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "admin"}
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected Context
+	}{
+		{name: "no override", expected: Context{Name: "default", Cluster: "dev", User: "developer"}},
+		{name: "context", args: []string{"--context=other"}, expected: Context{Name: "other", Cluster: "prod", User: "admin"}},
+		{name: "empty context", args: []string{"--context="}, expected: Context{Name: "default", Cluster: "dev", User: "developer"}},
+		{name: "cluster and user", args: []string{"--cluster=prod", "--user=admin"}, expected: Context{Name: "default", Cluster: "prod", User: "admin"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFlags := genericclioptions.NewConfigFlags(false)
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			configFlags.AddFlags(flags)
+			if err := flags.Parse(append([]string{"--kubeconfig=" + path}, tt.args...)); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := CurrentContext(NewContextClientGetter(configFlags))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %#v, got %#v", tt.expected, actual)
+			}
+
+			// the server of the clients comes from the same cluster
+			restConfig, err := configFlags.ToRESTConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cluster, ok := config.Clusters[actual.Cluster]; ok && restConfig.Host != cluster.Server {
+				t.Errorf("expected the clients to connect to %s, got %s", cluster.Server, restConfig.Host)
+			}
+		})
+	}
+
+	// a getter that wasn't wrapped only knows the current context of the kubeconfig
+	configFlags := genericclioptions.NewConfigFlags(false)
+	configFlags.KubeConfig = &path
+	other := "other"
+	configFlags.Context = &other
+	actual, err := CurrentContext(configFlags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Context{Name: "default", Cluster: "dev", User: "developer"}); actual != expected {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+}
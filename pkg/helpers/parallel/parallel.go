@@ -25,3 +25,23 @@ func Run(fns ...func() error) []error {
 	}
 	return errs
 }
+
+// RunLimited executes the provided functions with at most limit of them
+// running at once and collects any errors they return. A limit that isn't
+// positive runs them all at once, as Run does.
+func RunLimited(limit int, fns ...func() error) []error {
+	if limit <= 0 || limit >= len(fns) {
+		return Run(fns...)
+	}
+	sem := make(chan struct{}, limit)
+	limited := make([]func() error, len(fns))
+	for i := range fns {
+		fn := fns[i]
+		limited[i] = func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			return fn()
+		}
+	}
+	return Run(limited...)
+}
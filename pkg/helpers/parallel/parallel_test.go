@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestRun(t *testing.T) {
@@ -37,3 +38,30 @@ func TestRun(t *testing.T) {
 		t.Error("unexpected run")
 	}
 }
+
+func TestRunLimited(t *testing.T) {
+	running, max := int32(0), int32(0)
+	fns := []func() error{}
+	for i := 0; i < 20; i++ {
+		fns = append(fns, func() error {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				seen := atomic.LoadInt32(&max)
+				if current <= seen || atomic.CompareAndSwapInt32(&max, seen, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+	}
+	fns = append(fns, func() error { return fmt.Errorf("an error") })
+	errs := RunLimited(3, fns...)
+	if len(errs) != 1 {
+		t.Errorf("expected a single error, got %v", errs)
+	}
+	if max > 3 {
+		t.Errorf("expected at most 3 functions running at once, got %d", max)
+	}
+}